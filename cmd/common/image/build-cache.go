@@ -0,0 +1,221 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package image
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Bump it when the way the outputs are built changes without it being
+// reflected in the inputs of the steps.
+const buildCacheVersion = "1"
+
+// buildCache is a content-addressed cache of the outputs of the build steps.
+// The outputs of a step are stored under the digest of its inputs and of the
+// inputs shared by all the steps, like the builder image and build.yaml.
+type buildCache struct {
+	dir string
+	// outputDir is the output directory of the build, as seen from the host
+	outputDir string
+	// common are the inputs shared by all the steps
+	common []string
+}
+
+func defaultBuildCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "inspektor-gadget", "build")
+}
+
+func newBuildCache(dir, outputDir string, buildFileContent []byte) (*buildCache, error) {
+	if dir == "" {
+		return nil, errors.New("no cache directory given")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	return &buildCache{
+		dir:       dir,
+		outputDir: outputDir,
+		common: []string{
+			"version " + buildCacheVersion,
+			"build.yaml " + digestInputs(string(buildFileContent)),
+		},
+	}, nil
+}
+
+// addInput adds an input shared by all the steps
+func (c *buildCache) addInput(input string) {
+	c.common = append(c.common, input)
+}
+
+func digestInputs(inputs ...string) string {
+	h := sha256.New()
+	for _, input := range inputs {
+		io.WriteString(h, input)
+		io.WriteString(h, "\n")
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// hostFilesInput returns the digests of the given files of the host as inputs.
+// Empty paths and missing files are skipped.
+func hostFilesInput(paths ...string) ([]string, error) {
+	var inputs []string
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+		inputs = append(inputs, fmt.Sprintf("file %s %s", path, digestInputs(string(content))))
+	}
+	return inputs, nil
+}
+
+// key returns the key of the outputs of step built from inputs
+func (c *buildCache) key(step buildStep, inputs []string) string {
+	all := append([]string{}, c.common...)
+	all = append(all, "step "+step.name)
+	for _, input := range inputs {
+		// New lines separate the inputs
+		all = append(all, strings.ReplaceAll(input, "\n", " "))
+	}
+	return digestInputs(all...)
+}
+
+func (c *buildCache) entryDir(key string) string {
+	return filepath.Join(c.dir, "steps", key[:2], key)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// restore copies the outputs stored under key to the output directory. It
+// returns false if they aren't in the cache.
+func (c *buildCache) restore(key string, outputs []string) (bool, error) {
+	entryDir := c.entryDir(key)
+	for _, output := range outputs {
+		if _, err := os.Stat(filepath.Join(entryDir, output)); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return false, nil
+			}
+			return false, err
+		}
+	}
+
+	for _, output := range outputs {
+		if err := copyFile(filepath.Join(entryDir, output), filepath.Join(c.outputDir, output)); err != nil {
+			return false, fmt.Errorf("restoring %s: %w", output, err)
+		}
+	}
+	return true, nil
+}
+
+// store copies the outputs of a step from the output directory to the cache.
+// The entry is created atomically so concurrent builds never see it partially
+// written.
+func (c *buildCache) store(key string, outputs []string) error {
+	entryDir := c.entryDir(key)
+	if err := os.MkdirAll(filepath.Dir(entryDir), 0o755); err != nil {
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp(filepath.Dir(entryDir), ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	for _, output := range outputs {
+		if err := copyFile(filepath.Join(c.outputDir, output), filepath.Join(tmpDir, output)); err != nil {
+			return fmt.Errorf("storing %s: %w", output, err)
+		}
+	}
+
+	if err := os.Rename(tmpDir, entryDir); err != nil {
+		// The entry could have been stored by another build in the meantime,
+		// its content is the same.
+		if _, statErr := os.Stat(entryDir); statErr != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run runs step unless its outputs are already in the cache, in which case it
+// only restores them.
+func (c *buildCache) run(runner commandRunner, step buildStep) error {
+	if step.inputs == nil {
+		return step.run(runner)
+	}
+
+	inputs, err := step.inputs(runner)
+	if err != nil {
+		return fmt.Errorf("computing inputs of %s: %w", step.name, err)
+	}
+	key := c.key(step, inputs)
+
+	ok, err := c.restore(key, step.outputs)
+	if err != nil {
+		return fmt.Errorf("restoring %s from cache: %w", step.name, err)
+	}
+	if ok {
+		fmt.Printf("Using cached %s outputs\n", step.name)
+		return nil
+	}
+
+	if err := step.run(runner); err != nil {
+		return err
+	}
+
+	if err := c.store(key, step.outputs); err != nil {
+		return fmt.Errorf("storing %s in cache: %w", step.name, err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package image
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStep(outputDir string, input *string, runs *int) buildStep {
+	return buildStep{
+		name:    "test",
+		outputs: []string{"out.o"},
+		inputs: func(runner commandRunner) ([]string, error) {
+			return []string{*input}, nil
+		},
+		run: func(runner commandRunner) error {
+			*runs++
+			return os.WriteFile(filepath.Join(outputDir, "out.o"), []byte(*input), 0o644)
+		},
+	}
+}
+
+func TestBuildCache(t *testing.T) {
+	cacheDir := t.TempDir()
+	input := "foo"
+	runs := 0
+
+	build := func() {
+		outputDir := t.TempDir()
+		cache, err := newBuildCache(cacheDir, outputDir, []byte("ebpfsource: program.bpf.c\n"))
+		require.NoError(t, err)
+		cache.addInput("builder sha256:1234")
+
+		require.NoError(t, runPipeline(&localRunner{}, []buildStep{newTestStep(outputDir, &input, &runs)}, cache))
+
+		out, err := os.ReadFile(filepath.Join(outputDir, "out.o"))
+		require.NoError(t, err)
+		assert.Equal(t, input, string(out))
+	}
+
+	build()
+	assert.Equal(t, 1, runs)
+
+	// Same inputs: the outputs come from the cache
+	build()
+	assert.Equal(t, 1, runs)
+
+	// Changed inputs: the step runs again
+	input = "bar"
+	build()
+	assert.Equal(t, 2, runs)
+}
+
+func TestBuildCacheKey(t *testing.T) {
+	cache, err := newBuildCache(t.TempDir(), t.TempDir(), []byte("wasm: go\n"))
+	require.NoError(t, err)
+
+	step := buildStep{name: "ebpf-amd64"}
+	key := cache.key(step, []string{"cmd clang", "file program.bpf.c 1234"})
+	assert.Equal(t, key, cache.key(step, []string{"cmd clang", "file program.bpf.c 1234"}))
+	assert.NotEqual(t, key, cache.key(step, []string{"cmd clang", "file program.bpf.c 5678"}))
+	assert.NotEqual(t, key, cache.key(buildStep{name: "ebpf-arm64"}, []string{"cmd clang", "file program.bpf.c 1234"}))
+
+	cache.addInput("builder sha256:1234")
+	assert.NotEqual(t, key, cache.key(step, []string{"cmd clang", "file program.bpf.c 1234"}))
+}
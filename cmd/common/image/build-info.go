@@ -0,0 +1,285 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package image
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/oci"
+)
+
+var (
+	clangVersionRe   = regexp.MustCompile(`clang version (\S+)`)
+	goVersionRe      = regexp.MustCompile(`go version go(\S+)`)
+	rustVersionRe    = regexp.MustCompile(`^(?:cargo|rustc) (\S+)`)
+	bpftoolVersionRe = regexp.MustCompile(`bpftool v(\S+)`)
+	llvmVersionRe    = regexp.MustCompile(`(?:clang|LLVM) version (\S+)`)
+)
+
+// Prints the module providing each package the Wasm module depends on, but the
+// main one. Replaced modules are printed with the version of the replacement,
+// that is empty for local directories.
+const goListModulesTemplate = `{{with .Module}}{{if not .Main}}` +
+	`{{.Path}} {{with .Replace}}{{.Version}}{{else}}{{.Version}}{{end}}` +
+	`{{end}}{{end}}`
+
+// Prints the Go files and the go.mod file of the packages the Wasm module
+// depends on that belong to the main module or to a module replaced by a local
+// directory. The other modules are identified by their version.
+const goListSourcesTemplate = `{{$pkg := .}}{{with .Module}}` +
+	`{{if or .Main (and .Replace (not .Replace.Version))}}` +
+	`{{range $pkg.GoFiles}}{{$pkg.Dir}}/{{.}}{{"\n"}}{{end}}{{.GoMod}}{{"\n"}}` +
+	`{{end}}{{end}}`
+
+// collectBuildInfo gathers the versions of the tools and the inputs used by the
+// build pipeline. It has to use the same runner as the pipeline to see the same
+// environment.
+func collectBuildInfo(runner commandRunner, opts buildOptions) (*oci.BuildInfo, error) {
+	info := &oci.BuildInfo{
+		Tools: map[string]string{},
+	}
+
+	var sources []string
+
+	if opts.ebpfSourcePath != "" {
+		sources = append(sources, opts.ebpfSourcePath)
+
+		v, err := toolVersion(runner, []string{"clang", "--version"}, clangVersionRe)
+		if err != nil {
+			return nil, fmt.Errorf("getting clang version: %w", err)
+		}
+		info.Tools["clang"] = v
+
+		headers, err := includedHeaders(runner, opts)
+		if err != nil {
+			return nil, err
+		}
+		info.Headers, err = digestFiles(runner, headers)
+		if err != nil {
+			return nil, fmt.Errorf("computing digest of headers: %w", err)
+		}
+	}
+
+	if opts.wasmSourcePath != "" {
+		sources = append(sources, opts.wasmSourcePath)
+
+		switch {
+		case strings.HasSuffix(opts.wasmSourcePath, ".go"):
+			v, err := toolVersion(runner, []string{"go", "version"}, goVersionRe)
+			if err != nil {
+				return nil, fmt.Errorf("getting go version: %w", err)
+			}
+			info.Tools["go"] = v
+
+			info.GoModules, err = goModules(runner, opts)
+			if err != nil {
+				return nil, err
+			}
+		case strings.HasSuffix(opts.wasmSourcePath, ".rs"):
+			for _, tool := range []string{"cargo", "rustc"} {
+				v, err := toolVersion(runner, []string{tool, "--version"}, rustVersionRe)
+				if err != nil {
+					return nil, fmt.Errorf("getting %s version: %w", tool, err)
+				}
+				info.Tools[tool] = v
+			}
+		}
+	}
+
+	if opts.btfgen {
+		v, err := toolVersion(runner, []string{"bpftool", "version"}, bpftoolVersionRe)
+		if err != nil {
+			return nil, fmt.Errorf("getting bpftool version: %w", err)
+		}
+		info.Tools["bpftool"] = v
+	}
+
+	var err error
+	info.Sources, err = digestFiles(runner, sources)
+	if err != nil {
+		return nil, fmt.Errorf("computing digest of sources: %w", err)
+	}
+
+	return info, nil
+}
+
+// toolVersion runs cmd and extracts the version of the tool from its output
+// using re. The first line of the output is used if re doesn't match.
+func toolVersion(runner commandRunner, cmd []string, re *regexp.Regexp) (string, error) {
+	out, _, err := runner.run(cmd, nil)
+	if err != nil {
+		return "", err
+	}
+	if m := re.FindStringSubmatch(out); m != nil {
+		return m[1], nil
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(out), "\n")
+	return line, nil
+}
+
+// includedHeaders returns the headers included by the eBPF program when built
+// for any of the supported architectures.
+func includedHeaders(runner commandRunner, opts buildOptions) ([]string, error) {
+	headers := map[string]struct{}{}
+
+	for _, arch := range []string{oci.ArchAmd64, oci.ArchArm64} {
+		archHeaders, err := archIncludedHeaders(runner, arch, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, header := range archHeaders {
+			headers[header] = struct{}{}
+		}
+	}
+
+	return slices.Sorted(maps.Keys(headers)), nil
+}
+
+// archIncludedHeaders returns the headers included by the eBPF program when
+// built for arch, sorted.
+func archIncludedHeaders(runner commandRunner, arch string, opts buildOptions) ([]string, error) {
+	includeFlags, err := clangIncludeFlags(arch, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := []string{"clang", "-target", "bpf", "-w", "-M"}
+	cmd = append(cmd, includeFlags...)
+	cmd = append(cmd, opts.ebpfSourcePath)
+
+	out, _, err := runner.run(cmd, nil)
+	if err != nil {
+		return nil, fmt.Errorf("listing headers for %s: %w", arch, err)
+	}
+
+	headers := map[string]struct{}{}
+	for _, dep := range parseMakeDeps(out) {
+		if dep != filepath.Clean(opts.ebpfSourcePath) {
+			headers[dep] = struct{}{}
+		}
+	}
+
+	return slices.Sorted(maps.Keys(headers)), nil
+}
+
+// parseMakeDeps returns the prerequisites of the make rule generated by
+// clang -M.
+func parseMakeDeps(rule string) []string {
+	_, prerequisites, ok := strings.Cut(rule, ":")
+	if !ok {
+		return nil
+	}
+	prerequisites = strings.ReplaceAll(prerequisites, "\\\n", " ")
+
+	deps := strings.Fields(prerequisites)
+	for i, dep := range deps {
+		deps[i] = filepath.Clean(dep)
+	}
+	return deps
+}
+
+// digestFiles computes the SHA-256 digest of the given files. sha256sum is used
+// to be able to access the files in the builder container too.
+func digestFiles(runner commandRunner, paths []string) ([]oci.BuildMaterial, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	cmd := append([]string{"sha256sum", "--"}, paths...)
+	out, _, err := runner.run(cmd, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	materials := make([]oci.BuildMaterial, 0, len(paths))
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		digest, path, ok := strings.Cut(line, "  ")
+		if !ok {
+			return nil, fmt.Errorf("unexpected sha256sum output: %q", line)
+		}
+		materials = append(materials, oci.BuildMaterial{Path: path, Digest: digest})
+	}
+	return materials, nil
+}
+
+// goModules returns the Go modules linked into the Wasm module.
+func goModules(runner commandRunner, opts buildOptions) ([]oci.GoModule, error) {
+	cmd := []string{
+		"go", "list",
+		"-C", filepath.Dir(opts.wasmSourcePath),
+		"-deps",
+		"-f", goListModulesTemplate,
+		filepath.Base(opts.wasmSourcePath),
+	}
+	out, _, err := runner.run(cmd, goWasmEnv)
+	if err != nil {
+		return nil, fmt.Errorf("listing go modules: %w", err)
+	}
+
+	return parseGoModules(out), nil
+}
+
+// goSourceFiles returns the local Go files the Wasm module is built from, see
+// goListSourcesTemplate.
+func goSourceFiles(runner commandRunner, opts buildOptions) ([]string, error) {
+	cmd := []string{
+		"go", "list",
+		"-C", filepath.Dir(opts.wasmSourcePath),
+		"-deps",
+		"-f", goListSourcesTemplate,
+		filepath.Base(opts.wasmSourcePath),
+	}
+	out, _, err := runner.run(cmd, goWasmEnv)
+	if err != nil {
+		return nil, fmt.Errorf("listing go source files: %w", err)
+	}
+
+	files := map[string]struct{}{}
+	for _, line := range strings.Split(out, "\n") {
+		// Only absolute paths are printed, skip the other lines, like the
+		// ones about downloading modules.
+		if filepath.IsAbs(line) {
+			files[line] = struct{}{}
+		}
+	}
+	return slices.Sorted(maps.Keys(files)), nil
+}
+
+func parseGoModules(out string) []oci.GoModule {
+	seen := map[oci.GoModule]struct{}{}
+	for _, line := range strings.Split(out, "\n") {
+		// Modules are printed as "path version". Other lines, like the ones
+		// about downloading modules, are ignored.
+		fields := strings.Fields(line)
+		if len(fields) == 0 || len(fields) > 2 {
+			continue
+		}
+		mod := oci.GoModule{Path: fields[0]}
+		if len(fields) == 2 {
+			mod.Version = fields[1]
+		}
+		seen[mod] = struct{}{}
+	}
+
+	return slices.SortedFunc(maps.Keys(seen), func(a, b oci.GoModule) int {
+		return cmp.Or(cmp.Compare(a.Path, b.Path), cmp.Compare(a.Version, b.Version))
+	})
+}
@@ -17,7 +17,9 @@ package image
 import (
 	"fmt"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"slices"
 	"strings"
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/oci"
@@ -26,7 +28,54 @@ import (
 type buildStep struct {
 	opts       buildOptions
 	targetArch string
-	run        func(runner commandRunner) error
+	// name identifies the step in the build cache
+	name string
+	// outputs are the files generated by the step, relative to the output
+	// directory
+	outputs []string
+	// inputs describes everything the outputs depend on: tools, flags and
+	// digests of the input files. The step isn't cached if it's nil.
+	inputs func(runner commandRunner) ([]string, error)
+	run    func(runner commandRunner) error
+}
+
+// outputDirPlaceholder replaces the output directory in the commands used as
+// inputs of the steps, as it's a temporary directory by default.
+const outputDirPlaceholder = "$OUTPUT_DIR"
+
+// cmdInput describes cmd as an input of a step
+func cmdInput(cmd []string, opts buildOptions) string {
+	return "cmd " + strings.ReplaceAll(strings.Join(cmd, " "), opts.outputDir, outputDirPlaceholder)
+}
+
+// toolInput describes the version of a tool as an input of a step
+func toolInput(runner commandRunner, cmd []string, re *regexp.Regexp) (string, error) {
+	v, err := toolVersion(runner, cmd, re)
+	if err != nil {
+		return "", fmt.Errorf("getting %s version: %w", cmd[0], err)
+	}
+	return fmt.Sprintf("tool %s %s", cmd[0], v), nil
+}
+
+// filesInput describes the content of the given files as inputs of a step
+func filesInput(runner commandRunner, paths []string) ([]string, error) {
+	materials, err := digestFiles(runner, paths)
+	if err != nil {
+		return nil, fmt.Errorf("computing digest of inputs: %w", err)
+	}
+
+	inputs := make([]string, 0, len(materials))
+	for _, m := range materials {
+		inputs = append(inputs, fmt.Sprintf("file %s %s", m.Path, m.Digest))
+	}
+	return inputs, nil
+}
+
+// Environment used to build and inspect Go Wasm modules
+var goWasmEnv = []string{
+	"CGO_ENABLED=0",
+	"GOOS=wasip1",
+	"GOARCH=wasm",
 }
 
 var goArchToKernelArch = map[string]string{
@@ -50,38 +99,92 @@ func translateArchBtf(arch string) string {
 	return arch
 }
 
+// clangIncludeFlags returns the flags clang needs to find the headers included
+// by the eBPF program.
+func clangIncludeFlags(targetArch string, opts buildOptions) ([]string, error) {
+	kernelArch, ok := goArchToKernelArch[runtime.GOARCH]
+	if !ok {
+		return nil, fmt.Errorf("no kernel architecture corresponding to %q", runtime.GOARCH)
+	}
+
+	flags := []string{
+		fmt.Sprintf("-I/usr/include/%s-linux-gnu", kernelArch),
+		"-D", fmt.Sprintf("__TARGET_ARCH_%s", translateArch(targetArch)),
+		"-I", fmt.Sprintf("/usr/include/gadget/%s/", targetArch),
+	}
+
+	if opts.useInTreeHeaders {
+		flags = append(flags,
+			"-I", "/work/include/",
+			"-I", fmt.Sprintf("/work/include/gadget/%s/", targetArch),
+		)
+	}
+
+	return flags, nil
+}
+
+func clangCompileCmd(targetArch string, opts buildOptions) ([]string, error) {
+	includeFlags, err := clangIncludeFlags(targetArch, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := []string{
+		"clang",
+		"-target", "bpf",
+		"-Wall",
+		"-g",
+		"-O2",
+	}
+	cmd = append(cmd, includeFlags...)
+	cmd = append(cmd,
+		"-c", opts.ebpfSourcePath,
+		"-o", filepath.Join(opts.outputDir, fmt.Sprintf("%s.bpf.o", targetArch)),
+	)
+	return cmd, nil
+}
+
 func newClangCompileStep(targetArch string, opts buildOptions) buildStep {
 	clangCompileStep := buildStep{
 		opts:       opts,
 		targetArch: targetArch,
+		name:       "clang-" + targetArch,
+		outputs:    []string{fmt.Sprintf("%s.bpf.o", targetArch)},
 	}
 
-	clangCompileStep.run = func(runner commandRunner) error {
-		bpfObjectPath := filepath.Join(clangCompileStep.opts.outputDir, fmt.Sprintf("%s.bpf.o", clangCompileStep.targetArch))
+	clangCompileStep.inputs = func(runner commandRunner) ([]string, error) {
+		cmd, err := clangCompileCmd(clangCompileStep.targetArch, clangCompileStep.opts)
+		if err != nil {
+			return nil, err
+		}
+		inputs := []string{cmdInput(cmd, clangCompileStep.opts)}
 
-		kernelArch, ok := goArchToKernelArch[runtime.GOARCH]
-		if !ok {
-			return fmt.Errorf("no kernel architecture corresponding to %q", runtime.GOARCH)
+		for _, tool := range []string{"clang", "llvm-strip"} {
+			input, err := toolInput(runner, []string{tool, "--version"}, llvmVersionRe)
+			if err != nil {
+				return nil, err
+			}
+			inputs = append(inputs, input)
 		}
 
-		cmd := []string{
-			"clang",
-			"-target", "bpf",
-			"-Wall",
-			"-g",
-			"-O2",
-			fmt.Sprintf("-I/usr/include/%s-linux-gnu", kernelArch),
-			"-D", fmt.Sprintf("__TARGET_ARCH_%s", translateArch(clangCompileStep.targetArch)),
-			"-c", clangCompileStep.opts.ebpfSourcePath,
-			"-I", fmt.Sprintf("/usr/include/gadget/%s/", clangCompileStep.targetArch),
-			"-o", bpfObjectPath,
+		headers, err := archIncludedHeaders(runner, clangCompileStep.targetArch, clangCompileStep.opts)
+		if err != nil {
+			return nil, err
+		}
+		files, err := filesInput(runner, append([]string{clangCompileStep.opts.ebpfSourcePath}, headers...))
+		if err != nil {
+			return nil, err
 		}
 
-		if clangCompileStep.opts.useInTreeHeaders {
-			cmd = append(cmd,
-				"-I", "/work/include/",
-				"-I", fmt.Sprintf("/work/include/gadget/%s/", clangCompileStep.targetArch),
-			)
+		return append(inputs, files...), nil
+	}
+
+	clangCompileStep.run = func(runner commandRunner) error {
+		bpfObjectPath := filepath.Join(clangCompileStep.opts.outputDir, fmt.Sprintf("%s.bpf.o", clangCompileStep.targetArch))
+
+		cmd, err := clangCompileCmd(clangCompileStep.targetArch, clangCompileStep.opts)
+		if err != nil {
+			return err
 		}
 
 		if _, _, err := runner.run(cmd, nil); err != nil {
@@ -98,27 +201,64 @@ func newClangCompileStep(targetArch string, opts buildOptions) buildStep {
 	return clangCompileStep
 }
 
+func goBuildCmd(opts buildOptions) []string {
+	return []string{
+		"go", "build",
+		"-C", filepath.Dir(opts.wasmSourcePath),
+		"-o", filepath.Join(opts.outputDir, "program.wasm"),
+		// -buildmode=c-shared to build the wasm as a reactor module, see:
+		// https://github.com/WebAssembly/WASI/blob/main/legacy/application-abi.md#current-unstable-abi
+		"-buildmode=c-shared",
+		// Don't embed paths nor VCS information to build the same module
+		// from the same sources in any place.
+		"-trimpath",
+		"-buildvcs=false",
+		"-ldflags", "-w -s",
+		filepath.Base(opts.wasmSourcePath),
+	}
+}
+
 func newGoBuildStep(opts buildOptions) buildStep {
-	goBuildStep := buildStep{opts: opts}
+	goBuildStep := buildStep{
+		opts:    opts,
+		name:    "go",
+		outputs: []string{"program.wasm"},
+	}
 
-	goBuildStep.run = func(runner commandRunner) error {
-		cmd := []string{
-			"go", "build",
-			"-C", filepath.Dir(goBuildStep.opts.wasmSourcePath),
-			"-o", filepath.Join(goBuildStep.opts.outputDir, "program.wasm"),
-			// -buildmode=c-shared to build the wasm as a reactor module, see:
-			// https://github.com/WebAssembly/WASI/blob/main/legacy/application-abi.md#current-unstable-abi
-			"-buildmode=c-shared",
-			"-ldflags", "-w -s",
-			filepath.Base(goBuildStep.opts.wasmSourcePath),
-		}
-		env := []string{
-			"CGO_ENABLED=0",
-			"GOOS=wasip1",
-			"GOARCH=wasm",
+	goBuildStep.inputs = func(runner commandRunner) ([]string, error) {
+		inputs := []string{
+			cmdInput(goBuildCmd(goBuildStep.opts), goBuildStep.opts),
+			"env " + strings.Join(goWasmEnv, " "),
 		}
 
-		if _, _, err := runner.run(cmd, env); err != nil {
+		input, err := toolInput(runner, []string{"go", "version"}, goVersionRe)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, input)
+
+		modules, err := goModules(runner, goBuildStep.opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, mod := range modules {
+			inputs = append(inputs, fmt.Sprintf("module %s %s", mod.Path, mod.Version))
+		}
+
+		sources, err := goSourceFiles(runner, goBuildStep.opts)
+		if err != nil {
+			return nil, err
+		}
+		files, err := filesInput(runner, sources)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(inputs, files...), nil
+	}
+
+	goBuildStep.run = func(runner commandRunner) error {
+		if _, _, err := runner.run(goBuildCmd(goBuildStep.opts), goWasmEnv); err != nil {
 			return fmt.Errorf("go build wasm: %w", err)
 		}
 		return nil
@@ -128,7 +268,42 @@ func newGoBuildStep(opts buildOptions) buildStep {
 }
 
 func newCargoBuildStep(opts buildOptions) buildStep {
-	cargoBuildStep := buildStep{opts: opts}
+	cargoBuildStep := buildStep{
+		opts:    opts,
+		name:    "cargo",
+		outputs: []string{"program.wasm"},
+	}
+
+	cargoBuildStep.inputs = func(runner commandRunner) ([]string, error) {
+		var inputs []string
+		for _, tool := range []string{"cargo", "rustc"} {
+			input, err := toolInput(runner, []string{tool, "--version"}, rustVersionRe)
+			if err != nil {
+				return nil, err
+			}
+			inputs = append(inputs, input)
+		}
+
+		// The crate is built as a whole, its target folder excepted
+		crateDir := filepath.Join(filepath.Dir(cargoBuildStep.opts.wasmSourcePath), "..")
+		findOut, _, err := runner.run([]string{
+			"find", crateDir,
+			"-path", filepath.Join(crateDir, "target"), "-prune",
+			"-o", "-type", "f", "-print",
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("listing crate files: %w", err)
+		}
+		sources := strings.Split(strings.TrimSpace(findOut), "\n")
+		slices.Sort(sources)
+
+		files, err := filesInput(runner, sources)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(inputs, files...), nil
+	}
 
 	cargoBuildStep.run = func(runner commandRunner) error {
 		cmd := []string{"cargo"}
@@ -171,6 +346,42 @@ func newBtfgenStep(targetArch string, opts buildOptions) buildStep {
 	btfgenStep := buildStep{
 		opts:       opts,
 		targetArch: targetArch,
+		name:       "btfgen-" + targetArch,
+		outputs:    []string{fmt.Sprintf("btfs-%s.tar.gz", translateArchBtf(targetArch))},
+	}
+
+	btfgenStep.inputs = func(runner commandRunner) ([]string, error) {
+		input, err := toolInput(runner, []string{"bpftool", "version"}, bpftoolVersionRe)
+		if err != nil {
+			return nil, err
+		}
+		inputs := []string{input}
+
+		bpfObjectPath := filepath.Join(btfgenStep.opts.outputDir, fmt.Sprintf("%s.bpf.o", btfgenStep.targetArch))
+		files, err := filesInput(runner, []string{bpfObjectPath})
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			inputs = append(inputs, strings.ReplaceAll(f, btfgenStep.opts.outputDir, outputDirPlaceholder))
+		}
+
+		// Digesting the whole btfhub archive would take as long as running
+		// btfgen, rely on the names and sizes of its files instead.
+		findOut, _, err := runner.run([]string{
+			"find", btfgenStep.opts.btfHubArchivePath,
+			"-iregex", fmt.Sprintf(".*%s.*", translateArchBtf(btfgenStep.targetArch)),
+			"-type", "f",
+			"-name", "*.btf.tar.xz",
+			"-printf", "archive %P %s\\n",
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("listing btf archives: %w", err)
+		}
+		archives := strings.Split(strings.TrimSpace(findOut), "\n")
+		slices.Sort(archives)
+
+		return append(inputs, archives...), nil
 	}
 
 	btfgenStep.run = func(runner commandRunner) error {
@@ -221,8 +432,14 @@ func newBtfgenStep(targetArch string, opts buildOptions) buildStep {
 			}
 		}
 
+		// Create the archive in a reproducible way, see
+		// https://reproducible-builds.org/docs/archives/
 		if _, _, err := runner.run([]string{
-			"tar", "czf", btfArchivePath, "-C", outputBtfDirPath, ".",
+			"tar",
+			"--sort=name", "--mtime=@0",
+			"--owner=0", "--group=0", "--numeric-owner",
+			"--use-compress-program", "gzip -n",
+			"-cf", btfArchivePath, "-C", outputBtfDirPath, ".",
 		}, nil); err != nil {
 			return fmt.Errorf("creating btf archive for %s: %w", translatedArch, err)
 		}
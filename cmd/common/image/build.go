@@ -15,10 +15,13 @@
 package image
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"slices"
 	"strconv"
@@ -29,6 +32,7 @@ import (
 	"github.com/moby/moby/api/types/mount"
 	"github.com/moby/moby/client"
 	"github.com/moby/moby/client/pkg/jsonmessage"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 	"gopkg.in/yaml.v3"
@@ -65,6 +69,11 @@ type cmdOpts struct {
 	validateMetadata bool
 	btfgen           bool
 	btfhubarchive    string
+	attestations     bool
+	sbomFormat       string
+	cache            bool
+	cacheDir         string
+	verify           bool
 }
 
 func NewBuildCmd() *cobra.Command {
@@ -86,6 +95,11 @@ func NewBuildCmd() *cobra.Command {
 				return fmt.Errorf("invalid value for --builder-image-pull: %s. Valid values are %s", opts.builderImagePull, strings.Join(validValues, ","))
 			}
 
+			validSBOMFormats := []string{oci.SBOMFormatSPDX, oci.SBOMFormatCycloneDX}
+			if !slices.Contains(validSBOMFormats, opts.sbomFormat) {
+				return fmt.Errorf("invalid value for --sbom-format: %s. Valid values are %s", opts.sbomFormat, strings.Join(validSBOMFormats, ","))
+			}
+
 			fFlag := cmd.Flags().Lookup("file")
 			opts.fileChanged = fFlag.Changed
 
@@ -107,6 +121,13 @@ func NewBuildCmd() *cobra.Command {
 	cmd.Flags().BoolVar(&opts.btfgen, "btfgen", false, "Enable btfgen")
 	cmd.Flags().StringVar(&opts.btfhubarchive, "btfhub-archive", "", "Path to the location of the btfhub-archive files")
 
+	cmd.Flags().BoolVar(&opts.attestations, "attestations", true, "Attach provenance and SBOM attestations to the image")
+	cmd.Flags().StringVar(&opts.sbomFormat, "sbom-format", oci.SBOMFormatSPDX, "Format of the SBOM attestation [spdx, cyclonedx]")
+
+	cmd.Flags().BoolVar(&opts.cache, "cache", true, "Reuse the outputs of previous builds whose inputs didn't change")
+	cmd.Flags().StringVar(&opts.cacheDir, "cache-dir", defaultBuildCacheDir(), "Path to the build cache")
+	cmd.Flags().BoolVar(&opts.verify, "verify-reproducible", false, "Build the gadget a second time without cache and check both builds produce the same image")
+
 	return cmd
 }
 
@@ -181,8 +202,16 @@ func buildPipeline(opts buildOptions) ([]buildStep, error) {
 	return steps, nil
 }
 
-func runPipeline(runner commandRunner, steps []buildStep) error {
+// runPipeline runs the steps, skipping the ones whose outputs are in the cache,
+// if any.
+func runPipeline(runner commandRunner, steps []buildStep, cache *buildCache) error {
 	for _, step := range steps {
+		if cache != nil {
+			if err := cache.run(runner, step); err != nil {
+				return err
+			}
+			continue
+		}
 		if err := step.run(runner); err != nil {
 			return err
 		}
@@ -191,6 +220,8 @@ func runPipeline(runner commandRunner, steps []buildStep) error {
 }
 
 func runBuild(cmd *cobra.Command, opts *cmdOpts) error {
+	startedOn := time.Now()
+
 	conf := &buildFile{
 		EBPFSource: DEFAULT_EBPF_SOURCE,
 		Wasm:       DEFAULT_WASM,
@@ -295,36 +326,134 @@ func runBuild(cmd *cobra.Command, opts *cmdOpts) error {
 		return fmt.Errorf("at least one of ebpf source (program.bpf.c), metadata (gadget.yaml), .go files (present in go folder) or wasm module is required")
 	}
 
-	if conf.EBPFSource != "" || conf.Wasm != "" {
-		if opts.local {
-			steps, err := buildPipeline(buildOptions{
-				outputDir:         opts.outputDir,
-				ebpfSourcePath:    conf.EBPFSource,
-				wasmSourcePath:    conf.Wasm,
-				btfHubArchivePath: opts.btfhubarchive,
-				btfgen:            opts.btfgen,
-			})
-			if err != nil {
-				return fmt.Errorf("building build pipeline: %w", err)
-			}
+	var cache *buildCache
+	if opts.cache && opts.cacheDir == "" {
+		log.Warnf("No cache directory available, building without cache. Use --cache-dir to set one")
+	} else if opts.cache {
+		cache, err = newBuildCache(opts.cacheDir, opts.outputDir, buildContent)
+		if err != nil {
+			return err
+		}
+	}
 
-			runner := &localRunner{verbose: common.Verbose}
-			if common.Verbose {
-				fmt.Printf("Build logs start:\n")
-			}
-			if err := runPipeline(runner, steps); err != nil {
-				return fmt.Errorf("local build: %w", err)
-			}
-			if common.Verbose {
-				fmt.Printf("Build logs end\n")
-			}
-		} else {
-			if err := buildInContainer(opts, conf); err != nil {
-				return err
-			}
+	buildInfo, err := buildObjects(opts, conf, cache)
+	if err != nil {
+		return err
+	}
+	if !opts.attestations {
+		buildInfo = nil
+	} else if buildInfo == nil {
+		buildInfo = &oci.BuildInfo{}
+	}
+
+	buildOpts := &oci.BuildGadgetImageOpts{
+		EBPFSourcePath:   conf.EBPFSource,
+		ObjectPaths:      objectPaths(opts, conf),
+		MetadataPath:     conf.Metadata,
+		UpdateMetadata:   opts.updateMetadata,
+		ValidateMetadata: opts.validateMetadata,
+	}
+
+	buildOpts.CreatedDate, err = createdDate()
+	if err != nil {
+		return err
+	}
+
+	if buildInfo != nil {
+		buildInfo.WasmSourcePath = conf.Wasm
+		buildInfo.SBOMFormat = opts.sbomFormat
+		buildInfo.StartedOn = startedOn
+		buildInfo.FinishedOn = time.Now()
+		buildOpts.BuildInfo = buildInfo
+	}
+
+	desc, err := oci.BuildGadgetImage(context.TODO(), buildOpts, opts.image)
+	if err != nil {
+		return err
+	}
+
+	cmd.Printf("Successfully built %s\n", desc.String())
+
+	if opts.verify {
+		return verifyReproducible(cmd, opts, conf, buildOpts, desc)
+	}
+
+	return nil
+}
+
+// createdDate returns the creation date of the image. It only depends on the
+// sources, so building them again, on any machine, gives the same image: it's
+// SOURCE_DATE_EPOCH if set, the date of the last git commit changing the
+// current directory otherwise, or the Unix epoch if it isn't in a git
+// repository.
+func createdDate() (string, error) {
+	if sourceDateEpoch, ok := os.LookupEnv("SOURCE_DATE_EPOCH"); ok {
+		sde, err := strconv.ParseInt(sourceDateEpoch, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid SOURCE_DATE_EPOCH: %w", err)
 		}
+		return time.Unix(sde, 0).UTC().Format(time.RFC3339), nil
 	}
 
+	var commitDate int64
+	out, err := exec.Command("git", "log", "-1", "--format=%ct", "--", ".").Output()
+	if err == nil && len(bytes.TrimSpace(out)) > 0 {
+		commitDate, err = strconv.ParseInt(string(bytes.TrimSpace(out)), 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("parsing date of the last git commit %q: %w", out, err)
+		}
+	}
+	return time.Unix(commitDate, 0).UTC().Format(time.RFC3339), nil
+}
+
+// buildObjects builds the eBPF and Wasm objects of the gadget in the output
+// directory. If attestations are enabled, it also returns information about
+// the build.
+func buildObjects(opts *cmdOpts, conf *buildFile, cache *buildCache) (*oci.BuildInfo, error) {
+	if conf.EBPFSource == "" && conf.Wasm == "" {
+		return nil, nil
+	}
+
+	if !opts.local {
+		return buildInContainer(opts, conf, cache)
+	}
+
+	localBuildOpts := buildOptions{
+		outputDir:         opts.outputDir,
+		ebpfSourcePath:    conf.EBPFSource,
+		wasmSourcePath:    conf.Wasm,
+		btfHubArchivePath: opts.btfhubarchive,
+		btfgen:            opts.btfgen,
+	}
+	steps, err := buildPipeline(localBuildOpts)
+	if err != nil {
+		return nil, fmt.Errorf("building build pipeline: %w", err)
+	}
+
+	runner := &localRunner{verbose: common.Verbose}
+	if common.Verbose {
+		fmt.Printf("Build logs start:\n")
+	}
+	if err := runPipeline(runner, steps, cache); err != nil {
+		return nil, fmt.Errorf("local build: %w", err)
+	}
+	if common.Verbose {
+		fmt.Printf("Build logs end\n")
+	}
+
+	if !opts.attestations {
+		return nil, nil
+	}
+
+	buildInfo, err := collectBuildInfo(runner, localBuildOpts)
+	if err != nil {
+		return nil, fmt.Errorf("collecting build information: %w", err)
+	}
+	return buildInfo, nil
+}
+
+// objectPaths returns the paths of the objects of the gadget, per architecture
+func objectPaths(opts *cmdOpts, conf *buildFile) map[string]*oci.ObjectPath {
 	// TODO: make this configurable?
 	archs := []string{oci.ArchAmd64, oci.ArchArm64}
 	objectsPaths := map[string]*oci.ObjectPath{}
@@ -358,32 +487,88 @@ func runBuild(cmd *cobra.Command, opts *cmdOpts) error {
 		objectsPaths[arch] = obj
 	}
 
-	buildOpts := &oci.BuildGadgetImageOpts{
-		EBPFSourcePath:   conf.EBPFSource,
-		ObjectPaths:      objectsPaths,
-		MetadataPath:     conf.Metadata,
-		UpdateMetadata:   opts.updateMetadata,
-		ValidateMetadata: opts.validateMetadata,
+	return objectsPaths
+}
+
+// verifyReproducible builds the objects of the gadget again, without cache, and
+// checks they give the same image as the first build.
+func verifyReproducible(cmd *cobra.Command, opts *cmdOpts, conf *buildFile, buildOpts *oci.BuildGadgetImageOpts, desc *oci.GadgetImageDesc) error {
+	tmpDir, err := os.MkdirTemp("", "gadget-build-verify-")
+	if err != nil {
+		return fmt.Errorf("creating temp dir: %w", err)
 	}
+	defer os.RemoveAll(tmpDir)
 
-	if sourceDateEpoch, ok := os.LookupEnv("SOURCE_DATE_EPOCH"); ok {
-		sde, err := strconv.ParseInt(sourceDateEpoch, 10, 64)
-		if err != nil {
-			return fmt.Errorf("invalid SOURCE_DATE_EPOCH: %w", err)
-		}
-		buildOpts.CreatedDate = time.Unix(sde, 0).UTC().Format(time.RFC3339)
-	} else {
-		buildOpts.CreatedDate = time.Now().Format(time.RFC3339)
+	verifyOpts := *opts
+	verifyOpts.outputDir = tmpDir
+	verifyOpts.attestations = false
+	// The builder image was already ensured by the first build
+	verifyOpts.builderImagePull = "never"
+
+	cmd.Printf("Building again to verify the build is reproducible\n")
+	if _, err := buildObjects(&verifyOpts, conf, nil); err != nil {
+		return fmt.Errorf("building again: %w", err)
 	}
 
-	desc, err := oci.BuildGadgetImage(context.TODO(), buildOpts, opts.image)
+	// The metadata was already updated by the first build
+	verifyBuildOpts := *buildOpts
+	verifyBuildOpts.CreatedDate, err = createdDate()
 	if err != nil {
 		return err
 	}
+	verifyBuildOpts.ObjectPaths = objectPaths(&verifyOpts, conf)
+	verifyBuildOpts.UpdateMetadata = false
+	verifyBuildOpts.ValidateMetadata = false
+	verifyBuildOpts.BuildInfo = nil
 
-	cmd.Printf("Successfully built %s\n", desc.String())
+	digest, err := oci.ComputeGadgetImageDigest(context.TODO(), &verifyBuildOpts)
+	if err != nil {
+		return fmt.Errorf("computing digest of the second build: %w", err)
+	}
 
-	return nil
+	if digest == desc.Digest {
+		cmd.Printf("Build is reproducible\n")
+		return nil
+	}
+
+	var differing []string
+	for _, arch := range slices.Sorted(maps.Keys(buildOpts.ObjectPaths)) {
+		first, second := buildOpts.ObjectPaths[arch], verifyBuildOpts.ObjectPaths[arch]
+		for _, paths := range [][2]string{
+			{first.EBPF, second.EBPF},
+			{first.Wasm, second.Wasm},
+			{first.Btfgen, second.Btfgen},
+		} {
+			same, err := sameFileContent(paths[0], paths[1])
+			if err != nil {
+				return err
+			}
+			if !same && !slices.Contains(differing, filepath.Base(paths[0])) {
+				differing = append(differing, filepath.Base(paths[0]))
+			}
+		}
+	}
+
+	err = fmt.Errorf("build isn't reproducible: the second build has digest %s instead of %s", digest, desc.Digest)
+	if len(differing) > 0 {
+		err = fmt.Errorf("%w: %s differ", err, strings.Join(differing, ", "))
+	}
+	return err
+}
+
+func sameFileContent(a, b string) (bool, error) {
+	if a == "" || a == b {
+		return true, nil
+	}
+	contentA, err := os.ReadFile(a)
+	if err != nil {
+		return false, err
+	}
+	contentB, err := os.ReadFile(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(contentA, contentB), nil
 }
 
 func pullImage(ctx context.Context, cli *client.Client, imageReference string) error {
@@ -449,21 +634,32 @@ func ensureBuilderImage(ctx context.Context, cli *client.Client, builderImage st
 	return nil
 }
 
-func buildInContainer(opts *cmdOpts, conf *buildFile) error {
+// buildInContainer builds the gadget in a container created from the builder
+// image. If attestations are enabled, it also returns information about the
+// build.
+func buildInContainer(opts *cmdOpts, conf *buildFile, cache *buildCache) (*oci.BuildInfo, error) {
 	cwd, err := os.Getwd()
 	if err != nil {
-		return fmt.Errorf("getting current directory: %w", err)
+		return nil, fmt.Errorf("getting current directory: %w", err)
 	}
 
 	ctx := context.TODO()
 	cli, err := client.New(client.FromEnv)
 	if err != nil {
-		return fmt.Errorf("creating docker client: %w", err)
+		return nil, fmt.Errorf("creating docker client: %w", err)
 	}
 	defer cli.Close()
 
 	if err := ensureBuilderImage(ctx, cli, opts.builderImage, opts.builderImagePull); err != nil {
-		return err
+		return nil, err
+	}
+
+	if cache != nil {
+		id, err := builderImageID(ctx, cli, opts.builderImage)
+		if err != nil {
+			return nil, err
+		}
+		cache.addInput("builder " + id)
 	}
 
 	// where the gadget source code is mounted in the container
@@ -476,7 +672,7 @@ func buildInContainer(opts *cmdOpts, conf *buildFile) error {
 		pathHost = inspektorGadetSrcPath
 		// find the gadget relative path to the inspektor-gadget source
 		if !strings.HasPrefix(cwd, inspektorGadetSrcPath) {
-			return fmt.Errorf("the current directory %q is not under the inspektor-gadget source path %q", cwd, inspektorGadetSrcPath)
+			return nil, fmt.Errorf("the current directory %q is not under the inspektor-gadget source path %q", cwd, inspektorGadetSrcPath)
 		}
 		gadgetRelativePath := strings.TrimPrefix(cwd, inspektorGadetSrcPath)
 		gadgetSourcePath = filepath.Join("/work", gadgetRelativePath)
@@ -506,7 +702,7 @@ func buildInContainer(opts *cmdOpts, conf *buildFile) error {
 
 	steps, err := buildPipeline(buildOpts)
 	if err != nil {
-		return fmt.Errorf("building build pipeline: %w", err)
+		return nil, fmt.Errorf("building build pipeline: %w", err)
 	}
 
 	// The work mount ReadOnly field is updated as false, to allow Cargo.lock to compiled in /work folder for rust source code.
@@ -547,7 +743,7 @@ func buildInContainer(opts *cmdOpts, conf *buildFile) error {
 		},
 	)
 	if err != nil {
-		return fmt.Errorf("creating builder container: %w", err)
+		return nil, fmt.Errorf("creating builder container: %w", err)
 	}
 	defer func() {
 		if _, err := cli.ContainerRemove(ctx, resp.ID, client.ContainerRemoveOptions{Force: true}); err != nil {
@@ -556,7 +752,7 @@ func buildInContainer(opts *cmdOpts, conf *buildFile) error {
 	}()
 
 	if _, err := cli.ContainerStart(ctx, resp.ID, client.ContainerStartOptions{}); err != nil {
-		return fmt.Errorf("starting builder container: %w", err)
+		return nil, fmt.Errorf("starting builder container: %w", err)
 	}
 
 	runner := &containerRunner{
@@ -569,12 +765,51 @@ func buildInContainer(opts *cmdOpts, conf *buildFile) error {
 	if common.Verbose {
 		fmt.Println("Build logs start:")
 	}
-	if err := runPipeline(runner, steps); err != nil {
-		return fmt.Errorf("container build: %w", err)
+	if err := runPipeline(runner, steps, cache); err != nil {
+		return nil, fmt.Errorf("container build: %w", err)
 	}
 	if common.Verbose {
 		fmt.Println("Build logs end")
 	}
 
-	return nil
+	if !opts.attestations {
+		return nil, nil
+	}
+
+	info, err := collectBuildInfo(runner, buildOpts)
+	if err != nil {
+		return nil, fmt.Errorf("collecting build information: %w", err)
+	}
+	info.BuilderImage = opts.builderImage
+	info.BuilderImageDigest, err = builderImageDigest(ctx, cli, opts.builderImage)
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// builderImageID returns the ID of the builder image, that identifies it even
+// if it wasn't pulled from a registry.
+func builderImageID(ctx context.Context, cli *client.Client, image string) (string, error) {
+	result, err := cli.ImageInspect(ctx, image)
+	if err != nil {
+		return "", fmt.Errorf("inspecting builder image: %w", err)
+	}
+	return result.ID, nil
+}
+
+// builderImageDigest returns the manifest digest of the builder image, or an
+// empty string if the image wasn't pulled from a registry.
+func builderImageDigest(ctx context.Context, cli *client.Client, image string) (string, error) {
+	result, err := cli.ImageInspect(ctx, image)
+	if err != nil {
+		return "", fmt.Errorf("inspecting builder image: %w", err)
+	}
+	for _, repoDigest := range result.RepoDigests {
+		if _, digest, ok := strings.Cut(repoDigest, "@"); ok {
+			return digest, nil
+		}
+	}
+	return "", nil
 }
@@ -0,0 +1,63 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package image
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreatedDate(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	date, err := createdDate()
+	require.NoError(t, err)
+	assert.Equal(t, "2023-11-14T22:13:20Z", date)
+
+	t.Setenv("SOURCE_DATE_EPOCH", "yesterday")
+	_, err = createdDate()
+	require.Error(t, err)
+	os.Unsetenv("SOURCE_DATE_EPOCH")
+
+	// Outside of a git repository
+	dir := t.TempDir()
+	t.Chdir(dir)
+	date, err = createdDate()
+	require.NoError(t, err)
+	assert.Equal(t, "1970-01-01T00:00:00Z", date)
+
+	// Date of the last commit
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "program.bpf.c"), []byte("foo"), 0o644))
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "program.bpf.c"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "test"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Env = append(os.Environ(), "GIT_COMMITTER_DATE=1700000000 +0000")
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	date, err = createdDate()
+	require.NoError(t, err)
+	assert.Equal(t, "2023-11-14T22:13:20Z", date)
+}
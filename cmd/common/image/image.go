@@ -38,6 +38,7 @@ func NewImageCmd(r runtime.Runtime, addCommands []*cobra.Command) *cobra.Command
 	cmd.AddCommand(NewBuildCmd())
 	cmd.AddCommand(NewExportCmd())
 	cmd.AddCommand(NewImportCmd())
+	cmd.AddCommand(NewMirrorCmd())
 	cmd.AddCommand(NewPushCmd())
 	cmd.AddCommand(NewPullCmd())
 	cmd.AddCommand(NewTagCmd())
@@ -53,6 +53,7 @@ func NewInspectCmd(runtime runtime.Runtime) *cobra.Command {
 	cmd.PersistentFlags().String("extra-info", "", "Custom info type to display")
 	cmd.PersistentFlags().String("jsonpath", "", "JSONPath to extract from the extra info")
 	cmd.PersistentFlags().Bool("show-datasources", false, "Show datasources with their fields")
+	cmd.PersistentFlags().Bool("attestations", false, "Show the provenance and SBOM attestations of the image")
 
 	ociParams := apihelpers.ToParamDescs(ocihandler.OciHandler.InstanceParams()).ToParams()
 
@@ -115,6 +116,14 @@ func NewInspectCmd(runtime runtime.Runtime) *cobra.Command {
 		extraInfo, _ := cmd.PersistentFlags().GetString("extra-info")
 		jsonPath, _ := cmd.PersistentFlags().GetString("jsonpath")
 		showDataSources, _ := cmd.PersistentFlags().GetBool("show-datasources")
+		showAttestations, _ := cmd.PersistentFlags().GetBool("attestations")
+
+		if showAttestations {
+			if extraInfo != "" || showDataSources {
+				return fmt.Errorf("attestations cannot be used together with extra-info or show-datasources")
+			}
+			extraInfo = "oci.attestations"
+		}
 
 		if jsonPath != "" && extraInfo == "" && !showDataSources {
 			return fmt.Errorf("jsonpath %q can only be used with extra info or show-datasources", jsonPath)
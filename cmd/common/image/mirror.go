@@ -0,0 +1,106 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/inspektor-gadget/inspektor-gadget/cmd/common/utils"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/oci"
+)
+
+func NewMirrorCmd() *cobra.Command {
+	var opts oci.MirrorOptions
+	var outputMode string
+	supportedOutputModes := []string{utils.OutputModeJSON, utils.OutputModeJSONPretty}
+
+	cmd := &cobra.Command{
+		Use:   "mirror IMAGE [IMAGE n] --to DESTINATION",
+		Short: "Mirror images with their signatures between registries, OCI layouts and archives",
+		Long: `Mirror images, with all their architectures and signatures, between registries,
+OCI layout directories and OCI archives.
+
+Locations are either a registry prefix, e.g. registry.local:5000/gadgets, an
+OCI layout directory prefixed with "` + oci.MirrorOCILayoutPrefix + `", or an OCI archive prefixed
+with "` + oci.MirrorOCIArchivePrefix + `". Images are copied from their own registry by default.
+
+IMAGE can contain glob patterns: in the tag for registries and anywhere for
+OCI layouts and archives. Images already present in the destination aren't
+copied again.`,
+		Example: `  # Copy gadgets and their signatures to a USB drive
+  ig image mirror trace_exec:v0.40.0 trace_open:v0.40.0 --to oci-archive:/mnt/usb/gadgets.tar
+
+  # Publish them to the registry of a disconnected cluster
+  ig image mirror 'trace_*:v0.40.0' --from oci-archive:/mnt/usb/gadgets.tar --to registry.local:5000/gadgets`,
+		SilenceUsage: true,
+		Args:         cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			results, err := oci.MirrorGadgetImages(context.TODO(), args, &opts)
+			if err != nil {
+				return fmt.Errorf("mirroring images: %w", err)
+			}
+
+			switch outputMode {
+			case utils.OutputModeJSON:
+				bytes, err := json.Marshal(results)
+				if err != nil {
+					return fmt.Errorf("marshalling results to JSON: %w", err)
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(bytes))
+			case utils.OutputModeJSONPretty:
+				bytes, err := json.MarshalIndent(results, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshalling results to JSON: %w", err)
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(bytes))
+			case "":
+				for _, r := range results {
+					status := "Mirrored"
+					if !r.Copied {
+						status = "Up to date"
+					}
+					signed := ""
+					if !r.Signed {
+						signed = " (no signature)"
+					}
+					fmt.Fprintf(cmd.OutOrStdout(), "%s %s@%s%s\n", status, r.Destination, r.Digest, signed)
+				}
+			default:
+				return fmt.Errorf("invalid output mode %q, valid values are: %s", outputMode, strings.Join(supportedOutputModes, ", "))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Source, "from", "", "Location to copy images from, by default their own registry")
+	cmd.Flags().StringVar(&opts.Destination, "to", "", "Location to copy images to")
+	cmd.MarkFlagRequired("to")
+	cmd.Flags().StringVarP(
+		&outputMode,
+		"output",
+		"o",
+		"",
+		fmt.Sprintf("Output mode, possible values are: %s", strings.Join(supportedOutputModes, ", ")),
+	)
+	utils.AddRegistryAuthVariablesAndFlags(cmd, &opts.AuthOptions)
+
+	return cmd
+}
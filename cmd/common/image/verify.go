@@ -13,6 +13,7 @@ import (
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/signature/verifier"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/signature/verifier/cosign"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/signature/verifier/notation"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/signature/verifier/sigstore"
 )
 
 func NewVerifyCmd() *cobra.Command {
@@ -20,6 +21,8 @@ func NewVerifyCmd() *cobra.Command {
 	var authOpts oci.AuthOptions
 	var cosignPublicKeys string
 	var notationPolicy string
+	var sigstoreTrustedRoot string
+	var sigstorePolicy string
 
 	cmd := &cobra.Command{
 		Use:   "verify [gadget]",
@@ -36,6 +39,10 @@ func NewVerifyCmd() *cobra.Command {
 					Certificates:   strings.Split(notationCertificates, ","),
 					PolicyDocument: notationPolicy,
 				},
+				SigstoreVerifierOpts: sigstore.VerifierOptions{
+					TrustedRoot:    sigstoreTrustedRoot,
+					PolicyDocument: sigstorePolicy,
+				},
 			})
 			if err != nil {
 				return fmt.Errorf("initializing verifier: %w", err)
@@ -60,6 +67,8 @@ func NewVerifyCmd() *cobra.Command {
 	cmd.Flags().StringVar(&cosignPublicKeys, "public-keys", resources.InspektorGadgetPublicKey, "Public keys used to verify the gadgets with cosign")
 	cmd.Flags().StringVar(&notationCertificates, "notation-certificates", "", "Certificates used to verify the gadgets with notation")
 	cmd.Flags().StringVar(&notationPolicy, "notation-policy-document", "", "Policy Document used to verify the gadgets with notation")
+	cmd.Flags().StringVar(&sigstoreTrustedRoot, "sigstore-trusted-root", "", "Sigstore trusted root (trusted_root.json) used to verify the gadgets signed keylessly")
+	cmd.Flags().StringVar(&sigstorePolicy, "sigstore-policy-document", "", "Policy Document with the identities allowed to sign the gadgets keylessly")
 	utils.AddRegistryAuthVariablesAndFlags(cmd, &authOpts)
 
 	return cmd
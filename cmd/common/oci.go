@@ -37,6 +37,7 @@ import (
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
 	clioperator "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/cli"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/combiner"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/diff"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/generate_networkpolicy"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/limiter"
 	ocihandler "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/oci-handler"
@@ -64,6 +65,19 @@ var commandModesDescriptions = map[CommandMode]string{
 	CommandModeAttach: "Attach to a running gadget",
 }
 
+// replayRuntime is implemented by runtimes that can replay recordings without
+// being fully initialized
+type replayRuntime interface {
+	InitForReplay(globalRuntimeParams *params.Params) error
+}
+
+// isReplaying returns whether the runtime was asked to replay a recording
+// instead of running the gadget
+func isReplaying(runtimeParams *params.Params) bool {
+	p := runtimeParams.Get("replay")
+	return p != nil && p.AsString() != ""
+}
+
 func findGadgetInstances(runtime *grpcruntime.Runtime, runtimeParams *params.Params, idOrNames []string) (instances []*api.GadgetInstance, ambiguous []string, notfound []string, retErr error) {
 	gadgetInstances, err := runtime.GetGadgetInstances(context.Background(), runtimeParams)
 	if err != nil {
@@ -133,13 +147,6 @@ func NewRunCommand(rootCmd *cobra.Command, runtime runtime.Runtime, hiddenColumn
 		skipRuntimeInit := len(args) == 1 && (args[0] == "-h" || args[0] == "--help")
 
 		var err error
-		if !skipRuntimeInit {
-			err = runtime.Init(runtimeGlobalParams)
-			if err != nil {
-				return fmt.Errorf("initializing runtime: %w", err)
-			}
-			defer runtime.Close()
-		}
 
 		// set global operator flags from the config file
 		for o, p := range opGlobalParams {
@@ -164,6 +171,20 @@ func NewRunCommand(rootCmd *cobra.Command, runtime runtime.Runtime, hiddenColumn
 			return err
 		}
 
+		// The runtime is initialized once its params are known, as replaying
+		// a recording doesn't need the privileges running a gadget does
+		if !skipRuntimeInit {
+			if rt, ok := runtime.(replayRuntime); ok && isReplaying(runtimeParams) {
+				err = rt.InitForReplay(runtimeGlobalParams)
+			} else {
+				err = runtime.Init(runtimeGlobalParams)
+			}
+			if err != nil {
+				return fmt.Errorf("initializing runtime: %w", err)
+			}
+			defer runtime.Close()
+		}
+
 		// Before running the gadget, we need to get the gadget info to be able to set
 		// things (like params) up correctly
 		actualArgs := cmd.Flags().Args()
@@ -175,8 +196,13 @@ func NewRunCommand(rootCmd *cobra.Command, runtime runtime.Runtime, hiddenColumn
 			return fmt.Errorf("please only specify an image OR manifest")
 		}
 
+		replaying := isReplaying(runtimeParams)
+
 		ops := make([]operators.DataOperator, 0)
 		for _, op := range operators.GetDataOperators() {
+			if replaying && !operators.SupportsReplay(op) {
+				continue
+			}
 			// Initialize operator
 			err := op.Init(opGlobalParams[op.Name()])
 			if err != nil {
@@ -293,8 +319,13 @@ func NewRunCommand(rootCmd *cobra.Command, runtime runtime.Runtime, hiddenColumn
 
 		ctx := fe.GetContext()
 
+		replaying := isReplaying(runtimeParams)
+
 		ops := make([]operators.DataOperator, 0)
 		for _, op := range operators.GetDataOperators() {
+			if replaying && !operators.SupportsReplay(op) {
+				continue
+			}
 			if !initializedOperators {
 				// initialize operators if not yet done in PreRun (e.g. when -f was specified)
 				err := op.Init(opGlobalParams[op.Name()])
@@ -18,10 +18,12 @@ import (
 	"crypto/tls"
 	"fmt"
 	"os"
+	"os/signal"
 	"os/user"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"syscall"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -32,6 +34,9 @@ import (
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/config"
 	gadgetservice "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/audit"
+	filebackend "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/audit/file-backend"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/authz"
 	instancemanager "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/instance-manager"
 	filestore "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/store/file-store"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/runtime"
@@ -52,6 +57,12 @@ func newDaemonCommand(runtime runtime.Runtime) *cobra.Command {
 	var serverKey string
 	var serverCert string
 	var clientCA string
+	var auditLog string
+	var auditLogMaxSizeMB int
+	var auditLogMaxBackups int
+	var authzPolicy string
+	var quotas instancemanager.Quotas
+	var maxMapMemoryMB uint64
 
 	daemonCmd.PersistentFlags().StringVarP(
 		&group,
@@ -93,6 +104,72 @@ func newDaemonCommand(runtime runtime.Runtime) *cobra.Command {
 		"",
 		"Path to CA certificate for client validation")
 
+	daemonCmd.PersistentFlags().StringVar(
+		&auditLog,
+		"audit-log",
+		"",
+		"Path of a file to record requests to the daemon to; disabled if empty")
+
+	daemonCmd.PersistentFlags().IntVar(
+		&auditLogMaxSizeMB,
+		"audit-log-max-size",
+		filebackend.DefaultMaxSizeMB,
+		"Maximum size in MB of the audit log before it gets rotated")
+
+	daemonCmd.PersistentFlags().IntVar(
+		&auditLogMaxBackups,
+		"audit-log-max-backups",
+		filebackend.DefaultMaxBackups,
+		"Maximum number of rotated audit logs to keep; 0 keeps all of them")
+
+	daemonCmd.PersistentFlags().StringVar(
+		&authzPolicy,
+		"authz-policy",
+		"",
+		"Path of a policy file describing which clients may run which gadgets; all clients are allowed to do everything if empty")
+
+	daemonCmd.PersistentFlags().IntVar(
+		&quotas.MaxInstances,
+		"max-gadget-instances",
+		0,
+		"Maximum number of gadget instances; 0 means no limit")
+
+	daemonCmd.PersistentFlags().IntVar(
+		&quotas.MaxInstancesPerImage,
+		"max-gadget-instances-per-image",
+		0,
+		"Maximum number of gadget instances running the same image; 0 means no limit")
+
+	daemonCmd.PersistentFlags().Uint64Var(
+		&maxMapMemoryMB,
+		"max-gadget-map-memory",
+		0,
+		"Maximum memory in MB used by eBPF maps of all gadget instances; 0 means no limit")
+
+	daemonCmd.PersistentFlags().Float64Var(
+		&quotas.MaxCPUUsage,
+		"max-gadget-cpu",
+		0,
+		"Maximum CPU usage in percent of one CPU of the eBPF programs of a gadget instance; 0 means no limit")
+
+	daemonCmd.PersistentFlags().Uint64Var(
+		&quotas.MaxEventRate,
+		"max-gadget-event-rate",
+		0,
+		"Maximum number of events per second of a gadget instance; 0 means no limit")
+
+	daemonCmd.PersistentFlags().StringVar(
+		&quotas.EventRateAction,
+		"gadget-event-rate-action",
+		instancemanager.QuotaActionThrottle,
+		"What to do with gadget instances exceeding --max-gadget-event-rate: throttle or stop")
+
+	daemonCmd.PersistentFlags().DurationVar(
+		&quotas.Interval,
+		"gadget-quota-interval",
+		instancemanager.DefaultQuotaInterval,
+		"Interval in which the resource usage of gadget instances is checked")
+
 	service := gadgetservice.NewService(log.StandardLogger())
 
 	for _, params := range service.GetOperatorMap() {
@@ -171,7 +248,35 @@ All these options should be set at the same time to enable TLS connection`,
 			log.Warnf("no TLS configuration provided, communication between daemon and CLI will not be encrypted")
 		}
 
-		mgr, err := instancemanager.New(runtime)
+		var auditor *audit.Auditor
+		if auditLog != "" {
+			backend, err := filebackend.New(filebackend.Options{
+				Filename:   auditLog,
+				MaxSizeMB:  auditLogMaxSizeMB,
+				MaxBackups: auditLogMaxBackups,
+			})
+			if err != nil {
+				return fmt.Errorf("initializing audit log: %w", err)
+			}
+			auditor = audit.New(backend, log.StandardLogger())
+			service.SetAuditor(auditor)
+			log.Infof("writing audit log to %q", auditLog)
+		}
+
+		if authzPolicy != "" {
+			policy, err := authz.LoadPolicy(authzPolicy)
+			if err != nil {
+				return fmt.Errorf("loading authorization policy: %w", err)
+			}
+			service.SetPolicy(policy)
+			log.Infof("using authorization policy %q", authzPolicy)
+		}
+
+		quotas.MaxMapMemory = maxMapMemoryMB * 1024 * 1024
+		mgr, err := instancemanager.New(runtime,
+			instancemanager.WithAuditor(auditor),
+			instancemanager.WithQuotas(quotas),
+		)
 		if err != nil {
 			return fmt.Errorf("initializing manager: %w", err)
 		}
@@ -183,12 +288,26 @@ All these options should be set at the same time to enable TLS connection`,
 
 		service.SetStore(store)
 		service.SetInstanceManager(mgr)
-
-		return service.Run(gadgetservice.RunConfig{
-			SocketType: socketType,
-			SocketPath: socketPath,
-			SocketGID:  gid,
-		}, options...)
+		defer service.Close()
+
+		exitSignal := make(chan os.Signal, 1)
+		signal.Notify(exitSignal, syscall.SIGINT, syscall.SIGTERM)
+
+		errs := make(chan error, 1)
+		go func() {
+			errs <- service.Run(gadgetservice.RunConfig{
+				SocketType: socketType,
+				SocketPath: socketPath,
+				SocketGID:  gid,
+			}, options...)
+		}()
+
+		select {
+		case err := <-errs:
+			return err
+		case <-exitSignal:
+			return nil
+		}
 	}
 
 	return daemonCmd
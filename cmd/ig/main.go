@@ -38,16 +38,24 @@ import (
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/utils/host"
 
 	// Another blank import for the used operator
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/aggregate"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/alert"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/btfgen"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/cgroup"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/cli/columnar"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/ebpf"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/enricher"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/env"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/filter"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/formatters"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/join"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/localmanager"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/logs"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/otel-profiles"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/process"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/ratelimit"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/record"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/sink"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/socketenricher"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/sort"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/uidgidresolver"
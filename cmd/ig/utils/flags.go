@@ -23,6 +23,7 @@ import (
 
 	commonutils "github.com/inspektor-gadget/inspektor-gadget/cmd/common/utils"
 	containerutils "github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils"
+	runtimeclient "github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils/runtime-client"
 	containerutilsTypes "github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils/types"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/types"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/utils/host"
@@ -39,6 +40,10 @@ type CommonFlags struct {
 	// Saves all runtime socket paths
 	commonutils.RuntimesSocketPathConfig
 
+	// Socket paths of the runtimes only supported by ig
+	Incus  string
+	Nspawn string
+
 	// Containername allows to filter containers by name.
 	Containername string
 
@@ -108,6 +113,10 @@ func AddCommonFlags(command *cobra.Command, commonFlags *CommonFlags) {
 				socketPath, err = securejoin.SecureJoin(host.HostRoot, commonFlags.Crio)
 			case types.RuntimeNamePodman:
 				socketPath, err = securejoin.SecureJoin(host.HostRoot, commonFlags.Podman)
+			case types.RuntimeNameIncus:
+				socketPath, err = securejoin.SecureJoin(host.HostRoot, commonFlags.Incus)
+			case types.RuntimeNameNspawn:
+				socketPath, err = securejoin.SecureJoin(host.HostRoot, commonFlags.Nspawn)
 			default:
 				return commonutils.WrapInErrInvalidArg("--runtime / -r",
 					fmt.Errorf("runtime %q is not supported", p))
@@ -151,6 +160,20 @@ func AddCommonFlags(command *cobra.Command, commonFlags *CommonFlags) {
 	commonutils.AddOutputFlags(command, &commonFlags.OutputConfig)
 	commonutils.AddRuntimesSocketPathFlags(command, &commonFlags.RuntimesSocketPathConfig)
 
+	command.PersistentFlags().StringVar(
+		&commonFlags.Incus,
+		"incus-socketpath",
+		runtimeclient.IncusDefaultSocketPath,
+		"Incus or LXD Unix socket path",
+	)
+
+	command.PersistentFlags().StringVar(
+		&commonFlags.Nspawn,
+		"nspawn-socketpath",
+		runtimeclient.NspawnDefaultSocketPath,
+		"D-Bus system bus Unix socket path used to reach systemd-machined",
+	)
+
 	command.PersistentFlags().StringVarP(
 		&commonFlags.Containername,
 		"containername",
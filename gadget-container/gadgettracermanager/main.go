@@ -35,6 +35,9 @@ import (
 	"github.com/inspektor-gadget/inspektor-gadget/internal/version"
 	// Import this early to set the environment variable before any other package is imported
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/environment/k8s"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/audit"
+	filebackend "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/audit/file-backend"
+	k8seventsbackend "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/audit/k8s-events-backend"
 	instancemanager "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/instance-manager"
 	k8sconfigmapstore "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/store/k8s-configmap-store"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/runtime/local"
@@ -48,12 +51,16 @@ import (
 	// import for gadgettracermanager entrypoint"
 	"github.com/inspektor-gadget/inspektor-gadget/gadget-container/entrypoint"
 	// Blank import for some operators
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/aggregate"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/alert"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/btfgen"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/cgroup"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/ebpf"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/enricher"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/env"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/filter"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/formatters"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/join"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/kubeipresolver"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/kubemanager"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/kubenameresolver"
@@ -63,6 +70,8 @@ import (
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/otel-metrics"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/otel-profiles"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/process"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/ratelimit"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/sink"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/socketenricher"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/sort"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/uidgidresolver"
@@ -208,17 +217,26 @@ func main() {
 		service := gadgetservice.NewService(log.StandardLogger())
 		service.SetEventBufferLength(bufferLength)
 
-		mgr, err := instancemanager.New(local.New())
-		if err != nil {
-			log.Fatalf("initializing manager: %v", err)
-		}
-
 		gadgetNs := config.Config.GetString(gadgettracermanagerconfig.GadgetNamespace)
 		log.Infof("Config: %s=%s", gadgettracermanagerconfig.GadgetNamespace, gadgetNs)
 		if gadgetNs == "" {
 			log.Fatalf("gadget namespace must not be empty")
 		}
 
+		auditor, err := newAuditor(gadgetNs)
+		if err != nil {
+			log.Fatalf("initializing auditor: %v", err)
+		}
+		service.SetAuditor(auditor)
+
+		mgr, err := instancemanager.New(local.New(),
+			instancemanager.WithAuditor(auditor),
+			instancemanager.WithQuotas(newQuotas()),
+		)
+		if err != nil {
+			log.Fatalf("initializing manager: %v", err)
+		}
+
 		store, err := k8sconfigmapstore.New(mgr, gadgetNs)
 		if err != nil {
 			log.Fatalf("initializing store: %v", err)
@@ -248,3 +266,41 @@ func main() {
 		service.Close()
 	}
 }
+
+func newAuditor(gadgetNamespace string) (*audit.Auditor, error) {
+	backendName := config.Config.GetString(gadgettracermanagerconfig.AuditBackend)
+	log.Infof("Config: %s=%s", gadgettracermanagerconfig.AuditBackend, backendName)
+
+	var backend audit.Backend
+	var err error
+	switch backendName {
+	case gadgettracermanagerconfig.AuditBackendNone, "":
+		return nil, nil
+	case gadgettracermanagerconfig.AuditBackendFile:
+		backend, err = filebackend.New(filebackend.Options{
+			Filename: config.Config.GetString(gadgettracermanagerconfig.AuditLogFile),
+		})
+	case gadgettracermanagerconfig.AuditBackendK8sEvents:
+		backend, err = k8seventsbackend.New(gadgetNamespace)
+	default:
+		return nil, fmt.Errorf("invalid audit backend %q", backendName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return audit.New(backend, log.StandardLogger()), nil
+}
+
+func newQuotas() instancemanager.Quotas {
+	quotas := instancemanager.Quotas{
+		MaxInstances:         config.Config.GetInt(gadgettracermanagerconfig.MaxInstances),
+		MaxInstancesPerImage: config.Config.GetInt(gadgettracermanagerconfig.MaxInstancesPerImage),
+		MaxMapMemory:         config.Config.GetUint64(gadgettracermanagerconfig.MaxMapMemory) * 1024 * 1024,
+		MaxCPUUsage:          config.Config.GetFloat64(gadgettracermanagerconfig.MaxCPU),
+		MaxEventRate:         config.Config.GetUint64(gadgettracermanagerconfig.MaxEventRate),
+		EventRateAction:      config.Config.GetString(gadgettracermanagerconfig.EventRateAction),
+		Interval:             config.Config.GetDuration(gadgettracermanagerconfig.QuotaInterval),
+	}
+	log.Infof("Config: instance quotas: %+v", quotas)
+	return quotas
+}
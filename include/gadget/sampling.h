@@ -0,0 +1,132 @@
+/* SPDX-License-Identifier: (GPL-2.0 WITH Linux-syscall-note) OR Apache-2.0 */
+
+// This file defines helpers to sample and rate limit events in the kernel,
+// before they are written to the perf or ring buffer. Users configure them
+// with the sample-ratio, kernel-rate-limit and kernel-rate-limit-burst params.
+// The number of events dropped by these helpers is reported by user space
+// separately from the events lost because the buffer was full.
+
+#ifndef SAMPLING_H
+#define SAMPLING_H
+
+#include <bpf/bpf_helpers.h>
+#include <gadget/macros.h>
+
+#ifndef GADGET_RATE_LIMIT_MAX_KEYS
+#define GADGET_RATE_LIMIT_MAX_KEYS 10240
+#endif
+
+#define GADGET_NSEC_PER_SEC 1000000000ULL
+
+// Keep in sync with pkg/operators/ebpf/types/types.go
+#define GADGET_SAMPLED_OUT_RATIO 0
+#define GADGET_SAMPLED_OUT_RATE_LIMIT 1
+
+// Keep 1 in gadget_sample_ratio events; 0 and 1 keep all events
+const volatile __u32 gadget_sample_ratio = 1;
+GADGET_PARAM(gadget_sample_ratio);
+
+// Maximum number of events per second and key; 0 disables the rate limit
+const volatile __u32 gadget_rate_limit = 0;
+GADGET_PARAM(gadget_rate_limit);
+
+// Number of events per key that can exceed the rate limit in a burst; 0 uses
+// gadget_rate_limit
+const volatile __u32 gadget_rate_limit_burst = 0;
+GADGET_PARAM(gadget_rate_limit_burst);
+
+struct gadget_rate_limit_bucket {
+	// tokens are scaled by GADGET_NSEC_PER_SEC, so refilling doesn't need
+	// a division
+	__u64 tokens;
+	__u64 last;
+};
+
+struct {
+	__uint(type, BPF_MAP_TYPE_LRU_HASH);
+	__uint(max_entries, GADGET_RATE_LIMIT_MAX_KEYS);
+	__type(key, __u64);
+	__type(value, struct gadget_rate_limit_bucket);
+} ig_rate_limit SEC(".maps");
+
+// Number of dropped events per reason (GADGET_SAMPLED_OUT_*), read
+// periodically by user space, which reports the increase since its last read
+struct {
+	__uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+	__uint(max_entries, 2);
+	__type(key, __u32);
+	__type(value, __u64);
+} ig_sampled_out SEC(".maps");
+
+static __always_inline void gadget_count_sampled_out(__u32 reason)
+{
+	__u64 *cnt = bpf_map_lookup_elem(&ig_sampled_out, &reason);
+	if (cnt)
+		*cnt += 1;
+}
+
+// gadget_rate_limited returns true if the event exceeds the rate limit of the
+// given key. Buckets are updated without locking, so the limit is approximate
+// when events of the same key happen on several CPUs at the same time.
+static __always_inline bool gadget_rate_limited(__u64 key)
+{
+	struct gadget_rate_limit_bucket *bucket;
+	__u64 now, elapsed, max_tokens;
+	__u32 burst;
+
+	if (gadget_rate_limit == 0)
+		return false;
+
+	burst = gadget_rate_limit_burst ?: gadget_rate_limit;
+	max_tokens = (__u64)burst * GADGET_NSEC_PER_SEC;
+	now = bpf_ktime_get_boot_ns();
+
+	bucket = bpf_map_lookup_elem(&ig_rate_limit, &key);
+	if (!bucket) {
+		struct gadget_rate_limit_bucket new_bucket = {
+			.tokens = max_tokens - GADGET_NSEC_PER_SEC,
+			.last = now,
+		};
+		bpf_map_update_elem(&ig_rate_limit, &key, &new_bucket,
+				    BPF_NOEXIST);
+		return false;
+	}
+
+	elapsed = now > bucket->last ? now - bucket->last : 0;
+	bucket->last = now;
+	// Avoid overflows after long idle periods
+	if (elapsed >= max_tokens / gadget_rate_limit)
+		bucket->tokens = max_tokens;
+	else
+		bucket->tokens += elapsed * gadget_rate_limit;
+	if (bucket->tokens > max_tokens)
+		bucket->tokens = max_tokens;
+
+	if (bucket->tokens < GADGET_NSEC_PER_SEC)
+		return true;
+	bucket->tokens -= GADGET_NSEC_PER_SEC;
+	return false;
+}
+
+// gadget_should_sample_out returns true if the event should be dropped by
+// sampling or because it exceeds the rate limit of the given key. Use a
+// constant key for a global rate limit or e.g. the mount namespace id for a
+// limit per container. Call it before gadget_reserve_buf(), so dropped events
+// don't take space in the buffer.
+static __always_inline bool gadget_should_sample_out(__u64 key)
+{
+	if (gadget_sample_ratio > 1 &&
+	    bpf_get_prandom_u32() % gadget_sample_ratio != 0) {
+		gadget_count_sampled_out(GADGET_SAMPLED_OUT_RATIO);
+		return true;
+	}
+
+	if (gadget_rate_limited(key)) {
+		gadget_count_sampled_out(GADGET_SAMPLED_OUT_RATE_LIMIT);
+		return true;
+	}
+
+	return false;
+}
+
+#endif /* SAMPLING_H */
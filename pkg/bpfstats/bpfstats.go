@@ -24,6 +24,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/cilium/ebpf"
 	"golang.org/x/sys/unix"
@@ -187,3 +188,62 @@ func GetMapMemUsage(m *ebpf.Map) (uint64, error) {
 	}
 	return 0, fmt.Errorf("finding memlock in fdinfo")
 }
+
+// ObjectsVar is the name of the gadget context variable the ebpf operator
+// stores the Objects of a gadget in
+const ObjectsVar = "bpfstats.objects"
+
+// Objects are the eBPF programs and maps loaded by a gadget
+type Objects struct {
+	ProgramIDs []ebpf.ProgramID
+	MapIDs     []ebpf.MapID
+}
+
+// Usage is the combined resource usage of Objects
+type Usage struct {
+	// Runtime and RunCount are only counted while stats collection is enabled
+	// using EnableBPFStats()
+	Runtime  time.Duration
+	RunCount uint64
+
+	// MapMemory is the memory locked by the maps in bytes
+	MapMemory uint64
+}
+
+// Usage returns the current resource usage of the objects. Objects that don't
+// exist anymore are skipped.
+func (o *Objects) Usage() (Usage, error) {
+	usage := Usage{}
+	for _, id := range o.ProgramIDs {
+		prog, err := ebpf.NewProgramFromID(id)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return usage, fmt.Errorf("getting program ID (%d): %w", id, err)
+		}
+		stats, err := prog.Stats()
+		prog.Close()
+		if err != nil {
+			return usage, fmt.Errorf("getting stats of program ID (%d): %w", id, err)
+		}
+		usage.Runtime += stats.Runtime
+		usage.RunCount += stats.RunCount
+	}
+	for _, id := range o.MapIDs {
+		m, err := ebpf.NewMapFromID(id)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return usage, fmt.Errorf("getting map ID (%d): %w", id, err)
+		}
+		size, err := GetMapMemUsage(m)
+		m.Close()
+		if err != nil {
+			return usage, fmt.Errorf("getting memory usage of map ID (%d): %w", id, err)
+		}
+		usage.MapMemory += size
+	}
+	return usage, nil
+}
@@ -30,6 +30,9 @@ type oldResource[T any] struct {
 type CachedMap[Key comparable, T any] interface {
 	Clear()
 	Add(key Key, obj T)
+	// Remove moves an entry to the old entries: Get, GetCmp, Keys and Values
+	// still return it until it's pruned, between oldEntryTTL and
+	// 2*oldEntryTTL later, or until Add replaces it
 	Remove(key Key)
 	Keys() []Key
 	Values() []T
@@ -30,6 +30,8 @@ const (
 	PodmanSocketPath      = "podman-socketpath"
 	GadgetNamespace       = "gadget-namespace"
 	DaemonLogLevel        = "daemon-log-level"
+	AuditBackend          = "audit-backend"
+	AuditLogFile          = "audit-log-file"
 
 	VerifyImage        = "verify-image"
 	PublicKeys         = "public-keys"
@@ -37,6 +39,18 @@ const (
 	DisallowPulling    = "disallow-pulling"
 	AllowedGadgets     = "allowed-gadgets"
 
+	MaxInstances         = "instance-quotas.max-instances"
+	MaxInstancesPerImage = "instance-quotas.max-instances-per-image"
+	MaxMapMemory         = "instance-quotas.max-map-memory"
+	MaxCPU               = "instance-quotas.max-cpu"
+	MaxEventRate         = "instance-quotas.max-event-rate"
+	EventRateAction      = "instance-quotas.event-rate-action"
+	QuotaInterval        = "instance-quotas.interval"
+
+	AuditBackendNone      = "none"
+	AuditBackendFile      = "file"
+	AuditBackendK8sEvents = "k8s-events"
+
 	OtelMetricsListen        = "otel-metrics-listen"
 	OtelMetricsListenAddress = "otel-metrics-listen-address"
 )
@@ -46,6 +60,9 @@ func Init() error {
 
 	config.Config.SetDefault(EventsBufferLengthKey, 16384)
 	config.Config.SetDefault(DaemonLogLevel, "info")
+	config.Config.SetDefault(AuditBackend, AuditBackendNone)
+	config.Config.SetDefault(EventRateAction, "throttle")
+	config.Config.SetDefault(QuotaInterval, "10s")
 
 	err := config.Config.ReadInConfig()
 	if err != nil {
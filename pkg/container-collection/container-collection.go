@@ -77,6 +77,9 @@ type ContainerCollection struct {
 	closed bool
 	done   chan struct{}
 
+	// ready is closed once Initialize() has been called.
+	ready chan struct{}
+
 	// functions to be called on Close()
 	cleanUpFuncs []func()
 
@@ -97,6 +100,7 @@ type ContainerCollectionOption func(*ContainerCollection) error
 // we don't use a contructor in that case.
 func (cc *ContainerCollection) Initialize(options ...ContainerCollectionOption) error {
 	cc.done = make(chan struct{})
+	cc.ready = make(chan struct{})
 
 	if cc.initialized {
 		panic("Initialize already called")
@@ -119,6 +123,7 @@ func (cc *ContainerCollection) Initialize(options ...ContainerCollectionOption)
 	cc.initialContainers = nil
 
 	cc.initialized = true
+	close(cc.ready)
 	return nil
 }
 
@@ -0,0 +1,341 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containercollection
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/containerd/nri/pkg/api"
+	"github.com/containerd/nri/pkg/stub"
+	ocispec "github.com/opencontainers/runtime-spec/specs-go"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/types"
+)
+
+const (
+	nriPluginName = "inspektor-gadget"
+	// nriPluginIdx only defines the order in which NRI calls the plugins. As
+	// we don't adjust containers, it doesn't matter.
+	nriPluginIdx = "90"
+
+	nriReconnectDelay = 5 * time.Second
+)
+
+type nriEventType int
+
+const (
+	nriEventAdd nriEventType = iota
+	nriEventRemove
+	nriEventSync
+)
+
+type nriEvent struct {
+	typ        nriEventType
+	containers []*Container
+	ids        []string
+}
+
+// nriPlugin is an NRI v2 plugin receiving the lifecycle events of the pods
+// and containers from the container runtime
+type nriPlugin struct {
+	events chan nriEvent
+	done   <-chan struct{}
+
+	mu          sync.Mutex
+	runtimeName types.RuntimeName
+	pods        map[string]*api.PodSandbox
+}
+
+func (p *nriPlugin) send(ev nriEvent) {
+	select {
+	case p.events <- ev:
+	case <-p.done:
+	}
+}
+
+func (p *nriPlugin) Configure(_ context.Context, _, runtime, version string) (api.EventMask, error) {
+	log.Infof("NRI plugin: connected to %s %s", runtime, version)
+
+	p.mu.Lock()
+	p.runtimeName = types.String2RuntimeName(runtime)
+	p.mu.Unlock()
+
+	return api.MustParseEventMask("RunPodSandbox,RemovePodSandbox,StartContainer,StopContainer,RemoveContainer"), nil
+}
+
+func (p *nriPlugin) Synchronize(_ context.Context, pods []*api.PodSandbox, containers []*api.Container) ([]*api.ContainerUpdate, error) {
+	p.mu.Lock()
+	p.pods = make(map[string]*api.PodSandbox, len(pods))
+	for _, pod := range pods {
+		p.pods[pod.GetId()] = pod
+	}
+	p.mu.Unlock()
+
+	ev := nriEvent{typ: nriEventSync}
+	for _, ctr := range containers {
+		if ctr.GetState() != api.ContainerState_CONTAINER_RUNNING {
+			continue
+		}
+		c, err := p.container(nil, ctr)
+		if err != nil {
+			log.Debugf("NRI plugin: skipping container %s: %s", ctr.GetId(), err)
+			continue
+		}
+		ev.containers = append(ev.containers, c)
+	}
+	p.send(ev)
+	return nil, nil
+}
+
+func (p *nriPlugin) RunPodSandbox(_ context.Context, pod *api.PodSandbox) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pods[pod.GetId()] = pod
+	return nil
+}
+
+func (p *nriPlugin) RemovePodSandbox(_ context.Context, pod *api.PodSandbox) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.pods, pod.GetId())
+	return nil
+}
+
+func (p *nriPlugin) StartContainer(_ context.Context, pod *api.PodSandbox, ctr *api.Container) error {
+	c, err := p.container(pod, ctr)
+	if err != nil {
+		log.Warnf("NRI plugin: skipping container %s: %s", ctr.GetId(), err)
+		return nil
+	}
+	p.send(nriEvent{typ: nriEventAdd, containers: []*Container{c}})
+	return nil
+}
+
+func (p *nriPlugin) StopContainer(_ context.Context, _ *api.PodSandbox, ctr *api.Container) ([]*api.ContainerUpdate, error) {
+	p.send(nriEvent{typ: nriEventRemove, ids: []string{ctr.GetId()}})
+	return nil, nil
+}
+
+func (p *nriPlugin) RemoveContainer(_ context.Context, _ *api.PodSandbox, ctr *api.Container) error {
+	// Containers exiting by themselves aren't stopped through the runtime
+	p.send(nriEvent{typ: nriEventRemove, ids: []string{ctr.GetId()}})
+	return nil
+}
+
+// container returns the Container for an NRI container. pod can be nil to
+// take it from the pods known by the plugin.
+func (p *nriPlugin) container(pod *api.PodSandbox, ctr *api.Container) (*Container, error) {
+	p.mu.Lock()
+	runtimeName := p.runtimeName
+	if pod == nil {
+		pod = p.pods[ctr.GetPodSandboxId()]
+	}
+	p.mu.Unlock()
+
+	return nriContainerToContainer(runtimeName, pod, ctr)
+}
+
+// nriContainerToContainer creates a Container with the metadata provided by
+// NRI. The OCI config only contains the annotations, mounts and cgroup path,
+// which is what the enrichers use.
+func nriContainerToContainer(runtimeName types.RuntimeName, pod *api.PodSandbox, ctr *api.Container) (*Container, error) {
+	if ctr.GetId() == "" {
+		return nil, fmt.Errorf("container id not set")
+	}
+	if ctr.GetPid() == 0 {
+		return nil, fmt.Errorf("container pid not set")
+	}
+
+	spec := ocispec.Spec{
+		Annotations: ctr.GetAnnotations(),
+		Linux: &ocispec.Linux{
+			CgroupsPath: ctr.GetLinux().GetCgroupsPath(),
+		},
+	}
+	for _, m := range ctr.GetMounts() {
+		spec.Mounts = append(spec.Mounts, ocispec.Mount{
+			Destination: m.GetDestination(),
+			Type:        m.GetType(),
+			Source:      m.GetSource(),
+			Options:     m.GetOptions(),
+		})
+	}
+	ociConfig, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling OCI config: %w", err)
+	}
+
+	c := &Container{
+		Runtime: RuntimeMetadata{
+			BasicRuntimeMetadata: types.BasicRuntimeMetadata{
+				RuntimeName:   runtimeName,
+				ContainerID:   ctr.GetId(),
+				ContainerPID:  ctr.GetPid(),
+				ContainerName: ctr.GetName(),
+			},
+		},
+		OciConfig: string(ociConfig),
+		SandboxId: ctr.GetPodSandboxId(),
+	}
+	c.K8s.ContainerName = ctr.GetName()
+	if pod != nil {
+		c.K8s.Namespace = pod.GetNamespace()
+		c.K8s.PodName = pod.GetName()
+		c.K8s.PodUID = pod.GetUid()
+		c.SetPodLabels(pod.GetLabels())
+	}
+	return c, nil
+}
+
+// WithNRIPlugin registers Inspektor Gadget as an NRI v2 plugin in the
+// container runtime listening on socketPath to get the initial containers and
+// the stream of container events. Unlike the nri hook mode, it doesn't need
+// to install anything on the host. The plugin reconnects when the runtime is
+// restarted.
+func WithNRIPlugin(socketPath string) ContainerCollectionOption {
+	return func(cc *ContainerCollection) error {
+		p := &nriPlugin{
+			events: make(chan nriEvent, 64),
+			done:   cc.done,
+			pods:   make(map[string]*api.PodSandbox),
+		}
+
+		newStub := func(onClose func()) (stub.Stub, error) {
+			return stub.New(p,
+				stub.WithPluginName(nriPluginName),
+				stub.WithPluginIdx(nriPluginIdx),
+				stub.WithSocketPath(socketPath),
+				stub.WithOnClose(onClose),
+			)
+		}
+
+		// Fail early if the runtime doesn't support NRI, reconnections are
+		// handled in the background
+		closed := make(chan struct{})
+		s, err := newStub(func() { close(closed) })
+		if err != nil {
+			return fmt.Errorf("creating NRI plugin: %w", err)
+		}
+		if err := s.Start(context.TODO()); err != nil {
+			return fmt.Errorf("starting NRI plugin on %s: %w", socketPath, err)
+		}
+
+		var mu sync.Mutex
+		cc.cleanUpFuncs = append(cc.cleanUpFuncs, func() {
+			mu.Lock()
+			defer mu.Unlock()
+			s.Stop()
+		})
+
+		go func() {
+			for {
+				select {
+				case <-closed:
+				case <-cc.done:
+					return
+				}
+
+				log.Warnf("NRI plugin: connection to %s closed, reconnecting", socketPath)
+				for {
+					select {
+					case <-time.After(nriReconnectDelay):
+					case <-cc.done:
+						return
+					}
+
+					closed = make(chan struct{})
+					newClosed := closed
+					newS, err := newStub(func() { close(newClosed) })
+					if err == nil {
+						err = newS.Start(context.TODO())
+					}
+					if err != nil {
+						log.Debugf("NRI plugin: reconnecting to %s: %s", socketPath, err)
+						continue
+					}
+
+					mu.Lock()
+					s = newS
+					mu.Unlock()
+					break
+				}
+			}
+		}()
+
+		go handleNRIEvents(cc, p.events)
+
+		return nil
+	}
+}
+
+// handleNRIEvents adds and removes the containers notified by the NRI plugin
+// once the collection is initialized
+func handleNRIEvents(cc *ContainerCollection, events <-chan nriEvent) {
+	select {
+	case <-cc.ready:
+	case <-cc.done:
+		return
+	}
+
+	// known keeps the containers added by the plugin, to remove the ones
+	// that stopped while disconnected from the runtime
+	known := make(map[string]struct{})
+
+	for {
+		var ev nriEvent
+		select {
+		case ev = <-events:
+		case <-cc.done:
+			return
+		}
+
+		switch ev.typ {
+		case nriEventSync:
+			running := make(map[string]struct{}, len(ev.containers))
+			for _, c := range ev.containers {
+				running[c.Runtime.ContainerID] = struct{}{}
+			}
+			for id := range known {
+				if _, ok := running[id]; !ok {
+					log.Debugf("NRI plugin: removing container %s", id)
+					cc.RemoveContainer(id)
+					delete(known, id)
+				}
+			}
+			fallthrough
+		case nriEventAdd:
+			for _, c := range ev.containers {
+				if cc.GetContainer(c.Runtime.ContainerID) != nil {
+					known[c.Runtime.ContainerID] = struct{}{}
+					continue
+				}
+				log.Debugf("NRI plugin: adding container %s", c.Runtime.ContainerID)
+				cc.AddContainer(c)
+				known[c.Runtime.ContainerID] = struct{}{}
+			}
+		case nriEventRemove:
+			for _, id := range ev.ids {
+				log.Debugf("NRI plugin: removing container %s", id)
+				cc.RemoveContainer(id)
+				delete(known, id)
+			}
+		}
+	}
+}
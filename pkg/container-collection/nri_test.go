@@ -0,0 +1,125 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containercollection
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/containerd/nri/pkg/api"
+	ocispec "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/types"
+)
+
+func TestNRIContainerToContainer(t *testing.T) {
+	pod := &api.PodSandbox{
+		Id:        "sandbox1",
+		Name:      "mypod",
+		Uid:       "uid1",
+		Namespace: "default",
+		Labels:    map[string]string{"app": "web"},
+	}
+	ctr := &api.Container{
+		Id:           "ctr1",
+		PodSandboxId: "sandbox1",
+		Name:         "nginx",
+		Pid:          1234,
+		Annotations:  map[string]string{"io.kubernetes.cri.container-type": "container"},
+		Mounts: []*api.Mount{
+			{Destination: "/data", Type: "bind", Source: "/var/data", Options: []string{"rbind", "ro"}},
+		},
+		Linux: &api.LinuxContainer{
+			CgroupsPath: "kubepods-besteffort-poduid1.slice:cri-containerd:ctr1",
+		},
+	}
+
+	c, err := nriContainerToContainer(types.RuntimeNameContainerd, pod, ctr)
+	require.NoError(t, err)
+	require.Equal(t, "ctr1", c.Runtime.ContainerID)
+	require.Equal(t, uint32(1234), c.Runtime.ContainerPID)
+	require.Equal(t, types.RuntimeNameContainerd, c.Runtime.RuntimeName)
+	require.Equal(t, "sandbox1", c.SandboxId)
+	require.Equal(t, "nginx", c.K8s.ContainerName)
+	require.Equal(t, "mypod", c.K8s.PodName)
+	require.Equal(t, "default", c.K8s.Namespace)
+	require.Equal(t, "uid1", c.K8s.PodUID)
+	require.Equal(t, map[string]string{"app": "web"}, c.K8s.PodLabels)
+
+	var spec ocispec.Spec
+	require.NoError(t, json.Unmarshal([]byte(c.OciConfig), &spec))
+	require.Equal(t, ctr.Annotations, spec.Annotations)
+	require.Equal(t, ctr.Linux.CgroupsPath, spec.Linux.CgroupsPath)
+	require.Equal(t, []ocispec.Mount{
+		{Destination: "/data", Type: "bind", Source: "/var/data", Options: []string{"rbind", "ro"}},
+	}, spec.Mounts)
+
+	// Without pod, the Kubernetes metadata is filled by the enrichers
+	c, err = nriContainerToContainer(types.RuntimeNameContainerd, nil, ctr)
+	require.NoError(t, err)
+	require.Empty(t, c.K8s.PodName)
+	require.Nil(t, c.K8s.PodLabels)
+
+	_, err = nriContainerToContainer(types.RuntimeNameContainerd, pod, &api.Container{Id: "ctr2"})
+	require.Error(t, err)
+}
+
+func TestHandleNRIEvents(t *testing.T) {
+	newContainer := func(id string, mntns uint64) *Container {
+		c := &Container{Mntns: mntns, Netns: mntns}
+		c.Runtime.ContainerID = id
+		return c
+	}
+	hasContainers := func(cc *ContainerCollection, ids ...string) func() bool {
+		return func() bool {
+			n := 0
+			cc.ContainerRange(func(*Container) { n++ })
+			if n != len(ids) {
+				return false
+			}
+			for _, id := range ids {
+				if cc.GetContainer(id) == nil {
+					return false
+				}
+			}
+			return true
+		}
+	}
+
+	events := make(chan nriEvent, 8)
+	cc := &ContainerCollection{}
+	err := cc.Initialize(func(cc *ContainerCollection) error {
+		go handleNRIEvents(cc, events)
+		// Events received before the collection is ready are handled
+		// afterwards
+		events <- nriEvent{typ: nriEventSync, containers: []*Container{newContainer("ctr1", 1), newContainer("ctr2", 2)}}
+		return nil
+	})
+	require.NoError(t, err)
+	t.Cleanup(cc.Close)
+
+	require.Eventually(t, hasContainers(cc, "ctr1", "ctr2"), time.Second, 10*time.Millisecond)
+
+	events <- nriEvent{typ: nriEventAdd, containers: []*Container{newContainer("ctr3", 3)}}
+	events <- nriEvent{typ: nriEventRemove, ids: []string{"ctr1"}}
+	require.Eventually(t, hasContainers(cc, "ctr2", "ctr3"), time.Second, 10*time.Millisecond)
+
+	// A sync after a reconnection removes the containers that stopped in
+	// the meantime
+	events <- nriEvent{typ: nriEventSync, containers: []*Container{newContainer("ctr3", 3), newContainer("ctr4", 4)}}
+	require.Eventually(t, hasContainers(cc, "ctr3", "ctr4"), time.Second, 10*time.Millisecond)
+}
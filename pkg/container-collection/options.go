@@ -208,6 +208,18 @@ func WithContainerRuntimeEnrichment(runtime *containerutilsTypes.RuntimeConfig)
 			}
 		})
 
+		// Containers of some runtimes aren't detected by the container hooks,
+		// so get them from the runtime. Start watching before listing the
+		// current containers to not miss any of them.
+		if notifier, ok := runtimeClient.(runtimeclient.ContainerEventsNotifier); ok {
+			if err := watchRuntimeContainers(cc, runtime.Name, runtimeClient, notifier); err != nil {
+				if !cc.disableContainerRuntimeWarnings {
+					log.Warnf("Runtime enricher (%s): couldn't watch containers: %s",
+						runtime.Name, err)
+				}
+			}
+		}
+
 		// Enrich already running containers
 		containers, err := runtimeClient.GetContainers()
 		if err != nil {
@@ -225,37 +237,97 @@ func WithContainerRuntimeEnrichment(runtime *containerutilsTypes.RuntimeConfig)
 				continue
 			}
 
-			containerDetails, err := runtimeClient.GetContainerDetails(container.Runtime.ContainerID)
+			c, err := getRuntimeContainer(runtimeClient, container.Runtime.ContainerID)
 			if err != nil {
-				log.Debugf("Runtime enricher (%s): Skip container %q (ID: %s, image: %s): couldn't find container: %s",
+				log.Debugf("Runtime enricher (%s): Skip container %q (ID: %s, image: %s): %s",
 					runtime.Name, container.Runtime.ContainerName, container.Runtime.ContainerID,
 					container.Runtime.ContainerImageName, err)
 				continue
 			}
+			cc.initialContainers = append(cc.initialContainers, c)
+		}
 
-			pid := containerDetails.Pid
-			if pid > math.MaxUint32 {
-				log.Errorf("Container PID (%d) exceeds math.MaxUint32 (%d), skipping this container", pid, math.MaxUint32)
-				continue
-			}
+		return nil
+	}
+}
 
-			// Check if process exists. Better check now rather than fail later in the enrichment pipeline.
-			containerPidPath := filepath.Join(host.HostProcFs, fmt.Sprint(pid))
-			_, err = os.Stat(containerPidPath)
-			if os.IsNotExist(err) {
-				log.Warnf("Runtime enricher (%s): Skip container %q (ID: %s, image: %s): PID %d doesn't exist",
-					runtime.Name, container.Runtime.ContainerName, container.Runtime.ContainerID, container.Runtime.ContainerImageName, pid)
-				continue
-			}
+// getRuntimeContainer returns a new Container with the details of a running
+// container provided by the runtime client.
+func getRuntimeContainer(runtimeClient runtimeclient.ContainerRuntimeClient, containerID string) (*Container, error) {
+	containerDetails, err := runtimeClient.GetContainerDetails(containerID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find container: %w", err)
+	}
 
-			var c Container
-			c.Runtime.ContainerPID = uint32(pid)
-			enrichContainerWithContainerData(&containerDetails.ContainerData, &c)
-			cc.initialContainers = append(cc.initialContainers, &c)
-		}
+	pid := containerDetails.Pid
+	if pid > math.MaxUint32 {
+		return nil, fmt.Errorf("container PID (%d) exceeds math.MaxUint32 (%d)", pid, math.MaxUint32)
+	}
 
-		return nil
+	// Check if process exists. Better check now rather than fail later in the enrichment pipeline.
+	containerPidPath := filepath.Join(host.HostProcFs, fmt.Sprint(pid))
+	_, err = os.Stat(containerPidPath)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("PID %d doesn't exist", pid)
+	}
+
+	var c Container
+	c.Runtime.ContainerPID = uint32(pid)
+	enrichContainerWithContainerData(&containerDetails.ContainerData, &c)
+	return &c, nil
+}
+
+// watchRuntimeContainers adds and removes the containers notified by the
+// runtime client. Events are only handled once the collection is initialized,
+// so all the enrichers are set up.
+func watchRuntimeContainers(
+	cc *ContainerCollection,
+	runtimeName types.RuntimeName,
+	runtimeClient runtimeclient.ContainerRuntimeClient,
+	notifier runtimeclient.ContainerEventsNotifier,
+) error {
+	events := make(chan runtimeclient.ContainerEvent, 64)
+	err := notifier.WatchContainers(func(ev runtimeclient.ContainerEvent) {
+		select {
+		case events <- ev:
+		case <-cc.done:
+		}
+	})
+	if err != nil {
+		return err
 	}
+
+	go func() {
+		select {
+		case <-cc.ready:
+		case <-cc.done:
+			return
+		}
+
+		for {
+			var ev runtimeclient.ContainerEvent
+			select {
+			case ev = <-events:
+			case <-cc.done:
+				return
+			}
+
+			switch ev.Type {
+			case runtimeclient.ContainerEventStarted:
+				c, err := getRuntimeContainer(runtimeClient, ev.ContainerID)
+				if err != nil {
+					log.Debugf("Runtime watcher (%s): Skip container %q: %s", runtimeName, ev.ContainerID, err)
+					continue
+				}
+				log.Debugf("Runtime watcher (%s): adding container %q", runtimeName, ev.ContainerID)
+				cc.AddContainer(c)
+			case runtimeclient.ContainerEventStopped:
+				log.Debugf("Runtime watcher (%s): removing container %q", runtimeName, ev.ContainerID)
+				cc.RemoveContainer(ev.ContainerID)
+			}
+		}
+	}()
+	return nil
 }
 
 // WithPodInformer uses a pod informer to get both initial containers and the
@@ -828,6 +900,12 @@ func WithOCIConfigEnrichment() ContainerCollectionOption {
 func WithOCIConfigForInitialContainer() ContainerCollectionOption {
 	return func(cc *ContainerCollection) error {
 		for _, container := range cc.initialContainers {
+			// These runtimes don't run their containers from an OCI bundle
+			if container.Runtime.RuntimeName == types.RuntimeNameIncus ||
+				container.Runtime.RuntimeName == types.RuntimeNameNspawn {
+				continue
+			}
+
 			info, err := processhelpers.GetProcessInfo(int(container.ContainerPid()), 0, &procOpts{})
 			if err != nil {
 				log.Errorf("OCIConfig enricher: failed to get process info for container %s: %s", container.Runtime.ContainerID, err)
@@ -31,6 +31,8 @@ import (
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils/containerd"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils/crio"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils/docker"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils/incus"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils/nspawn"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils/podman"
 	runtimeclient "github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils/runtime-client"
 	containerutilsTypes "github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils/types"
@@ -44,6 +46,8 @@ var AvailableRuntimes = []string{
 	types.RuntimeNameContainerd.String(),
 	types.RuntimeNameCrio.String(),
 	types.RuntimeNamePodman.String(),
+	types.RuntimeNameIncus.String(),
+	types.RuntimeNameNspawn.String(),
 }
 
 var AvailableRuntimeProtocols = []string{
@@ -77,6 +81,18 @@ func NewContainerRuntimeClient(runtime *containerutilsTypes.RuntimeConfig) (runt
 			socketPath = filepath.Join(host.HostRoot, envsp)
 		}
 		return podman.NewPodmanClient(socketPath), nil
+	case types.RuntimeNameIncus:
+		socketPath := runtime.SocketPath
+		if envsp := os.Getenv("INSPEKTOR_GADGET_INCUS_SOCKETPATH"); envsp != "" && socketPath == "" {
+			socketPath = filepath.Join(host.HostRoot, envsp)
+		}
+		return incus.NewIncusClient(socketPath), nil
+	case types.RuntimeNameNspawn:
+		socketPath := runtime.SocketPath
+		if envsp := os.Getenv("INSPEKTOR_GADGET_NSPAWN_SOCKETPATH"); envsp != "" && socketPath == "" {
+			socketPath = filepath.Join(host.HostRoot, envsp)
+		}
+		return nspawn.NewNspawnClient(socketPath), nil
 	default:
 		return nil, fmt.Errorf("unknown container runtime: %s (available %s)",
 			runtime.Name, strings.Join(AvailableRuntimes, ", "))
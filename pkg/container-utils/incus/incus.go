@@ -0,0 +1,363 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package incus implements a runtime client for Incus and LXD system
+// containers. Both expose the same REST API on a local unix socket.
+package incus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils/cgroups"
+	runtimeclient "github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils/runtime-client"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/types"
+)
+
+const (
+	defaultConnectionTimeout = 2 * time.Second
+	instancesListURL         = "http://incus/1.0/instances?recursion=1&all-projects=true"
+	instanceURL              = "http://incus/1.0/instances/%s?project=%s"
+	instanceStateURL         = "http://incus/1.0/instances/%s/state?project=%s"
+	eventsURL                = "ws://incus/1.0/events?type=lifecycle&all-projects=true"
+
+	defaultProject        = "default"
+	instanceTypeContainer = "container"
+	configImageDesc       = "image.description"
+	configImageOS         = "image.os"
+	configImageRel        = "image.release"
+	configBaseImage       = "volatile.base_image"
+	lifecycleStarted      = "instance-started"
+	lifecycleRestart      = "instance-restarted"
+	lifecycleStopped      = "instance-stopped"
+	lifecycleShutdown     = "instance-shutdown"
+	lifecycleDeleted      = "instance-deleted"
+)
+
+type IncusClient struct {
+	client http.Client
+	dialer websocket.Dialer
+
+	mu     sync.Mutex
+	events *websocket.Conn
+	closed bool
+}
+
+func NewIncusClient(socketPath string) runtimeclient.ContainerRuntimeClient {
+	if socketPath == "" {
+		socketPath = runtimeclient.IncusDefaultSocketPath
+	}
+
+	dial := func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", socketPath)
+	}
+
+	return &IncusClient{
+		client: http.Client{
+			Transport: &http.Transport{
+				DialContext: dial,
+			},
+			Timeout: defaultConnectionTimeout,
+		},
+		dialer: websocket.Dialer{
+			NetDialContext:   dial,
+			HandshakeTimeout: defaultConnectionTimeout,
+		},
+	}
+}
+
+// response is the envelope of all the responses of the Incus API
+type response struct {
+	Type      string          `json:"type"`
+	Error     string          `json:"error"`
+	ErrorCode int             `json:"error_code"`
+	Metadata  json.RawMessage `json:"metadata"`
+}
+
+type instance struct {
+	Name           string            `json:"name"`
+	Project        string            `json:"project"`
+	Type           string            `json:"type"`
+	Status         string            `json:"status"`
+	ExpandedConfig map[string]string `json:"expanded_config"`
+}
+
+type instanceState struct {
+	Status string `json:"status"`
+	Pid    int    `json:"pid"`
+}
+
+func (c *IncusClient) get(url string, metadata any) error {
+	resp, err := c.client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var r response
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	if r.Type == "error" || resp.StatusCode != http.StatusOK {
+		if r.Error == "" {
+			r.Error = resp.Status
+		}
+		return errors.New(r.Error)
+	}
+	if err := json.Unmarshal(r.Metadata, metadata); err != nil {
+		return fmt.Errorf("decoding metadata: %w", err)
+	}
+	return nil
+}
+
+// containerID returns the ID of an instance. Incus uses the same format to
+// name the instances in LXC, e.g. in the cgroup path: instances in the
+// default project keep their name, others are prefixed with the project name.
+// Instance and project names can't contain "_".
+func containerID(project, name string) string {
+	if project == "" || project == defaultProject {
+		return name
+	}
+	return project + "_" + name
+}
+
+func parseContainerID(containerID string) (project, name string, err error) {
+	containerID, err = runtimeclient.ParseContainerID(types.RuntimeNameIncus, containerID)
+	if err != nil {
+		return "", "", err
+	}
+	if project, name, ok := strings.Cut(containerID, "_"); ok {
+		return project, name, nil
+	}
+	return defaultProject, containerID, nil
+}
+
+func statusToRuntimeClientState(status string) string {
+	switch status {
+	case "Running", "Frozen":
+		return runtimeclient.StateRunning
+	case "Stopped":
+		return runtimeclient.StateExited
+	default:
+		return runtimeclient.StateUnknown
+	}
+}
+
+func instanceToContainerData(i *instance) *runtimeclient.ContainerData {
+	imageName := i.ExpandedConfig[configImageDesc]
+	if imageName == "" && i.ExpandedConfig[configImageOS] != "" {
+		imageName = strings.TrimSuffix(i.ExpandedConfig[configImageOS]+"/"+i.ExpandedConfig[configImageRel], "/")
+	}
+	imageID := i.ExpandedConfig[configBaseImage]
+	imageDigest := ""
+	if imageID != "" {
+		imageDigest = "sha256:" + imageID
+	}
+
+	return &runtimeclient.ContainerData{
+		Runtime: runtimeclient.RuntimeContainerData{
+			ContainerID:          containerID(i.Project, i.Name),
+			ContainerName:        i.Name,
+			RuntimeName:          types.RuntimeNameIncus,
+			ContainerImageName:   imageName,
+			ContainerImageID:     imageID,
+			ContainerImageDigest: imageDigest,
+			State:                statusToRuntimeClientState(i.Status),
+		},
+	}
+}
+
+func (c *IncusClient) GetContainers() ([]*runtimeclient.ContainerData, error) {
+	var instances []*instance
+	if err := c.get(instancesListURL, &instances); err != nil {
+		return nil, fmt.Errorf("listing instances: %w", err)
+	}
+
+	ret := make([]*runtimeclient.ContainerData, 0, len(instances))
+	for _, i := range instances {
+		// Virtual machines aren't containers
+		if i.Type != instanceTypeContainer {
+			continue
+		}
+		ret = append(ret, instanceToContainerData(i))
+	}
+	return ret, nil
+}
+
+func (c *IncusClient) getInstance(containerID string) (*instance, error) {
+	project, name, err := parseContainerID(containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	i := &instance{}
+	if err := c.get(fmt.Sprintf(instanceURL, url.PathEscape(name), url.QueryEscape(project)), i); err != nil {
+		return nil, fmt.Errorf("getting instance %q: %w", containerID, err)
+	}
+	if i.Type != instanceTypeContainer {
+		return nil, fmt.Errorf("instance %q is not a container but a %s", containerID, i.Type)
+	}
+	if i.Project == "" {
+		i.Project = project
+	}
+	return i, nil
+}
+
+func (c *IncusClient) GetContainer(containerID string) (*runtimeclient.ContainerData, error) {
+	i, err := c.getInstance(containerID)
+	if err != nil {
+		return nil, err
+	}
+	return instanceToContainerData(i), nil
+}
+
+func (c *IncusClient) GetContainerDetails(containerID string) (*runtimeclient.ContainerDetailsData, error) {
+	i, err := c.getInstance(containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &instanceState{}
+	err = c.get(fmt.Sprintf(instanceStateURL, url.PathEscape(i.Name), url.QueryEscape(i.Project)), state)
+	if err != nil {
+		return nil, fmt.Errorf("getting state of instance %q: %w", containerID, err)
+	}
+	if state.Pid == 0 {
+		return nil, errors.New("got zero pid")
+	}
+
+	containerData := instanceToContainerData(i)
+	containerData.Runtime.State = statusToRuntimeClientState(state.Status)
+
+	containerDetailsData := &runtimeclient.ContainerDetailsData{
+		ContainerData: *containerData,
+		Pid:           state.Pid,
+	}
+
+	// The API doesn't provide the cgroup path, take it from the init process
+	cgroupPathV1, cgroupPathV2, err := cgroups.GetCgroupPaths(state.Pid)
+	if err == nil {
+		containerDetailsData.CgroupsPath = cgroupPathV1
+		if containerDetailsData.CgroupsPath == "" {
+			containerDetailsData.CgroupsPath = cgroupPathV2
+		}
+	} else {
+		log.Warnf("failed to get cgroups info of container %s from /proc/%d/cgroup: %s",
+			containerID, state.Pid, err)
+	}
+
+	return containerDetailsData, nil
+}
+
+type lifecycleEvent struct {
+	Type     string `json:"type"`
+	Project  string `json:"project"`
+	Metadata struct {
+		Action string `json:"action"`
+		Source string `json:"source"`
+	} `json:"metadata"`
+}
+
+// parseLifecycleEvent returns the container events for an Incus lifecycle
+// event
+func parseLifecycleEvent(ev *lifecycleEvent) []runtimeclient.ContainerEvent {
+	var eventTypes []runtimeclient.ContainerEventType
+	switch ev.Metadata.Action {
+	case lifecycleStarted:
+		eventTypes = []runtimeclient.ContainerEventType{runtimeclient.ContainerEventStarted}
+	case lifecycleStopped, lifecycleShutdown, lifecycleDeleted:
+		eventTypes = []runtimeclient.ContainerEventType{runtimeclient.ContainerEventStopped}
+	case lifecycleRestart:
+		// The init process changes, so handle it as a new container
+		eventTypes = []runtimeclient.ContainerEventType{runtimeclient.ContainerEventStopped, runtimeclient.ContainerEventStarted}
+	default:
+		return nil
+	}
+
+	// source is like "/1.0/instances/name?project=project"
+	source, err := url.Parse(ev.Metadata.Source)
+	if err != nil || path.Dir(source.Path) != "/1.0/instances" {
+		return nil
+	}
+	project := ev.Project
+	if p := source.Query().Get("project"); p != "" {
+		project = p
+	}
+	id := containerID(project, path.Base(source.Path))
+
+	events := make([]runtimeclient.ContainerEvent, 0, len(eventTypes))
+	for _, typ := range eventTypes {
+		events = append(events, runtimeclient.ContainerEvent{Type: typ, ContainerID: id})
+	}
+	return events
+}
+
+func (c *IncusClient) WatchContainers(callback func(runtimeclient.ContainerEvent)) error {
+	conn, _, err := c.dialer.Dial(eventsURL, nil)
+	if err != nil {
+		return fmt.Errorf("connecting to events: %w", err)
+	}
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		conn.Close()
+		return errors.New("client closed")
+	}
+	c.events = conn
+	c.mu.Unlock()
+
+	go func() {
+		for {
+			var ev lifecycleEvent
+			if err := conn.ReadJSON(&ev); err != nil {
+				c.mu.Lock()
+				closed := c.closed
+				c.mu.Unlock()
+				if !closed {
+					log.Warnf("IncusClient: reading events: %s", err)
+				}
+				return
+			}
+			for _, containerEvent := range parseLifecycleEvent(&ev) {
+				callback(containerEvent)
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *IncusClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	if c.events != nil {
+		return c.events.Close()
+	}
+	return nil
+}
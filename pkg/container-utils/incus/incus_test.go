@@ -0,0 +1,218 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package incus
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	runtimeclient "github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils/runtime-client"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/types"
+)
+
+func TestContainerID(t *testing.T) {
+	type testCase struct {
+		project string
+		name    string
+		id      string
+	}
+	testCases := []testCase{
+		{project: "default", name: "c1", id: "c1"},
+		{project: "", name: "c1", id: "c1"},
+		{project: "web", name: "c1", id: "web_c1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.id, func(t *testing.T) {
+			assert.Equal(t, tc.id, containerID(tc.project, tc.name))
+
+			project, name, err := parseContainerID("incus://" + tc.id)
+			require.NoError(t, err)
+			if tc.project == "" {
+				tc.project = defaultProject
+			}
+			assert.Equal(t, tc.project, project)
+			assert.Equal(t, tc.name, name)
+		})
+	}
+
+	_, _, err := parseContainerID("docker://c1")
+	require.Error(t, err)
+}
+
+func TestParseLifecycleEvent(t *testing.T) {
+	type testCase struct {
+		name     string
+		event    string
+		expected []runtimeclient.ContainerEvent
+	}
+	testCases := []testCase{
+		{
+			name:  "started",
+			event: `{"type":"lifecycle","project":"default","metadata":{"action":"instance-started","source":"/1.0/instances/c1"}}`,
+			expected: []runtimeclient.ContainerEvent{
+				{Type: runtimeclient.ContainerEventStarted, ContainerID: "c1"},
+			},
+		},
+		{
+			name:  "stopped in project",
+			event: `{"type":"lifecycle","project":"web","metadata":{"action":"instance-stopped","source":"/1.0/instances/c1?project=web"}}`,
+			expected: []runtimeclient.ContainerEvent{
+				{Type: runtimeclient.ContainerEventStopped, ContainerID: "web_c1"},
+			},
+		},
+		{
+			name:  "restarted",
+			event: `{"type":"lifecycle","metadata":{"action":"instance-restarted","source":"/1.0/instances/c1"}}`,
+			expected: []runtimeclient.ContainerEvent{
+				{Type: runtimeclient.ContainerEventStopped, ContainerID: "c1"},
+				{Type: runtimeclient.ContainerEventStarted, ContainerID: "c1"},
+			},
+		},
+		{
+			name:  "other action",
+			event: `{"type":"lifecycle","metadata":{"action":"instance-updated","source":"/1.0/instances/c1"}}`,
+		},
+		{
+			name:  "snapshot",
+			event: `{"type":"lifecycle","metadata":{"action":"instance-started","source":"/1.0/instances/c1/snapshots/s1"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var ev lifecycleEvent
+			require.NoError(t, json.Unmarshal([]byte(tc.event), &ev))
+			assert.Equal(t, tc.expected, parseLifecycleEvent(&ev))
+		})
+	}
+}
+
+func syncResponse(metadata any) map[string]any {
+	return map[string]any{"type": "sync", "status": "Success", "status_code": 200, "metadata": metadata}
+}
+
+func newTestServer(t *testing.T, events chan string) string {
+	socketPath := filepath.Join(t.TempDir(), "unix.socket")
+	l, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	c1 := map[string]any{
+		"name":    "c1",
+		"project": "default",
+		"type":    "container",
+		"status":  "Running",
+		"expanded_config": map[string]string{
+			"image.description":   "Debian bookworm amd64",
+			"volatile.base_image": "abcd",
+		},
+	}
+	vm := map[string]any{"name": "vm1", "project": "default", "type": "virtual-machine", "status": "Running"}
+	c2 := map[string]any{"name": "c2", "project": "web", "type": "container", "status": "Stopped"}
+
+	mux := http.NewServeMux()
+	reply := func(w http.ResponseWriter, code int, v any) {
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(v)
+	}
+	mux.HandleFunc("GET /1.0/instances", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, http.StatusOK, syncResponse([]any{c1, vm, c2}))
+	})
+	mux.HandleFunc("GET /1.0/instances/{name}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.PathValue("name") + "/" + r.URL.Query().Get("project") {
+		case "c1/default":
+			reply(w, http.StatusOK, syncResponse(c1))
+		case "vm1/default":
+			reply(w, http.StatusOK, syncResponse(vm))
+		default:
+			reply(w, http.StatusNotFound, map[string]any{"type": "error", "error": "Instance not found", "error_code": 404})
+		}
+	})
+	mux.HandleFunc("GET /1.0/instances/{name}/state", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, http.StatusOK, syncResponse(map[string]any{"status": "Running", "pid": 1}))
+	})
+	mux.HandleFunc("GET /1.0/events", func(w http.ResponseWriter, r *http.Request) {
+		upgrader := websocket.Upgrader{}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for ev := range events {
+			conn.WriteMessage(websocket.TextMessage, []byte(ev))
+		}
+	})
+
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(l)
+	t.Cleanup(func() {
+		srv.Close()
+	})
+	return socketPath
+}
+
+func TestIncusClient(t *testing.T) {
+	events := make(chan string)
+	defer close(events)
+	c := NewIncusClient(newTestServer(t, events))
+	defer c.Close()
+
+	containers, err := c.GetContainers()
+	require.NoError(t, err)
+	require.Len(t, containers, 2)
+	assert.Equal(t, runtimeclient.RuntimeContainerData{
+		RuntimeName:          types.RuntimeNameIncus,
+		ContainerID:          "c1",
+		ContainerName:        "c1",
+		ContainerImageName:   "Debian bookworm amd64",
+		ContainerImageID:     "abcd",
+		ContainerImageDigest: "sha256:abcd",
+		State:                runtimeclient.StateRunning,
+	}, containers[0].Runtime)
+	assert.Equal(t, "web_c2", containers[1].Runtime.ContainerID)
+	assert.Equal(t, runtimeclient.StateExited, containers[1].Runtime.State)
+
+	details, err := c.GetContainerDetails("c1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, details.Pid)
+	assert.Equal(t, "c1", details.Runtime.ContainerName)
+
+	_, err = c.GetContainer("web_nope")
+	require.ErrorContains(t, err, "Instance not found")
+	_, err = c.GetContainer("vm1")
+	require.ErrorContains(t, err, "not a container")
+
+	notifier, ok := c.(runtimeclient.ContainerEventsNotifier)
+	require.True(t, ok)
+	received := make(chan runtimeclient.ContainerEvent, 1)
+	require.NoError(t, notifier.WatchContainers(func(ev runtimeclient.ContainerEvent) {
+		received <- ev
+	}))
+	events <- `{"type":"lifecycle","project":"default","metadata":{"action":"instance-started","source":"/1.0/instances/c1"}}`
+	select {
+	case ev := <-received:
+		assert.Equal(t, runtimeclient.ContainerEvent{Type: runtimeclient.ContainerEventStarted, ContainerID: "c1"}, ev)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the event")
+	}
+}
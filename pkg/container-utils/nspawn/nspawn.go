@@ -0,0 +1,311 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package nspawn implements a runtime client for the containers registered in
+// systemd-machined, like the ones started by systemd-nspawn. machined is
+// reached through D-Bus.
+package nspawn
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils/cgroups"
+	runtimeclient "github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils/runtime-client"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/types"
+)
+
+const (
+	machinedDest          = "org.freedesktop.machine1"
+	machinedPath          = dbus.ObjectPath("/org/freedesktop/machine1")
+	managerInterface      = "org.freedesktop.machine1.Manager"
+	machineInterface      = "org.freedesktop.machine1.Machine"
+	signalMachineNew      = "MachineNew"
+	signalMachineRemoved  = "MachineRemoved"
+	machineClassContainer = "container"
+	errServiceUnknown     = "org.freedesktop.DBus.Error.ServiceUnknown"
+)
+
+type NspawnClient struct {
+	socketPath string
+
+	mu     sync.Mutex
+	conn   *dbus.Conn
+	closed bool
+}
+
+func NewNspawnClient(socketPath string) runtimeclient.ContainerRuntimeClient {
+	if socketPath == "" {
+		socketPath = runtimeclient.NspawnDefaultSocketPath
+	}
+
+	return &NspawnClient{socketPath: socketPath}
+}
+
+// getConn returns the connection to the system bus, connecting to it on the
+// first use like the other clients connecting to their runtime on each
+// request
+func (c *NspawnClient) getConn() (*dbus.Conn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil, errors.New("client closed")
+	}
+	if c.conn != nil && c.conn.Connected() {
+		return c.conn, nil
+	}
+
+	conn, err := dbus.Connect("unix:path=" + c.socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to system bus at %q: %w", c.socketPath, err)
+	}
+	c.conn = conn
+	return conn, nil
+}
+
+func (c *NspawnClient) call(path dbus.ObjectPath, method string, ret any, args ...any) error {
+	conn, err := c.getConn()
+	if err != nil {
+		return err
+	}
+	return conn.Object(machinedDest, path).Call(method, 0, args...).Store(ret)
+}
+
+// machine contains the properties of org.freedesktop.machine1.Machine used
+// by the client
+type machine struct {
+	Name          string
+	Class         string
+	Leader        uint32
+	RootDirectory string
+	State         string
+}
+
+func stateToRuntimeClientState(state string) string {
+	switch state {
+	case "opening":
+		return runtimeclient.StateCreated
+	case "running":
+		return runtimeclient.StateRunning
+	case "closing":
+		return runtimeclient.StateExited
+	default:
+		return runtimeclient.StateUnknown
+	}
+}
+
+func machineToContainerData(m *machine) *runtimeclient.ContainerData {
+	return &runtimeclient.ContainerData{
+		Runtime: runtimeclient.RuntimeContainerData{
+			ContainerID:        m.Name,
+			ContainerName:      m.Name,
+			RuntimeName:        types.RuntimeNameNspawn,
+			ContainerImageName: m.RootDirectory,
+			State:              stateToRuntimeClientState(m.State),
+		},
+	}
+}
+
+func (c *NspawnClient) getMachineByPath(path dbus.ObjectPath) (*machine, error) {
+	var props map[string]dbus.Variant
+	err := c.call(path, "org.freedesktop.DBus.Properties.GetAll", &props, machineInterface)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &machine{}
+	for name, dst := range map[string]any{
+		"Name":          &m.Name,
+		"Class":         &m.Class,
+		"Leader":        &m.Leader,
+		"RootDirectory": &m.RootDirectory,
+		"State":         &m.State,
+	} {
+		if v, ok := props[name]; ok {
+			if err := v.Store(dst); err != nil {
+				return nil, fmt.Errorf("reading property %s: %w", name, err)
+			}
+		}
+	}
+	return m, nil
+}
+
+func (c *NspawnClient) getMachine(containerID string) (*machine, error) {
+	containerID, err := runtimeclient.ParseContainerID(types.RuntimeNameNspawn, containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var path dbus.ObjectPath
+	err = c.call(machinedPath, managerInterface+".GetMachine", &path, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("getting machine %q: %w", containerID, err)
+	}
+
+	m, err := c.getMachineByPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("getting properties of machine %q: %w", containerID, err)
+	}
+	if m.Class != machineClassContainer {
+		return nil, fmt.Errorf("machine %q is not a container but a %s", containerID, m.Class)
+	}
+	return m, nil
+}
+
+func (c *NspawnClient) GetContainers() ([]*runtimeclient.ContainerData, error) {
+	var machines []struct {
+		Name    string
+		Class   string
+		Service string
+		Path    dbus.ObjectPath
+	}
+	err := c.call(machinedPath, managerInterface+".ListMachines", &machines)
+	if err != nil {
+		// The system bus is usually available even if machined isn't
+		// installed, don't complain about it
+		var dbusErr dbus.Error
+		if errors.As(err, &dbusErr) && dbusErr.Name == errServiceUnknown {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("listing machines: %w", err)
+	}
+
+	ret := make([]*runtimeclient.ContainerData, 0, len(machines))
+	for _, listed := range machines {
+		// Skip virtual machines and the host
+		if listed.Class != machineClassContainer {
+			continue
+		}
+		m, err := c.getMachineByPath(listed.Path)
+		if err != nil {
+			log.Debugf("NspawnClient: getting properties of machine %q: %s", listed.Name, err)
+			continue
+		}
+		ret = append(ret, machineToContainerData(m))
+	}
+	return ret, nil
+}
+
+func (c *NspawnClient) GetContainer(containerID string) (*runtimeclient.ContainerData, error) {
+	m, err := c.getMachine(containerID)
+	if err != nil {
+		return nil, err
+	}
+	return machineToContainerData(m), nil
+}
+
+func (c *NspawnClient) GetContainerDetails(containerID string) (*runtimeclient.ContainerDetailsData, error) {
+	m, err := c.getMachine(containerID)
+	if err != nil {
+		return nil, err
+	}
+	if m.Leader == 0 {
+		return nil, errors.New("got zero pid")
+	}
+
+	containerDetailsData := &runtimeclient.ContainerDetailsData{
+		ContainerData: *machineToContainerData(m),
+		Pid:           int(m.Leader),
+	}
+
+	// machined only knows the unit of the machine, take the cgroup from the
+	// leader process
+	cgroupPathV1, cgroupPathV2, err := cgroups.GetCgroupPaths(int(m.Leader))
+	if err == nil {
+		containerDetailsData.CgroupsPath = cgroupPathV1
+		if containerDetailsData.CgroupsPath == "" {
+			containerDetailsData.CgroupsPath = cgroupPathV2
+		}
+	} else {
+		log.Warnf("failed to get cgroups info of container %s from /proc/%d/cgroup: %s",
+			containerID, m.Leader, err)
+	}
+
+	return containerDetailsData, nil
+}
+
+// parseSignal returns the container event for a MachineNew or MachineRemoved
+// signal
+func parseSignal(signal *dbus.Signal) (runtimeclient.ContainerEvent, bool) {
+	var ev runtimeclient.ContainerEvent
+	switch signal.Name {
+	case managerInterface + "." + signalMachineNew:
+		ev.Type = runtimeclient.ContainerEventStarted
+	case managerInterface + "." + signalMachineRemoved:
+		ev.Type = runtimeclient.ContainerEventStopped
+	default:
+		return ev, false
+	}
+	if len(signal.Body) < 1 {
+		return ev, false
+	}
+	name, ok := signal.Body[0].(string)
+	if !ok {
+		return ev, false
+	}
+	ev.ContainerID = name
+	return ev, true
+}
+
+func (c *NspawnClient) WatchContainers(callback func(runtimeclient.ContainerEvent)) error {
+	conn, err := c.getConn()
+	if err != nil {
+		return err
+	}
+
+	for _, member := range []string{signalMachineNew, signalMachineRemoved} {
+		err := conn.AddMatchSignal(
+			dbus.WithMatchObjectPath(machinedPath),
+			dbus.WithMatchInterface(managerInterface),
+			dbus.WithMatchMember(member),
+		)
+		if err != nil {
+			return fmt.Errorf("subscribing to %s: %w", member, err)
+		}
+	}
+
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+
+	go func() {
+		// The channel is closed when the connection is closed
+		for signal := range signals {
+			ev, ok := parseSignal(signal)
+			if !ok {
+				continue
+			}
+			// Events of virtual machines are filtered out by the callers
+			// when getting the details of the container
+			callback(ev)
+		}
+	}()
+	return nil
+}
+
+func (c *NspawnClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Closing the connection also closes the channel of the signals
+	c.closed = true
+	if c.conn != nil {
+		return c.conn.Close()
+	}
+	return nil
+}
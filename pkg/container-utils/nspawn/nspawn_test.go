@@ -0,0 +1,94 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nspawn
+
+import (
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+
+	runtimeclient "github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils/runtime-client"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/types"
+)
+
+func TestParseSignal(t *testing.T) {
+	type testCase struct {
+		name     string
+		signal   *dbus.Signal
+		expected runtimeclient.ContainerEvent
+		ok       bool
+	}
+	testCases := []testCase{
+		{
+			name: "new",
+			signal: &dbus.Signal{
+				Name: "org.freedesktop.machine1.Manager.MachineNew",
+				Body: []any{"debian", dbus.ObjectPath("/org/freedesktop/machine1/machine/debian")},
+			},
+			expected: runtimeclient.ContainerEvent{Type: runtimeclient.ContainerEventStarted, ContainerID: "debian"},
+			ok:       true,
+		},
+		{
+			name: "removed",
+			signal: &dbus.Signal{
+				Name: "org.freedesktop.machine1.Manager.MachineRemoved",
+				Body: []any{"debian", dbus.ObjectPath("/org/freedesktop/machine1/machine/debian")},
+			},
+			expected: runtimeclient.ContainerEvent{Type: runtimeclient.ContainerEventStopped, ContainerID: "debian"},
+			ok:       true,
+		},
+		{
+			name: "other signal",
+			signal: &dbus.Signal{
+				Name: "org.freedesktop.DBus.NameAcquired",
+				Body: []any{":1.42"},
+			},
+		},
+		{
+			name: "invalid body",
+			signal: &dbus.Signal{
+				Name: "org.freedesktop.machine1.Manager.MachineNew",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ev, ok := parseSignal(tc.signal)
+			assert.Equal(t, tc.ok, ok)
+			if tc.ok {
+				assert.Equal(t, tc.expected, ev)
+			}
+		})
+	}
+}
+
+func TestMachineToContainerData(t *testing.T) {
+	data := machineToContainerData(&machine{
+		Name:          "debian",
+		Class:         "container",
+		Leader:        1234,
+		RootDirectory: "/var/lib/machines/debian",
+		State:         "running",
+	})
+	assert.Equal(t, runtimeclient.RuntimeContainerData{
+		RuntimeName:        types.RuntimeNameNspawn,
+		ContainerID:        "debian",
+		ContainerName:      "debian",
+		ContainerImageName: "/var/lib/machines/debian",
+		State:              runtimeclient.StateRunning,
+	}, data.Runtime)
+}
@@ -29,6 +29,9 @@ const (
 	ContainerdDefaultSocketPath = "/run/containerd/containerd.sock"
 	DockerDefaultSocketPath     = "/run/docker.sock"
 	CriDockerDefaultSocketPath  = "/run/cri-dockerd.sock"
+	IncusDefaultSocketPath      = "/var/lib/incus/unix.socket"
+	// systemd-machined is reached through the system bus
+	NspawnDefaultSocketPath = "/run/dbus/system_bus_socket"
 )
 
 var ErrPauseContainer = errors.New("it is a pause container")
@@ -132,6 +135,33 @@ type ContainerRuntimeClient interface {
 	Close() error
 }
 
+// ContainerEventType is the type of a ContainerEvent.
+type ContainerEventType int
+
+const (
+	// ContainerEventStarted is sent when a container starts running.
+	ContainerEventStarted ContainerEventType = iota
+
+	// ContainerEventStopped is sent when a container stops running.
+	ContainerEventStopped
+)
+
+// ContainerEvent is sent by ContainerEventsNotifier when a container is
+// started or stopped.
+type ContainerEvent struct {
+	Type        ContainerEventType
+	ContainerID string
+}
+
+// ContainerEventsNotifier is implemented by the runtime clients of runtimes
+// that don't run their containers through an OCI runtime like runc, so they
+// aren't detected by the container hooks, e.g. Incus or systemd-nspawn.
+type ContainerEventsNotifier interface {
+	// WatchContainers starts calling callback each time a container is
+	// started or stopped, until the client is closed.
+	WatchContainers(callback func(ContainerEvent)) error
+}
+
 func ParseContainerID(expectedRuntime types.RuntimeName, containerID string) (string, error) {
 	// If ID contains a prefix, it must match the format "<runtime>://<ID>"
 	split := strings.SplitN(containerID, "://", 2)
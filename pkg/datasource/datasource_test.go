@@ -797,3 +797,39 @@ func randBytes(n int) []byte {
 	rand.Read(ret)
 	return ret
 }
+
+func TestValueString(t *testing.T) {
+	ds, err := New(TypeSingle, "event")
+	require.NoError(t, err)
+
+	i32, err := ds.AddField("i32", api.Kind_Int32)
+	require.NoError(t, err)
+	u64, err := ds.AddField("u64", api.Kind_Uint64)
+	require.NoError(t, err)
+	f32, err := ds.AddField("f32", api.Kind_Float32)
+	require.NoError(t, err)
+	b, err := ds.AddField("b", api.Kind_Bool)
+	require.NoError(t, err)
+	str, err := ds.AddField("str", api.Kind_String)
+	require.NoError(t, err)
+	bytes, err := ds.AddField("bytes", api.Kind_Bytes)
+	require.NoError(t, err)
+
+	data, err := ds.NewPacketSingle()
+	require.NoError(t, err)
+	defer ds.Release(data)
+
+	require.NoError(t, i32.PutInt32(data, -42))
+	require.NoError(t, u64.PutUint64(data, 18446744073709551615))
+	require.NoError(t, f32.PutFloat32(data, 1.5))
+	require.NoError(t, b.PutBool(data, true))
+	require.NoError(t, str.PutString(data, "foo"))
+	require.NoError(t, bytes.PutBytes(data, []byte{0xca, 0xfe}))
+
+	assert.Equal(t, "-42", ValueString(i32, data))
+	assert.Equal(t, "18446744073709551615", ValueString(u64, data))
+	assert.Equal(t, "1.5", ValueString(f32, data))
+	assert.Equal(t, "true", ValueString(b, data))
+	assert.Equal(t, "foo", ValueString(str, data))
+	assert.Equal(t, "cafe", ValueString(bytes, data))
+}
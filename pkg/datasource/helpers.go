@@ -15,8 +15,10 @@
 package datasource
 
 import (
+	"encoding/hex"
 	"fmt"
 	"math"
+	"strconv"
 
 	"golang.org/x/exp/constraints"
 
@@ -151,3 +153,48 @@ func GetKeyValueFunc[S ~string, T any](
 		}, nil
 	}
 }
+
+// ValueString returns the value of a field as text; values of types without a
+// text representation, like bytes or arrays, are hex encoded
+func ValueString(f FieldAccessor, data Data) string {
+	switch f.Type() {
+	case api.Kind_Int8:
+		v, _ := f.Int8(data)
+		return strconv.FormatInt(int64(v), 10)
+	case api.Kind_Int16:
+		v, _ := f.Int16(data)
+		return strconv.FormatInt(int64(v), 10)
+	case api.Kind_Int32:
+		v, _ := f.Int32(data)
+		return strconv.FormatInt(int64(v), 10)
+	case api.Kind_Int64:
+		v, _ := f.Int64(data)
+		return strconv.FormatInt(v, 10)
+	case api.Kind_Uint8:
+		v, _ := f.Uint8(data)
+		return strconv.FormatUint(uint64(v), 10)
+	case api.Kind_Uint16:
+		v, _ := f.Uint16(data)
+		return strconv.FormatUint(uint64(v), 10)
+	case api.Kind_Uint32:
+		v, _ := f.Uint32(data)
+		return strconv.FormatUint(uint64(v), 10)
+	case api.Kind_Uint64:
+		v, _ := f.Uint64(data)
+		return strconv.FormatUint(v, 10)
+	case api.Kind_Float32:
+		v, _ := f.Float32(data)
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case api.Kind_Float64:
+		v, _ := f.Float64(data)
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case api.Kind_Bool:
+		v, _ := f.Bool(data)
+		return strconv.FormatBool(v)
+	case api.Kind_String, api.Kind_CString:
+		v, _ := f.String(data)
+		return v
+	default:
+		return hex.EncodeToString(f.Get(data))
+	}
+}
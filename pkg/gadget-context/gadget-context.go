@@ -249,6 +249,12 @@ func (c *GadgetContext) SetParams(params []*api.Param) {
 	c.params = append(c.params, params...)
 }
 
+// ParamValues returns the param values given to Run(), nil if the gadget isn't
+// being run
+func (c *GadgetContext) ParamValues() api.ParamValues {
+	return maps.Clone(c.paramValues)
+}
+
 // processCustomParams extracts and processes custom parameters from the
 // metadata file. It processes the parameters, applies any parameter values and
 // template processing to the metadata file, and adds the processed parameters
@@ -233,7 +233,7 @@ func (c *GadgetContext) Run(paramValues api.ParamValues) error {
 		}
 	}()
 
-	// keep a copy - currently only used for custom params in SetMetadata()
+	// keep a copy - used for custom params in SetMetadata() and by ParamValues()
 	c.paramValues = paramValues
 
 	metricAttribs := attribute.NewSet(
@@ -85,6 +85,60 @@ func TestGetStringValuesPerDataSource(t *testing.T) {
 	}
 }
 
+func TestGetListValuesPerDataSource(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    map[string]string
+		expectedErr bool
+	}{
+		{
+			name:     "empty input",
+			input:    "",
+			expected: map[string]string{},
+		},
+		{
+			name:     "valid without datasource",
+			input:    "field1,field2",
+			expected: map[string]string{"": "field1,field2"},
+		},
+		{
+			name:     "valid with multiple datasources",
+			input:    "datasource1:field1,field2;datasource2:field3",
+			expected: map[string]string{"datasource1": "field1,field2", "datasource2": "field3"},
+		},
+		{
+			name:     "valid with empty element",
+			input:    "datasource1:field1;",
+			expected: map[string]string{"datasource1": "field1"},
+		},
+		{
+			name:        "invalid mixing datasource and no datasource",
+			input:       "field1;datasource1:field2",
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := GetListValuesPerDataSource(test.input)
+			if test.expectedErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			if !test.expectedErr {
+				require.Equal(t, test.expected, got)
+			}
+		})
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	require.Nil(t, SplitList(""))
+	require.Equal(t, []string{"a", "b"}, SplitList(" a, ,b,"))
+}
+
 func TestGetIntValuesPerDataSource(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -137,6 +137,39 @@ func GetStringValuesPerDataSource(s string) (map[string]string, error) {
 	return res, nil
 }
 
+// GetListValuesPerDataSource works like GetStringValuesPerDataSource, but expects data sources to be separated by ';'
+// so their values can be comma-separated lists, like `datasource1:field1,field2;datasource2:field3` or `field1,field2`
+func GetListValuesPerDataSource(s string) (map[string]string, error) {
+	res := make(map[string]string)
+	for _, v := range strings.Split(s, ";") {
+		if strings.TrimSpace(v) == "" {
+			continue
+		}
+		dsName, value, ok := strings.Cut(v, ":")
+		if !ok {
+			dsName, value = "", v
+		}
+		res[dsName] = value
+	}
+	// Check edge cases
+	if _, ok := res[""]; ok && len(res) > 1 {
+		return nil, fmt.Errorf("mixed values with and without specifying data source")
+	}
+	return res, nil
+}
+
+// SplitList splits a comma-separated list like the values returned by GetListValuesPerDataSource, ignoring empty
+// elements
+func SplitList(s string) []string {
+	var res []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
 // GetIntValuesPerDataSource works like GetStringValuesPerDataSource, but will return int values instead
 func GetIntValuesPerDataSource(s string) (map[string]int, error) {
 	var err error
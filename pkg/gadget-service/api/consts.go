@@ -95,3 +95,10 @@ const (
 	// instance; in this case the imageName of the GadgetInfoRequest is evaluated as the ID of the gadget instance
 	GadgetInfoRequestFlagUseInstance = 1 << iota
 )
+
+const (
+	// ParamServerSideProjection is added to the param values of a GadgetRunRequest by clients that only need the
+	// fields they requested for output; the service will then drop the payload of all other fields before sending
+	// events
+	ParamServerSideProjection = "runtime.server-side-projection"
+)
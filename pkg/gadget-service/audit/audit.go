@@ -0,0 +1,170 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package audit records who started, modified or removed gadgets using the
+// gadget service. Records are handed to a Backend, like a file (see
+// audit/file-backend) or Kubernetes Events (see audit/k8s-events-backend).
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/peercred"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	ocihandler "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/oci-handler"
+)
+
+const (
+	OperationCreateGadgetInstance = "CreateGadgetInstance"
+	OperationRemoveGadgetInstance = "RemoveGadgetInstance"
+	OperationRunGadget            = "RunGadget"
+	OperationGetGadgetInfo        = "GetGadgetInfo"
+
+	// OperationStartGadgetInstance and OperationStopGadgetInstance are
+	// recorded by the instance manager whenever a gadget instance is actually
+	// started or stopped on this node, regardless of who requested it
+	OperationStartGadgetInstance = "StartGadgetInstance"
+	OperationStopGadgetInstance  = "StopGadgetInstance"
+
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Caller identifies the client of a request
+type Caller struct {
+	// Subject of the verified TLS client certificate
+	Subject string `json:"subject,omitempty"`
+
+	// UID, GID and PID of the client process when connected using a unix
+	// socket
+	UID *uint32 `json:"uid,omitempty"`
+	GID *uint32 `json:"gid,omitempty"`
+	PID *int32  `json:"pid,omitempty"`
+
+	// Address of the client
+	Address string `json:"address,omitempty"`
+}
+
+func (c *Caller) String() string {
+	switch {
+	case c == nil:
+		return "internal"
+	case c.Subject != "":
+		return c.Subject
+	case c.UID != nil:
+		return fmt.Sprintf("uid=%d,gid=%d,pid=%d", *c.UID, *c.GID, *c.PID)
+	case c.Address != "":
+		return c.Address
+	}
+	return "unknown"
+}
+
+// CallerFromContext returns the identity of the client of a gRPC request
+func CallerFromContext(ctx context.Context) *Caller {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	c := &Caller{}
+	if p.Addr != nil && p.Addr.String() != "" && p.Addr.String() != "@" {
+		c.Address = p.Addr.String()
+	}
+	switch info := p.AuthInfo.(type) {
+	case credentials.TLSInfo:
+		if len(info.State.VerifiedChains) > 0 && len(info.State.VerifiedChains[0]) > 0 {
+			c.Subject = info.State.VerifiedChains[0][0].Subject.String()
+		}
+	case peercred.AuthInfo:
+		c.UID, c.GID, c.PID = &info.UID, &info.GID, &info.PID
+	}
+	return c
+}
+
+// ImageDigest returns the digest of the gadget image used by gadgetCtx or an
+// empty string if it isn't known (yet)
+func ImageDigest(gadgetCtx operators.GadgetContext) string {
+	v, ok := gadgetCtx.GetVar(ocihandler.ImageDigestVar)
+	if !ok {
+		return ""
+	}
+	digest, _ := v.(string)
+	return digest
+}
+
+// Record is a single entry of the audit log
+type Record struct {
+	Time         time.Time         `json:"time"`
+	Operation    string            `json:"operation"`
+	Caller       *Caller           `json:"caller,omitempty"`
+	InstanceID   string            `json:"instanceID,omitempty"`
+	InstanceName string            `json:"instanceName,omitempty"`
+	Image        string            `json:"image,omitempty"`
+	ImageDigest  string            `json:"imageDigest,omitempty"`
+	Params       map[string]string `json:"params,omitempty"`
+	Outcome      string            `json:"outcome"`
+	Error        string            `json:"error,omitempty"`
+}
+
+// Backend stores audit records
+type Backend interface {
+	Write(record *Record) error
+	Close() error
+}
+
+// Auditor completes records and writes them to a Backend. All methods can be
+// called on a nil Auditor, in which case records are discarded.
+type Auditor struct {
+	backend Backend
+	logger  logger.Logger
+}
+
+func New(backend Backend, logger logger.Logger) *Auditor {
+	return &Auditor{
+		backend: backend,
+		logger:  logger,
+	}
+}
+
+// Record sets time and outcome of the record based on err and writes it to
+// the backend. Failing to write a record is logged, but doesn't fail the
+// audited operation.
+func (a *Auditor) Record(record *Record, err error) {
+	if a == nil {
+		return
+	}
+	if record.Time.IsZero() {
+		record.Time = time.Now()
+	}
+	record.Outcome = OutcomeSuccess
+	if err != nil {
+		record.Outcome = OutcomeFailure
+		record.Error = err.Error()
+	}
+	if err := a.backend.Write(record); err != nil {
+		a.logger.Warnf("writing audit record for %s by %s: %v", record.Operation, record.Caller, err)
+	}
+}
+
+func (a *Auditor) Close() error {
+	if a == nil {
+		return nil
+	}
+	return a.backend.Close()
+}
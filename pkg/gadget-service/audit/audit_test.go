@@ -0,0 +1,141 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/peercred"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestCallerFromContext(t *testing.T) {
+	type testCase struct {
+		name           string
+		peer           *peer.Peer
+		expected       *Caller
+		expectedString string
+	}
+
+	testCases := []testCase{
+		{
+			name:           "no peer",
+			expectedString: "internal",
+		},
+		{
+			name: "tcp without tls",
+			peer: &peer.Peer{
+				Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1234},
+			},
+			expected:       &Caller{Address: "127.0.0.1:1234"},
+			expectedString: "127.0.0.1:1234",
+		},
+		{
+			name: "tls",
+			peer: &peer.Peer{
+				Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1234},
+				AuthInfo: credentials.TLSInfo{
+					State: tls.ConnectionState{
+						VerifiedChains: [][]*x509.Certificate{{
+							{Subject: pkix.Name{CommonName: "alice", Organization: []string{"ops"}}},
+						}},
+					},
+				},
+			},
+			expected:       &Caller{Subject: "CN=alice,O=ops", Address: "10.0.0.1:1234"},
+			expectedString: "CN=alice,O=ops",
+		},
+		{
+			name: "unix socket",
+			peer: &peer.Peer{
+				Addr:     &net.UnixAddr{Net: "unix", Name: "@"},
+				AuthInfo: peercred.AuthInfo{UID: 1000, GID: 100, PID: 42},
+			},
+			expected:       &Caller{UID: ptr(uint32(1000)), GID: ptr(uint32(100)), PID: ptr(int32(42))},
+			expectedString: "uid=1000,gid=100,pid=42",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.peer != nil {
+				ctx = peer.NewContext(ctx, tc.peer)
+			}
+			caller := CallerFromContext(ctx)
+			assert.Equal(t, tc.expected, caller)
+			assert.Equal(t, tc.expectedString, caller.String())
+		})
+	}
+}
+
+type testBackend struct {
+	records []*Record
+	err     error
+	closed  bool
+}
+
+func (b *testBackend) Write(record *Record) error {
+	b.records = append(b.records, record)
+	return b.err
+}
+
+func (b *testBackend) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestAuditor(t *testing.T) {
+	backend := &testBackend{}
+	auditor := New(backend, logger.DefaultLogger())
+
+	auditor.Record(&Record{Operation: OperationRunGadget}, nil)
+	auditor.Record(&Record{Operation: OperationRemoveGadgetInstance}, errors.New("not found"))
+
+	require.Len(t, backend.records, 2)
+	assert.Equal(t, OutcomeSuccess, backend.records[0].Outcome)
+	assert.Empty(t, backend.records[0].Error)
+	assert.False(t, backend.records[0].Time.IsZero())
+	assert.Equal(t, OutcomeFailure, backend.records[1].Outcome)
+	assert.Equal(t, "not found", backend.records[1].Error)
+
+	// failing to write must not panic or fail
+	backend.err = errors.New("disk full")
+	auditor.Record(&Record{Operation: OperationGetGadgetInfo}, nil)
+
+	require.NoError(t, auditor.Close())
+	assert.True(t, backend.closed)
+}
+
+func TestNilAuditor(t *testing.T) {
+	var auditor *Auditor
+	auditor.Record(&Record{Operation: OperationRunGadget}, nil)
+	require.NoError(t, auditor.Close())
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filebackend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/audit"
+)
+
+const (
+	DefaultFilename   = "/var/log/ig/audit.log"
+	DefaultMaxSizeMB  = 100
+	DefaultMaxBackups = 10
+)
+
+type Options struct {
+	Filename string
+
+	// MaxSizeMB is the size of the file before it gets rotated
+	MaxSizeMB int
+
+	// MaxBackups is the number of rotated files to keep; 0 keeps all of them
+	MaxBackups int
+
+	// MaxAgeDays is the number of days to keep rotated files; 0 keeps them
+	// regardless of their age
+	MaxAgeDays int
+
+	Compress bool
+}
+
+// FileBackend writes audit records as JSON lines to a file that is rotated
+// once it reaches a given size
+type FileBackend struct {
+	mu     sync.Mutex
+	writer *lumberjack.Logger
+}
+
+func New(opts Options) (*FileBackend, error) {
+	if opts.Filename == "" {
+		opts.Filename = DefaultFilename
+	}
+	if opts.MaxSizeMB <= 0 {
+		opts.MaxSizeMB = DefaultMaxSizeMB
+	}
+	if opts.MaxBackups < 0 || opts.MaxAgeDays < 0 {
+		return nil, fmt.Errorf("max backups and max age must be >= 0")
+	}
+
+	// The audit log contains the parameters of all gadget runs, so don't let
+	// others read it
+	dir := filepath.Dir(opts.Filename)
+	if err := os.MkdirAll(dir, 0o700); err != nil && !errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("creating directory %q: %w", dir, err)
+	}
+
+	return &FileBackend{
+		writer: &lumberjack.Logger{
+			Filename:   opts.Filename,
+			MaxSize:    opts.MaxSizeMB,
+			MaxBackups: opts.MaxBackups,
+			MaxAge:     opts.MaxAgeDays,
+			Compress:   opts.Compress,
+		},
+	}, nil
+}
+
+func (b *FileBackend) Write(record *audit.Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshaling audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, err := b.writer.Write(line); err != nil {
+		return fmt.Errorf("writing audit record: %w", err)
+	}
+	return nil
+}
+
+func (b *FileBackend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.writer.Close()
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filebackend
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/audit"
+)
+
+func TestFileBackend(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "audit", "audit.log")
+	b, err := New(Options{Filename: filename})
+	require.NoError(t, err)
+
+	records := []*audit.Record{
+		{
+			Time:      time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+			Operation: audit.OperationCreateGadgetInstance,
+			Image:     "trace_exec",
+			Params:    map[string]string{"operator.oci.verify-image": "true"},
+			Outcome:   audit.OutcomeSuccess,
+		},
+		{
+			Time:       time.Date(2026, 1, 2, 3, 4, 6, 0, time.UTC),
+			Operation:  audit.OperationRemoveGadgetInstance,
+			InstanceID: "abc",
+			Outcome:    audit.OutcomeFailure,
+			Error:      "not found",
+		},
+	}
+	for _, r := range records {
+		require.NoError(t, b.Write(r))
+	}
+	require.NoError(t, b.Close())
+
+	info, err := os.Stat(filepath.Dir(filename))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+
+	f, err := os.Open(filename)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var got []*audit.Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		r := &audit.Record{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), r))
+		got = append(got, r)
+	}
+	require.NoError(t, scanner.Err())
+	assert.Equal(t, records, got)
+}
@@ -0,0 +1,142 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8seventsbackend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/audit"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/k8sutil"
+)
+
+const (
+	// RecordAnnotation holds the complete audit record as JSON
+	RecordAnnotation = "audit.inspektor-gadget.io/record"
+
+	component = "gadget-audit"
+
+	// maxMessageLength is the maximum length of an event message accepted by
+	// the API server
+	maxMessageLength = 1024
+
+	writeTimeout = 5 * time.Second
+)
+
+// EventsBackend writes audit records as Kubernetes Events. Events about gadget
+// instances refer to the ConfigMap of the instance, so they're shown by
+// `kubectl describe configmap`; all other events refer to the node.
+type EventsBackend struct {
+	clientset *kubernetes.Clientset
+	namespace string
+	nodeName  string
+}
+
+func New(namespace string) (*EventsBackend, error) {
+	nodeName := os.Getenv("NODE_NAME")
+	if nodeName == "" {
+		return nil, errors.New("NODE_NAME environment variable is not set, cannot use Kubernetes Events for auditing")
+	}
+	clientset, err := k8sutil.NewClientset("", "k8s-events-backend")
+	if err != nil {
+		return nil, err
+	}
+	return &EventsBackend{
+		clientset: clientset,
+		namespace: namespace,
+		nodeName:  nodeName,
+	}, nil
+}
+
+func (b *EventsBackend) newEvent(record *audit.Record) (*corev1.Event, error) {
+	recordJSON, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling audit record: %w", err)
+	}
+
+	involvedObject := corev1.ObjectReference{
+		Kind: "Node",
+		Name: b.nodeName,
+	}
+	if record.InstanceID != "" {
+		involvedObject = corev1.ObjectReference{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+			Namespace:  b.namespace,
+			Name:       record.InstanceID,
+		}
+	}
+
+	eventType := corev1.EventTypeNormal
+	message := fmt.Sprintf("%s of %q by %s on node %s", record.Operation, record.Image, record.Caller, b.nodeName)
+	if record.Outcome != audit.OutcomeSuccess {
+		eventType = corev1.EventTypeWarning
+		message += " failed: " + record.Error
+	}
+	if len(message) > maxMessageLength {
+		message = message[:maxMessageLength]
+	}
+
+	eventTime := v1.NewTime(record.Time)
+	return &corev1.Event{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", involvedObject.Name, record.Time.UnixNano()),
+			Namespace: b.namespace,
+			Annotations: map[string]string{
+				RecordAnnotation: string(recordJSON),
+			},
+		},
+		Source: corev1.EventSource{
+			Component: component,
+			Host:      b.nodeName,
+		},
+		Count:               1,
+		ReportingController: "github.com/inspektor-gadget/inspektor-gadget",
+		ReportingInstance:   b.nodeName,
+		FirstTimestamp:      eventTime,
+		LastTimestamp:       eventTime,
+		InvolvedObject:      involvedObject,
+		Type:                eventType,
+		Reason:              record.Operation,
+		Message:             message,
+	}, nil
+}
+
+func (b *EventsBackend) Write(record *audit.Record) error {
+	event, err := b.newEvent(record)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+
+	if _, err := b.clientset.CoreV1().Events(b.namespace).Create(ctx, event, v1.CreateOptions{}); err != nil {
+		return fmt.Errorf("creating event: %w", err)
+	}
+	return nil
+}
+
+func (b *EventsBackend) Close() error {
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8seventsbackend
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/audit"
+)
+
+func TestNewEvent(t *testing.T) {
+	type testCase struct {
+		name              string
+		record            *audit.Record
+		expectedKind      string
+		expectedName      string
+		expectedType      string
+		expectedMessage   string
+		expectedTruncated bool
+	}
+
+	now := time.Now()
+	testCases := []testCase{
+		{
+			name: "instance",
+			record: &audit.Record{
+				Time:       now,
+				Operation:  audit.OperationCreateGadgetInstance,
+				InstanceID: "abc",
+				Image:      "trace_exec",
+				Outcome:    audit.OutcomeSuccess,
+			},
+			expectedKind:    "ConfigMap",
+			expectedName:    "abc",
+			expectedType:    corev1.EventTypeNormal,
+			expectedMessage: `CreateGadgetInstance of "trace_exec" by internal on node node1`,
+		},
+		{
+			name: "failed run",
+			record: &audit.Record{
+				Time:      now,
+				Operation: audit.OperationRunGadget,
+				Caller:    &audit.Caller{Address: "127.0.0.1:1234"},
+				Image:     "trace_exec",
+				Outcome:   audit.OutcomeFailure,
+				Error:     "image not allowed",
+			},
+			expectedKind:    "Node",
+			expectedName:    "node1",
+			expectedType:    corev1.EventTypeWarning,
+			expectedMessage: `RunGadget of "trace_exec" by 127.0.0.1:1234 on node node1 failed: image not allowed`,
+		},
+		{
+			name: "long error",
+			record: &audit.Record{
+				Time:      now,
+				Operation: audit.OperationRunGadget,
+				Image:     "trace_exec",
+				Outcome:   audit.OutcomeFailure,
+				Error:     strings.Repeat("x", 2*maxMessageLength),
+			},
+			expectedKind:      "Node",
+			expectedName:      "node1",
+			expectedType:      corev1.EventTypeWarning,
+			expectedTruncated: true,
+		},
+	}
+
+	b := &EventsBackend{namespace: "gadget", nodeName: "node1"}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			event, err := b.newEvent(tc.record)
+			require.NoError(t, err)
+
+			assert.Equal(t, "gadget", event.Namespace)
+			assert.Equal(t, tc.expectedKind, event.InvolvedObject.Kind)
+			assert.Equal(t, tc.expectedName, event.InvolvedObject.Name)
+			assert.Equal(t, tc.expectedType, event.Type)
+			assert.Equal(t, tc.record.Operation, event.Reason)
+			if tc.expectedTruncated {
+				assert.Len(t, event.Message, maxMessageLength)
+			} else {
+				assert.Equal(t, tc.expectedMessage, event.Message)
+			}
+
+			record := &audit.Record{}
+			require.NoError(t, json.Unmarshal([]byte(event.Annotations[RecordAnnotation]), record))
+			assert.Equal(t, tc.record.Operation, record.Operation)
+			assert.Equal(t, tc.record.Error, record.Error)
+		})
+	}
+}
@@ -0,0 +1,341 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package authz implements an authorization policy for the gadget service. A
+// policy maps the identity of a client (see audit.Caller) to a rule that
+// describes which RPCs the client may use, which gadget images it may run and
+// which gadget params it may set.
+package authz
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/yaml"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/audit"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/oci"
+)
+
+const (
+	MethodGetGadgetInfo        = "GetGadgetInfo"
+	MethodRunGadget            = "RunGadget"
+	MethodCreateGadgetInstance = "CreateGadgetInstance"
+	MethodListGadgetInstances  = "ListGadgetInstances"
+	MethodGetGadgetInstance    = "GetGadgetInstance"
+	MethodRemoveGadgetInstance = "RemoveGadgetInstance"
+
+	// methodGetInfo only returns the version of the server and is always
+	// allowed, so clients can still check for version skew
+	methodGetInfo = "GetInfo"
+)
+
+var methods = []string{
+	MethodGetGadgetInfo,
+	MethodRunGadget,
+	MethodCreateGadgetInstance,
+	MethodListGadgetInstances,
+	MethodGetGadgetInstance,
+	MethodRemoveGadgetInstance,
+}
+
+// Policy is a list of rules; the first rule matching a client applies to it.
+// Clients that don't match any rule are denied access.
+type Policy struct {
+	Rules []*Rule `json:"rules"`
+}
+
+// Rule describes what matching clients are allowed to do. Empty lists don't
+// restrict anything.
+type Rule struct {
+	// Name is used in error messages
+	Name string `json:"name"`
+
+	// Subjects are patterns (see path.Match) for the subject of the TLS client
+	// certificate, like "CN=alice,O=ops"
+	Subjects []string `json:"subjects,omitempty"`
+
+	// UIDs and GIDs of clients connected using a unix socket
+	UIDs []uint32 `json:"uids,omitempty"`
+	GIDs []uint32 `json:"gids,omitempty"`
+
+	// Methods are the gRPC methods the client may call, e.g. "RunGadget"
+	Methods []string `json:"methods,omitempty"`
+
+	// Images that may be run. An entry is either a full image reference, a
+	// prefix ending with "*" or an image name with a digest like
+	// "ghcr.io/inspektor-gadget/gadget/trace_exec@sha256:..."
+	Images []string `json:"images,omitempty"`
+
+	// AllowedParams and DeniedParams are patterns (see path.Match) for the
+	// params the client may set to a value different from its default.
+	// Patterns without a dot only match the key of the param, e.g. "iface",
+	// others the fully qualified name, e.g. "operator.oci.ebpf.iface".
+	AllowedParams []string `json:"allowedParams,omitempty"`
+	DeniedParams  []string `json:"deniedParams,omitempty"`
+}
+
+// LoadPolicy reads a policy from the given YAML file
+func LoadPolicy(filename string) (*Policy, error) {
+	blob, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading policy: %w", err)
+	}
+	policy := &Policy{}
+	if err := yaml.UnmarshalStrict(blob, policy); err != nil {
+		return nil, fmt.Errorf("unmarshaling policy %q: %w", filename, err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("validating policy %q: %w", filename, err)
+	}
+	return policy, nil
+}
+
+// Validate checks that all patterns and methods of the policy are valid
+func (p *Policy) Validate() error {
+	for i, rule := range p.Rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule %d", i)
+		}
+		for _, method := range rule.Methods {
+			if method != "*" && !slices.Contains(methods, method) {
+				return fmt.Errorf("%s: unknown method %q, expected one of %v", rule.Name, method, methods)
+			}
+		}
+		for _, patterns := range [][]string{rule.Subjects, rule.AllowedParams, rule.DeniedParams} {
+			for _, pattern := range patterns {
+				if _, err := path.Match(pattern, ""); err != nil {
+					return fmt.Errorf("%s: invalid pattern %q: %w", rule.Name, pattern, err)
+				}
+			}
+		}
+		for j, image := range rule.Images {
+			if image == "" {
+				return fmt.Errorf("%s: empty image", rule.Name)
+			}
+			if strings.HasSuffix(image, "*") {
+				continue
+			}
+			// Allow short names like "trace_exec"
+			named, err := oci.NormalizeImageName(image)
+			if err != nil {
+				return fmt.Errorf("%s: invalid image %q: %w", rule.Name, image, err)
+			}
+			rule.Images[j] = named.String()
+		}
+	}
+	return nil
+}
+
+// RuleFor returns the first rule matching the given caller or nil if no rule
+// matches
+func (p *Policy) RuleFor(caller *audit.Caller) *Rule {
+	for _, rule := range p.Rules {
+		if rule.matches(caller) {
+			return rule
+		}
+	}
+	return nil
+}
+
+func (r *Rule) matches(caller *audit.Caller) bool {
+	if len(r.Subjects) == 0 && len(r.UIDs) == 0 && len(r.GIDs) == 0 {
+		return true
+	}
+	if caller == nil {
+		return false
+	}
+	if caller.Subject != "" && matchAny(r.Subjects, caller.Subject) {
+		return true
+	}
+	if caller.UID != nil && slices.Contains(r.UIDs, *caller.UID) {
+		return true
+	}
+	if caller.GID != nil && slices.Contains(r.GIDs, *caller.GID) {
+		return true
+	}
+	return false
+}
+
+func matchAny(patterns []string, s string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, s); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthorizeMethod checks whether the rule allows calling the given gRPC method
+func (r *Rule) AuthorizeMethod(method string) error {
+	if r == nil || len(r.Methods) == 0 || method == methodGetInfo {
+		return nil
+	}
+	if slices.Contains(r.Methods, "*") || slices.Contains(r.Methods, method) {
+		return nil
+	}
+	return status.Errorf(codes.PermissionDenied, "%s is not allowed to call %s", r.Name, method)
+}
+
+// AuthorizeImageReference checks whether the rule could allow running the
+// given image before its digest is known. Images allowed by their digest need
+// to be checked again using AuthorizeImage once the digest is known.
+func (r *Rule) AuthorizeImageReference(image string) error {
+	return r.authorizeImage(image, "", false)
+}
+
+// AuthorizeImage checks whether the rule allows running the given image with
+// the given digest
+func (r *Rule) AuthorizeImage(image string, digest string) error {
+	return r.authorizeImage(image, digest, true)
+}
+
+func (r *Rule) authorizeImage(image string, digest string, digestKnown bool) error {
+	if r == nil || len(r.Images) == 0 {
+		return nil
+	}
+
+	named, err := oci.NormalizeImageName(image)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "normalizing image: %v", err)
+	}
+	imageStr := named.String()
+	imageDigest := named.Name() + "@" + digest
+
+	for _, allowed := range r.Images {
+		if name, _, ok := strings.Cut(allowed, "@"); ok {
+			if !digestKnown && name == named.Name() {
+				return nil
+			}
+			if digest != "" && imageDigest == allowed {
+				return nil
+			}
+			continue
+		}
+		if imageStr == allowed {
+			return nil
+		}
+		if allowed[len(allowed)-1] == '*' && strings.HasPrefix(imageStr, allowed[:len(allowed)-1]) {
+			return nil
+		}
+	}
+	return status.Errorf(codes.PermissionDenied, "%s is not allowed to run image %q", r.Name, imageStr)
+}
+
+// AuthorizeParams checks whether the rule allows setting the given values.
+// Only values differing from the default value of their param are checked, as
+// clients usually send the values of all params.
+func (r *Rule) AuthorizeParams(params []*api.Param, values api.ParamValues) error {
+	if r == nil || (len(r.AllowedParams) == 0 && len(r.DeniedParams) == 0) {
+		return nil
+	}
+	for _, p := range params {
+		key := p.Prefix + p.Key
+		value, ok := values[key]
+		if !ok || value == p.DefaultValue {
+			continue
+		}
+		if len(r.AllowedParams) > 0 && !matchParam(r.AllowedParams, p) ||
+			matchParam(r.DeniedParams, p) {
+			return status.Errorf(codes.PermissionDenied, "%s is not allowed to set param %q", r.Name, key)
+		}
+	}
+	return nil
+}
+
+func matchParam(patterns []string, p *api.Param) bool {
+	for _, pattern := range patterns {
+		name := p.Prefix + p.Key
+		if !strings.Contains(pattern, ".") {
+			name = p.Key
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type ruleKey struct{}
+
+// RuleFromContext returns the rule that applies to the client of a request
+// or nil if there is no policy
+func RuleFromContext(ctx context.Context) *Rule {
+	rule, _ := ctx.Value(ruleKey{}).(*Rule)
+	return rule
+}
+
+// ContextWithRule returns a copy of ctx with rule applying to the client of
+// the request
+func ContextWithRule(ctx context.Context, rule *Rule) context.Context {
+	return context.WithValue(ctx, ruleKey{}, rule)
+}
+
+func (p *Policy) authorize(ctx context.Context, fullMethod string) (context.Context, error) {
+	method := path.Base(fullMethod)
+	if method == methodGetInfo {
+		return ctx, nil
+	}
+	caller := audit.CallerFromContext(ctx)
+	rule := p.RuleFor(caller)
+	if rule == nil {
+		return nil, status.Errorf(codes.PermissionDenied, "no authorization rule for %s", caller)
+	}
+	if err := rule.AuthorizeMethod(method); err != nil {
+		return nil, err
+	}
+	return ContextWithRule(ctx, rule), nil
+}
+
+// UnaryServerInterceptor checks that the client of a request is allowed to
+// call the requested method and makes its rule available using
+// RuleFromContext
+func (p *Policy) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := p.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// StreamServerInterceptor is the same as UnaryServerInterceptor for streaming
+// methods
+func (p *Policy) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := p.authorize(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
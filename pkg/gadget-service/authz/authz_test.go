@@ -0,0 +1,452 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authz
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/audit"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/peercred"
+)
+
+const testDigest = "sha256:9d4c52d3c1a0e3e3d13b2a4a7a5bc1f16c0cbf1e0dd2c12ec1e0c1f3a5d4c2b1"
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func requireDenied(t *testing.T, err error) {
+	t.Helper()
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestLoadPolicy(t *testing.T) {
+	type testCase struct {
+		name          string
+		policy        string
+		expectedError bool
+	}
+
+	testCases := []testCase{
+		{
+			name: "valid",
+			policy: `
+rules:
+- name: ops
+  subjects: ["CN=*,O=ops"]
+  methods: ["*"]
+- uids: [1000]
+  methods: [RunGadget, GetGadgetInfo]
+  images: [trace_exec, "ghcr.io/inspektor-gadget/gadget/trace_*"]
+  deniedParams: [iface, trace-pipe]
+`,
+		},
+		{
+			name: "unknown method",
+			policy: `
+rules:
+- methods: [DeleteEverything]
+`,
+			expectedError: true,
+		},
+		{
+			name: "unknown field",
+			policy: `
+rules:
+- user: alice
+`,
+			expectedError: true,
+		},
+		{
+			name: "invalid pattern",
+			policy: `
+rules:
+- deniedParams: ["[iface"]
+`,
+			expectedError: true,
+		},
+		{
+			name: "invalid image",
+			policy: `
+rules:
+- images: ["UPPERCASE"]
+`,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "policy.yaml")
+			require.NoError(t, os.WriteFile(filename, []byte(tc.policy), 0o600))
+
+			policy, err := LoadPolicy(filename)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, policy.Rules, 2)
+			assert.Equal(t, "ops", policy.Rules[0].Name)
+			assert.Equal(t, "rule 1", policy.Rules[1].Name)
+			assert.Equal(t, []string{
+				"ghcr.io/inspektor-gadget/gadget/trace_exec:latest",
+				"ghcr.io/inspektor-gadget/gadget/trace_*",
+			}, policy.Rules[1].Images)
+		})
+	}
+}
+
+func TestRuleFor(t *testing.T) {
+	policy := &Policy{
+		Rules: []*Rule{
+			{Name: "ops", Subjects: []string{"CN=*,O=ops"}},
+			{Name: "users", UIDs: []uint32{1000}, GIDs: []uint32{100}},
+		},
+	}
+	require.NoError(t, policy.Validate())
+
+	type testCase struct {
+		name         string
+		caller       *audit.Caller
+		expectedRule string
+	}
+
+	testCases := []testCase{
+		{
+			name:         "subject",
+			caller:       &audit.Caller{Subject: "CN=alice,O=ops"},
+			expectedRule: "ops",
+		},
+		{
+			name:   "other subject",
+			caller: &audit.Caller{Subject: "CN=mallory,O=dev"},
+		},
+		{
+			name:         "uid",
+			caller:       &audit.Caller{UID: ptr(uint32(1000)), GID: ptr(uint32(1000))},
+			expectedRule: "users",
+		},
+		{
+			name:         "gid",
+			caller:       &audit.Caller{UID: ptr(uint32(1001)), GID: ptr(uint32(100))},
+			expectedRule: "users",
+		},
+		{
+			name:   "other uid",
+			caller: &audit.Caller{UID: ptr(uint32(1001)), GID: ptr(uint32(1001))},
+		},
+		{
+			name:   "address only",
+			caller: &audit.Caller{Address: "127.0.0.1:1234"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule := policy.RuleFor(tc.caller)
+			if tc.expectedRule == "" {
+				assert.Nil(t, rule)
+				return
+			}
+			require.NotNil(t, rule)
+			assert.Equal(t, tc.expectedRule, rule.Name)
+		})
+	}
+
+	// A rule without identities matches all clients
+	policy.Rules = append(policy.Rules, &Rule{Name: "default"})
+	assert.Equal(t, "default", policy.RuleFor(&audit.Caller{Address: "127.0.0.1:1234"}).Name)
+}
+
+func TestAuthorizeMethod(t *testing.T) {
+	rule := &Rule{Name: "test", Methods: []string{MethodRunGadget, MethodListGadgetInstances}}
+	require.NoError(t, rule.AuthorizeMethod(MethodRunGadget))
+	require.NoError(t, rule.AuthorizeMethod(MethodListGadgetInstances))
+	require.NoError(t, rule.AuthorizeMethod(methodGetInfo))
+	requireDenied(t, rule.AuthorizeMethod(MethodRemoveGadgetInstance))
+
+	require.NoError(t, (&Rule{Methods: []string{"*"}}).AuthorizeMethod(MethodRemoveGadgetInstance))
+	require.NoError(t, (&Rule{}).AuthorizeMethod(MethodRemoveGadgetInstance))
+
+	var nilRule *Rule
+	require.NoError(t, nilRule.AuthorizeMethod(MethodRemoveGadgetInstance))
+}
+
+func TestAuthorizeImage(t *testing.T) {
+	policy := &Policy{
+		Rules: []*Rule{{
+			Images: []string{
+				"trace_exec",
+				"ghcr.io/inspektor-gadget/gadget/snapshot_*",
+				"trace_open@" + testDigest,
+			},
+		}},
+	}
+	require.NoError(t, policy.Validate())
+	rule := policy.Rules[0]
+
+	type testCase struct {
+		name             string
+		image            string
+		digest           string
+		referenceAllowed bool
+		allowed          bool
+	}
+
+	testCases := []testCase{
+		{
+			name:             "short name",
+			image:            "trace_exec",
+			digest:           "sha256:1234",
+			referenceAllowed: true,
+			allowed:          true,
+		},
+		{
+			name:             "full name",
+			image:            "ghcr.io/inspektor-gadget/gadget/trace_exec:latest",
+			referenceAllowed: true,
+			allowed:          true,
+		},
+		{
+			name:  "other tag",
+			image: "trace_exec:v1.0.0",
+		},
+		{
+			name:             "prefix",
+			image:            "snapshot_process",
+			referenceAllowed: true,
+			allowed:          true,
+		},
+		{
+			name:             "digest",
+			image:            "trace_open:v1.0.0",
+			digest:           testDigest,
+			referenceAllowed: true,
+			allowed:          true,
+		},
+		{
+			name:             "other digest",
+			image:            "trace_open",
+			digest:           "sha256:1234",
+			referenceAllowed: true,
+		},
+		{
+			name:             "unknown digest",
+			image:            "trace_open",
+			referenceAllowed: true,
+		},
+		{
+			name:   "other image",
+			image:  "trace_tcp",
+			digest: testDigest,
+		},
+		{
+			name:  "other registry",
+			image: "example.com/trace_exec",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := rule.AuthorizeImageReference(tc.image)
+			if tc.referenceAllowed {
+				require.NoError(t, err)
+			} else {
+				requireDenied(t, err)
+			}
+
+			err = rule.AuthorizeImage(tc.image, tc.digest)
+			if tc.allowed {
+				require.NoError(t, err)
+			} else {
+				requireDenied(t, err)
+			}
+		})
+	}
+
+	require.NoError(t, (&Rule{}).AuthorizeImage("trace_tcp", ""))
+}
+
+func TestAuthorizeParams(t *testing.T) {
+	params := []*api.Param{
+		{Prefix: "operator.oci.ebpf.", Key: "iface"},
+		{Prefix: "operator.oci.ebpf.", Key: "trace-pipe", DefaultValue: "false"},
+		{Prefix: "operator.oci.ebpf.", Key: "pid"},
+		{Prefix: "operator.filter.", Key: "filter"},
+	}
+
+	type testCase struct {
+		name          string
+		rule          *Rule
+		values        api.ParamValues
+		expectedError bool
+	}
+
+	testCases := []testCase{
+		{
+			name: "defaults",
+			rule: &Rule{DeniedParams: []string{"iface", "trace-pipe"}},
+			values: api.ParamValues{
+				"operator.oci.ebpf.iface":      "",
+				"operator.oci.ebpf.trace-pipe": "false",
+				"operator.oci.ebpf.pid":        "42",
+			},
+		},
+		{
+			name:          "denied key",
+			rule:          &Rule{DeniedParams: []string{"iface", "trace-pipe"}},
+			values:        api.ParamValues{"operator.oci.ebpf.trace-pipe": "true"},
+			expectedError: true,
+		},
+		{
+			name:          "denied full name",
+			rule:          &Rule{DeniedParams: []string{"operator.oci.ebpf.*"}},
+			values:        api.ParamValues{"operator.oci.ebpf.pid": "42"},
+			expectedError: true,
+		},
+		{
+			name:   "allowed",
+			rule:   &Rule{AllowedParams: []string{"filter", "operator.oci.ebpf.pid"}},
+			values: api.ParamValues{"operator.oci.ebpf.pid": "42", "operator.filter.filter": "comm==cat"},
+		},
+		{
+			name:          "not allowed",
+			rule:          &Rule{AllowedParams: []string{"filter"}},
+			values:        api.ParamValues{"operator.oci.ebpf.iface": "eth0"},
+			expectedError: true,
+		},
+		{
+			name:   "unknown params are ignored",
+			rule:   &Rule{AllowedParams: []string{"filter"}},
+			values: api.ParamValues{"operator.unknown.iface": "eth0"},
+		},
+		{
+			name:   "no restrictions",
+			rule:   &Rule{},
+			values: api.ParamValues{"operator.oci.ebpf.iface": "eth0"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.rule.AuthorizeParams(params, tc.values)
+			if tc.expectedError {
+				requireDenied(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	policy := &Policy{
+		Rules: []*Rule{
+			{Name: "ops", Subjects: []string{"CN=alice"}, Methods: []string{MethodListGadgetInstances}},
+			{Name: "root", UIDs: []uint32{0}},
+		},
+	}
+	require.NoError(t, policy.Validate())
+	interceptor := policy.UnaryServerInterceptor()
+
+	tlsPeer := &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1234},
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{
+				VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "alice"}}}},
+			},
+		},
+	}
+	unixPeer := &peer.Peer{
+		Addr:     &net.UnixAddr{Net: "unix", Name: "@"},
+		AuthInfo: peercred.AuthInfo{UID: 1000, GID: 1000, PID: 42},
+	}
+
+	type testCase struct {
+		name          string
+		peer          *peer.Peer
+		method        string
+		expectedRule  string
+		expectedError bool
+	}
+
+	testCases := []testCase{
+		{
+			name:         "allowed",
+			peer:         tlsPeer,
+			method:       "/api.GadgetInstanceManager/ListGadgetInstances",
+			expectedRule: "ops",
+		},
+		{
+			name:          "method not allowed",
+			peer:          tlsPeer,
+			method:        "/api.GadgetInstanceManager/RemoveGadgetInstance",
+			expectedError: true,
+		},
+		{
+			name:          "no rule",
+			peer:          unixPeer,
+			method:        "/api.GadgetManager/GetGadgetInfo",
+			expectedError: true,
+		},
+		{
+			name:   "get info",
+			peer:   unixPeer,
+			method: "/api.BuiltInGadgetManager/GetInfo",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := peer.NewContext(context.Background(), tc.peer)
+			called := false
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, func(ctx context.Context, req any) (any, error) {
+				called = true
+				rule := RuleFromContext(ctx)
+				if tc.expectedRule == "" {
+					assert.Nil(t, rule)
+				} else if assert.NotNil(t, rule) {
+					assert.Equal(t, tc.expectedRule, rule.Name)
+				}
+				return nil, nil
+			})
+			if tc.expectedError {
+				requireDenied(t, err)
+				assert.False(t, called)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, called)
+		})
+	}
+}
@@ -18,17 +18,21 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/bpfstats"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
 	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/audit"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/simple"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/runtime"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/runtime/local"
 )
 
 type gadgetState int
@@ -63,6 +67,7 @@ type GadgetInstance struct {
 	mu                   sync.Mutex
 	gadgetInfoSerialized *api.GadgetEvent
 	gadgetInfo           *api.GadgetInfo
+	imageDigest          string
 	eventBuffer          []*bufferedEvent
 	eventBufferOffs      int
 	eventOverflow        bool
@@ -71,6 +76,18 @@ type GadgetInstance struct {
 	state                gadgetState
 	error                error
 	ready                chan struct{}
+
+	// quota bookkeeping, see quota.go
+	objects          *bpfstats.Objects
+	events           uint64
+	droppedEvents    uint64
+	rateWindowStart  time.Time
+	rateWindowEvents uint64
+	lastCheck        time.Time
+	lastUsage        bpfstats.Usage
+	lastEvents       uint64
+	quotaMessage     string
+	quotaErr         error
 }
 
 func (p *GadgetInstance) GadgetInfo() (*api.GadgetInfo, error) {
@@ -80,6 +97,17 @@ func (p *GadgetInstance) GadgetInfo() (*api.GadgetInfo, error) {
 	return p.gadgetInfo, p.error
 }
 
+// Image returns the image of the gadget instance and its digest; it waits
+// until the gadget is initialized, as the digest isn't known before
+func (p *GadgetInstance) Image(ctx context.Context) (string, string, error) {
+	select {
+	case <-p.ready:
+	case <-ctx.Done():
+		return "", "", ctx.Err()
+	}
+	return p.request.GetImageName(), p.imageDigest, nil
+}
+
 func (p *GadgetInstance) AddClient(client api.GadgetManager_RunGadgetServer) chan struct{} {
 	log.Debugf("[%s] client connected", p.gadgetInfo.Id)
 	p.mu.Lock()
@@ -127,6 +155,44 @@ func (p *GadgetInstance) AddClient(client api.GadgetManager_RunGadgetServer) cha
 	return done
 }
 
+// active returns whether the gadget instance is running or about to run
+func (p *GadgetInstance) active() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state != stateError && p.quotaErr == nil
+}
+
+// stopForQuota stops the gadget instance; err is reported as its state
+func (p *GadgetInstance) stopForQuota(err error) {
+	log.Warnf("stopping gadget instance %q: %v", p.id, err)
+	p.mu.Lock()
+	if p.quotaErr == nil {
+		p.quotaErr = err
+	}
+	p.mu.Unlock()
+	p.cancel()
+}
+
+// throttled counts an event and returns whether it exceeds the event rate
+// quota and should be dropped; p.mu must be held
+func (p *GadgetInstance) throttled(now time.Time) bool {
+	p.events++
+	quotas := &p.mgr.quotas
+	if quotas.MaxEventRate == 0 || quotas.EventRateAction != QuotaActionThrottle {
+		return false
+	}
+	if now.Sub(p.rateWindowStart) >= time.Second {
+		p.rateWindowStart = now
+		p.rateWindowEvents = 0
+	}
+	if p.rateWindowEvents >= quotas.MaxEventRate {
+		p.droppedEvents++
+		return true
+	}
+	p.rateWindowEvents++
+	return false
+}
+
 func (p *GadgetInstance) RemoveClients() {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -139,7 +205,37 @@ func (p *GadgetInstance) Run(
 	ctx context.Context,
 	runtime runtime.Runtime,
 	logger logger.Logger,
-) error {
+) (err error) {
+	record := &audit.Record{
+		Operation:    audit.OperationStartGadgetInstance,
+		InstanceID:   p.id,
+		InstanceName: p.name,
+		Image:        p.request.GetImageName(),
+		Params:       p.request.GetParamValues(),
+	}
+	started := false
+	defer func() {
+		// Report why the gadget instance was stopped by the quota monitor
+		p.mu.Lock()
+		if p.quotaErr != nil {
+			err = p.quotaErr
+		}
+		p.mu.Unlock()
+
+		// If the gadget was started successfully, this records when and why
+		// it stopped; otherwise it records that starting failed
+		if started {
+			record = &audit.Record{
+				Operation:    audit.OperationStopGadgetInstance,
+				InstanceID:   p.id,
+				InstanceName: p.name,
+				Image:        record.Image,
+				ImageDigest:  record.ImageDigest,
+			}
+		}
+		p.mgr.auditor.Record(record, err)
+	}()
+
 	if p.request.Version != api.VersionGadgetRunProtocol {
 		return fmt.Errorf("expected version to be %d, got %d", api.VersionGadgetRunProtocol, p.request.Version)
 	}
@@ -175,6 +271,10 @@ func (p *GadgetInstance) Run(
 					}
 
 					p.mu.Lock()
+					if p.throttled(time.Now()) {
+						p.mu.Unlock()
+						return nil
+					}
 					p.eventBuffer[p.eventBufferOffs] = event
 					p.eventBufferOffs = (p.eventBufferOffs + 1) % len(p.eventBuffer)
 					if p.eventBufferOffs == 0 {
@@ -199,7 +299,21 @@ func (p *GadgetInstance) Run(
 				Payload: d,
 			}
 			p.gadgetInfo = gi
+			p.imageDigest = audit.ImageDigest(gadgetCtx)
 			close(p.ready)
+
+			record.ImageDigest = p.imageDigest
+			p.mgr.auditor.Record(record, nil)
+			started = true
+			return nil
+		}),
+		simple.OnStart(func(gadgetCtx operators.GadgetContext) error {
+			// The ebpf operator has loaded its objects by now
+			if objects, ok := gadgetCtx.GetVar(bpfstats.ObjectsVar); ok {
+				p.mu.Lock()
+				p.objects, _ = objects.(*bpfstats.Objects)
+				p.mu.Unlock()
+			}
 			return nil
 		}),
 	)
@@ -223,6 +337,11 @@ func (p *GadgetInstance) Run(
 	runtimeParams := runtime.ParamDescs().ToParams()
 	runtimeParams.CopyFromMap(p.request.ParamValues, "runtime.")
 
+	// Don't let remote clients replay arbitrary files from the node
+	if err := runtimeParams.Set(local.ParamReplay, ""); err != nil {
+		return fmt.Errorf("disabling replay: %w", err)
+	}
+
 	p.mu.Lock()
 	p.state = stateRunning
 	p.mu.Unlock()
@@ -21,7 +21,9 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/bpfstats"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/audit"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
@@ -60,6 +62,21 @@ type Manager struct {
 
 	runtime runtime.Runtime
 
+	auditor *audit.Auditor
+
+	quotas Quotas
+
+	// mapMemory is the memory used by the eBPF maps of all gadget instances
+	// when the quotas were checked the last time
+	mapMemory uint64
+
+	// getUsage returns the resource usage of a gadget instance
+	getUsage func(gi *GadgetInstance) (bpfstats.Usage, error)
+
+	done      chan struct{}
+	closeOnce sync.Once
+	monitorWg sync.WaitGroup
+
 	Service
 }
 
@@ -67,6 +84,8 @@ func New(runtime runtime.Runtime, options ...Option) (*Manager, error) {
 	mgr := &Manager{
 		gadgetInstances: make(map[string]*GadgetInstance),
 		runtime:         runtime,
+		getUsage:        instanceUsage,
+		done:            make(chan struct{}),
 	}
 	for _, opt := range options {
 		err := opt(mgr)
@@ -74,9 +93,28 @@ func New(runtime runtime.Runtime, options ...Option) (*Manager, error) {
 			return nil, err
 		}
 	}
+	if err := mgr.quotas.validate(); err != nil {
+		return nil, err
+	}
+	if mgr.quotas.monitored() {
+		mgr.monitorWg.Add(1)
+		go func() {
+			defer mgr.monitorWg.Done()
+			mgr.monitorQuotas()
+		}()
+	}
 	return mgr, nil
 }
 
+// Close stops monitoring the quotas of gadget instances and waits until BPF
+// stats are disabled again
+func (m *Manager) Close() {
+	m.closeOnce.Do(func() {
+		close(m.done)
+	})
+	m.monitorWg.Wait()
+}
+
 // RemoveGadget cancels and removes a gadget
 func (m *Manager) RemoveGadget(id string) error {
 	m.mu.Lock()
@@ -104,6 +142,16 @@ func (m *Manager) RunGadget(instance *api.GadgetInstance) {
 		ready:           make(chan struct{}),
 	}
 	m.mu.Lock()
+	if err := m.admitLocked(instance); err != nil {
+		log.Warnf("not running gadget instance %q: %v", instance.Id, err)
+		gi.state = stateError
+		gi.error = err
+		close(gi.ready)
+		m.gadgetInstances[gi.id] = gi
+		m.mu.Unlock()
+		cancel()
+		return
+	}
 	m.gadgetInstances[gi.id] = gi
 	// Adopt all clients in the waiting room
 	if m.asyncGadgetRunCreation {
@@ -139,6 +187,14 @@ func (m *Manager) RunGadget(instance *api.GadgetInstance) {
 	}()
 }
 
+// Instance returns the gadget instance with the given ID or nil if there is
+// none
+func (m *Manager) Instance(id string) *GadgetInstance {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.gadgetInstances[id]
+}
+
 func (m *Manager) LookupInstance(gadgetInstanceID string) *GadgetInstance {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -170,7 +226,9 @@ func (m *Manager) InstanceState(gadgetInstanceID string) (*api.GadgetInstanceSta
 	if gi == nil {
 		return nil, ErrNotFound
 	}
-	var msg string
+	gi.mu.Lock()
+	defer gi.mu.Unlock()
+	msg := gi.quotaMessage
 	if gi.error != nil {
 		msg = gi.error.Error()
 	}
@@ -14,6 +14,10 @@
 
 package instancemanager
 
+import (
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/audit"
+)
+
 type Option func(*Manager) error
 
 func WithAsync(val bool) Option {
@@ -22,3 +26,19 @@ func WithAsync(val bool) Option {
 		return nil
 	}
 }
+
+// WithAuditor records when gadget instances are started and stopped
+func WithAuditor(auditor *audit.Auditor) Option {
+	return func(m *Manager) error {
+		m.auditor = auditor
+		return nil
+	}
+}
+
+// WithQuotas limits the resources gadget instances can use
+func WithQuotas(quotas Quotas) Option {
+	return func(m *Manager) error {
+		m.quotas = quotas
+		return nil
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancemanager
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/bpfstats"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+const (
+	ErrQuotaExceeded = mgrError("quota exceeded")
+
+	// QuotaActionThrottle drops events exceeding MaxEventRate
+	QuotaActionThrottle = "throttle"
+	// QuotaActionStop stops gadget instances exceeding MaxEventRate
+	QuotaActionStop = "stop"
+
+	DefaultQuotaInterval = 10 * time.Second
+)
+
+// Quotas limit the resources gadget instances can use on a node. Zero values
+// don't limit anything.
+type Quotas struct {
+	// MaxInstances is the maximum number of gadget instances running on the
+	// node
+	MaxInstances int
+
+	// MaxInstancesPerImage is the maximum number of gadget instances running
+	// the same image on the node
+	MaxInstancesPerImage int
+
+	// MaxMapMemory is the maximum memory in bytes locked by the eBPF maps of
+	// all gadget instances. If it's exceeded, the instance using the most
+	// memory is stopped and no new instances are admitted.
+	MaxMapMemory uint64
+
+	// MaxCPUUsage is the maximum CPU usage of the eBPF programs of a single
+	// gadget instance in percent of one CPU. Instances exceeding it are
+	// stopped.
+	MaxCPUUsage float64
+
+	// MaxEventRate is the maximum number of events per second a single gadget
+	// instance can emit; what happens to instances exceeding it depends on
+	// EventRateAction
+	MaxEventRate uint64
+
+	// EventRateAction is either QuotaActionThrottle (default) or
+	// QuotaActionStop
+	EventRateAction string
+
+	// Interval in which the usage of gadget instances is checked; defaults to
+	// DefaultQuotaInterval
+	Interval time.Duration
+}
+
+func (q *Quotas) validate() error {
+	switch q.EventRateAction {
+	case "":
+		q.EventRateAction = QuotaActionThrottle
+	case QuotaActionThrottle, QuotaActionStop:
+	default:
+		return fmt.Errorf("invalid event rate action %q, expected %q or %q",
+			q.EventRateAction, QuotaActionThrottle, QuotaActionStop)
+	}
+	if q.Interval == 0 {
+		q.Interval = DefaultQuotaInterval
+	}
+	if q.Interval < 0 {
+		return fmt.Errorf("invalid quota interval %v", q.Interval)
+	}
+	return nil
+}
+
+// monitored returns whether the usage of running gadget instances needs to be
+// checked
+func (q *Quotas) monitored() bool {
+	return q.MaxMapMemory > 0 || q.MaxCPUUsage > 0 ||
+		(q.MaxEventRate > 0 && q.EventRateAction == QuotaActionStop)
+}
+
+// Admit checks whether a new gadget instance can be run without exceeding the
+// quotas
+func (m *Manager) Admit(instance *api.GadgetInstance) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.admitLocked(instance)
+}
+
+func (m *Manager) admitLocked(instance *api.GadgetInstance) error {
+	q := &m.quotas
+	instances := 0
+	perImage := 0
+	for id, gi := range m.gadgetInstances {
+		if id == instance.Id || !gi.active() {
+			continue
+		}
+		instances++
+		if gi.request.GetImageName() == instance.GetGadgetConfig().GetImageName() {
+			perImage++
+		}
+	}
+	if q.MaxInstances > 0 && instances >= q.MaxInstances {
+		return fmt.Errorf("%w: %d gadget instances are running, at most %d are allowed",
+			ErrQuotaExceeded, instances, q.MaxInstances)
+	}
+	if q.MaxInstancesPerImage > 0 && perImage >= q.MaxInstancesPerImage {
+		return fmt.Errorf("%w: %d gadget instances of %q are running, at most %d are allowed",
+			ErrQuotaExceeded, perImage, instance.GetGadgetConfig().GetImageName(), q.MaxInstancesPerImage)
+	}
+	if q.MaxMapMemory > 0 && m.mapMemory >= q.MaxMapMemory {
+		return fmt.Errorf("%w: gadget instances use %d bytes of map memory, at most %d are allowed",
+			ErrQuotaExceeded, m.mapMemory, q.MaxMapMemory)
+	}
+	return nil
+}
+
+// instanceUsage returns the current usage of a gadget instance
+func instanceUsage(gi *GadgetInstance) (bpfstats.Usage, error) {
+	gi.mu.Lock()
+	objects := gi.objects
+	gi.mu.Unlock()
+	if objects == nil {
+		return bpfstats.Usage{}, nil
+	}
+	return objects.Usage()
+}
+
+func (m *Manager) monitorQuotas() {
+	if m.quotas.MaxCPUUsage > 0 {
+		if err := bpfstats.EnableBPFStats(); err != nil {
+			log.Warnf("CPU usage of gadget instances can't be limited: %v", err)
+		} else {
+			defer bpfstats.DisableBPFStats()
+		}
+	}
+
+	ticker := time.NewTicker(m.quotas.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-ticker.C:
+			m.checkQuotas(time.Now())
+		}
+	}
+}
+
+// checkQuotas stops or throttles running gadget instances exceeding the
+// quotas
+func (m *Manager) checkQuotas(now time.Time) {
+	q := &m.quotas
+
+	m.mu.Lock()
+	instances := make([]*GadgetInstance, 0, len(m.gadgetInstances))
+	for _, gi := range m.gadgetInstances {
+		if gi.active() {
+			instances = append(instances, gi)
+		}
+	}
+	m.mu.Unlock()
+
+	var totalMapMemory uint64
+	var largest *GadgetInstance
+	var largestMapMemory uint64
+	for _, gi := range instances {
+		usage, err := m.getUsage(gi)
+		if err != nil {
+			log.Warnf("getting usage of gadget instance %q: %v", gi.id, err)
+			continue
+		}
+
+		gi.mu.Lock()
+		elapsed := now.Sub(gi.lastCheck)
+		cpuUsage := 0.0
+		eventRate := 0.0
+		if !gi.lastCheck.IsZero() && elapsed > 0 {
+			cpuUsage = float64(usage.Runtime-gi.lastUsage.Runtime) / float64(elapsed) * 100.0
+			eventRate = float64(gi.events-gi.lastEvents) / elapsed.Seconds()
+		}
+		dropped := gi.droppedEvents
+		gi.droppedEvents = 0
+		gi.lastCheck = now
+		gi.lastUsage = usage
+		gi.lastEvents = gi.events
+
+		gi.quotaMessage = ""
+		if dropped > 0 {
+			gi.quotaMessage = fmt.Sprintf("throttled: dropped %d events exceeding %d events/s", dropped, q.MaxEventRate)
+		}
+		gi.mu.Unlock()
+
+		switch {
+		case q.MaxCPUUsage > 0 && cpuUsage > q.MaxCPUUsage:
+			gi.stopForQuota(fmt.Errorf("%w: CPU usage of %.1f%% exceeds %.1f%%", ErrQuotaExceeded, cpuUsage, q.MaxCPUUsage))
+			continue
+		case q.MaxEventRate > 0 && q.EventRateAction == QuotaActionStop && eventRate > float64(q.MaxEventRate):
+			gi.stopForQuota(fmt.Errorf("%w: %.0f events/s exceed %d events/s", ErrQuotaExceeded, eventRate, q.MaxEventRate))
+			continue
+		}
+
+		totalMapMemory += usage.MapMemory
+		if largest == nil || usage.MapMemory > largestMapMemory {
+			largest = gi
+			largestMapMemory = usage.MapMemory
+		}
+	}
+
+	if q.MaxMapMemory > 0 && totalMapMemory > q.MaxMapMemory && largest != nil {
+		largest.stopForQuota(fmt.Errorf("%w: gadget instances use %d bytes of map memory, at most %d are allowed; this instance used %d",
+			ErrQuotaExceeded, totalMapMemory, q.MaxMapMemory, largestMapMemory))
+		totalMapMemory -= largestMapMemory
+	}
+
+	m.mu.Lock()
+	m.mapMemory = totalMapMemory
+	m.mu.Unlock()
+}
@@ -0,0 +1,224 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancemanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/bpfstats"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+func newTestManager(t *testing.T, quotas Quotas) *Manager {
+	t.Helper()
+	require.NoError(t, quotas.validate())
+	return &Manager{
+		gadgetInstances: make(map[string]*GadgetInstance),
+		quotas:          quotas,
+		getUsage:        instanceUsage,
+		done:            make(chan struct{}),
+	}
+}
+
+func addTestInstance(m *Manager, id string, image string, state gadgetState) *GadgetInstance {
+	gi := &GadgetInstance{
+		id:      id,
+		mgr:     m,
+		request: &api.GadgetRunRequest{ImageName: image},
+		state:   state,
+		cancel:  func() {},
+	}
+	m.gadgetInstances[id] = gi
+	return gi
+}
+
+func testInstance(id string, image string) *api.GadgetInstance {
+	return &api.GadgetInstance{
+		Id:           id,
+		GadgetConfig: &api.GadgetRunRequest{ImageName: image},
+	}
+}
+
+func TestQuotasValidate(t *testing.T) {
+	q := Quotas{}
+	require.NoError(t, q.validate())
+	assert.Equal(t, QuotaActionThrottle, q.EventRateAction)
+	assert.Equal(t, DefaultQuotaInterval, q.Interval)
+	assert.False(t, q.monitored())
+
+	q = Quotas{EventRateAction: "ignore"}
+	require.Error(t, q.validate())
+
+	q = Quotas{Interval: -time.Second}
+	require.Error(t, q.validate())
+
+	q = Quotas{MaxEventRate: 10, EventRateAction: QuotaActionStop}
+	require.NoError(t, q.validate())
+	assert.True(t, q.monitored())
+}
+
+func TestAdmit(t *testing.T) {
+	type testCase struct {
+		name      string
+		quotas    Quotas
+		mapMemory uint64
+		instance  *api.GadgetInstance
+		expectErr bool
+	}
+
+	tests := []testCase{
+		{
+			name:     "no_quotas",
+			instance: testInstance("new", "trace_open"),
+		},
+		{
+			name:      "max_instances",
+			quotas:    Quotas{MaxInstances: 2},
+			instance:  testInstance("new", "trace_dns"),
+			expectErr: true,
+		},
+		{
+			name:     "max_instances_same_id",
+			quotas:   Quotas{MaxInstances: 2},
+			instance: testInstance("a", "trace_open"),
+		},
+		{
+			name:      "max_instances_per_image",
+			quotas:    Quotas{MaxInstancesPerImage: 1},
+			instance:  testInstance("new", "trace_open"),
+			expectErr: true,
+		},
+		{
+			name:     "max_instances_per_image_other_image",
+			quotas:   Quotas{MaxInstancesPerImage: 1},
+			instance: testInstance("new", "trace_dns"),
+		},
+		{
+			name:      "max_map_memory",
+			quotas:    Quotas{MaxMapMemory: 1024},
+			mapMemory: 1024,
+			instance:  testInstance("new", "trace_dns"),
+			expectErr: true,
+		},
+		{
+			name:      "max_map_memory_below",
+			quotas:    Quotas{MaxMapMemory: 1024},
+			mapMemory: 512,
+			instance:  testInstance("new", "trace_dns"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := newTestManager(t, test.quotas)
+			m.mapMemory = test.mapMemory
+			addTestInstance(m, "a", "trace_open", stateRunning)
+			addTestInstance(m, "b", "trace_exec", stateRunning)
+			// Failed instances don't count
+			addTestInstance(m, "c", "trace_open", stateError)
+
+			err := m.Admit(test.instance)
+			if test.expectErr {
+				require.ErrorIs(t, err, ErrQuotaExceeded)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCheckQuotas(t *testing.T) {
+	m := newTestManager(t, Quotas{
+		MaxMapMemory:    3000,
+		MaxCPUUsage:     10,
+		MaxEventRate:    100,
+		EventRateAction: QuotaActionStop,
+	})
+	usage := map[string]bpfstats.Usage{}
+	m.getUsage = func(gi *GadgetInstance) (bpfstats.Usage, error) {
+		return usage[gi.id], nil
+	}
+
+	cpu := addTestInstance(m, "cpu", "trace_open", stateRunning)
+	rate := addTestInstance(m, "rate", "trace_exec", stateRunning)
+	small := addTestInstance(m, "small", "trace_dns", stateRunning)
+	large := addTestInstance(m, "large", "trace_tcp", stateRunning)
+
+	usage["small"] = bpfstats.Usage{MapMemory: 1000}
+	usage["large"] = bpfstats.Usage{MapMemory: 1500}
+
+	// The first check only records the usage
+	now := time.Now()
+	m.checkQuotas(now)
+	for _, gi := range []*GadgetInstance{cpu, rate, small, large} {
+		require.True(t, gi.active())
+	}
+	require.Equal(t, uint64(2500), m.mapMemory)
+
+	now = now.Add(10 * time.Second)
+	usage["cpu"] = bpfstats.Usage{Runtime: 2 * time.Second}
+	usage["large"] = bpfstats.Usage{MapMemory: 2500}
+	rate.events = 2000
+	small.events = 500
+	m.checkQuotas(now)
+
+	require.ErrorIs(t, cpu.quotaErr, ErrQuotaExceeded)
+	assert.Contains(t, cpu.quotaErr.Error(), "CPU usage of 20.0% exceeds 10.0%")
+	require.ErrorIs(t, rate.quotaErr, ErrQuotaExceeded)
+	assert.Contains(t, rate.quotaErr.Error(), "200 events/s exceed 100 events/s")
+	require.ErrorIs(t, large.quotaErr, ErrQuotaExceeded)
+	assert.NoError(t, small.quotaErr)
+	assert.Equal(t, uint64(1000), m.mapMemory)
+}
+
+func TestThrottled(t *testing.T) {
+	m := newTestManager(t, Quotas{MaxEventRate: 2})
+	gi := addTestInstance(m, "a", "trace_open", stateRunning)
+
+	now := time.Now()
+	assert.False(t, gi.throttled(now))
+	assert.False(t, gi.throttled(now))
+	assert.True(t, gi.throttled(now.Add(500*time.Millisecond)))
+	assert.False(t, gi.throttled(now.Add(time.Second)))
+	assert.Equal(t, uint64(4), gi.events)
+	assert.Equal(t, uint64(1), gi.droppedEvents)
+
+	m.checkQuotas(now.Add(time.Second))
+	state, err := m.InstanceState("a")
+	require.NoError(t, err)
+	assert.Equal(t, api.GadgetInstanceStatus_StatusRunning, state.Status)
+	assert.Equal(t, "throttled: dropped 1 events exceeding 2 events/s", state.Message)
+}
+
+func TestCloseStopsMonitor(t *testing.T) {
+	m, err := New(nil, WithQuotas(Quotas{MaxEventRate: 10, Interval: time.Millisecond}))
+	require.NoError(t, err)
+
+	closed := make(chan struct{})
+	go func() {
+		m.Close()
+		m.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("closing the manager didn't stop monitoring the quotas")
+	}
+}
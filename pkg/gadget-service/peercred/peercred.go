@@ -0,0 +1,103 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package peercred provides gRPC transport credentials for unix sockets that
+// make the credentials (uid, gid and pid) of the connected process available
+// to the server using peer.FromContext().
+package peercred
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"golang.org/x/sys/unix"
+	"google.golang.org/grpc/credentials"
+)
+
+const AuthType = "peercred"
+
+// AuthInfo holds the credentials of the process connected to a unix socket
+type AuthInfo struct {
+	credentials.CommonAuthInfo
+	UID uint32
+	GID uint32
+	PID int32
+}
+
+func (AuthInfo) AuthType() string {
+	return AuthType
+}
+
+type transportCredentials struct{}
+
+// NewTransportCredentials returns transport credentials that read the peer
+// credentials of unix socket connections. The connection itself is not
+// secured; connections that aren't using unix sockets are passed through
+// without AuthInfo.
+func NewTransportCredentials() credentials.TransportCredentials {
+	return &transportCredentials{}
+}
+
+func (c *transportCredentials) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return conn, nil, nil
+}
+
+func (c *transportCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return conn, nil, nil
+	}
+	ucred, err := getPeerCred(unixConn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting peer credentials: %w", err)
+	}
+	return conn, AuthInfo{
+		CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.NoSecurity},
+		UID:            ucred.Uid,
+		GID:            ucred.Gid,
+		PID:            ucred.Pid,
+	}, nil
+}
+
+func (c *transportCredentials) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{SecurityProtocol: AuthType}
+}
+
+func (c *transportCredentials) Clone() credentials.TransportCredentials {
+	return &transportCredentials{}
+}
+
+func (c *transportCredentials) OverrideServerName(string) error {
+	return nil
+}
+
+func getPeerCred(conn *net.UnixConn) (*unix.Ucred, error) {
+	rawConn, err := conn.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+	var ucred *unix.Ucred
+	var sockErr error
+	err = rawConn.Control(func(fd uintptr) {
+		ucred, sockErr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if sockErr != nil {
+		return nil, sockErr
+	}
+	return ucred, nil
+}
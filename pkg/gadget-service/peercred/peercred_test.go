@@ -0,0 +1,65 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package peercred
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerHandshake(t *testing.T) {
+	listener, err := net.Listen("unix", filepath.Join(t.TempDir(), "test.socket"))
+	require.NoError(t, err)
+	defer listener.Close()
+
+	client, err := net.Dial("unix", listener.Addr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	server, err := listener.Accept()
+	require.NoError(t, err)
+	defer server.Close()
+
+	_, authInfo, err := NewTransportCredentials().ServerHandshake(server)
+	require.NoError(t, err)
+	info, ok := authInfo.(AuthInfo)
+	require.True(t, ok)
+	assert.Equal(t, uint32(os.Getuid()), info.UID)
+	assert.Equal(t, uint32(os.Getgid()), info.GID)
+	assert.Equal(t, int32(os.Getpid()), info.PID)
+}
+
+func TestServerHandshakeTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	client, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	server, err := listener.Accept()
+	require.NoError(t, err)
+	defer server.Close()
+
+	_, authInfo, err := NewTransportCredentials().ServerHandshake(server)
+	require.NoError(t, err)
+	assert.Nil(t, authInfo)
+}
@@ -0,0 +1,290 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gadgetservice
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
+	clioperator "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/cli"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/filter/filterfunc"
+)
+
+// Param values of operators that decide which fields the client is going to
+// consume. The operators aren't imported to avoid registering the client-only
+// ones on the server.
+const (
+	cliFieldsParam      = "operator.cli." + clioperator.ParamFields
+	cliModeParam        = "operator.cli." + clioperator.ParamMode
+	sortByParam         = "operator.sort.sort"
+	diffKeysParam       = "operator.diff.diff-keys"
+	filterParam         = "operator.filter.filter"
+	filterExprParam     = "operator.filter.filter-expr"
+	otelLogsExportParam = "operator.otel-logs.otel-logs-exporter"
+)
+
+// Annotations of data sources that are consumed by client-side operators that
+// need access to all fields
+var clientConsumerAnnotations = []string{
+	"metrics.collect",
+	"metrics.print",
+	"generate_networkpolicy.enable",
+}
+
+// projection holds the payload indexes of a data source that the client didn't
+// ask for and that don't need to be sent
+type projection struct {
+	strip []uint32
+	// fields are the full names of fields that lose their payload
+	fields []string
+}
+
+// newProjections computes projections for all data sources that will only be
+// used for columns output on the client. Only fields that have a payload of
+// their own get stripped; members of static containers (like the ones coming
+// from eBPF) share their payload with other fields and are always sent.
+func newProjections(dataSources map[string]datasource.DataSource, paramValues api.ParamValues) (map[string]*projection, error) {
+	if paramValues[api.ParamServerSideProjection] != "true" {
+		return nil, nil
+	}
+
+	// exported logs would need all fields
+	if paramValues[otelLogsExportParam] != "" {
+		return nil, nil
+	}
+
+	modes, _, err := clioperator.ParseOutputModes(paramValues[cliModeParam])
+	if err != nil {
+		return nil, fmt.Errorf("parsing output modes: %w", err)
+	}
+	fieldLookup, err := apihelpers.GetListValuesPerDataSource(paramValues[cliFieldsParam])
+	if err != nil {
+		return nil, fmt.Errorf("parsing fields: %w", err)
+	}
+
+	// Fields used by other operators that could run on the client are sent as
+	// well
+	usedFieldsLookup := make(map[string][]string)
+	sortLookup, err := apihelpers.GetListValuesPerDataSource(paramValues[sortByParam])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", sortByParam, err)
+	}
+	for dsName, fields := range sortLookup {
+		for _, field := range apihelpers.SplitList(fields) {
+			usedFieldsLookup[dsName] = append(usedFieldsLookup[dsName], strings.TrimPrefix(field, "-"))
+		}
+	}
+
+	// The diff operator compares all fields of the rows, also with baselines
+	// recorded without projection
+	diffLookup, err := apihelpers.GetListValuesPerDataSource(paramValues[diffKeysParam])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", diffKeysParam, err)
+	}
+
+	projections := make(map[string]*projection)
+	for _, ds := range dataSources {
+		if !usesColumnsOutput(ds, modes) {
+			continue
+		}
+
+		if _, ok := lookupDataSource(diffLookup, ds.Name()); ok {
+			continue
+		}
+
+		// Fields used by filter expressions can't be known without compiling
+		// them
+		if paramValues[filterExprParam] != "" || paramValues[filterExprParam+"."+ds.Name()] != "" {
+			continue
+		}
+
+		p, err := ds.Parser()
+		if err != nil {
+			return nil, fmt.Errorf("getting parser for data source %q: %w", ds.Name(), err)
+		}
+		requested := p.GetDefaultColumns()
+		if fields, ok := lookupDataSource(fieldLookup, ds.Name()); ok {
+			requested = clioperator.ParseFields(fields, requested)
+		}
+		requested = append(requested, usedFieldsLookup[""]...)
+		requested = append(requested, usedFieldsLookup[ds.Name()]...)
+		for _, param := range []string{filterParam, filterParam + "." + ds.Name()} {
+			fields, err := filterfunc.Fields(paramValues[param])
+			if err != nil {
+				return nil, fmt.Errorf("parsing %s: %w", param, err)
+			}
+			requested = append(requested, fields...)
+		}
+
+		if prj := newProjection(ds.Fields(), requested); prj != nil {
+			projections[ds.Name()] = prj
+		}
+	}
+	return projections, nil
+}
+
+func newProjection(fields []*api.Field, requested []string) *projection {
+	wanted := make(map[string]struct{}, len(requested))
+	for _, name := range requested {
+		wanted[strings.ToLower(name)] = struct{}{}
+	}
+	// fields may be replaced by others when printing them
+	for _, f := range fields {
+		if _, ok := wanted[strings.ToLower(f.FullName)]; !ok {
+			continue
+		}
+		if replacement, ok := f.Annotations[datasource.ColumnsReplaceAnnotation]; ok {
+			wanted[strings.ToLower(replacement)] = struct{}{}
+		}
+	}
+
+	keep := make(map[uint32]struct{})
+	candidates := make(map[uint32][]string)
+	for _, f := range fields {
+		if datasource.FieldFlagEmpty.In(f.Flags) {
+			continue
+		}
+		_, isWanted := wanted[strings.ToLower(f.FullName)]
+		if isWanted || f.Size > 0 ||
+			datasource.FieldFlagContainer.In(f.Flags) ||
+			datasource.FieldFlagStaticMember.In(f.Flags) {
+			keep[f.PayloadIndex] = struct{}{}
+			continue
+		}
+		if datasource.FieldFlagUnreferenced.In(f.Flags) {
+			// already invisible to the client, but its payload might still
+			// be in use by another field
+			continue
+		}
+		candidates[f.PayloadIndex] = append(candidates[f.PayloadIndex], f.FullName)
+	}
+
+	prj := &projection{}
+	for idx, names := range candidates {
+		if _, ok := keep[idx]; ok {
+			continue
+		}
+		prj.strip = append(prj.strip, idx)
+		prj.fields = append(prj.fields, names...)
+	}
+	if len(prj.strip) == 0 {
+		return nil
+	}
+	slices.Sort(prj.strip)
+	slices.Sort(prj.fields)
+	return prj
+}
+
+// apply marks all stripped fields of ds as unreferenced, so that clients don't
+// try to access them. Fields are cloned, as they're shared with the actual
+// DataSource.
+func (p *projection) apply(ds *api.DataSource) {
+	for i, f := range ds.Fields {
+		if !slices.Contains(p.fields, f.FullName) {
+			continue
+		}
+		nf := proto.Clone(f).(*api.Field)
+		datasource.FieldFlagUnreferenced.AddTo(&nf.Flags)
+		ds.Fields[i] = nf
+	}
+}
+
+// marshal serializes the given GadgetData or GadgetDataArray without the
+// payloads that have been stripped
+func (p *projection) marshal(raw proto.Message) ([]byte, error) {
+	if p == nil {
+		return proto.Marshal(raw)
+	}
+
+	var elements []*api.DataElement
+	switch d := raw.(type) {
+	case *api.GadgetData:
+		elements = []*api.DataElement{d.Data}
+	case *api.GadgetDataArray:
+		elements = d.DataArray
+	}
+
+	// Temporarily remove the payloads and restore them afterward, as the
+	// packet might still be in use
+	saved := make([][]byte, 0, len(elements)*len(p.strip))
+	for _, e := range elements {
+		if e == nil {
+			continue
+		}
+		for _, idx := range p.strip {
+			if int(idx) < len(e.Payload) {
+				saved = append(saved, e.Payload[idx])
+				e.Payload[idx] = nil
+			}
+		}
+	}
+
+	d, err := proto.Marshal(raw)
+
+	for _, e := range elements {
+		if e == nil {
+			continue
+		}
+		for _, idx := range p.strip {
+			if int(idx) < len(e.Payload) {
+				e.Payload[idx] = saved[0]
+				saved = saved[1:]
+			}
+		}
+	}
+	return d, err
+}
+
+func lookupDataSource(values map[string]string, dsName string) (string, bool) {
+	if v, ok := values[dsName]; ok {
+		return v, true
+	}
+	v, ok := values[""]
+	return v, ok
+}
+
+// usesColumnsOutput returns whether the data source is only going to be
+// printed in columns mode by the client
+func usesColumnsOutput(ds datasource.DataSource, modes map[string]string) bool {
+	annotations := ds.Annotations()
+	for _, annotation := range clientConsumerAnnotations {
+		if annotations[annotation] == "true" {
+			return false
+		}
+	}
+
+	supportedModes := clioperator.DefaultSupportedOutputModes
+	if v := annotations[clioperator.AnnotationSupportedOutputModes]; v != "" {
+		supportedModes = strings.Split(v, ",")
+	}
+
+	mode, ok := modes[ds.Name()]
+	if !ok {
+		mode, ok = modes[""]
+	}
+	if !ok {
+		mode = annotations[clioperator.AnnotationDefaultOutputMode]
+		if mode == "" {
+			mode = clioperator.DefaultOutputMode
+		}
+	}
+	return mode == clioperator.ModeColumns && slices.Contains(supportedModes, mode)
+}
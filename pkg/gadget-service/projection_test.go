@@ -0,0 +1,259 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gadgetservice
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+type testDataSource struct {
+	ds                   datasource.DataSource
+	comm, pid, args, cwd datasource.FieldAccessor
+}
+
+func newTestDataSource(t testing.TB) *testDataSource {
+	ds, err := datasource.New(datasource.TypeSingle, "exec")
+	require.NoError(t, err)
+
+	tds := &testDataSource{ds: ds}
+	tds.comm, err = ds.AddField("comm", api.Kind_String)
+	require.NoError(t, err)
+	tds.pid, err = ds.AddField("pid", api.Kind_Uint32)
+	require.NoError(t, err)
+	tds.args, err = ds.AddField("args", api.Kind_String, datasource.WithFlags(datasource.FieldFlagHidden))
+	require.NoError(t, err)
+	tds.cwd, err = ds.AddField("cwd", api.Kind_String)
+	require.NoError(t, err)
+	return tds
+}
+
+func (tds *testDataSource) newPacket(t testing.TB) datasource.PacketSingle {
+	p, err := tds.ds.NewPacketSingle()
+	require.NoError(t, err)
+	require.NoError(t, tds.comm.PutString(p, "cat"))
+	require.NoError(t, tds.pid.PutUint32(p, 1234))
+	require.NoError(t, tds.args.PutString(p, strings.Repeat("--some-argument ", 16)))
+	require.NoError(t, tds.cwd.PutString(p, "/home/user/some/directory"))
+	return p
+}
+
+func TestNewProjections(t *testing.T) {
+	type testCase struct {
+		name           string
+		paramValues    api.ParamValues
+		expectedFields []string
+	}
+
+	testCases := []testCase{
+		{
+			name:        "not requested",
+			paramValues: api.ParamValues{cliFieldsParam: "comm"},
+		},
+		{
+			name:           "default fields",
+			paramValues:    api.ParamValues{api.ParamServerSideProjection: "true"},
+			expectedFields: []string{"args"},
+		},
+		{
+			name: "explicit fields",
+			paramValues: api.ParamValues{
+				api.ParamServerSideProjection: "true",
+				cliFieldsParam:                "COMM,pid",
+			},
+			expectedFields: []string{"args", "cwd"},
+		},
+		{
+			name: "explicit fields for data source",
+			paramValues: api.ParamValues{
+				api.ParamServerSideProjection: "true",
+				cliFieldsParam:                "other:cwd;exec:comm",
+			},
+			expectedFields: []string{"args", "cwd", "pid"},
+		},
+		{
+			name: "added fields",
+			paramValues: api.ParamValues{
+				api.ParamServerSideProjection: "true",
+				cliFieldsParam:                "+args",
+			},
+		},
+		{
+			name: "removed fields",
+			paramValues: api.ParamValues{
+				api.ParamServerSideProjection: "true",
+				cliFieldsParam:                "-cwd",
+			},
+			expectedFields: []string{"args", "cwd"},
+		},
+		{
+			name: "sort fields",
+			paramValues: api.ParamValues{
+				api.ParamServerSideProjection: "true",
+				cliFieldsParam:                "comm",
+				sortByParam:                   "-cwd",
+			},
+			expectedFields: []string{"args", "pid"},
+		},
+		{
+			name: "diff keys",
+			paramValues: api.ParamValues{
+				api.ParamServerSideProjection: "true",
+				cliFieldsParam:                "comm",
+				diffKeysParam:                 "exec:pid",
+			},
+		},
+		{
+			name: "diff keys for other data source",
+			paramValues: api.ParamValues{
+				api.ParamServerSideProjection: "true",
+				cliFieldsParam:                "comm",
+				diffKeysParam:                 "other:pid",
+			},
+			expectedFields: []string{"args", "cwd", "pid"},
+		},
+		{
+			name: "filter fields",
+			paramValues: api.ParamValues{
+				api.ParamServerSideProjection: "true",
+				cliFieldsParam:                "comm",
+				filterParam:                   "cwd~^/home",
+				filterParam + ".exec":         "args!=foo",
+			},
+			expectedFields: []string{"pid"},
+		},
+		{
+			name: "filter expression",
+			paramValues: api.ParamValues{
+				api.ParamServerSideProjection: "true",
+				cliFieldsParam:                "comm",
+				filterExprParam + ".exec":     `cwd startsWith "/home"`,
+			},
+		},
+		{
+			name: "json output",
+			paramValues: api.ParamValues{
+				api.ParamServerSideProjection: "true",
+				cliFieldsParam:                "comm",
+				cliModeParam:                  "json",
+			},
+		},
+		{
+			name: "otel logs",
+			paramValues: api.ParamValues{
+				api.ParamServerSideProjection: "true",
+				cliFieldsParam:                "comm",
+				otelLogsExportParam:           "default",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tds := newTestDataSource(t)
+			projections, err := newProjections(map[string]datasource.DataSource{"exec": tds.ds}, tc.paramValues)
+			require.NoError(t, err)
+
+			prj := projections["exec"]
+			if tc.expectedFields == nil {
+				assert.Nil(t, prj)
+				return
+			}
+			require.NotNil(t, prj)
+			assert.Equal(t, tc.expectedFields, prj.fields)
+		})
+	}
+}
+
+func TestProjectionMarshal(t *testing.T) {
+	tds := newTestDataSource(t)
+	projections, err := newProjections(map[string]datasource.DataSource{"exec": tds.ds}, api.ParamValues{
+		api.ParamServerSideProjection: "true",
+		cliFieldsParam:                "comm,pid",
+	})
+	require.NoError(t, err)
+	prj := projections["exec"]
+	require.NotNil(t, prj)
+
+	apiDs := &api.DataSource{
+		Name:   tds.ds.Name(),
+		Type:   uint32(tds.ds.Type()),
+		Fields: tds.ds.Fields(),
+	}
+	prj.apply(apiDs)
+
+	// fields of the actual data source must not be touched
+	for _, f := range tds.ds.Fields() {
+		assert.False(t, datasource.FieldFlagUnreferenced.In(f.Flags), f.FullName)
+	}
+
+	p := tds.newPacket(t)
+	full, err := proto.Marshal(p.Raw())
+	require.NoError(t, err)
+	projected, err := prj.marshal(p.Raw())
+	require.NoError(t, err)
+	assert.Less(t, len(projected), len(full))
+
+	// the packet must still be intact on the server
+	cwd, err := tds.cwd.String(p)
+	require.NoError(t, err)
+	assert.Equal(t, "/home/user/some/directory", cwd)
+
+	clientDs, err := datasource.NewFromAPI(apiDs)
+	require.NoError(t, err)
+	assert.Nil(t, clientDs.GetField("args"))
+	assert.Nil(t, clientDs.GetField("cwd"))
+
+	clientPacket, err := clientDs.NewPacketSingleFromRaw(projected)
+	require.NoError(t, err)
+	comm, err := clientDs.GetField("comm").String(clientPacket)
+	require.NoError(t, err)
+	assert.Equal(t, "cat", comm)
+	pid, err := clientDs.GetField("pid").Uint32(clientPacket)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1234), pid)
+}
+
+func BenchmarkProjectionMarshal(b *testing.B) {
+	tds := newTestDataSource(b)
+	projections, err := newProjections(map[string]datasource.DataSource{"exec": tds.ds}, api.ParamValues{
+		api.ParamServerSideProjection: "true",
+		cliFieldsParam:                "comm,pid",
+	})
+	require.NoError(b, err)
+
+	p := tds.newPacket(b)
+	for _, prj := range []*projection{nil, projections["exec"]} {
+		name := "full"
+		if prj != nil {
+			name = "projected"
+		}
+		b.Run(name, func(b *testing.B) {
+			var size int
+			for i := 0; i < b.N; i++ {
+				d, _ := prj.marshal(p.Raw())
+				size = len(d)
+			}
+			b.ReportMetric(float64(size), "bytes/event")
+		})
+	}
+}
@@ -18,22 +18,25 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"sync"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
-	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/peer"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
 	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/audit"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/authz"
+	instancemanager "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/instance-manager"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/simple"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/runtime/local"
 )
 
 func (s *Service) initOperators() error {
@@ -50,28 +53,32 @@ func (s *Service) GetOperatorMap() map[operators.DataOperator]*params.Params {
 	return s.operators
 }
 
-func (s *Service) GetGadgetInfo(ctx context.Context, req *api.GetGadgetInfoRequest) (*api.GetGadgetInfoResponse, error) {
+func (s *Service) GetGadgetInfo(ctx context.Context, req *api.GetGadgetInfoRequest) (_ *api.GetGadgetInfoResponse, err error) {
 	metricAttribs := attribute.NewSet(
 		attribute.KeyValue{Key: "gadget_image", Value: attribute.StringValue(req.ImageName)},
 	)
 	defer s.ctrGetGadgetInfo.Add(context.Background(), 1, metric.WithAttributeSet(metricAttribs))
 
+	record := &audit.Record{
+		Operation: audit.OperationGetGadgetInfo,
+		Caller:    audit.CallerFromContext(ctx),
+		Image:     req.ImageName,
+		Params:    req.ParamValues,
+	}
+	defer func() { s.auditor.Record(record, err) }()
+
 	if req.Version != api.VersionGadgetInfo {
 		return nil, fmt.Errorf("expected version to be %d, got %d", api.VersionGadgetInfo, req.Version)
 	}
 
-	p, ok := peer.FromContext(ctx)
-	if ok && p.AuthInfo != nil {
-		tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
-		if !ok {
-			return nil, fmt.Errorf("authentication information is not TLS: %v", p.AuthInfo)
-		}
-
-		subject := tlsInfo.State.VerifiedChains[0][0].Subject
-		s.logger.Infof("[%s] GetGadgetInfo(%q)", subject, req.ImageName)
+	if record.Caller != nil && record.Caller.Subject != "" {
+		s.logger.Infof("[%s] GetGadgetInfo(%q)", record.Caller.Subject, req.ImageName)
 	}
 
 	if req.Flags&api.GadgetInfoRequestFlagUseInstance != 0 {
+		// ImageName holds the ID or name of the instance in this case
+		record.Image = ""
+		record.InstanceID = req.ImageName
 		if s.instanceMgr == nil {
 			return nil, fmt.Errorf("instance manager not initialized")
 		}
@@ -83,9 +90,19 @@ func (s *Service) GetGadgetInfo(ctx context.Context, req *api.GetGadgetInfoReque
 		if err != nil {
 			return nil, err
 		}
+		record.InstanceID = gadgetInfo.Id
+		record.InstanceName = gadgetInfo.Name
+		if err := authorizeInstance(ctx, authz.RuleFromContext(ctx), gi, record); err != nil {
+			return nil, err
+		}
 		return &api.GetGadgetInfoResponse{GadgetInfo: gadgetInfo}, nil
 	}
 
+	rule := authz.RuleFromContext(ctx)
+	if err := rule.AuthorizeImageReference(req.ImageName); err != nil {
+		return nil, err
+	}
+
 	// Get all available operators
 	ops := make([]operators.DataOperator, 0)
 	for op := range s.operators {
@@ -101,20 +118,69 @@ func (s *Service) GetGadgetInfo(ctx context.Context, req *api.GetGadgetInfoReque
 	)
 
 	gi, err := s.runtime.GetGadgetInfo(gadgetCtx, s.runtime.ParamDescs().ToParams(), req.ParamValues)
+	record.ImageDigest = audit.ImageDigest(gadgetCtx)
 	if err != nil {
 		return nil, fmt.Errorf("getting gadget info: %w", err)
 	}
+	if err := rule.AuthorizeImage(req.ImageName, record.ImageDigest); err != nil {
+		return nil, err
+	}
 	return &api.GetGadgetInfoResponse{GadgetInfo: gi}, nil
 }
 
-func (s *Service) RunGadget(runGadget api.GadgetManager_RunGadgetServer) error {
+// authorizeGadget checks the image digest and the params of a gadget whose
+// operators have already been instantiated
+func authorizeGadget(rule *authz.Rule, gadgetCtx operators.GadgetContext, paramValues api.ParamValues) error {
+	if err := rule.AuthorizeImage(gadgetCtx.ImageName(), audit.ImageDigest(gadgetCtx)); err != nil {
+		return err
+	}
+	return rule.AuthorizeParams(gadgetCtx.Params(), paramValues)
+}
+
+// authorizeInstance checks whether rule allows running the gadget of an
+// existing gadget instance and adds its image to record
+func authorizeInstance(ctx context.Context, rule *authz.Rule, gi *instancemanager.GadgetInstance, record *audit.Record) error {
+	image, digest, err := gi.Image(ctx)
+	if err != nil {
+		return err
+	}
+	record.Image = image
+	record.ImageDigest = digest
+	return rule.AuthorizeImage(image, digest)
+}
+
+// authzPriority makes the authz operator pre-start before all other operators
+const authzPriority = math.MinInt32
+
+// newAuthzOperator returns an operator that checks whether rule allows running
+// the gadget. Params are only known once all operators have been instantiated,
+// so they're checked when pre-starting, before any other operator acts on them.
+func newAuthzOperator(rule *authz.Rule, paramValues api.ParamValues) operators.DataOperator {
+	return simple.New("authz",
+		simple.WithPriority(authzPriority),
+		simple.OnPreStart(func(gadgetCtx operators.GadgetContext) error {
+			return authorizeGadget(rule, gadgetCtx, paramValues)
+		}),
+	)
+}
+
+func (s *Service) RunGadget(runGadget api.GadgetManager_RunGadgetServer) (err error) {
 	ctrl, err := runGadget.Recv()
 	if err != nil {
 		return err
 	}
 
+	// Runs are recorded once they're done, so the outcome is known
+	record := &audit.Record{
+		Time:      time.Now(),
+		Operation: audit.OperationRunGadget,
+		Caller:    audit.CallerFromContext(runGadget.Context()),
+	}
+	defer func() { s.auditor.Record(record, err) }()
+
 	attachRequest := ctrl.GetAttachRequest()
 	if attachRequest != nil {
+		record.InstanceID = attachRequest.Id
 		if attachRequest.Version != api.VersionGadgetRunProtocol {
 			return fmt.Errorf("expected version to be %d, got %d", api.VersionGadgetRunProtocol, attachRequest.Version)
 		}
@@ -122,6 +188,16 @@ func (s *Service) RunGadget(runGadget api.GadgetManager_RunGadgetServer) error {
 			return errors.New("instance manager not initialized")
 		}
 
+		if rule := authz.RuleFromContext(runGadget.Context()); rule != nil {
+			gi := s.instanceMgr.Instance(attachRequest.Id)
+			if gi == nil {
+				return fmt.Errorf("gadget %s not found", attachRequest.Id)
+			}
+			if err := authorizeInstance(runGadget.Context(), rule, gi, record); err != nil {
+				return err
+			}
+		}
+
 		s.ctrAttachGadget.Add(context.Background(), 1)
 		return s.instanceMgr.AttachToGadgetInstance(attachRequest.Id, runGadget)
 	}
@@ -130,27 +206,27 @@ func (s *Service) RunGadget(runGadget api.GadgetManager_RunGadgetServer) error {
 	if ociRequest == nil {
 		return fmt.Errorf("expected first control message to be gadget run request")
 	}
+	record.Image = ociRequest.ImageName
+	record.Params = ociRequest.ParamValues
 
 	metricAttribs := attribute.NewSet(
 		attribute.KeyValue{Key: "gadget_image", Value: attribute.StringValue(ociRequest.ImageName)},
 	)
 	defer s.ctrRunGadget.Add(context.Background(), 1, metric.WithAttributeSet(metricAttribs))
 
-	p, ok := peer.FromContext(runGadget.Context())
-	if ok && p.AuthInfo != nil {
-		tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
-		if !ok {
-			return fmt.Errorf("authentication information are not TLS: %v", p.AuthInfo)
-		}
-
-		subject := tlsInfo.State.VerifiedChains[0][0].Subject
-		s.logger.Infof("[%s] RunGadget(%q)", subject, ociRequest.ImageName)
+	if record.Caller != nil && record.Caller.Subject != "" {
+		s.logger.Infof("[%s] RunGadget(%q)", record.Caller.Subject, ociRequest.ImageName)
 	}
 
 	if ociRequest.Version != api.VersionGadgetRunProtocol {
 		return fmt.Errorf("expected version to be %d, got %d", api.VersionGadgetRunProtocol, ociRequest.Version)
 	}
 
+	rule := authz.RuleFromContext(runGadget.Context())
+	if err := rule.AuthorizeImageReference(ociRequest.ImageName); err != nil {
+		return err
+	}
+
 	// Create payload buffer
 	outputBuffer := make(chan *api.GadgetEvent, s.eventBufferLength)
 
@@ -232,12 +308,26 @@ func (s *Service) RunGadget(runGadget api.GadgetManager_RunGadgetServer) error {
 				dsLookup[ds.Name] = ds.Id
 			}
 
+			// Drop the payload of fields the client didn't ask for; filtering already
+			// happened on this side by the filter operator
+			projections, err := newProjections(gadgetCtx.GetDataSources(), ociRequest.ParamValues)
+			if err != nil {
+				return fmt.Errorf("computing projections: %w", err)
+			}
+			for _, ds := range gi.DataSources {
+				if prj, ok := projections[ds.Name]; ok {
+					log.Debugf("not sending fields %v of data source %q", prj.fields, ds.Name)
+					prj.apply(ds)
+				}
+			}
+
 			// todo: skip DataSources we're not interested in
 
 			for _, ds := range gadgetCtx.GetDataSources() {
 				dsID := dsLookup[ds.Name()]
+				prj := projections[ds.Name()]
 				ds.SubscribePacket(func(ds datasource.DataSource, packet datasource.Packet) error {
-					d, _ := proto.Marshal(packet.Raw())
+					d, _ := prj.marshal(packet.Raw())
 
 					event := &api.GadgetEvent{
 						Type:         api.EventTypeGadgetPayload,
@@ -279,7 +369,7 @@ func (s *Service) RunGadget(runGadget api.GadgetManager_RunGadgetServer) error {
 	for op := range s.operators {
 		ops = append(ops, op)
 	}
-	ops = append(ops, svc)
+	ops = append(ops, svc, newAuthzOperator(rule, ociRequest.ParamValues))
 
 	gadgetCtx := gadgetcontext.New(
 		runGadget.Context(),
@@ -293,7 +383,13 @@ func (s *Service) RunGadget(runGadget api.GadgetManager_RunGadgetServer) error {
 	runtimeParams := s.runtime.ParamDescs().ToParams()
 	runtimeParams.CopyFromMap(ociRequest.ParamValues, "runtime.")
 
+	// Don't let remote clients replay arbitrary files from the node
+	if err := runtimeParams.Set(local.ParamReplay, ""); err != nil {
+		return fmt.Errorf("disabling replay: %w", err)
+	}
+
 	err = s.runtime.RunGadget(gadgetCtx, runtimeParams, ociRequest.ParamValues)
+	record.ImageDigest = audit.ImageDigest(gadgetCtx)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,223 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gadgetservice
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/authz"
+	instancemanager "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/instance-manager"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	ocihandler "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/oci-handler"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/runtime"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/runtime/local"
+)
+
+// sideEffectOperator acts on its param when pre-starting, like the record
+// operator creating a file
+type sideEffectOperator struct {
+	preStarted bool
+}
+
+func (o *sideEffectOperator) Name() string                        { return "sideeffect" }
+func (o *sideEffectOperator) Init(params *params.Params) error    { return nil }
+func (o *sideEffectOperator) GlobalParams() api.Params            { return nil }
+func (o *sideEffectOperator) Priority() int                       { return 0 }
+func (o *sideEffectOperator) Start(operators.GadgetContext) error { return nil }
+func (o *sideEffectOperator) Stop(operators.GadgetContext) error  { return nil }
+func (o *sideEffectOperator) Close(operators.GadgetContext) error { return nil }
+
+func (o *sideEffectOperator) InstanceParams() api.Params {
+	return api.Params{{Key: "file"}}
+}
+
+func (o *sideEffectOperator) InstantiateDataOperator(gadgetCtx operators.GadgetContext, instanceParamValues api.ParamValues) (operators.DataOperatorInstance, error) {
+	return o, nil
+}
+
+func (o *sideEffectOperator) PreStart(gadgetCtx operators.GadgetContext) error {
+	o.preStarted = true
+	return nil
+}
+
+func TestAuthzOperatorDeniedParam(t *testing.T) {
+	rule := &authz.Rule{Name: "test", DeniedParams: []string{"file"}}
+	paramValues := api.ParamValues{"operator.sideeffect.file": "/etc/passwd"}
+
+	op := &sideEffectOperator{}
+	gadgetCtx := gadgetcontext.New(
+		context.Background(),
+		"test",
+		gadgetcontext.WithDataOperators(op, newAuthzOperator(rule, paramValues)),
+		gadgetcontext.WithAsRemoteCall(true),
+	)
+
+	err := gadgetCtx.Run(paramValues)
+	require.Error(t, err)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	require.False(t, op.preStarted, "operator pre-started before the params were authorized")
+}
+
+const (
+	testInstanceID     = "0123456789abcdef0123456789abcdef"
+	testInstanceDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+)
+
+// digestOperator sets the image digest like the oci handler does
+type digestOperator struct {
+	sideEffectOperator
+}
+
+func (o *digestOperator) Name() string { return "digest" }
+
+func (o *digestOperator) InstanceParams() api.Params { return nil }
+
+func (o *digestOperator) InstantiateDataOperator(gadgetCtx operators.GadgetContext, instanceParamValues api.ParamValues) (operators.DataOperatorInstance, error) {
+	gadgetCtx.SetVar(ocihandler.ImageDigestVar, testInstanceDigest)
+	return o, nil
+}
+
+// testRuntime runs the operators of a gadget until it's cancelled
+type testRuntime struct {
+	runtime.Runtime
+}
+
+func (r *testRuntime) ParamDescs() params.ParamDescs {
+	return params.ParamDescs{{Key: local.ParamReplay}}
+}
+
+func (r *testRuntime) RunGadget(gadgetCtx runtime.GadgetContext, runtimeParams *params.Params, paramValueMap api.ParamValues) error {
+	return gadgetCtx.(*gadgetcontext.GadgetContext).Run(paramValueMap)
+}
+
+// testStore holds a single gadget instance
+type testStore struct {
+	api.UnimplementedGadgetInstanceManagerServer
+	instance *api.GadgetInstance
+	removed  bool
+}
+
+func (s *testStore) ResumeStoredGadgets() error { return nil }
+
+func (s *testStore) GetGadgetInstance(ctx context.Context, id *api.GadgetInstanceId) (*api.GadgetInstance, error) {
+	if id.Id != s.instance.Id {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	return s.instance, nil
+}
+
+func (s *testStore) RemoveGadgetInstance(ctx context.Context, id *api.GadgetInstanceId) (*api.StatusResponse, error) {
+	s.removed = true
+	return &api.StatusResponse{}, nil
+}
+
+// newTestService returns a service with a running instance of trace_exec
+func newTestService(t *testing.T) (*Service, *testStore) {
+	t.Helper()
+
+	s := NewService(logger.DefaultLogger())
+	s.operators = map[operators.DataOperator]*params.Params{&digestOperator{}: nil}
+
+	mgr, err := instancemanager.New(&testRuntime{})
+	require.NoError(t, err)
+	s.SetInstanceManager(mgr)
+
+	instance := &api.GadgetInstance{
+		Id:   testInstanceID,
+		Name: "test",
+		GadgetConfig: &api.GadgetRunRequest{
+			ImageName: "trace_exec",
+			Version:   api.VersionGadgetRunProtocol,
+		},
+	}
+	store := &testStore{instance: instance}
+	s.SetStore(store)
+
+	mgr.RunGadget(instance)
+	t.Cleanup(func() { mgr.RemoveGadget(testInstanceID) })
+	return s, store
+}
+
+var (
+	otherImageRule = &authz.Rule{Name: "other", Images: []string{"ghcr.io/inspektor-gadget/gadget/trace_open:latest"}}
+	digestRule     = &authz.Rule{Name: "digest", Images: []string{"ghcr.io/inspektor-gadget/gadget/trace_exec@" + testInstanceDigest}}
+)
+
+func TestAuthzGetInstanceInfo(t *testing.T) {
+	s, _ := newTestService(t)
+	req := &api.GetGadgetInfoRequest{
+		ImageName: "test",
+		Flags:     api.GadgetInfoRequestFlagUseInstance,
+		Version:   api.VersionGadgetInfo,
+	}
+
+	_, err := s.GetGadgetInfo(authz.ContextWithRule(context.Background(), otherImageRule), req)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	res, err := s.GetGadgetInfo(authz.ContextWithRule(context.Background(), digestRule), req)
+	require.NoError(t, err)
+	require.Equal(t, testInstanceID, res.GadgetInfo.Id)
+}
+
+// attachServer sends a request to attach to a gadget instance
+type attachServer struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *attachServer) Context() context.Context { return s.ctx }
+
+func (s *attachServer) Send(*api.GadgetEvent) error { return nil }
+
+func (s *attachServer) Recv() (*api.GadgetControlRequest, error) {
+	return &api.GadgetControlRequest{
+		Event: &api.GadgetControlRequest_AttachRequest{
+			AttachRequest: &api.GadgetAttachRequest{
+				Id:      testInstanceID,
+				Version: api.VersionGadgetRunProtocol,
+			},
+		},
+	}, nil
+}
+
+func TestAuthzAttachToInstance(t *testing.T) {
+	s, _ := newTestService(t)
+
+	err := s.RunGadget(&attachServer{ctx: authz.ContextWithRule(context.Background(), otherImageRule)})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestAuthzRemoveInstance(t *testing.T) {
+	s, store := newTestService(t)
+	id := &api.GadgetInstanceId{Id: testInstanceID}
+
+	_, err := s.RemoveGadgetInstance(authz.ContextWithRule(context.Background(), otherImageRule), id)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	require.False(t, store.removed, "instance removed although the rule denies its image")
+
+	_, err = s.RemoveGadgetInstance(authz.ContextWithRule(context.Background(), digestRule), id)
+	require.NoError(t, err)
+	require.True(t, store.removed)
+}
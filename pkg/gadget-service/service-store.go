@@ -16,13 +16,33 @@ package gadgetservice
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
 	"github.com/inspektor-gadget/inspektor-gadget/internal/namesgenerator"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/audit"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/authz"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
 )
 
-func (s *Service) CreateGadgetInstance(ctx context.Context, request *api.CreateGadgetInstanceRequest) (*api.CreateGadgetInstanceResponse, error) {
+func (s *Service) CreateGadgetInstance(ctx context.Context, request *api.CreateGadgetInstanceRequest) (_ *api.CreateGadgetInstanceResponse, err error) {
+	record := &audit.Record{
+		Operation: audit.OperationCreateGadgetInstance,
+		Caller:    audit.CallerFromContext(ctx),
+		Image:     request.GetGadgetInstance().GetGadgetConfig().GetImageName(),
+		Params:    request.GetGadgetInstance().GetGadgetConfig().GetParamValues(),
+	}
+	defer func() {
+		record.InstanceID = request.GetGadgetInstance().GetId()
+		record.InstanceName = request.GetGadgetInstance().GetName()
+		s.auditor.Record(record, err)
+	}()
+
 	// Create random ID if not set by the client
 	if request.GadgetInstance.Id == "" {
 		var err error
@@ -41,9 +61,44 @@ func (s *Service) CreateGadgetInstance(ctx context.Context, request *api.CreateG
 	} else if !api.IsValidInstanceName(request.GadgetInstance.Name) {
 		return nil, fmt.Errorf("invalid gadget instance name: %s", request.GadgetInstance.Name)
 	}
+	if rule := authz.RuleFromContext(ctx); rule != nil {
+		if err := s.authorizeGadgetInstance(ctx, rule, request.GadgetInstance.GadgetConfig); err != nil {
+			return nil, err
+		}
+	}
+	if s.instanceMgr != nil {
+		if err := s.instanceMgr.Admit(request.GadgetInstance); err != nil {
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
+		}
+	}
 	return s.store.CreateGadgetInstance(ctx, request)
 }
 
+// authorizeGadgetInstance checks whether rule allows running the gadget of a
+// new instance; the gadget is prepared like for GetGadgetInfo to learn its
+// digest and params, as the instance itself is started asynchronously
+func (s *Service) authorizeGadgetInstance(ctx context.Context, rule *authz.Rule, config *api.GadgetRunRequest) error {
+	if err := rule.AuthorizeImageReference(config.GetImageName()); err != nil {
+		return err
+	}
+
+	ops := make([]operators.DataOperator, 0)
+	for op := range s.operators {
+		ops = append(ops, op)
+	}
+
+	gadgetCtx := gadgetcontext.New(
+		ctx,
+		config.GetImageName(),
+		gadgetcontext.WithDataOperators(ops...),
+		gadgetcontext.WithAsRemoteCall(true),
+	)
+	if _, err := s.runtime.GetGadgetInfo(gadgetCtx, s.runtime.ParamDescs().ToParams(), config.GetParamValues()); err != nil {
+		return fmt.Errorf("getting gadget info: %w", err)
+	}
+	return authorizeGadget(rule, gadgetCtx, config.GetParamValues())
+}
+
 func (s *Service) ListGadgetInstances(ctx context.Context, request *api.ListGadgetInstancesRequest) (*api.ListGadgetInstanceResponse, error) {
 	resp, err := s.store.ListGadgetInstances(ctx, request)
 	if err != nil {
@@ -76,8 +131,44 @@ func (s *Service) GetGadgetInstance(ctx context.Context, id *api.GadgetInstanceI
 }
 
 func (s *Service) RemoveGadgetInstance(ctx context.Context, id *api.GadgetInstanceId) (*api.StatusResponse, error) {
+	record := &audit.Record{
+		Operation:  audit.OperationRemoveGadgetInstance,
+		Caller:     audit.CallerFromContext(ctx),
+		InstanceID: id.GetId(),
+	}
 	if !api.IsValidInstanceID(id.Id) {
-		return nil, fmt.Errorf("invalid gadget instance id: %s", id.Id)
+		err := fmt.Errorf("invalid gadget instance id: %s", id.Id)
+		s.auditor.Record(record, err)
+		return nil, err
+	}
+	if rule := authz.RuleFromContext(ctx); rule != nil {
+		if err := s.authorizeInstanceRemoval(ctx, rule, id, record); err != nil {
+			s.auditor.Record(record, err)
+			return nil, err
+		}
+	}
+	res, err := s.store.RemoveGadgetInstance(ctx, id)
+	if err == nil && res.Result != 0 {
+		s.auditor.Record(record, errors.New(res.Message))
+	} else {
+		s.auditor.Record(record, err)
+	}
+	return res, err
+}
+
+// authorizeInstanceRemoval checks whether rule allows running the gadget of the
+// instance to remove. The image is taken from the store if the instance
+// doesn't run locally; its digest is unknown then.
+func (s *Service) authorizeInstanceRemoval(ctx context.Context, rule *authz.Rule, id *api.GadgetInstanceId, record *audit.Record) error {
+	if s.instanceMgr != nil {
+		if gi := s.instanceMgr.Instance(id.Id); gi != nil {
+			return authorizeInstance(ctx, rule, gi, record)
+		}
+	}
+	instance, err := s.store.GetGadgetInstance(ctx, id)
+	if err != nil {
+		return fmt.Errorf("getting gadget instance from store: %w", err)
 	}
-	return s.store.RemoveGadgetInstance(ctx, id)
+	record.Image = instance.GetGadgetConfig().GetImageName()
+	return rule.AuthorizeImage(record.Image, "")
 }
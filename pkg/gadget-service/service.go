@@ -31,7 +31,10 @@ import (
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/config"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
 	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/audit"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/authz"
 	instancemanager "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/instance-manager"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/peercred"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/store"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/metrics"
@@ -66,6 +69,8 @@ type Service struct {
 	logger            logger.Logger
 	servers           map[*grpc.Server]struct{}
 	eventBufferLength uint64
+	auditor           *audit.Auditor
+	policy            *authz.Policy
 
 	// operators stores all global parameters for DataOperators (non-legacy)
 	operators map[operators.DataOperator]*params.Params
@@ -108,6 +113,8 @@ func (s *Service) SetEventBufferLength(val uint64) {
 	s.eventBufferLength = val
 }
 
+// SetInstanceManager sets the manager of gadget instances; it's closed when the
+// service is closed
 func (s *Service) SetInstanceManager(mgr *instancemanager.Manager) {
 	s.instanceMgr = mgr
 	mgr.Service = s
@@ -117,6 +124,18 @@ func (s *Service) SetStore(store store.Store) {
 	s.store = store
 }
 
+// SetAuditor sets the auditor that records requests to the service; it's
+// closed when the service is closed
+func (s *Service) SetAuditor(auditor *audit.Auditor) {
+	s.auditor = auditor
+}
+
+// SetPolicy sets the authorization policy for clients of the service; if it
+// isn't set, all clients are allowed to do everything
+func (s *Service) SetPolicy(policy *authz.Policy) {
+	s.policy = policy
+}
+
 func (s *Service) GetInfo(ctx context.Context, request *api.InfoRequest) (*api.InfoResponse, error) {
 	return &api.InfoResponse{
 		Version:       "1.0", // TODO
@@ -213,6 +232,19 @@ func (s *Service) Run(runConfig RunConfig, serverOptions ...grpc.ServerOption) e
 		return fmt.Errorf("invalid socket type: %s", runConfig.SocketType)
 	}
 
+	if runConfig.SocketType == "unix" {
+		// Make the credentials of clients available for auditing; this can be
+		// overridden by credentials in serverOptions
+		serverOptions = append([]grpc.ServerOption{grpc.Creds(peercred.NewTransportCredentials())}, serverOptions...)
+	}
+
+	if s.policy != nil {
+		serverOptions = append(serverOptions,
+			grpc.ChainUnaryInterceptor(s.policy.UnaryServerInterceptor()),
+			grpc.ChainStreamInterceptor(s.policy.StreamServerInterceptor()),
+		)
+	}
+
 	server := grpc.NewServer(serverOptions...)
 	api.RegisterBuiltInGadgetManagerServer(server, s)
 	api.RegisterGadgetManagerServer(server, s)
@@ -243,4 +275,10 @@ func (s *Service) Close() {
 		server.Stop()
 		delete(s.servers, server)
 	}
+	if s.instanceMgr != nil {
+		s.instanceMgr.Close()
+	}
+	if err := s.auditor.Close(); err != nil {
+		s.logger.Warnf("closing auditor: %v", err)
+	}
 }
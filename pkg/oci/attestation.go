@@ -0,0 +1,636 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/distribution/reference"
+	slsa "github.com/in-toto/attestation/go/predicates/provenance/v1"
+	spb "github.com/in-toto/attestation/go/v1"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
+
+	"github.com/inspektor-gadget/inspektor-gadget/internal/version"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/signature/exporter"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/signature/puller"
+)
+
+const (
+	// Attestations are in-toto statements stored in their own manifest, which
+	// refers to the gadget image index through the subject field.
+	attestationArtifactType = "application/vnd.in-toto+json"
+	attestationMediaType    = "application/vnd.in-toto+json"
+	predicateTypeAnnotation = "in-toto.io/predicate-type"
+
+	ProvenancePredicateType = "https://slsa.dev/provenance/v1"
+	SPDXPredicateType       = "https://spdx.dev/Document/v2.3"
+	CycloneDXPredicateType  = "https://cyclonedx.org/bom"
+
+	gadgetBuildType = "https://github.com/inspektor-gadget/inspektor-gadget/gadget-build/v1"
+	gadgetBuilderID = "https://github.com/inspektor-gadget/inspektor-gadget/cmd/ig"
+)
+
+// Kinds of attestations that can be required when verifying a gadget image.
+const (
+	AttestationProvenance = "provenance"
+	AttestationSBOM       = "sbom"
+)
+
+// Formats of the SBOM attached to gadget images.
+const (
+	SBOMFormatSPDX      = "spdx"
+	SBOMFormatCycloneDX = "cyclonedx"
+)
+
+// BuildMaterial is a file used to build a gadget image.
+type BuildMaterial struct {
+	// Path of the file, as seen by the build tools
+	Path string
+	// Hex-encoded SHA-256 digest of the file
+	Digest string
+}
+
+// GoModule is a Go module linked into the Wasm module of a gadget.
+type GoModule struct {
+	Path    string
+	Version string
+}
+
+// BuildInfo describes how the objects of a gadget image were produced. It's
+// recorded in the provenance and the SBOM attached to the image.
+type BuildInfo struct {
+	// Builder image used to build the gadget. Empty when local tools are used.
+	BuilderImage string
+	// Digest of the builder image, if known
+	BuilderImageDigest string
+	// Source of the Wasm module, either a program or an already built module
+	WasmSourcePath string
+	// Version of the tools used during the build, indexed by tool name
+	Tools map[string]string
+	// Source files of the gadget
+	Sources []BuildMaterial
+	// Headers included by the eBPF program
+	Headers []BuildMaterial
+	// Go modules linked into the Wasm module
+	GoModules []GoModule
+	// Format of the SBOM, SBOMFormatSPDX by default
+	SBOMFormat string
+
+	StartedOn  time.Time
+	FinishedOn time.Time
+}
+
+// attestationPredicateTypes returns the predicate types that satisfy the given
+// kind of attestation.
+func attestationPredicateTypes(kind string) ([]string, error) {
+	switch kind {
+	case AttestationProvenance:
+		return []string{ProvenancePredicateType}, nil
+	case AttestationSBOM:
+		return []string{SPDXPredicateType, CycloneDXPredicateType}, nil
+	default:
+		return nil, fmt.Errorf("unknown attestation kind %q", kind)
+	}
+}
+
+// ValidateAttestationKinds checks that all the given kinds of attestations are
+// known.
+func ValidateAttestationKinds(kinds []string) error {
+	for _, kind := range kinds {
+		if _, err := attestationPredicateTypes(kind); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func attestationSubject(name string, indexDesc ocispec.Descriptor) []*spb.ResourceDescriptor {
+	return []*spb.ResourceDescriptor{
+		{
+			Name: name,
+			Digest: map[string]string{
+				indexDesc.Digest.Algorithm().String(): indexDesc.Digest.Encoded(),
+			},
+		},
+	}
+}
+
+func toStruct(v any) (*structpb.Struct, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	s := &structpb.Struct{}
+	if err := protojson.Unmarshal(b, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func materialDescriptors(materials []BuildMaterial) []*spb.ResourceDescriptor {
+	rds := make([]*spb.ResourceDescriptor, 0, len(materials))
+	for _, m := range materials {
+		rds = append(rds, &spb.ResourceDescriptor{
+			Name:   m.Path,
+			Digest: map[string]string{"sha256": m.Digest},
+		})
+	}
+	return rds
+}
+
+func builderImageDescriptor(info *BuildInfo) *spb.ResourceDescriptor {
+	rd := &spb.ResourceDescriptor{
+		Name: info.BuilderImage,
+	}
+	if algo, encoded, ok := strings.Cut(info.BuilderImageDigest, ":"); ok {
+		rd.Digest = map[string]string{algo: encoded}
+	}
+	return rd
+}
+
+// provenanceStatement creates a SLSA v1 provenance for the image described by
+// subject.
+func provenanceStatement(subject []*spb.ResourceDescriptor, opts *BuildGadgetImageOpts) (*spb.Statement, error) {
+	info := opts.BuildInfo
+
+	btfgen := false
+	for _, paths := range opts.ObjectPaths {
+		btfgen = btfgen || paths.Btfgen != ""
+	}
+
+	externalParameters, err := toStruct(map[string]any{
+		"ebpfSource":   opts.EBPFSourcePath,
+		"wasm":         info.WasmSourcePath,
+		"metadata":     opts.MetadataPath,
+		"btfgen":       btfgen,
+		"builderImage": info.BuilderImage,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating external parameters: %w", err)
+	}
+
+	internalParameters, err := toStruct(map[string]any{
+		"local":          info.BuilderImage == "",
+		"updateMetadata": opts.UpdateMetadata,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating internal parameters: %w", err)
+	}
+
+	dependencies := materialDescriptors(info.Sources)
+	dependencies = append(dependencies, materialDescriptors(info.Headers)...)
+	for _, mod := range info.GoModules {
+		dependencies = append(dependencies, &spb.ResourceDescriptor{
+			Name: mod.Path,
+			Uri:  goModulePurl(mod),
+		})
+	}
+
+	builderVersion := map[string]string{"ig": version.VersionString()}
+	for tool, v := range info.Tools {
+		builderVersion[tool] = v
+	}
+
+	builder := &slsa.Builder{
+		Id:      gadgetBuilderID,
+		Version: builderVersion,
+	}
+	if info.BuilderImage != "" {
+		builder.BuilderDependencies = []*spb.ResourceDescriptor{builderImageDescriptor(info)}
+	}
+
+	metadata := &slsa.BuildMetadata{}
+	if !info.StartedOn.IsZero() {
+		metadata.StartedOn = timestamppb.New(info.StartedOn)
+	}
+	if !info.FinishedOn.IsZero() {
+		metadata.FinishedOn = timestamppb.New(info.FinishedOn)
+	}
+
+	provenance := &slsa.Provenance{
+		BuildDefinition: &slsa.BuildDefinition{
+			BuildType:            gadgetBuildType,
+			ExternalParameters:   externalParameters,
+			InternalParameters:   internalParameters,
+			ResolvedDependencies: dependencies,
+		},
+		RunDetails: &slsa.RunDetails{
+			Builder:  builder,
+			Metadata: metadata,
+		},
+	}
+	if err := provenance.Validate(); err != nil {
+		return nil, fmt.Errorf("validating provenance: %w", err)
+	}
+
+	provenanceJSON, err := protojson.Marshal(provenance)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling provenance: %w", err)
+	}
+	predicate := &structpb.Struct{}
+	if err := protojson.Unmarshal(provenanceJSON, predicate); err != nil {
+		return nil, fmt.Errorf("unmarshalling provenance: %w", err)
+	}
+
+	return &spb.Statement{
+		Type:          spb.StatementTypeUri,
+		Subject:       subject,
+		PredicateType: ProvenancePredicateType,
+		Predicate:     predicate,
+	}, nil
+}
+
+// sbomStatement creates a statement holding the SBOM of the image described
+// by subject.
+func sbomStatement(subject []*spb.ResourceDescriptor, opts *BuildGadgetImageOpts) (*spb.Statement, error) {
+	var document any
+	var predicateType string
+
+	switch opts.BuildInfo.SBOMFormat {
+	case SBOMFormatSPDX, "":
+		document = newSPDXDocument(subject[0], opts)
+		predicateType = SPDXPredicateType
+	case SBOMFormatCycloneDX:
+		document = newCycloneDXBOM(subject[0], opts)
+		predicateType = CycloneDXPredicateType
+	default:
+		return nil, fmt.Errorf("unsupported SBOM format %q", opts.BuildInfo.SBOMFormat)
+	}
+
+	predicate, err := toStruct(document)
+	if err != nil {
+		return nil, fmt.Errorf("creating SBOM: %w", err)
+	}
+
+	return &spb.Statement{
+		Type:          spb.StatementTypeUri,
+		Subject:       subject,
+		PredicateType: predicateType,
+		Predicate:     predicate,
+	}, nil
+}
+
+type attestationStore interface {
+	oras.GraphTarget
+	content.Deleter
+}
+
+// attachAttestations creates the provenance and the SBOM of the image index
+// and pushes them as referrers of it. Attestations left by previous builds of
+// the same image are removed.
+func attachAttestations(ctx context.Context, store attestationStore, indexDesc ocispec.Descriptor, name string, opts *BuildGadgetImageOpts) error {
+	if name == "" {
+		name = "gadget"
+	}
+	subject := attestationSubject(name, indexDesc)
+
+	provenance, err := provenanceStatement(subject, opts)
+	if err != nil {
+		return fmt.Errorf("creating provenance: %w", err)
+	}
+	sbom, err := sbomStatement(subject, opts)
+	if err != nil {
+		return fmt.Errorf("creating SBOM: %w", err)
+	}
+
+	old, err := registry.Referrers(ctx, store, indexDesc, attestationArtifactType)
+	if err != nil {
+		return fmt.Errorf("listing attestations: %w", err)
+	}
+	for _, desc := range old {
+		if err := store.Delete(ctx, desc); err != nil {
+			return fmt.Errorf("removing attestation %s: %w", desc.Digest, err)
+		}
+	}
+
+	for _, statement := range []*spb.Statement{provenance, sbom} {
+		if err := pushStatement(ctx, store, indexDesc, statement, opts.CreatedDate); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func pushStatement(ctx context.Context, target oras.Target, subject ocispec.Descriptor, statement *spb.Statement, createdDate string) error {
+	if err := statement.Validate(); err != nil {
+		return fmt.Errorf("validating %q statement: %w", statement.PredicateType, err)
+	}
+
+	statementJSON, err := protojson.Marshal(statement)
+	if err != nil {
+		return fmt.Errorf("marshalling %q statement: %w", statement.PredicateType, err)
+	}
+
+	layerDesc := content.NewDescriptorFromBytes(attestationMediaType, statementJSON)
+	layerDesc.Annotations = map[string]string{
+		predicateTypeAnnotation: statement.PredicateType,
+	}
+	err = pushDescriptorIfNotExists(ctx, target, layerDesc, bytes.NewReader(statementJSON))
+	if err != nil {
+		return fmt.Errorf("pushing %q statement: %w", statement.PredicateType, err)
+	}
+
+	annotations := map[string]string{
+		predicateTypeAnnotation: statement.PredicateType,
+	}
+	if createdDate != "" {
+		annotations[ocispec.AnnotationCreated] = createdDate
+	}
+
+	_, err = oras.PackManifest(ctx, target, oras.PackManifestVersion1_1, attestationArtifactType, oras.PackManifestOptions{
+		Subject:             &subject,
+		Layers:              []ocispec.Descriptor{layerDesc},
+		ManifestAnnotations: annotations,
+	})
+	if err != nil {
+		return fmt.Errorf("packing %q attestation: %w", statement.PredicateType, err)
+	}
+
+	return nil
+}
+
+// copyAttestations copies the attestations referring to desc from src to dst.
+// Attestations are signed like images, so their signing information is copied
+// too when available.
+func copyAttestations(ctx context.Context, src oras.ReadOnlyGraphTarget, dst oras.Target, desc ocispec.Descriptor) error {
+	referrers, err := registry.Referrers(ctx, src, desc, attestationArtifactType)
+	if err != nil {
+		return fmt.Errorf("listing attestations: %w", err)
+	}
+	for _, referrer := range referrers {
+		if err := oras.CopyGraph(ctx, src, dst, referrer, oras.DefaultCopyGraphOptions); err != nil {
+			return fmt.Errorf("copying attestation %s: %w", referrer.Digest, err)
+		}
+		if err := copySigningInformation(ctx, src, dst, referrer); err != nil {
+			log.Debugf("no signing information copied for attestation %s: %v", referrer.Digest, err)
+		}
+	}
+	return nil
+}
+
+// copySigningInformation copies the signing information of desc from src to
+// dst.
+func copySigningInformation(ctx context.Context, src oras.ReadOnlyGraphTarget, dst oras.Target, desc ocispec.Descriptor) error {
+	if repo, ok := src.(*remote.Repository); ok {
+		return puller.DefaultSignaturePuller.PullSigningInformation(ctx, repo, dst, desc.Digest.String())
+	}
+	return exporter.DefaultSignatureExporter.ExportSigningInformation(ctx, src, dst, desc)
+}
+
+// getAttestations returns the in-toto statements attached to desc, sorted by
+// predicate type. Statements whose subject doesn't match desc are rejected. If
+// verify is set, the attestations it fails for are ignored.
+func getAttestations(ctx context.Context, target oras.ReadOnlyTarget, desc ocispec.Descriptor, verify func(ocispec.Descriptor) error) ([]*spb.Statement, error) {
+	graph, ok := target.(content.ReadOnlyGraphStorage)
+	if !ok {
+		return nil, errors.New("target doesn't support listing referrers")
+	}
+
+	referrers, err := registry.Referrers(ctx, graph, desc, attestationArtifactType)
+	if err != nil {
+		return nil, fmt.Errorf("listing attestations: %w", err)
+	}
+
+	statements := make([]*spb.Statement, 0, len(referrers))
+	for _, referrer := range referrers {
+		if verify != nil {
+			if err := verify(referrer); err != nil {
+				log.Debugf("ignoring attestation %s: %v", referrer.Digest, err)
+				continue
+			}
+		}
+
+		manifestBytes, err := getContentBytesFromDescriptor(ctx, target, referrer)
+		if err != nil {
+			return nil, fmt.Errorf("getting attestation manifest: %w", err)
+		}
+		manifest := &ocispec.Manifest{}
+		if err := json.Unmarshal(manifestBytes, manifest); err != nil {
+			return nil, fmt.Errorf("decoding attestation manifest: %w", err)
+		}
+
+		for _, layer := range manifest.Layers {
+			if layer.MediaType != attestationMediaType {
+				continue
+			}
+			statementBytes, err := getContentBytesFromDescriptor(ctx, target, layer)
+			if err != nil {
+				return nil, fmt.Errorf("getting attestation: %w", err)
+			}
+			statement := &spb.Statement{}
+			if err := protojson.Unmarshal(statementBytes, statement); err != nil {
+				return nil, fmt.Errorf("decoding attestation %s: %w", layer.Digest, err)
+			}
+			if err := statement.Validate(); err != nil {
+				return nil, fmt.Errorf("validating attestation %s: %w", layer.Digest, err)
+			}
+			if !slices.ContainsFunc(statement.Subject, func(rd *spb.ResourceDescriptor) bool {
+				return rd.Digest[desc.Digest.Algorithm().String()] == desc.Digest.Encoded()
+			}) {
+				return nil, fmt.Errorf("attestation %s doesn't refer to %s", layer.Digest, desc.Digest)
+			}
+			statements = append(statements, statement)
+		}
+	}
+
+	slices.SortFunc(statements, func(a, b *spb.Statement) int {
+		return strings.Compare(a.PredicateType, b.PredicateType)
+	})
+
+	return statements, nil
+}
+
+// GetGadgetAttestations returns the in-toto statements attached to the given
+// image. If target is nil, the local store is used.
+func GetGadgetAttestations(ctx context.Context, target oras.ReadOnlyTarget, image string) ([]*spb.Statement, error) {
+	if target == nil {
+		var err error
+		target, err = newLocalOciStore()
+		if err != nil {
+			return nil, fmt.Errorf("getting local oci store: %w", err)
+		}
+	}
+	imageRef, err := NormalizeImageName(image)
+	if err != nil {
+		return nil, fmt.Errorf("normalizing image: %w", err)
+	}
+	desc, err := target.Resolve(ctx, imageRef.String())
+	if err != nil {
+		return nil, fmt.Errorf("resolving image %q: %w", imageRef.String(), err)
+	}
+	return getAttestations(ctx, target, desc, nil)
+}
+
+// missingAttestations returns the kinds of attestations in required not
+// satisfied by any of the statements.
+func missingAttestations(statements []*spb.Statement, required []string) ([]string, error) {
+	var missing []string
+	for _, kind := range required {
+		predicateTypes, err := attestationPredicateTypes(kind)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.ContainsFunc(statements, func(s *spb.Statement) bool {
+			return slices.Contains(predicateTypes, s.PredicateType)
+		}) {
+			missing = append(missing, kind)
+		}
+	}
+	return missing, nil
+}
+
+// digestTarget resolves references by digest. Local stores only resolve tags
+// and plain digests, while verifiers look up attestations as name@digest.
+type digestTarget struct {
+	oras.GraphTarget
+}
+
+func (t digestTarget) Resolve(ctx context.Context, ref string) (ocispec.Descriptor, error) {
+	if _, digest, ok := strings.Cut(ref, "@"); ok {
+		ref = digest
+	}
+	return t.GraphTarget.Resolve(ctx, ref)
+}
+
+// checkAttestations returns the kinds of attestations required by imgOpts
+// that desc doesn't have. If signature verification is enabled, only the
+// attestations signed according to the verifier are taken into account.
+func checkAttestations(ctx context.Context, target oras.ReadOnlyTarget, imageRef reference.Named, desc ocispec.Descriptor, imgOpts *ImageOptions) ([]string, error) {
+	var verify func(ocispec.Descriptor) error
+	if imgOpts.VerifySignature {
+		if imgOpts.Verifier == nil {
+			return nil, errors.New("signature verification requested but no verifier provided")
+		}
+		graphTarget, ok := target.(oras.GraphTarget)
+		if !ok {
+			return nil, errors.New("target doesn't support verifying attestation signatures")
+		}
+		verify = func(referrer ocispec.Descriptor) error {
+			ref, err := reference.WithDigest(reference.TrimNamed(imageRef), referrer.Digest)
+			if err != nil {
+				return fmt.Errorf("creating attestation reference: %w", err)
+			}
+			return imgOpts.Verifier.Verify(ctx, digestTarget{graphTarget}, ref)
+		}
+	}
+
+	statements, err := getAttestations(ctx, target, desc, verify)
+	if err != nil {
+		return nil, err
+	}
+	return missingAttestations(statements, imgOpts.RequiredAttestations)
+}
+
+func missingAttestationsError(image string, missing []string, imgOpts *ImageOptions) error {
+	kind := strings.Join(missing, ", ")
+	if imgOpts.VerifySignature {
+		kind = "signed " + kind
+	}
+	return fmt.Errorf("gadget %q has no %s attestation", image, kind)
+}
+
+// VerifyGadgetAttestations checks that the image has the attestations
+// required by imgOpts. If signature verification is enabled, the attestations
+// must be signed like the image itself. If target is nil, the local store is
+// used and the attestations not found are pulled from the registry and checked
+// again.
+func VerifyGadgetAttestations(ctx context.Context, target oras.ReadOnlyTarget, image string, imgOpts *ImageOptions) error {
+	if len(imgOpts.RequiredAttestations) == 0 {
+		return nil
+	}
+
+	imageRef, err := NormalizeImageName(image)
+	if err != nil {
+		return fmt.Errorf("normalizing image name: %w", err)
+	}
+
+	if target != nil {
+		desc, err := target.Resolve(ctx, imageRef.String())
+		if err != nil {
+			return fmt.Errorf("resolving %q: %w", image, err)
+		}
+		missing, err := checkAttestations(ctx, target, imageRef, desc, imgOpts)
+		if err != nil {
+			return fmt.Errorf("checking attestations of %q: %w", image, err)
+		}
+		if len(missing) > 0 {
+			return missingAttestationsError(image, missing, imgOpts)
+		}
+		return nil
+	}
+
+	return retry("VerifyGadgetAttestations", func() error {
+		imageStore, err := newLocalOciStore()
+		if err != nil {
+			return fmt.Errorf("getting oci store: %w", err)
+		}
+
+		desc, err := imageStore.Resolve(ctx, imageRef.String())
+		if err != nil {
+			return fmt.Errorf("resolving %q in local store: %w", image, err)
+		}
+
+		missing, err := checkAttestations(ctx, imageStore, imageRef, desc, imgOpts)
+		if err != nil {
+			return fmt.Errorf("checking attestations of %q: %w", image, err)
+		}
+		if len(missing) == 0 {
+			return nil
+		}
+
+		if imgOpts.DisallowPulling {
+			return missingAttestationsError(image, missing, imgOpts)
+		}
+
+		log.Warn("attestations not found, will pull them and try verification again")
+
+		repo, err := newRepository(imageRef, &imgOpts.AuthOptions)
+		if err != nil {
+			return fmt.Errorf("creating remote repository: %w", err)
+		}
+		if err := copyAttestations(ctx, repo, imageStore, desc); err != nil {
+			return fmt.Errorf("pulling attestations of %q: %w", image, err)
+		}
+		if err := imageStore.saveIndexWithLock(); err != nil {
+			return err
+		}
+
+		missing, err = checkAttestations(ctx, imageStore, imageRef, desc, imgOpts)
+		if err != nil {
+			return fmt.Errorf("checking attestations of %q: %w", image, err)
+		}
+		if len(missing) > 0 {
+			return missingAttestationsError(image, missing, imgOpts)
+		}
+
+		return nil
+	})
+}
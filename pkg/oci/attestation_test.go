@@ -0,0 +1,292 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	spb "github.com/in-toto/attestation/go/v1"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/signature/helpers"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/signature/verifier"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/signature/verifier/cosign"
+)
+
+const testCreatedDate = "2026-01-02T03:04:05Z"
+
+func testBuildOpts(t *testing.T, sbomFormat string) *BuildGadgetImageOpts {
+	t.Helper()
+
+	objPath := filepath.Join(t.TempDir(), "amd64.bpf.o")
+	require.NoError(t, os.WriteFile(objPath, []byte("not really an eBPF object"), 0o600))
+
+	return &BuildGadgetImageOpts{
+		EBPFSourcePath: "program.bpf.c",
+		ObjectPaths: map[string]*ObjectPath{
+			ArchAmd64: {EBPF: objPath},
+		},
+		MetadataPath: filepath.Join(t.TempDir(), "gadget.yaml"),
+		CreatedDate:  testCreatedDate,
+		BuildInfo: &BuildInfo{
+			BuilderImage:       "ghcr.io/inspektor-gadget/gadget-builder:main",
+			BuilderImageDigest: "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+			WasmSourcePath:     "go/program.go",
+			Tools:              map[string]string{"clang": "18.1.8", "go": "1.25.7"},
+			Sources: []BuildMaterial{
+				{Path: "/work/program.bpf.c", Digest: "2222222222222222222222222222222222222222222222222222222222222222"},
+			},
+			Headers: []BuildMaterial{
+				{Path: "/usr/include/gadget/amd64/vmlinux.h", Digest: "3333333333333333333333333333333333333333333333333333333333333333"},
+			},
+			GoModules: []GoModule{
+				{Path: "github.com/inspektor-gadget/inspektor-gadget", Version: "v0.50.0"},
+			},
+			SBOMFormat: sbomFormat,
+		},
+	}
+}
+
+func testImageIndex(t *testing.T, store *oci.Store, opts *BuildGadgetImageOpts) ocispec.Descriptor {
+	t.Helper()
+
+	indexDesc, err := createImageIndex(context.Background(), store, opts)
+	require.NoError(t, err)
+	return indexDesc
+}
+
+func predicateTypes(statements []*spb.Statement) []string {
+	var ret []string
+	for _, s := range statements {
+		ret = append(ret, s.PredicateType)
+	}
+	return ret
+}
+
+func TestAttachAttestations(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		sbomFormat        string
+		sbomPredicateType string
+		sbomField         string
+	}{
+		{SBOMFormatSPDX, SPDXPredicateType, "spdxVersion"},
+		{SBOMFormatCycloneDX, CycloneDXPredicateType, "bomFormat"},
+	} {
+		t.Run(tc.sbomFormat, func(t *testing.T) {
+			store, err := oci.New(t.TempDir())
+			require.NoError(t, err)
+
+			opts := testBuildOpts(t, tc.sbomFormat)
+			indexDesc := testImageIndex(t, store, opts)
+
+			require.NoError(t, attachAttestations(ctx, store, indexDesc, "ghcr.io/foo/bar", opts))
+			// Attestations of previous builds are replaced
+			require.NoError(t, attachAttestations(ctx, store, indexDesc, "ghcr.io/foo/bar", opts))
+
+			statements, err := getAttestations(ctx, store, indexDesc, nil)
+			require.NoError(t, err)
+			require.ElementsMatch(t, []string{ProvenancePredicateType, tc.sbomPredicateType}, predicateTypes(statements))
+
+			missing, err := missingAttestations(statements, []string{AttestationProvenance, AttestationSBOM})
+			require.NoError(t, err)
+			assert.Empty(t, missing)
+
+			for _, s := range statements {
+				require.Len(t, s.Subject, 1)
+				assert.Equal(t, "ghcr.io/foo/bar", s.Subject[0].Name)
+				assert.Equal(t, indexDesc.Digest.Encoded(), s.Subject[0].Digest["sha256"])
+
+				predicate := s.Predicate.AsMap()
+				switch s.PredicateType {
+				case ProvenancePredicateType:
+					runDetails := predicate["runDetails"].(map[string]any)
+					builder := runDetails["builder"].(map[string]any)
+					assert.Equal(t, gadgetBuilderID, builder["id"])
+					assert.Equal(t, "18.1.8", builder["version"].(map[string]any)["clang"])
+
+					buildDefinition := predicate["buildDefinition"].(map[string]any)
+					assert.Equal(t, gadgetBuildType, buildDefinition["buildType"])
+					assert.Len(t, buildDefinition["resolvedDependencies"], 3)
+					assert.Contains(t, buildDefinition["resolvedDependencies"], map[string]any{
+						"name": "github.com/inspektor-gadget/inspektor-gadget",
+						"uri":  "pkg:golang/github.com/inspektor-gadget/inspektor-gadget@v0.50.0",
+					})
+				case tc.sbomPredicateType:
+					assert.Contains(t, predicate, tc.sbomField)
+				}
+			}
+		})
+	}
+}
+
+func TestMissingAttestations(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := oci.New(t.TempDir())
+	require.NoError(t, err)
+
+	indexDesc := testImageIndex(t, store, testBuildOpts(t, SBOMFormatSPDX))
+
+	statements, err := getAttestations(ctx, store, indexDesc, nil)
+	require.NoError(t, err)
+	assert.Empty(t, statements)
+
+	missing, err := missingAttestations(statements, []string{AttestationProvenance, AttestationSBOM})
+	require.NoError(t, err)
+	assert.Equal(t, []string{AttestationProvenance, AttestationSBOM}, missing)
+
+	_, err = missingAttestations(statements, []string{"foo"})
+	require.ErrorContains(t, err, "unknown attestation kind")
+	require.Error(t, ValidateAttestationKinds([]string{AttestationSBOM, "foo"}))
+}
+
+func TestAttestationForAnotherImage(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := oci.New(t.TempDir())
+	require.NoError(t, err)
+
+	opts := testBuildOpts(t, SBOMFormatSPDX)
+	indexDesc := testImageIndex(t, store, opts)
+
+	opts.ObjectPaths[ArchArm64] = opts.ObjectPaths[ArchAmd64]
+	otherDesc := testImageIndex(t, store, opts)
+
+	// A statement about another image is attached to this one
+	statement, err := provenanceStatement(attestationSubject("other", otherDesc), opts)
+	require.NoError(t, err)
+	require.NoError(t, pushStatement(ctx, store, indexDesc, statement, testCreatedDate))
+
+	_, err = getAttestations(ctx, store, indexDesc, nil)
+	require.ErrorContains(t, err, "doesn't refer to")
+}
+
+func TestCopyAttestations(t *testing.T) {
+	ctx := context.Background()
+
+	src, err := oci.New(t.TempDir())
+	require.NoError(t, err)
+	dst, err := oci.New(t.TempDir())
+	require.NoError(t, err)
+
+	opts := testBuildOpts(t, SBOMFormatSPDX)
+	indexDesc := testImageIndex(t, src, opts)
+	require.NoError(t, attachAttestations(ctx, src, indexDesc, "ghcr.io/foo/bar", opts))
+
+	testImageIndex(t, dst, opts)
+	require.NoError(t, copyAttestations(ctx, src, dst, indexDesc))
+
+	statements, err := getAttestations(ctx, dst, indexDesc, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{ProvenancePredicateType, SPDXPredicateType}, predicateTypes(statements))
+}
+
+// signLegacy signs desc using the legacy cosign format
+func signLegacy(t *testing.T, store *oci.Store, desc ocispec.Descriptor, signer signature.Signer) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	payload := fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"ghcr.io/foo/bar"},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, desc.Digest)
+	sig, err := signer.SignMessage(strings.NewReader(payload))
+	require.NoError(t, err)
+
+	layerDesc := content.NewDescriptorFromBytes("application/vnd.dev.cosign.simplesigning.v1+json", []byte(payload))
+	layerDesc.Annotations = map[string]string{
+		"dev.cosignproject.cosign/signature": base64.StdEncoding.EncodeToString(sig),
+	}
+	require.NoError(t, store.Push(ctx, layerDesc, strings.NewReader(payload)))
+
+	manifestDesc, err := oras.PackManifest(ctx, store, oras.PackManifestVersion1_0, "", oras.PackManifestOptions{
+		Layers: []ocispec.Descriptor{layerDesc},
+	})
+	require.NoError(t, err)
+
+	tag, err := helpers.CraftCosignSignatureTag(desc.Digest.String())
+	require.NoError(t, err)
+	require.NoError(t, store.Tag(ctx, manifestDesc, tag))
+}
+
+func TestVerifyGadgetAttestationsSignature(t *testing.T) {
+	ctx := context.Background()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	signer, err := signature.LoadECDSASignerVerifier(key, crypto.SHA256)
+	require.NoError(t, err)
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	v, err := verifier.NewSignatureVerifier(verifier.VerifierOptions{
+		CosignVerifierOpts: cosign.VerifierOptions{
+			PublicKeys: []string{string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub}))},
+		},
+	})
+	require.NoError(t, err)
+
+	store, err := oci.New(t.TempDir())
+	require.NoError(t, err)
+
+	opts := testBuildOpts(t, SBOMFormatSPDX)
+	indexDesc := testImageIndex(t, store, opts)
+	require.NoError(t, store.Tag(ctx, indexDesc, "ghcr.io/foo/bar:latest"))
+	require.NoError(t, attachAttestations(ctx, store, indexDesc, "ghcr.io/foo/bar", opts))
+
+	imgOpts := &ImageOptions{
+		VerifyOptions: VerifyOptions{
+			RequiredAttestations: []string{AttestationProvenance, AttestationSBOM},
+		},
+	}
+	require.NoError(t, VerifyGadgetAttestations(ctx, store, "ghcr.io/foo/bar", imgOpts))
+
+	// Unsigned attestations don't count when verifying signatures
+	imgOpts.VerifySignature = true
+	imgOpts.Verifier = v
+	err = VerifyGadgetAttestations(ctx, store, "ghcr.io/foo/bar", imgOpts)
+	require.ErrorContains(t, err, "has no signed provenance, sbom attestation")
+
+	referrers, err := registry.Referrers(ctx, store, indexDesc, attestationArtifactType)
+	require.NoError(t, err)
+	for _, referrer := range referrers {
+		if referrer.Annotations[predicateTypeAnnotation] == ProvenancePredicateType {
+			signLegacy(t, store, referrer, signer)
+		}
+	}
+	err = VerifyGadgetAttestations(ctx, store, "ghcr.io/foo/bar", imgOpts)
+	require.ErrorContains(t, err, "has no signed sbom attestation")
+
+	imgOpts.RequiredAttestations = []string{AttestationProvenance}
+	require.NoError(t, VerifyGadgetAttestations(ctx, store, "ghcr.io/foo/bar", imgOpts))
+}
@@ -31,6 +31,7 @@ import (
 	"gopkg.in/yaml.v2"
 	"oras.land/oras-go/v2"
 	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
 	"oras.land/oras-go/v2/errdef"
 
 	"github.com/inspektor-gadget/inspektor-gadget/internal/version"
@@ -78,6 +79,9 @@ type BuildGadgetImageOpts struct {
 	ValidateMetadata bool
 	// Date and time on which the image is built (date-time string as defined by RFC 3339).
 	CreatedDate string
+	// Information about how the objects were built. If set, provenance and SBOM attestations
+	// are attached to the image.
+	BuildInfo *BuildInfo
 }
 
 // BuildGadgetImage creates an OCI image with the objects provided in opts. The image parameter in
@@ -94,6 +98,16 @@ func BuildGadgetImage(ctx context.Context, opts *BuildGadgetImageOpts, image str
 	return desc, err
 }
 
+// ComputeGadgetImageDigest returns the digest of the image BuildGadgetImage would
+// create from opts, without storing it. The metadata file is used as is.
+func ComputeGadgetImageDigest(ctx context.Context, opts *BuildGadgetImageOpts) (string, error) {
+	indexDesc, err := createImageIndex(ctx, memory.New(), opts)
+	if err != nil {
+		return "", fmt.Errorf("creating image index: %w", err)
+	}
+	return indexDesc.Digest.String(), nil
+}
+
 func buildGadgetImage(ctx context.Context, opts *BuildGadgetImageOpts, image string) (*GadgetImageDesc, error) {
 	ociStore, err := newLocalOciStore()
 	if err != nil {
@@ -122,7 +136,7 @@ func buildGadgetImage(ctx context.Context, opts *BuildGadgetImageOpts, image str
 	}
 
 	if image != "" {
-		targetImage, err := normalizeImageName(image)
+		targetImage, err := NormalizeImageName(image)
 		if err != nil {
 			return nil, fmt.Errorf("normalizing image: %w", err)
 		}
@@ -138,6 +152,12 @@ func buildGadgetImage(ctx context.Context, opts *BuildGadgetImageOpts, image str
 		}
 	}
 
+	if opts.BuildInfo != nil {
+		if err := attachAttestations(ctx, ociStore, indexDesc, imageDesc.Repository, opts); err != nil {
+			return nil, fmt.Errorf("attaching attestations: %w", err)
+		}
+	}
+
 	if err := ociStore.saveIndexWithLock(); err != nil {
 		return nil, err
 	}
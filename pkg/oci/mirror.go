@@ -0,0 +1,471 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/distribution/reference"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	log "github.com/sirupsen/logrus"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+const (
+	// MirrorOCILayoutPrefix is the prefix of locations that are OCI layout
+	// directories, e.g. oci:/mnt/usb/gadgets
+	MirrorOCILayoutPrefix = "oci:"
+	// MirrorOCIArchivePrefix is the prefix of locations that are tarballs of
+	// OCI layouts, like the ones created by ExportGadgetImages, e.g.
+	// oci-archive:/mnt/usb/gadgets.tar
+	MirrorOCIArchivePrefix = "oci-archive:"
+)
+
+type MirrorOptions struct {
+	AuthOptions
+
+	// Source is where images are copied from. When empty, images are copied
+	// from their own registry.
+	Source string
+	// Destination is where images are copied to
+	Destination string
+}
+
+// MirrorResult is the result of mirroring an image
+type MirrorResult struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Digest      string `json:"digest"`
+	// Copied is false when the destination already had the image
+	Copied bool `json:"copied"`
+	// Signed is true when signing information was mirrored with the image
+	Signed bool `json:"signed"`
+}
+
+// mirrorLocation is a registry, an OCI layout directory or an OCI archive
+// images are mirrored from or to. Images are identified by their normalized
+// name everywhere; each location maps them to its own references.
+type mirrorLocation interface {
+	// target returns the target containing the image and the reference of
+	// the image in it
+	target(image reference.Named) (oras.ReadOnlyGraphTarget, string, error)
+	// list returns the images matching the pattern
+	list(ctx context.Context, pattern string) ([]reference.Named, error)
+	// close writes pending changes
+	close() error
+	String() string
+}
+
+func newMirrorLocation(ctx context.Context, location string, authOpts *AuthOptions, write bool) (mirrorLocation, error) {
+	switch {
+	case strings.HasPrefix(location, MirrorOCILayoutPrefix):
+		dir := strings.TrimPrefix(location, MirrorOCILayoutPrefix)
+		if !write {
+			if _, err := os.Stat(filepath.Join(dir, ocispec.ImageLayoutFile)); err != nil {
+				return nil, fmt.Errorf("opening oci layout %q: %w", dir, err)
+			}
+		}
+		store, err := oci.NewWithContext(ctx, dir)
+		if err != nil {
+			return nil, fmt.Errorf("opening oci layout %q: %w", dir, err)
+		}
+		return &layoutLocation{name: location, store: store}, nil
+	case strings.HasPrefix(location, MirrorOCIArchivePrefix):
+		file := strings.TrimPrefix(location, MirrorOCIArchivePrefix)
+		if write {
+			return newArchiveLocation(ctx, location, file)
+		}
+		store, err := oci.NewFromTar(ctx, file)
+		if err != nil {
+			return nil, fmt.Errorf("opening oci archive %q: %w", file, err)
+		}
+		return &layoutLocation{name: location, store: store}, nil
+	default:
+		return &registryLocation{prefix: strings.TrimSuffix(location, "/"), authOpts: authOpts}, nil
+	}
+}
+
+// MirrorGadgetImages copies the images, with all their architectures and
+// signing information, from the source to the destination. Images can contain
+// glob patterns: in the tag for registries and anywhere for OCI layouts and
+// archives. Images already present in the destination with the same digest
+// aren't copied again.
+func MirrorGadgetImages(ctx context.Context, images []string, opts *MirrorOptions) ([]*MirrorResult, error) {
+	src, err := newMirrorLocation(ctx, opts.Source, &opts.AuthOptions, false)
+	if err != nil {
+		return nil, fmt.Errorf("opening source: %w", err)
+	}
+	defer src.close()
+
+	if opts.Destination == "" {
+		return nil, errors.New("destination is required")
+	}
+	dst, err := newMirrorLocation(ctx, opts.Destination, &opts.AuthOptions, true)
+	if err != nil {
+		return nil, fmt.Errorf("opening destination: %w", err)
+	}
+
+	var results []*MirrorResult
+	for _, pattern := range images {
+		matches, err := src.list(ctx, pattern)
+		if err != nil {
+			dst.close()
+			return results, fmt.Errorf("listing images matching %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			dst.close()
+			return results, fmt.Errorf("no images matching %q in %s", pattern, src)
+		}
+
+		for _, image := range matches {
+			result, err := mirrorImage(ctx, src, dst, image)
+			if err != nil {
+				dst.close()
+				return results, fmt.Errorf("mirroring %q: %w", image, err)
+			}
+			results = append(results, result)
+		}
+	}
+
+	if err := dst.close(); err != nil {
+		return results, fmt.Errorf("writing destination: %w", err)
+	}
+	return results, nil
+}
+
+func mirrorImage(ctx context.Context, src, dst mirrorLocation, image reference.Named) (*MirrorResult, error) {
+	srcTarget, srcRef, err := src.target(image)
+	if err != nil {
+		return nil, err
+	}
+	dstReadTarget, dstRef, err := dst.target(image)
+	if err != nil {
+		return nil, err
+	}
+	dstTarget, ok := dstReadTarget.(oras.Target)
+	if !ok {
+		return nil, fmt.Errorf("%s is read-only", dst)
+	}
+
+	desc, err := srcTarget.Resolve(ctx, srcRef)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %q: %w", srcRef, err)
+	}
+
+	result := &MirrorResult{
+		Source:      srcRef,
+		Destination: dstRef,
+		Digest:      desc.Digest.String(),
+	}
+
+	existing, err := dstTarget.Resolve(ctx, dstRef)
+	switch {
+	case err == nil && existing.Digest == desc.Digest:
+		log.Debugf("%s is up to date", dstRef)
+	case err == nil || errors.Is(err, errdef.ErrNotFound):
+		// Blobs already present in the destination aren't copied again
+		if _, err := oras.Copy(ctx, srcTarget, srcRef, dstTarget, dstRef, oras.DefaultCopyOptions); err != nil {
+			return nil, fmt.Errorf("copying %q to %q: %w", srcRef, dstRef, err)
+		}
+		result.Copied = true
+	default:
+		return nil, fmt.Errorf("resolving %q: %w", dstRef, err)
+	}
+
+	if err := copyAttestations(ctx, srcTarget, dstTarget, desc); err != nil {
+		log.Warnf("no attestations mirrored for %q: %v", srcRef, err)
+	}
+
+	// Signing information is looked up every time, as images can be signed
+	// after being mirrored
+	if err := copySigningInformation(ctx, srcTarget, dstTarget, desc); err != nil {
+		log.Warnf("no signing information mirrored for %q: %v", srcRef, err)
+	} else {
+		result.Signed = true
+	}
+
+	return result, nil
+}
+
+func sortImages(images []reference.Named) {
+	slices.SortFunc(images, func(a, b reference.Named) int {
+		return strings.Compare(a.String(), b.String())
+	})
+}
+
+func hasGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// normalizePattern normalizes an image name that can contain glob patterns
+// the same way as NormalizeImageName
+func normalizePattern(pattern string) string {
+	domain, remainder := SplitIGDomain(pattern)
+	if !strings.Contains(remainder[strings.LastIndex(remainder, "/")+1:], ":") {
+		remainder += ":latest"
+	}
+	return domain + "/" + remainder
+}
+
+// registryLocation maps images to repositories of their own registry or, if
+// prefix is set, to <prefix>/<path>, e.g. registry.local:5000/mirror/
+// inspektor-gadget/gadget/trace_exec for ghcr.io/inspektor-gadget/gadget/
+// trace_exec.
+type registryLocation struct {
+	prefix   string
+	authOpts *AuthOptions
+}
+
+func (r *registryLocation) ref(image reference.Named) (reference.Named, error) {
+	if r.prefix == "" {
+		return image, nil
+	}
+	name := r.prefix + "/" + reference.Path(image)
+	if tagged, ok := image.(reference.Tagged); ok {
+		name += ":" + tagged.Tag()
+	}
+	return NormalizeImageName(name)
+}
+
+func (r *registryLocation) target(image reference.Named) (oras.ReadOnlyGraphTarget, string, error) {
+	ref, err := r.ref(image)
+	if err != nil {
+		return nil, "", err
+	}
+	repo, err := newRepository(ref, r.authOpts)
+	if err != nil {
+		return nil, "", err
+	}
+	return repo, ref.String(), nil
+}
+
+func (r *registryLocation) list(ctx context.Context, pattern string) ([]reference.Named, error) {
+	if !hasGlob(pattern) {
+		image, err := NormalizeImageName(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return []reference.Named{image}, nil
+	}
+
+	normalized := normalizePattern(pattern)
+	i := strings.LastIndex(normalized, ":")
+	repoName, tagPattern := normalized[:i], normalized[i+1:]
+	if hasGlob(repoName) {
+		return nil, errors.New("registries only support patterns in tags")
+	}
+	image, err := NormalizeImageName(repoName)
+	if err != nil {
+		return nil, err
+	}
+	image = reference.TrimNamed(image)
+
+	repoTarget, _, err := r.target(image)
+	if err != nil {
+		return nil, err
+	}
+	var images []reference.Named
+	err = repoTarget.(*remote.Repository).Tags(ctx, "", func(tags []string) error {
+		for _, tag := range tags {
+			if ok, _ := path.Match(tagPattern, tag); !ok {
+				continue
+			}
+			tagged, err := reference.WithTag(image, tag)
+			if err != nil {
+				return err
+			}
+			images = append(images, tagged)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing tags: %w", err)
+	}
+	sortImages(images)
+	return images, nil
+}
+
+func (r *registryLocation) close() error {
+	return nil
+}
+
+func (r *registryLocation) String() string {
+	if r.prefix == "" {
+		return "registry"
+	}
+	return r.prefix
+}
+
+// taggedStore is an OCI layout store, which can be read-only
+type taggedStore interface {
+	oras.ReadOnlyGraphTarget
+	Tags(ctx context.Context, last string, fn func(tags []string) error) error
+}
+
+// layoutLocation keeps images in an OCI layout with their normalized name as
+// tag, like the local store and ExportGadgetImages
+type layoutLocation struct {
+	name  string
+	store taggedStore
+
+	// tags contains the tags of the listed images by normalized name, for
+	// layouts created by other tools
+	tags map[string]string
+}
+
+func (l *layoutLocation) target(image reference.Named) (oras.ReadOnlyGraphTarget, string, error) {
+	if tag, ok := l.tags[image.String()]; ok {
+		return l.store, tag, nil
+	}
+	return l.store, image.String(), nil
+}
+
+// isSignatureTag reports whether the tag is one of the tags of signing
+// information, e.g. sha256-<digest>.sig for cosign
+func isSignatureTag(tag string) bool {
+	return strings.HasPrefix(tag, "sha256-") || strings.HasPrefix(tag, "sha256:")
+}
+
+func (l *layoutLocation) list(ctx context.Context, pattern string) ([]reference.Named, error) {
+	normalized := normalizePattern(pattern)
+
+	var images []reference.Named
+	err := l.store.Tags(ctx, "", func(tags []string) error {
+		for _, tag := range tags {
+			if isSignatureTag(tag) {
+				continue
+			}
+			image, err := NormalizeImageName(tag)
+			if err != nil {
+				log.Debugf("ignoring tag %q: %v", tag, err)
+				continue
+			}
+			if ok, _ := path.Match(normalized, image.String()); ok {
+				if l.tags == nil {
+					l.tags = make(map[string]string)
+				}
+				l.tags[image.String()] = tag
+				images = append(images, image)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing tags: %w", err)
+	}
+	sortImages(images)
+	return images, nil
+}
+
+func (l *layoutLocation) close() error {
+	return nil
+}
+
+func (l *layoutLocation) String() string {
+	return l.name
+}
+
+// archiveLocation writes images to an OCI archive. The content of an existing
+// archive is kept, so images already in it aren't copied again.
+type archiveLocation struct {
+	layoutLocation
+	file   string
+	tmpDir string
+}
+
+func newArchiveLocation(ctx context.Context, name, file string) (*archiveLocation, error) {
+	tmpDir, err := os.MkdirTemp("", "gadget-mirror-")
+	if err != nil {
+		return nil, fmt.Errorf("creating temp dir: %w", err)
+	}
+	store, err := oci.NewWithContext(ctx, tmpDir)
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return nil, fmt.Errorf("creating oci storage: %w", err)
+	}
+
+	if _, err := os.Stat(file); err == nil {
+		existing, err := oci.NewFromTar(ctx, file)
+		if err != nil {
+			os.RemoveAll(tmpDir)
+			return nil, fmt.Errorf("opening oci archive %q: %w", file, err)
+		}
+		err = existing.Tags(ctx, "", func(tags []string) error {
+			for _, tag := range tags {
+				_, err := oras.ExtendedCopy(ctx, existing, tag, store, tag, oras.DefaultExtendedCopyOptions)
+				if err != nil {
+					return fmt.Errorf("copying %q: %w", tag, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			os.RemoveAll(tmpDir)
+			return nil, fmt.Errorf("loading oci archive %q: %w", file, err)
+		}
+	}
+
+	return &archiveLocation{
+		layoutLocation: layoutLocation{name: name, store: store},
+		file:           file,
+		tmpDir:         tmpDir,
+	}, nil
+}
+
+func (a *archiveLocation) close() error {
+	if a.tmpDir == "" {
+		return nil
+	}
+	defer func() {
+		os.RemoveAll(a.tmpDir)
+		a.tmpDir = ""
+	}()
+
+	index, err := sortIndex(filepath.Join(a.tmpDir, ocispec.ImageIndexFile))
+	if err != nil {
+		return err
+	}
+
+	var tarHeaderTime time.Time
+	if index.Annotations != nil && index.Annotations[ocispec.AnnotationCreated] != "" {
+		tarHeaderTime, err = time.Parse(time.RFC3339, index.Annotations[ocispec.AnnotationCreated])
+		if err != nil {
+			return fmt.Errorf("parsing created time: %w", err)
+		}
+	}
+
+	// Replace the archive only once it's complete
+	tmpFile := a.file + ".tmp"
+	if err := tarFolderToFile(a.tmpDir, tmpFile, tarHeaderTime); err != nil {
+		os.Remove(tmpFile)
+		return fmt.Errorf("creating oci archive: %w", err)
+	}
+	if err := os.Rename(tmpFile, a.file); err != nil {
+		os.Remove(tmpFile)
+		return fmt.Errorf("renaming oci archive: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/content/oci"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/signature/helpers"
+)
+
+// signedStore returns a copy of the OCI layout with signed images used by the
+// signature tests
+func signedStore(t *testing.T) string {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), "oci-store")
+	require.NoError(t, os.CopyFS(dir, os.DirFS(filepath.Join("..", "signature", "testdata", "oci-store"))))
+	return dir
+}
+
+func destinations(results []*MirrorResult) []string {
+	var ret []string
+	for _, r := range results {
+		ret = append(ret, r.Destination)
+	}
+	return ret
+}
+
+func TestNormalizePattern(t *testing.T) {
+	for pattern, expected := range map[string]string{
+		"trace_*":                      "ghcr.io/inspektor-gadget/gadget/trace_*:latest",
+		"trace_*:v0.*":                 "ghcr.io/inspektor-gadget/gadget/trace_*:v0.*",
+		"ttl.sh/signed_*":              "ttl.sh/signed_*:latest",
+		"localhost:5000/gadgets/*:v1*": "localhost:5000/gadgets/*:v1*",
+	} {
+		assert.Equal(t, expected, normalizePattern(pattern), pattern)
+	}
+}
+
+func TestMirrorToArchive(t *testing.T) {
+	ctx := context.Background()
+	archive := filepath.Join(t.TempDir(), "mirror.tar")
+	opts := &MirrorOptions{
+		Source:      MirrorOCILayoutPrefix + signedStore(t),
+		Destination: MirrorOCIArchivePrefix + archive,
+	}
+
+	results, err := MirrorGadgetImages(ctx, []string{"ttl.sh/signed_with_cosign_*"}, opts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"ttl.sh/signed_with_cosign_bundle:latest",
+		"ttl.sh/signed_with_cosign_legacy:latest",
+		"ttl.sh/signed_with_cosign_oci11:latest",
+	}, destinations(results))
+	for _, r := range results {
+		assert.True(t, r.Copied, r.Destination)
+		assert.True(t, r.Signed, r.Destination)
+	}
+
+	// Mirroring again only adds the new images
+	results, err = MirrorGadgetImages(ctx, []string{
+		"ttl.sh/signed_with_cosign_legacy",
+		"ttl.sh/signed_with_notation_config_media_type_empty",
+	}, opts)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.False(t, results[0].Copied)
+	assert.True(t, results[1].Copied)
+
+	store, err := oci.NewFromTar(ctx, archive)
+	require.NoError(t, err)
+	var tags []string
+	require.NoError(t, store.Tags(ctx, "", func(t []string) error {
+		tags = append(tags, t...)
+		return nil
+	}))
+	assert.Contains(t, tags, "ttl.sh/signed_with_cosign_bundle:latest")
+	assert.Contains(t, tags, "ttl.sh/signed_with_notation_config_media_type_empty:latest")
+
+	// The legacy cosign signature is kept
+	sigTag, err := helpers.CraftCosignSignatureTag(results[0].Digest)
+	require.NoError(t, err)
+	assert.Contains(t, tags, sigTag)
+
+	_, err = MirrorGadgetImages(ctx, []string{"ttl.sh/unknown_*"}, opts)
+	require.ErrorContains(t, err, "no images matching")
+}
+
+func TestMirrorThroughRegistry(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(registry.New(registry.WithReferrersSupport(true)))
+	t.Cleanup(server.Close)
+	host := strings.TrimPrefix(server.URL, "http://")
+	authOpts := AuthOptions{InsecureRegistries: []string{host}}
+
+	images := []string{
+		"ttl.sh/signed_with_cosign_legacy",
+		"ttl.sh/signed_with_notation_config_media_type_signature",
+	}
+
+	// Publish the images to the registry of the disconnected environment
+	results, err := MirrorGadgetImages(ctx, images, &MirrorOptions{
+		AuthOptions: authOpts,
+		Source:      MirrorOCILayoutPrefix + signedStore(t),
+		Destination: host + "/mirror",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		host + "/mirror/signed_with_cosign_legacy:latest",
+		host + "/mirror/signed_with_notation_config_media_type_signature:latest",
+	}, destinations(results))
+	for _, r := range results {
+		assert.True(t, r.Copied, r.Destination)
+		assert.True(t, r.Signed, r.Destination)
+	}
+
+	// And get them back with their signatures, using patterns in tags
+	dir := filepath.Join(t.TempDir(), "layout")
+	opts := &MirrorOptions{
+		AuthOptions: authOpts,
+		Source:      host + "/mirror",
+		Destination: MirrorOCILayoutPrefix + dir,
+	}
+	results, err = MirrorGadgetImages(ctx, []string{
+		"ttl.sh/signed_with_cosign_legacy:lat*",
+		"ttl.sh/signed_with_notation_config_media_type_signature",
+	}, opts)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for _, r := range results {
+		assert.True(t, r.Copied, r.Destination)
+		assert.True(t, r.Signed, r.Destination)
+	}
+
+	store, err := oci.New(dir)
+	require.NoError(t, err)
+	_, err = helpers.FindNotationSignatureTag(ctx, store, results[1].Digest)
+	require.NoError(t, err)
+
+	results, err = MirrorGadgetImages(ctx, images, opts)
+	require.NoError(t, err)
+	for _, r := range results {
+		assert.False(t, r.Copied, r.Destination)
+	}
+
+	_, err = MirrorGadgetImages(ctx, []string{"ttl.sh/signed_*"}, opts)
+	require.ErrorContains(t, err, "only support patterns in tags")
+}
@@ -65,6 +65,8 @@ type AllowedGadgetsOptions struct {
 type VerifyOptions struct {
 	VerifySignature bool
 	Verifier        *verifier.SignatureVerifier
+	// Kinds of attestations (provenance, sbom) the image must have
+	RequiredAttestations []string
 }
 
 type ImageOptions struct {
@@ -143,7 +145,7 @@ func VerifyGadgetImage(ctx context.Context, image string, imgOpts *ImageOptions)
 			return fmt.Errorf("getting oci store: %w", err)
 		}
 
-		imageRef, err := normalizeImageName(image)
+		imageRef, err := NormalizeImageName(image)
 		if err != nil {
 			return fmt.Errorf("normalizing image name: %w", err)
 		}
@@ -207,7 +209,7 @@ func pullGadgetImage(ctx context.Context, image string, authOpts *AuthOptions) (
 
 // pullGadgetImageToStore pulls the gadget image into the given store and returns its descriptor.
 func pullGadgetImageToStore(ctx context.Context, imageStore oras.Target, image string, authOpts *AuthOptions) (*GadgetImageDesc, error) {
-	targetImage, err := normalizeImageName(image)
+	targetImage, err := NormalizeImageName(image)
 	if err != nil {
 		return nil, fmt.Errorf("normalizing image: %w", err)
 	}
@@ -230,7 +232,7 @@ func pullGadgetImageToStore(ctx context.Context, imageStore oras.Target, image s
 }
 
 func pullIfNotExist(ctx context.Context, imageStore oras.Target, authOpts *AuthOptions, image string) error {
-	targetImage, err := normalizeImageName(image)
+	targetImage, err := NormalizeImageName(image)
 	if err != nil {
 		return fmt.Errorf("normalizing image: %w", err)
 	}
@@ -267,6 +269,10 @@ func pullImage(ctx context.Context, targetImage reference.Named, imageStore oras
 		return nil, fmt.Errorf("copying to local repository: %w", err)
 	}
 
+	if err := copyAttestations(ctx, repo, imageStore, desc); err != nil {
+		log.Warnf("error pulling attestations: %v", err)
+	}
+
 	imageDigest := desc.Digest.String()
 	if err := puller.DefaultSignaturePuller.PullSigningInformation(ctx, repo, imageStore, imageDigest); err != nil {
 		log.Warnf("error pulling signature: %v", err)
@@ -284,7 +290,7 @@ func PushGadgetImage(ctx context.Context, image string, authOpts *AuthOptions) (
 		return nil, fmt.Errorf("getting oci store: %w", err)
 	}
 
-	targetImage, err := normalizeImageName(image)
+	targetImage, err := NormalizeImageName(image)
 	if err != nil {
 		return nil, fmt.Errorf("normalizing image: %w", err)
 	}
@@ -298,6 +304,10 @@ func PushGadgetImage(ctx context.Context, image string, authOpts *AuthOptions) (
 		return nil, fmt.Errorf("copying to remote repository: %w", err)
 	}
 
+	if err := copyAttestations(ctx, ociStore, repo, desc); err != nil {
+		log.Warnf("error pushing attestations: %v", err)
+	}
+
 	imageDesc := &GadgetImageDesc{
 		Repository: targetImage.Name(),
 		Digest:     desc.Digest.String(),
@@ -322,11 +332,11 @@ func TagGadgetImage(ctx context.Context, srcImage, dstImage string) (*GadgetImag
 }
 
 func tagGadgetImage(ctx context.Context, srcImage, dstImage string) (*GadgetImageDesc, error) {
-	src, err := normalizeImageName(srcImage)
+	src, err := NormalizeImageName(srcImage)
 	if err != nil {
 		return nil, fmt.Errorf("normalizing src image: %w", err)
 	}
-	dst, err := normalizeImageName(dstImage)
+	dst, err := NormalizeImageName(dstImage)
 	if err != nil {
 		return nil, fmt.Errorf("normalizing dst image: %w", err)
 	}
@@ -409,7 +419,7 @@ func ExportGadgetImages(ctx context.Context, dstFile string, images ...string) e
 	}
 
 	for _, image := range images {
-		targetImage, err := normalizeImageName(image)
+		targetImage, err := NormalizeImageName(image)
 		if err != nil {
 			return fmt.Errorf("normalizing image: %w", err)
 		}
@@ -419,6 +429,10 @@ func ExportGadgetImages(ctx context.Context, dstFile string, images ...string) e
 			return fmt.Errorf("copying image to remote repository: %w", err)
 		}
 
+		if err := copyAttestations(ctx, ociStore, dstStore, desc); err != nil {
+			log.Warnf("no attestations exported for %q: %v", image, err)
+		}
+
 		err = exporter.DefaultSignatureExporter.ExportSigningInformation(ctx, ociStore, dstStore, desc)
 		if errors.Is(err, errdef.ErrNotFound) {
 			continue
@@ -615,7 +629,7 @@ func GetGadgetImageDesc(ctx context.Context, image string) (*GadgetImageDesc, er
 		return nil, fmt.Errorf("getting oci store: %w", err)
 	}
 
-	targetImage, err := normalizeImageName(image)
+	targetImage, err := NormalizeImageName(image)
 	if err != nil {
 		return nil, fmt.Errorf("normalizing image: %w", err)
 	}
@@ -642,7 +656,7 @@ func deleteGadgetImage(ctx context.Context, image string) error {
 		return fmt.Errorf("getting oci store: %w", err)
 	}
 
-	targetImage, err := normalizeImageName(image)
+	targetImage, err := NormalizeImageName(image)
 	if err != nil {
 		return fmt.Errorf("normalizing image: %w", err)
 	}
@@ -716,7 +730,7 @@ func SplitIGDomain(name string) (domain, remainder string) {
 	return
 }
 
-func normalizeImageName(image string) (reference.Named, error) {
+func NormalizeImageName(image string) (reference.Named, error) {
 	// Use the default gadget's registry if no domain is specified.
 	domain, remainer := SplitIGDomain(image)
 
@@ -868,7 +882,7 @@ func ensureImage(ctx context.Context, imageStore oras.GraphTarget, image string,
 		}
 	case PullImageNever:
 		// Just check if the image exists to report a better error message
-		targetImage, err := normalizeImageName(image)
+		targetImage, err := NormalizeImageName(image)
 		if err != nil {
 			return fmt.Errorf("normalizing image: %w", err)
 		}
@@ -880,7 +894,7 @@ func ensureImage(ctx context.Context, imageStore oras.GraphTarget, image string,
 	if len(imgOpts.AllowedGadgets) > 0 {
 		found := false
 
-		normalizedImage, err := normalizeImageName(image)
+		normalizedImage, err := NormalizeImageName(image)
 		if err != nil {
 			return fmt.Errorf("normalizing image: %w", err)
 		}
@@ -975,9 +989,30 @@ func GetManifestForHost(ctx context.Context, target oras.ReadOnlyTarget, image s
 	return getManifestForHost(ctx, target, image)
 }
 
+// GetImageDigest returns the digest of the given image as shown by `ig image
+// list`. If target is nil, the local store is used.
+func GetImageDigest(ctx context.Context, target oras.ReadOnlyTarget, image string) (string, error) {
+	if target == nil {
+		var err error
+		target, err = newLocalOciStore()
+		if err != nil {
+			return "", fmt.Errorf("getting local oci store: %w", err)
+		}
+	}
+	imageRef, err := NormalizeImageName(image)
+	if err != nil {
+		return "", fmt.Errorf("normalizing image: %w", err)
+	}
+	desc, err := target.Resolve(ctx, imageRef.String())
+	if err != nil {
+		return "", fmt.Errorf("resolving image %q: %w", imageRef.String(), err)
+	}
+	return desc.Digest.String(), nil
+}
+
 // getIndex gets an index for the given image
 func getIndex(ctx context.Context, target oras.ReadOnlyTarget, image string) (*ocispec.Index, error) {
-	imageRef, err := normalizeImageName(image)
+	imageRef, err := NormalizeImageName(image)
 	if err != nil {
 		return nil, fmt.Errorf("normalizing image: %w", err)
 	}
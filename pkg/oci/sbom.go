@@ -0,0 +1,337 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+
+	spb "github.com/in-toto/attestation/go/v1"
+
+	"github.com/inspektor-gadget/inspektor-gadget/internal/version"
+)
+
+// goModulePurl returns the package URL of a Go module, see
+// https://github.com/package-url/purl-spec/blob/main/PURL-TYPES.rst#golang
+func goModulePurl(mod GoModule) string {
+	if mod.Version == "" {
+		return "pkg:golang/" + mod.Path
+	}
+	return fmt.Sprintf("pkg:golang/%s@%s", mod.Path, mod.Version)
+}
+
+// SPDX 2.3 document, only the fields used by gadget images are defined. See
+// https://spdx.github.io/spdx-spec/v2.3/
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Files             []spdxFile         `json:"files,omitempty"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created,omitempty"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID                string            `json:"SPDXID"`
+	Name                  string            `json:"name"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	Checksums             []spdxChecksum    `json:"checksums,omitempty"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs,omitempty"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose,omitempty"`
+}
+
+type spdxFile struct {
+	SPDXID    string         `json:"SPDXID"`
+	FileName  string         `json:"fileName"`
+	Checksums []spdxChecksum `json:"checksums"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+var spdxIDInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+func spdxID(kind, name string) string {
+	return "SPDXRef-" + kind + "-" + spdxIDInvalidChars.ReplaceAllString(name, "-")
+}
+
+func spdxChecksums(digests map[string]string) []spdxChecksum {
+	var checksums []spdxChecksum
+	for _, algo := range slices.Sorted(maps.Keys(digests)) {
+		name := algo
+		switch algo {
+		case "sha256":
+			name = "SHA256"
+		case "sha512":
+			name = "SHA512"
+		}
+		checksums = append(checksums, spdxChecksum{Algorithm: name, ChecksumValue: digests[algo]})
+	}
+	return checksums
+}
+
+func newSPDXDocument(subject *spb.ResourceDescriptor, opts *BuildGadgetImageOpts) *spdxDocument {
+	info := opts.BuildInfo
+
+	gadgetID := spdxID("Package", subject.Name)
+	doc := &spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              subject.Name,
+		DocumentNamespace: fmt.Sprintf("https://inspektor-gadget.io/spdx/%s-%s", subject.Name, subject.Digest["sha256"]),
+		CreationInfo: spdxCreationInfo{
+			Created:  opts.CreatedDate,
+			Creators: []string{"Tool: ig-" + version.VersionString()},
+		},
+		Packages: []spdxPackage{
+			{
+				SPDXID:                gadgetID,
+				Name:                  subject.Name,
+				DownloadLocation:      "NOASSERTION",
+				Checksums:             spdxChecksums(subject.Digest),
+				PrimaryPackagePurpose: "APPLICATION",
+			},
+		},
+		Relationships: []spdxRelationship{
+			{
+				SPDXElementID:      "SPDXRef-DOCUMENT",
+				RelationshipType:   "DESCRIBES",
+				RelatedSPDXElement: gadgetID,
+			},
+		},
+	}
+
+	if info.BuilderImage != "" {
+		builder := builderImageDescriptor(info)
+		id := spdxID("Container", info.BuilderImage)
+		doc.Packages = append(doc.Packages, spdxPackage{
+			SPDXID:                id,
+			Name:                  info.BuilderImage,
+			DownloadLocation:      "NOASSERTION",
+			Checksums:             spdxChecksums(builder.Digest),
+			PrimaryPackagePurpose: "CONTAINER",
+		})
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      id,
+			RelationshipType:   "BUILD_TOOL_OF",
+			RelatedSPDXElement: gadgetID,
+		})
+	}
+
+	for _, tool := range slices.Sorted(maps.Keys(info.Tools)) {
+		id := spdxID("Tool", tool)
+		doc.Packages = append(doc.Packages, spdxPackage{
+			SPDXID:                id,
+			Name:                  tool,
+			VersionInfo:           info.Tools[tool],
+			DownloadLocation:      "NOASSERTION",
+			PrimaryPackagePurpose: "APPLICATION",
+		})
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      id,
+			RelationshipType:   "BUILD_TOOL_OF",
+			RelatedSPDXElement: gadgetID,
+		})
+	}
+
+	for _, mod := range info.GoModules {
+		id := spdxID("GoModule", mod.Path)
+		doc.Packages = append(doc.Packages, spdxPackage{
+			SPDXID:           id,
+			Name:             mod.Path,
+			VersionInfo:      mod.Version,
+			DownloadLocation: "NOASSERTION",
+			ExternalRefs: []spdxExternalRef{
+				{
+					ReferenceCategory: "PACKAGE-MANAGER",
+					ReferenceType:     "purl",
+					ReferenceLocator:  goModulePurl(mod),
+				},
+			},
+			PrimaryPackagePurpose: "LIBRARY",
+		})
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      gadgetID,
+			RelationshipType:   "DEPENDS_ON",
+			RelatedSPDXElement: id,
+		})
+	}
+
+	for _, material := range append(slices.Clone(info.Sources), info.Headers...) {
+		id := spdxID("File", material.Path)
+		doc.Files = append(doc.Files, spdxFile{
+			SPDXID:    id,
+			FileName:  material.Path,
+			Checksums: spdxChecksums(map[string]string{"sha256": material.Digest}),
+		})
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      gadgetID,
+			RelationshipType:   "GENERATED_FROM",
+			RelatedSPDXElement: id,
+		})
+	}
+
+	return doc
+}
+
+// CycloneDX 1.5 BOM, only the fields used by gadget images are defined. See
+// https://cyclonedx.org/docs/1.5/json/
+type cycloneDXBOM struct {
+	BOMFormat    string                `json:"bomFormat"`
+	SpecVersion  string                `json:"specVersion"`
+	Version      int                   `json:"version"`
+	Metadata     cycloneDXMetadata     `json:"metadata"`
+	Components   []cycloneDXComponent  `json:"components,omitempty"`
+	Dependencies []cycloneDXDependency `json:"dependencies,omitempty"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string              `json:"timestamp,omitempty"`
+	Tools     cycloneDXTools      `json:"tools"`
+	Component *cycloneDXComponent `json:"component"`
+}
+
+type cycloneDXTools struct {
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXComponent struct {
+	BOMRef  string          `json:"bom-ref,omitempty"`
+	Type    string          `json:"type"`
+	Name    string          `json:"name"`
+	Version string          `json:"version,omitempty"`
+	Purl    string          `json:"purl,omitempty"`
+	Hashes  []cycloneDXHash `json:"hashes,omitempty"`
+}
+
+type cycloneDXHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cycloneDXDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+func cycloneDXHashes(digests map[string]string) []cycloneDXHash {
+	var hashes []cycloneDXHash
+	for _, algo := range slices.Sorted(maps.Keys(digests)) {
+		name := algo
+		switch algo {
+		case "sha256":
+			name = "SHA-256"
+		case "sha512":
+			name = "SHA-512"
+		}
+		hashes = append(hashes, cycloneDXHash{Alg: name, Content: digests[algo]})
+	}
+	return hashes
+}
+
+func newCycloneDXBOM(subject *spb.ResourceDescriptor, opts *BuildGadgetImageOpts) *cycloneDXBOM {
+	info := opts.BuildInfo
+
+	bom := &cycloneDXBOM{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+		Metadata: cycloneDXMetadata{
+			Timestamp: opts.CreatedDate,
+			Tools: cycloneDXTools{
+				Components: []cycloneDXComponent{
+					{Type: "application", Name: "ig", Version: version.VersionString()},
+				},
+			},
+			Component: &cycloneDXComponent{
+				BOMRef: subject.Name,
+				Type:   "application",
+				Name:   subject.Name,
+				Hashes: cycloneDXHashes(subject.Digest),
+			},
+		},
+	}
+
+	if info.BuilderImage != "" {
+		builder := builderImageDescriptor(info)
+		bom.Metadata.Tools.Components = append(bom.Metadata.Tools.Components, cycloneDXComponent{
+			Type:   "container",
+			Name:   info.BuilderImage,
+			Hashes: cycloneDXHashes(builder.Digest),
+		})
+	}
+
+	for _, tool := range slices.Sorted(maps.Keys(info.Tools)) {
+		bom.Metadata.Tools.Components = append(bom.Metadata.Tools.Components, cycloneDXComponent{
+			Type:    "application",
+			Name:    tool,
+			Version: info.Tools[tool],
+		})
+	}
+
+	dependency := cycloneDXDependency{Ref: subject.Name}
+	for _, mod := range info.GoModules {
+		purl := goModulePurl(mod)
+		bom.Components = append(bom.Components, cycloneDXComponent{
+			BOMRef:  purl,
+			Type:    "library",
+			Name:    mod.Path,
+			Version: mod.Version,
+			Purl:    purl,
+		})
+		dependency.DependsOn = append(dependency.DependsOn, purl)
+	}
+	if len(dependency.DependsOn) > 0 {
+		bom.Dependencies = []cycloneDXDependency{dependency}
+	}
+
+	for _, material := range append(slices.Clone(info.Sources), info.Headers...) {
+		bom.Components = append(bom.Components, cycloneDXComponent{
+			BOMRef: "file:" + material.Path,
+			Type:   "file",
+			Name:   material.Path,
+			Hashes: cycloneDXHashes(map[string]string{"sha256": material.Digest}),
+		})
+	}
+
+	return bom
+}
@@ -0,0 +1,245 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package aggregate is a data operator that groups the events of a data source
+// of type single by a list of fields and calculates aggregates like count, sum
+// or percentiles per group. The original data source is replaced by a data
+// source of type array that periodically emits one entry per group, so
+// operators like sort, limiter and combiner can be used on the result.
+package aggregate
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	clioperator "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/cli"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+)
+
+const (
+	name             = "aggregate"
+	ParamGroupBy     = "group-by"
+	ParamAggregate   = "aggregate"
+	ParamInterval    = "aggregate-interval"
+	DataSourcePrefix = "aggregate"
+
+	// Priority needs to be higher than the one of the filter operator (9000),
+	// so only filtered events are aggregated, and lower than the one of the
+	// sort operator (9500)
+	Priority = 9100
+)
+
+type aggregateOperator struct{}
+
+func (a *aggregateOperator) Name() string {
+	return name
+}
+
+func (a *aggregateOperator) Init(params *params.Params) error {
+	return nil
+}
+
+func (a *aggregateOperator) GlobalParams() api.Params {
+	return nil
+}
+
+func (a *aggregateOperator) InstanceParams() api.Params {
+	return api.Params{
+		{
+			Key:   ParamGroupBy,
+			Title: "Group By",
+			Description: "Group events by fields. Join multiple fields with ','. " +
+				"If using multiple data sources, prefix fields with 'datasourcename:' and separate with ';'",
+			TypeHint: api.TypeString,
+			Tags:     []string{api.TagGroupDataManipulation},
+		},
+		{
+			Key:   ParamAggregate,
+			Title: "Aggregate",
+			Description: "Aggregate functions to calculate for each group: count, sum(field), min(field), max(field), " +
+				"avg(field), p50(field), p95(field) and p99(field). Join multiple functions with ','. Defaults to count if " +
+				"only group-by is given. If using multiple data sources, prefix functions with 'datasourcename:' and separate with ';'",
+			TypeHint: api.TypeString,
+			Tags:     []string{api.TagGroupDataManipulation},
+		},
+		{
+			Key:          ParamInterval,
+			Title:        "Aggregate Interval",
+			Description:  "Interval in which aggregated data is emitted; groups are reset after each interval",
+			DefaultValue: "1s",
+			TypeHint:     api.TypeDuration,
+			Tags:         []string{api.TagGroupDataManipulation},
+		},
+	}
+}
+
+func (a *aggregateOperator) InstantiateDataOperator(gadgetCtx operators.GadgetContext, instanceParamValues api.ParamValues) (operators.DataOperatorInstance, error) {
+	groupByPerDs, err := apihelpers.GetListValuesPerDataSource(instanceParamValues[ParamGroupBy])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamGroupBy, err)
+	}
+	aggregatePerDs, err := apihelpers.GetListValuesPerDataSource(instanceParamValues[ParamAggregate])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamAggregate, err)
+	}
+	if len(groupByPerDs) == 0 && len(aggregatePerDs) == 0 {
+		return nil, nil
+	}
+
+	params := apihelpers.ToParamDescs(a.InstanceParams()).ToParams()
+	if err := params.CopyFromMap(instanceParamValues, ""); err != nil {
+		return nil, err
+	}
+	interval := params.Get(ParamInterval).AsDuration()
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid value for %s: %s", ParamInterval, interval)
+	}
+
+	instance := &aggregateOperatorInstance{
+		interval:    interval,
+		aggregators: make(map[datasource.DataSource]*aggregator),
+	}
+
+	for _, ds := range gadgetCtx.GetDataSources() {
+		groupBy, hasGroupBy := groupByPerDs[ds.Name()]
+		aggs, hasAggs := aggregatePerDs[ds.Name()]
+		dsSpecific := hasGroupBy || hasAggs
+		if !dsSpecific {
+			groupBy, hasGroupBy = groupByPerDs[""]
+			aggs, hasAggs = aggregatePerDs[""]
+		}
+		if !hasGroupBy && !hasAggs {
+			continue
+		}
+
+		if ds.Type() != datasource.TypeSingle {
+			if dsSpecific {
+				return nil, fmt.Errorf("aggregations can only be used on data sources of type single, %q is not", ds.Name())
+			}
+			continue
+		}
+
+		if !hasAggs {
+			aggs = fnCount
+		}
+		aggregations, err := parseAggregations(aggs)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s for data source %q: %w", ParamAggregate, ds.Name(), err)
+		}
+
+		// Register a new data source that will emit the aggregated data
+		outDs, err := gadgetCtx.RegisterDataSource(
+			datasource.TypeArray,
+			fmt.Sprintf("%s-%s", DataSourcePrefix, ds.Name()),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("registering aggregate data source for %s: %w", ds.Name(), err)
+		}
+		outDs.AddAnnotation(api.FetchIntervalAnnotation, interval.String())
+		outDs.AddAnnotation(clioperator.AnnotationClearScreenBefore, "true")
+
+		agg, err := newAggregator(ds, outDs, apihelpers.SplitList(groupBy), aggregations)
+		if err != nil {
+			return nil, fmt.Errorf("aggregating data source %q: %w", ds.Name(), err)
+		}
+
+		// Disable original data source to avoid other operators subscribing to it
+		ds.Unreference()
+
+		gadgetCtx.Logger().Debugf("aggregate: aggregating %q into %q", ds.Name(), outDs.Name())
+		instance.aggregators[ds] = agg
+	}
+
+	if len(instance.aggregators) == 0 {
+		return nil, nil
+	}
+	return instance, nil
+}
+
+func (a *aggregateOperator) Priority() int {
+	return Priority
+}
+
+func (a *aggregateOperator) SupportsReplay() bool {
+	return true
+}
+
+type aggregateOperatorInstance struct {
+	interval    time.Duration
+	aggregators map[datasource.DataSource]*aggregator
+	done        chan struct{}
+	wg          sync.WaitGroup
+}
+
+func (a *aggregateOperatorInstance) Name() string {
+	return name
+}
+
+func (a *aggregateOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
+	for ds, agg := range a.aggregators {
+		ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+			agg.add(data)
+			return nil
+		}, Priority)
+	}
+	return nil
+}
+
+func (a *aggregateOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
+	a.done = make(chan struct{})
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+
+		ticker := time.NewTicker(a.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-a.done:
+				return
+			case <-ticker.C:
+				for _, agg := range a.aggregators {
+					if err := agg.emit(); err != nil {
+						gadgetCtx.Logger().Warnf("aggregate: emitting %q: %v", agg.outDs.Name(), err)
+					}
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (a *aggregateOperatorInstance) Stop(gadgetCtx operators.GadgetContext) error {
+	if a.done != nil {
+		close(a.done)
+		a.wg.Wait()
+		a.done = nil
+	}
+	return nil
+}
+
+func (a *aggregateOperatorInstance) Close(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+var Operator = &aggregateOperator{}
+
+func init() {
+	operators.RegisterDataOperator(Operator)
+}
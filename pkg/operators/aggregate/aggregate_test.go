@@ -0,0 +1,274 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregate
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/simple"
+	testds "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/testing/datasource"
+)
+
+func TestParseAggregations(t *testing.T) {
+	type testCase struct {
+		name     string
+		in       string
+		expected []aggregation
+		err      bool
+	}
+
+	testCases := []testCase{
+		{
+			name:     "count",
+			in:       "count",
+			expected: []aggregation{{fn: fnCount}},
+		},
+		{
+			name: "multiple",
+			in:   "count, sum(size) ,p95(proc.pid)",
+			expected: []aggregation{
+				{fn: fnCount},
+				{fn: fnSum, field: "size"},
+				{fn: fnP95, field: "proc.pid"},
+			},
+		},
+		{
+			name: "empty",
+			in:   "",
+			err:  true,
+		},
+		{
+			name: "unknown function",
+			in:   "median(size)",
+			err:  true,
+		},
+		{
+			name: "missing field",
+			in:   "sum()",
+			err:  true,
+		},
+		{
+			name: "missing parenthesis",
+			in:   "sum(size",
+			err:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			aggs, err := parseAggregations(tc.in)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, aggs)
+		})
+	}
+}
+
+type testEvent struct {
+	comm string
+	size uint32
+}
+
+var testEvents = []testEvent{
+	{"cat", 10},
+	{"ls", 5},
+	{"cat", 30},
+	{"cat", 20},
+	{"ls", 1},
+}
+
+func newTestDataSource(t *testing.T, register testds.RegisterFunc) (datasource.DataSource, datasource.FieldAccessor, datasource.FieldAccessor) {
+	ds, fields := testds.New(t, register, "foo",
+		testds.Field{Name: "proc.comm", Kind: api.Kind_String},
+		testds.Field{Name: "size", Kind: api.Kind_Uint32},
+	)
+	return ds, fields[0], fields[1]
+}
+
+func TestAggregator(t *testing.T) {
+	ds, comm, size := newTestDataSource(t, testds.Unregistered)
+	outDs, err := datasource.New(datasource.TypeArray, "aggregate-foo")
+	require.NoError(t, err)
+
+	aggs, err := parseAggregations("count,sum(size),min(size),max(size),avg(size),p50(size),p99(size)")
+	require.NoError(t, err)
+	agg, err := newAggregator(ds, outDs, []string{"proc.comm"}, aggs)
+	require.NoError(t, err)
+
+	for _, ev := range testEvents {
+		p, err := ds.NewPacketSingle()
+		require.NoError(t, err)
+		require.NoError(t, comm.PutString(p, ev.comm))
+		require.NoError(t, size.PutUint32(p, ev.size))
+		agg.add(p)
+		ds.Release(p)
+	}
+
+	pa, err := outDs.NewPacketArray()
+	require.NoError(t, err)
+	require.NoError(t, agg.fill(pa))
+	require.Equal(t, 2, pa.Len())
+
+	getString := func(d datasource.Data, name string) string {
+		v, err := outDs.GetField(name).String(d)
+		require.NoError(t, err)
+		return v
+	}
+	getInt := func(d datasource.Data, name string) int64 {
+		v, err := outDs.GetField(name).Int64(d)
+		require.NoError(t, err)
+		return v
+	}
+	getFloat := func(d datasource.Data, name string) float64 {
+		v, err := outDs.GetField(name).Float64(d)
+		require.NoError(t, err)
+		return v
+	}
+
+	cat := pa.Get(0)
+	assert.Equal(t, "cat", getString(cat, "proc.comm"))
+	count, err := outDs.GetField("count").Uint64(cat)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), count)
+	assert.Equal(t, int64(60), getInt(cat, "sum_size"))
+	assert.Equal(t, int64(10), getInt(cat, "min_size"))
+	assert.Equal(t, int64(30), getInt(cat, "max_size"))
+	assert.Equal(t, 20.0, getFloat(cat, "avg_size"))
+	assert.Equal(t, 20.0, getFloat(cat, "p50_size"))
+	assert.Equal(t, 30.0, getFloat(cat, "p99_size"))
+
+	ls := pa.Get(1)
+	assert.Equal(t, "ls", getString(ls, "proc.comm"))
+	assert.Equal(t, int64(6), getInt(ls, "sum_size"))
+	assert.Equal(t, 3.0, getFloat(ls, "avg_size"))
+
+	// groups are reset after filling a packet
+	pa, err = outDs.NewPacketArray()
+	require.NoError(t, err)
+	require.NoError(t, agg.fill(pa))
+	assert.Equal(t, 0, pa.Len())
+}
+
+func TestNewAggregatorErrors(t *testing.T) {
+	ds, _, _ := newTestDataSource(t, testds.Unregistered)
+
+	type testCase struct {
+		name    string
+		groupBy []string
+		aggs    []aggregation
+	}
+	testCases := []testCase{
+		{
+			name:    "unknown group-by field",
+			groupBy: []string{"pid"},
+			aggs:    []aggregation{{fn: fnCount}},
+		},
+		{
+			name: "unknown field",
+			aggs: []aggregation{{fn: fnSum, field: "pid"}},
+		},
+		{
+			name: "non-numeric field",
+			aggs: []aggregation{{fn: fnAvg, field: "proc.comm"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			outDs, err := datasource.New(datasource.TypeArray, "aggregate-foo")
+			require.NoError(t, err)
+			_, err = newAggregator(ds, outDs, tc.groupBy, tc.aggs)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestAggregateOperator(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	var ds datasource.DataSource
+	var comm, size datasource.FieldAccessor
+
+	producer := simple.New("producer",
+		simple.WithPriority(Priority-1),
+		simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+			ds, comm, size = newTestDataSource(t, gadgetCtx.RegisterDataSource)
+			return nil
+		}),
+		simple.OnStart(func(gadgetCtx operators.GadgetContext) error {
+			for _, ev := range testEvents {
+				p, err := ds.NewPacketSingle()
+				require.NoError(t, err)
+				comm.PutString(p, ev.comm)
+				size.PutUint32(p, ev.size)
+				require.NoError(t, ds.EmitAndRelease(p))
+			}
+			return nil
+		}),
+	)
+
+	var once sync.Once
+	var counts map[string]uint64
+	verifier := simple.New("verifier",
+		simple.WithPriority(Priority+1),
+		simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+			dataSources := gadgetCtx.GetDataSources()
+			assert.NotContains(t, dataSources, "foo")
+			outDs, ok := dataSources["aggregate-foo"]
+			require.True(t, ok)
+			assert.Equal(t, datasource.TypeArray, outDs.Type())
+
+			outComm := outDs.GetField("proc.comm")
+			outCount := outDs.GetField("count")
+			return outDs.SubscribeArray(func(ds datasource.DataSource, arr datasource.DataArray) error {
+				if arr.Len() == 0 {
+					return nil
+				}
+				once.Do(func() {
+					counts = make(map[string]uint64)
+					for i := 0; i < arr.Len(); i++ {
+						c, _ := outComm.String(arr.Get(i))
+						n, _ := outCount.Uint64(arr.Get(i))
+						counts[c] = n
+					}
+					cancel()
+				})
+				return nil
+			}, Priority+1)
+		}),
+	)
+
+	gadgetCtx := gadgetcontext.New(ctx, "", gadgetcontext.WithDataOperators(Operator, producer, verifier))
+	err := gadgetCtx.Run(api.ParamValues{
+		"operator.aggregate.group-by":           "proc.comm",
+		"operator.aggregate.aggregate-interval": "50ms",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]uint64{"cat": 3, "ls": 2}, counts)
+}
@@ -0,0 +1,323 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregate
+
+import (
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
+)
+
+const (
+	fnCount = "count"
+	fnSum   = "sum"
+	fnMin   = "min"
+	fnMax   = "max"
+	fnAvg   = "avg"
+	fnP50   = "p50"
+	fnP95   = "p95"
+	fnP99   = "p99"
+)
+
+var percentiles = map[string]float64{
+	fnP50: 50,
+	fnP95: 95,
+	fnP99: 99,
+}
+
+type aggregation struct {
+	fn string
+	// field is the name of the field to aggregate; empty for count
+	field string
+}
+
+// parseAggregations parses a list like `count,sum(size),p95(latency)`
+func parseAggregations(s string) ([]aggregation, error) {
+	var res []aggregation
+	for _, v := range apihelpers.SplitList(s) {
+		if v == fnCount {
+			res = append(res, aggregation{fn: fnCount})
+			continue
+		}
+		fn, field, ok := strings.Cut(v, "(")
+		if !ok || !strings.HasSuffix(field, ")") {
+			return nil, fmt.Errorf("invalid aggregation %q: expected function(field)", v)
+		}
+		field = strings.TrimSpace(strings.TrimSuffix(field, ")"))
+		fn = strings.TrimSpace(fn)
+		switch fn {
+		case fnSum, fnMin, fnMax, fnAvg, fnP50, fnP95, fnP99:
+		default:
+			return nil, fmt.Errorf("invalid aggregation %q: unknown function %q", v, fn)
+		}
+		if field == "" {
+			return nil, fmt.Errorf("invalid aggregation %q: missing field", v)
+		}
+		res = append(res, aggregation{fn: fn, field: field})
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no aggregations given")
+	}
+	return res, nil
+}
+
+// numericValueFunc returns a function that reads the value of f as float64
+func numericValueFunc(f datasource.FieldAccessor) (fn func(datasource.Data) float64, isInt bool, err error) {
+	switch f.Type() {
+	case api.Kind_Uint8, api.Kind_Uint16, api.Kind_Uint32, api.Kind_Uint64,
+		api.Kind_Int8, api.Kind_Int16, api.Kind_Int32, api.Kind_Int64:
+		intFn, err := datasource.AsInt64(f)
+		if err != nil {
+			return nil, false, err
+		}
+		return func(d datasource.Data) float64 { return float64(intFn(d)) }, true, nil
+	case api.Kind_Float32, api.Kind_Float64:
+		floatFn, err := datasource.AsFloat64(f)
+		if err != nil {
+			return nil, false, err
+		}
+		return floatFn, false, nil
+	}
+	return nil, false, fmt.Errorf("field %q of type %s is not numeric", f.FullName(), f.Type())
+}
+
+// stats holds the intermediate state of an aggregation for a single group
+type stats struct {
+	sum    float64
+	min    float64
+	max    float64
+	values []float64
+}
+
+type group struct {
+	keys  [][]byte
+	count uint64
+	stats []stats
+}
+
+type aggregator struct {
+	mu     sync.Mutex
+	groups map[string]*group
+
+	groupBy      []datasource.FieldAccessor
+	aggregations []aggregation
+	// values read the values of the aggregated fields; nil for count
+	values []func(datasource.Data) float64
+
+	outDs      datasource.DataSource
+	outGroupBy []datasource.FieldAccessor
+	outFields  []datasource.FieldAccessor
+	keyBuf     []byte
+}
+
+func newAggregator(ds datasource.DataSource, outDs datasource.DataSource, groupBy []string, aggregations []aggregation) (*aggregator, error) {
+	agg := &aggregator{
+		groups:       make(map[string]*group),
+		aggregations: aggregations,
+		outDs:        outDs,
+	}
+
+	for _, name := range groupBy {
+		f := ds.GetField(name)
+		if f == nil {
+			return nil, fmt.Errorf("field %q not found", name)
+		}
+		if datasource.FieldFlagEmpty.In(f.Flags()) {
+			return nil, fmt.Errorf("field %q has no value to group by", name)
+		}
+		out, err := outDs.AddField(f.FullName(), f.Type(),
+			datasource.WithTags(f.Tags()...),
+			datasource.WithAnnotations(f.Annotations()),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("adding field %q: %w", name, err)
+		}
+		agg.groupBy = append(agg.groupBy, f)
+		agg.outGroupBy = append(agg.outGroupBy, out)
+	}
+
+	for _, a := range aggregations {
+		outName := a.fn
+		outKind := api.Kind_Float64
+		var valueFn func(datasource.Data) float64
+
+		switch a.fn {
+		case fnCount:
+			outKind = api.Kind_Uint64
+		default:
+			f := ds.GetField(a.field)
+			if f == nil {
+				return nil, fmt.Errorf("field %q not found", a.field)
+			}
+			fn, isInt, err := numericValueFunc(f)
+			if err != nil {
+				return nil, err
+			}
+			valueFn = fn
+			if isInt && (a.fn == fnSum || a.fn == fnMin || a.fn == fnMax) {
+				outKind = api.Kind_Int64
+			}
+			outName = a.fn + "_" + strings.ReplaceAll(f.FullName(), ".", "_")
+		}
+
+		out, err := outDs.AddField(outName, outKind)
+		if err != nil {
+			return nil, fmt.Errorf("adding field %q: %w", outName, err)
+		}
+		agg.values = append(agg.values, valueFn)
+		agg.outFields = append(agg.outFields, out)
+	}
+
+	return agg, nil
+}
+
+func (agg *aggregator) add(data datasource.Data) {
+	agg.mu.Lock()
+	defer agg.mu.Unlock()
+
+	agg.keyBuf = agg.keyBuf[:0]
+	for _, f := range agg.groupBy {
+		b := f.Get(data)
+		agg.keyBuf = binary.AppendUvarint(agg.keyBuf, uint64(len(b)))
+		agg.keyBuf = append(agg.keyBuf, b...)
+	}
+
+	g, ok := agg.groups[string(agg.keyBuf)]
+	if !ok {
+		g = &group{
+			keys:  make([][]byte, 0, len(agg.groupBy)),
+			stats: make([]stats, len(agg.aggregations)),
+		}
+		for _, f := range agg.groupBy {
+			g.keys = append(g.keys, slices.Clone(f.Get(data)))
+		}
+		for i := range g.stats {
+			g.stats[i].min = math.Inf(1)
+			g.stats[i].max = math.Inf(-1)
+		}
+		agg.groups[string(agg.keyBuf)] = g
+	}
+
+	g.count++
+	for i, valueFn := range agg.values {
+		if valueFn == nil {
+			continue
+		}
+		v := valueFn(data)
+		s := &g.stats[i]
+		s.sum += v
+		s.min = min(s.min, v)
+		s.max = max(s.max, v)
+		if _, ok := percentiles[agg.aggregations[i].fn]; ok {
+			s.values = append(s.values, v)
+		}
+	}
+}
+
+// percentile returns the percentile p of the sorted values using the nearest
+// rank method
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank-1, 0)]
+}
+
+func (g *group) value(a aggregation, s *stats) float64 {
+	switch a.fn {
+	case fnSum:
+		return s.sum
+	case fnMin:
+		return s.min
+	case fnMax:
+		return s.max
+	case fnAvg:
+		return s.sum / float64(g.count)
+	}
+	return percentile(s.values, percentiles[a.fn])
+}
+
+// fill writes the current state of all groups to the given packet and resets
+// them afterward
+func (agg *aggregator) fill(pa datasource.PacketArray) error {
+	agg.mu.Lock()
+	groups := agg.groups
+	agg.groups = make(map[string]*group, len(groups))
+	agg.mu.Unlock()
+
+	// Emit the biggest groups first; this can be changed using the sort
+	// operator
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		if c := cmp.Compare(groups[b].count, groups[a].count); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+
+	for _, k := range keys {
+		g := groups[k]
+		d := pa.New()
+		for i, f := range agg.outGroupBy {
+			if err := f.Set(d, g.keys[i]); err != nil {
+				return fmt.Errorf("setting field %q: %w", f.Name(), err)
+			}
+		}
+		for i, a := range agg.aggregations {
+			f := agg.outFields[i]
+			if a.fn == fnCount {
+				f.PutUint64(d, g.count)
+				continue
+			}
+			s := &g.stats[i]
+			if s.values != nil {
+				slices.Sort(s.values)
+			}
+			v := g.value(a, s)
+			if f.Type() == api.Kind_Int64 {
+				f.PutInt64(d, int64(v))
+			} else {
+				f.PutFloat64(d, v)
+			}
+		}
+		pa.Append(d)
+	}
+	return nil
+}
+
+// emit emits the aggregated data of the current interval
+func (agg *aggregator) emit() error {
+	pa, err := agg.outDs.NewPacketArray()
+	if err != nil {
+		return fmt.Errorf("creating packet: %w", err)
+	}
+	if err := agg.fill(pa); err != nil {
+		agg.outDs.Release(pa)
+		return err
+	}
+	return agg.outDs.EmitAndRelease(pa)
+}
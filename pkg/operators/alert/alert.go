@@ -0,0 +1,521 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package alert is a data operator that sends alerts to webhooks, Alertmanager
+// or Slack-compatible services when events match the expressions of alerting
+// rules. Rules and destinations are defined in the configuration file and
+// attached to gadget instances using an instance parameter, so they also run
+// for gadget instances running unattended.
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/config"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/expr"
+	jsonformatter "github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/formatters/json"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/common"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+)
+
+const (
+	name = "alert"
+
+	ParamAlert = "alert"
+
+	DestinationWebhook      = "webhook"
+	DestinationAlertmanager = "alertmanager"
+	DestinationSlack        = "slack"
+
+	DefaultSeverity = "warning"
+	DefaultSummary  = "{{.Rule}}: event from {{.GadgetName}} on {{.Node}}"
+	DefaultCooldown = 5 * time.Minute
+
+	// Priority is used both for the operator and its subscriptions; it's
+	// higher than the one of the filter operator to only check the filtered
+	// events
+	Priority = 9990
+
+	queueSize    = 1000
+	maxAttempts  = 3
+	flushTimeout = 10 * time.Second
+
+	// errorLogInterval is the number of errors evaluating a rule between two
+	// warnings
+	errorLogInterval = 1000
+
+	// pruneThreshold is the number of deduplication keys above which the
+	// expired ones are removed
+	pruneThreshold = 1024
+)
+
+var supportedDestinations = []string{DestinationWebhook, DestinationAlertmanager, DestinationSlack}
+
+type destinationConfig struct {
+	Type               string            `json:"type" yaml:"type"`
+	URL                string            `json:"url" yaml:"url"`
+	Headers            map[string]string `json:"headers" yaml:"headers"`
+	Timeout            time.Duration     `json:"timeout" yaml:"timeout"`
+	InsecureSkipVerify bool              `json:"insecureSkipVerify" yaml:"insecureSkipVerify"`
+}
+
+// validate checks the configuration and sets the defaults
+func (c *destinationConfig) validate() error {
+	if !slices.Contains(supportedDestinations, c.Type) {
+		return fmt.Errorf("unsupported destination type %q; expected one of %s", c.Type,
+			strings.Join(supportedDestinations, ", "))
+	}
+	if c.URL == "" {
+		return fmt.Errorf("url is required")
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	return nil
+}
+
+type ruleConfig struct {
+	// DataSource limits the rule to a data source, by default it applies to
+	// all data sources of the gadget
+	DataSource  string            `json:"dataSource" yaml:"dataSource"`
+	Expr        string            `json:"expr" yaml:"expr"`
+	Key         string            `json:"key" yaml:"key"`
+	Cooldown    time.Duration     `json:"cooldown" yaml:"cooldown"`
+	Destination string            `json:"destination" yaml:"destination"`
+	Severity    string            `json:"severity" yaml:"severity"`
+	Summary     string            `json:"summary" yaml:"summary"`
+	Labels      map[string]string `json:"labels" yaml:"labels"`
+
+	keyTmpl     *template.Template
+	summaryTmpl *template.Template
+}
+
+// validate checks the configuration and sets the defaults. Expressions are
+// compiled when the rule is attached to a gadget, as they depend on the fields
+// of its data sources.
+func (c *ruleConfig) validate(destinations map[string]*destinationConfig) error {
+	if c.Expr == "" {
+		return fmt.Errorf("expr is required")
+	}
+	if _, ok := destinations[c.Destination]; !ok {
+		return fmt.Errorf("destination not found: %q", c.Destination)
+	}
+	if c.Severity == "" {
+		c.Severity = DefaultSeverity
+	}
+	if c.Summary == "" {
+		c.Summary = DefaultSummary
+	}
+	if c.Cooldown == 0 {
+		c.Cooldown = DefaultCooldown
+	}
+
+	var err error
+	if c.keyTmpl, err = newTemplate("key", c.Key); err != nil {
+		return err
+	}
+	if c.summaryTmpl, err = newTemplate("summary", c.Summary); err != nil {
+		return err
+	}
+	return nil
+}
+
+// templateVars are the variables available in the key and summary templates
+type templateVars struct {
+	Rule       string
+	DataSource string
+	Gadget     string
+	GadgetName string
+	Instance   string
+	InstanceID string
+	Node       string
+
+	// Fields contains the fields of the event, e.g. {{.Fields.proc.comm}}
+	Fields map[string]any
+}
+
+func newTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s template %q: %w", name, text, err)
+	}
+	return tmpl, nil
+}
+
+func render(tmpl *template.Template, vars *templateVars) (string, error) {
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, vars); err != nil {
+		return "", fmt.Errorf("rendering %s template: %w", tmpl.Name(), err)
+	}
+	return b.String(), nil
+}
+
+type alertOperator struct {
+	destinations map[string]*destinationConfig
+	rules        map[string]*ruleConfig
+}
+
+func (a *alertOperator) Name() string {
+	return name
+}
+
+func (a *alertOperator) Init(params *params.Params) error {
+	a.destinations = make(map[string]*destinationConfig)
+	a.rules = make(map[string]*ruleConfig)
+
+	if config.Config == nil {
+		return nil
+	}
+
+	destinations := make(map[string]*destinationConfig)
+	log.Debugf("loading alert destinations")
+	err := config.Config.UnmarshalKey("operator.alert.destinations", &destinations)
+	if err != nil {
+		log.Warnf("failed to load operator.alert.destinations: %v", err)
+	}
+	for k, v := range destinations {
+		if err := v.validate(); err != nil {
+			return fmt.Errorf("alert destination %q: %w", k, err)
+		}
+		a.destinations[k] = v
+		log.Debugf("> destination %q of type %q loaded", k, v.Type)
+	}
+
+	rules := make(map[string]*ruleConfig)
+	log.Debugf("loading alert rules")
+	err = config.Config.UnmarshalKey("operator.alert.rules", &rules)
+	if err != nil {
+		log.Warnf("failed to load operator.alert.rules: %v", err)
+	}
+	for k, v := range rules {
+		if err := v.validate(a.destinations); err != nil {
+			return fmt.Errorf("alert rule %q: %w", k, err)
+		}
+		a.rules[k] = v
+		log.Debugf("> rule %q loaded", k)
+	}
+	return nil
+}
+
+func (a *alertOperator) GlobalParams() api.Params {
+	return nil
+}
+
+func (a *alertOperator) InstanceParams() api.Params {
+	return api.Params{
+		{
+			Key:          ParamAlert,
+			Title:        "Alert Rules",
+			Description:  "Comma-separated list of alerting rules from the configuration file to attach to the gadget",
+			DefaultValue: "",
+			TypeHint:     api.TypeString,
+			Tags:         []string{api.TagGroupDataCollection},
+		},
+	}
+}
+
+// ruleInstance is a rule attached to a data source of a gadget
+type ruleInstance struct {
+	name     string
+	cfg      *ruleConfig
+	program  func(datasource.Data) (bool, error)
+	notifier notifier
+
+	// errors counts the errors evaluating the rule for the events
+	errors atomic.Uint64
+}
+
+// warnf logs an error evaluating the rule for an event. As it can happen for
+// every event, only the first error and then every errorLogInterval errors
+// are logged.
+func (r *ruleInstance) warnf(logger logger.Logger, format string, args ...any) {
+	if n := r.errors.Add(1); n%errorLogInterval == 1 {
+		logger.Warnf("alert: rule %q: %s (%d errors so far)", r.name, fmt.Sprintf(format, args...), n)
+	}
+}
+
+func (a *alertOperator) InstantiateDataOperator(gadgetCtx operators.GadgetContext, instanceParamValues api.ParamValues) (operators.DataOperatorInstance, error) {
+	var ruleNames []string
+	for _, ruleName := range strings.Split(instanceParamValues[ParamAlert], ",") {
+		if ruleName = strings.TrimSpace(ruleName); ruleName != "" {
+			ruleNames = append(ruleNames, ruleName)
+		}
+	}
+	if len(ruleNames) == 0 {
+		return nil, nil
+	}
+
+	notifiers := make(map[string]notifier)
+	rules := make(map[datasource.DataSource][]*ruleInstance)
+	for _, ruleName := range ruleNames {
+		cfg, ok := a.rules[ruleName]
+		if !ok {
+			return nil, fmt.Errorf("alert rule not found: %q", ruleName)
+		}
+
+		n, ok := notifiers[cfg.Destination]
+		if !ok {
+			n = newNotifier(a.destinations[cfg.Destination])
+			notifiers[cfg.Destination] = n
+		}
+
+		found := false
+		for _, ds := range gadgetCtx.GetDataSources() {
+			if cfg.DataSource != "" && cfg.DataSource != ds.Name() {
+				continue
+			}
+			found = true
+
+			prog, err := expr.CompileFilterProgram(ds, cfg.Expr)
+			if err != nil {
+				return nil, fmt.Errorf("alert rule %q: compiling expression %q for data source %q: %w",
+					ruleName, cfg.Expr, ds.Name(), err)
+			}
+			rules[ds] = append(rules[ds], &ruleInstance{
+				name: ruleName,
+				cfg:  cfg,
+				program: func(data datasource.Data) (bool, error) {
+					ret, err := expr.Run(prog, data)
+					if err != nil {
+						return false, err
+					}
+					match, ok := ret.(bool)
+					if !ok {
+						return false, fmt.Errorf("expression returned non-bool value: %T", ret)
+					}
+					return match, nil
+				},
+				notifier: n,
+			})
+		}
+		if !found {
+			return nil, fmt.Errorf("alert rule %q: data source %q not found", ruleName, cfg.DataSource)
+		}
+	}
+
+	return &alertOperatorInstance{
+		rules:   rules,
+		fired:   make(map[string]time.Time),
+		now:     time.Now,
+		backoff: time.Second,
+	}, nil
+}
+
+func (a *alertOperator) Priority() int {
+	return Priority
+}
+
+// pendingAlert is an alert waiting to be sent
+type pendingAlert struct {
+	notifier notifier
+	alert    *alert
+}
+
+type alertOperatorInstance struct {
+	rules map[datasource.DataSource][]*ruleInstance
+
+	// fired contains the time until which alerts with the same rule and
+	// deduplication key are suppressed
+	firedMu sync.Mutex
+	fired   map[string]time.Time
+
+	queue *common.DeliveryQueue[*pendingAlert]
+
+	now     func() time.Time
+	backoff time.Duration
+	logger  logger.Logger
+}
+
+func (a *alertOperatorInstance) Name() string {
+	return name
+}
+
+// suppress reports whether an alert for the rule and key was already fired
+// during the cooldown, and records it otherwise
+func (a *alertOperatorInstance) suppress(rule *ruleInstance, key string) bool {
+	if rule.cfg.Cooldown < 0 {
+		return false
+	}
+
+	a.firedMu.Lock()
+	defer a.firedMu.Unlock()
+
+	now := a.now()
+	id := rule.name + "\x00" + key
+	if until, ok := a.fired[id]; ok && now.Before(until) {
+		return true
+	}
+
+	if len(a.fired) >= pruneThreshold {
+		for k, until := range a.fired {
+			if !now.Before(until) {
+				delete(a.fired, k)
+			}
+		}
+	}
+	a.fired[id] = now.Add(rule.cfg.Cooldown)
+	return false
+}
+
+// eventFields returns the fields of the event as nested maps, like -o json
+func eventFields(formatter *jsonformatter.Formatter, data datasource.Data) (map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(formatter.Marshal(data)))
+	// Keep 64 bit integers as they are
+	dec.UseNumber()
+
+	var fields map[string]any
+	if err := dec.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("decoding event: %w", err)
+	}
+	return fields, nil
+}
+
+func (a *alertOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
+	a.logger = gadgetCtx.Logger()
+	node := common.NodeName()
+
+	// Alerts are dropped if the destinations can't keep up, to not block the
+	// data sources
+	a.queue = common.NewDeliveryQueue(common.DeliveryQueueConfig{
+		Name:         name,
+		Size:         queueSize,
+		MaxAttempts:  maxAttempts,
+		RetryBackoff: a.backoff,
+		FlushTimeout: flushTimeout,
+	}, a.send, a.logger)
+
+	for ds, rules := range a.rules {
+		formatter, err := jsonformatter.New(ds, jsonformatter.WithShowAll(true))
+		if err != nil {
+			return fmt.Errorf("creating json formatter for data source %q: %w", ds.Name(), err)
+		}
+
+		ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+			var fields map[string]any
+			for _, rule := range rules {
+				match, err := rule.program(data)
+				if err != nil {
+					rule.warnf(a.logger, "running expression for data source %q: %v", ds.Name(), err)
+					continue
+				}
+				if !match {
+					continue
+				}
+
+				if fields == nil {
+					fields, err = eventFields(formatter, data)
+					if err != nil {
+						a.logger.Warnf("alert: data source %q: %v", ds.Name(), err)
+						return nil
+					}
+				}
+
+				vars := &templateVars{
+					Rule:       rule.name,
+					DataSource: ds.Name(),
+					Gadget:     gadgetCtx.ImageName(),
+					GadgetName: common.GadgetShortName(gadgetCtx.ImageName()),
+					Instance:   gadgetCtx.Name(),
+					InstanceID: gadgetCtx.ID(),
+					Node:       node,
+					Fields:     fields,
+				}
+
+				key, err := render(rule.cfg.keyTmpl, vars)
+				if err != nil {
+					rule.warnf(a.logger, "%v", err)
+				}
+				if a.suppress(rule, key) {
+					continue
+				}
+
+				summary, err := render(rule.cfg.summaryTmpl, vars)
+				if err != nil {
+					rule.warnf(a.logger, "%v", err)
+					summary = rule.name
+				}
+
+				a.queue.Enqueue(&pendingAlert{
+					notifier: rule.notifier,
+					alert: &alert{
+						Rule:       rule.name,
+						Key:        key,
+						Severity:   rule.cfg.Severity,
+						Summary:    summary,
+						Labels:     rule.cfg.Labels,
+						DataSource: ds.Name(),
+						Gadget:     vars.Gadget,
+						Instance:   vars.Instance,
+						InstanceID: vars.InstanceID,
+						Node:       node,
+						Timestamp:  a.now(),
+						Event:      fields,
+					},
+				})
+			}
+			return nil
+		}, Priority)
+	}
+
+	return nil
+}
+
+// send sends an alert; the queue delivers them one by one
+func (a *alertOperatorInstance) send(ctx context.Context, alerts []*pendingAlert) error {
+	for _, p := range alerts {
+		if err := p.notifier.Notify(ctx, p.alert); err != nil {
+			return fmt.Errorf("sending alert of rule %q: %w", p.alert.Rule, err)
+		}
+		a.logger.Debugf("alert: rule %q fired with key %q", p.alert.Rule, p.alert.Key)
+	}
+	return nil
+}
+
+func (a *alertOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func (a *alertOperatorInstance) Stop(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+// Close sends the queued alerts; alerts not sent before the flush timeout are
+// dropped
+func (a *alertOperatorInstance) Close(gadgetCtx operators.GadgetContext) error {
+	if a.queue != nil {
+		a.queue.Close()
+	}
+	return nil
+}
+
+var Operator = &alertOperator{}
+
+func init() {
+	operators.RegisterDataOperator(Operator)
+}
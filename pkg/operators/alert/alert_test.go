@@ -0,0 +1,387 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/testing/gadget-context"
+)
+
+// recorder is an HTTP server recording the bodies of the requests. The first
+// failures requests fail.
+type recorder struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	paths    []string
+	bodies   []string
+	headers  []http.Header
+	failures int
+}
+
+func newRecorder(t *testing.T) *recorder {
+	r := &recorder{}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.failures > 0 {
+			r.failures--
+			http.Error(w, "try again later", http.StatusServiceUnavailable)
+			return
+		}
+		r.paths = append(r.paths, req.URL.Path)
+		r.bodies = append(r.bodies, string(body))
+		r.headers = append(r.headers, req.Header.Clone())
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *recorder) requests() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.bodies...)
+}
+
+func testAlert() *alert {
+	return &alert{
+		Rule:       "netcat",
+		Key:        "prod/nc",
+		Severity:   "critical",
+		Summary:    "nc executed in prod",
+		Labels:     map[string]string{"team": "security"},
+		DataSource: "exec",
+		Gadget:     "trace_exec",
+		Node:       "node1",
+		Timestamp:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Event: map[string]any{
+			"proc": map[string]any{"comm": "nc", "pid": json.Number("42")},
+			"k8s":  map[string]any{"namespace": "prod"},
+		},
+	}
+}
+
+func TestDestinationValidate(t *testing.T) {
+	cfg := &destinationConfig{Type: DestinationSlack, URL: "http://127.0.0.1"}
+	require.NoError(t, cfg.validate())
+	assert.Equal(t, 10*time.Second, cfg.Timeout)
+
+	for _, cfg := range []*destinationConfig{
+		{Type: "pagerduty", URL: "http://127.0.0.1"},
+		{Type: DestinationWebhook},
+	} {
+		require.Error(t, cfg.validate(), "%+v", cfg)
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	destinations := map[string]*destinationConfig{"hook": {}}
+
+	cfg := &ruleConfig{Expr: `proc.comm == "nc"`, Destination: "hook"}
+	require.NoError(t, cfg.validate(destinations))
+	assert.Equal(t, DefaultSeverity, cfg.Severity)
+	assert.Equal(t, DefaultCooldown, cfg.Cooldown)
+
+	for _, cfg := range []*ruleConfig{
+		{Destination: "hook"},
+		{Expr: "true", Destination: "other"},
+		{Expr: "true", Destination: "hook", Key: "{{.Fields"},
+		{Expr: "true", Destination: "hook", Summary: "{{end}}"},
+	} {
+		require.Error(t, cfg.validate(destinations), "%+v", cfg)
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		"proc.comm":     "nc",
+		"proc.pid":      "42",
+		"k8s.namespace": "prod",
+	}, flatten(testAlert().Event))
+	assert.Equal(t, "k8s_namespace", labelName("k8s.namespace"))
+	assert.Equal(t, "_1st", labelName("1st"))
+}
+
+func TestNotifiers(t *testing.T) {
+	for _, tc := range []struct {
+		typ      string
+		url      string
+		path     string
+		expected string
+	}{
+		{
+			typ:  DestinationWebhook,
+			url:  "/hooks/ig",
+			path: "/hooks/ig",
+			expected: `{
+				"rule": "netcat",
+				"key": "prod/nc",
+				"severity": "critical",
+				"summary": "nc executed in prod",
+				"labels": {"team": "security"},
+				"dataSource": "exec",
+				"gadget": "trace_exec",
+				"node": "node1",
+				"timestamp": "2026-01-02T03:04:05Z",
+				"event": {"proc": {"comm": "nc", "pid": 42}, "k8s": {"namespace": "prod"}}
+			}`,
+		},
+		{
+			typ:  DestinationAlertmanager,
+			url:  "/",
+			path: "/api/v2/alerts",
+			expected: `[{
+				"labels": {
+					"alertname": "netcat",
+					"severity": "critical",
+					"ig_gadget": "trace_exec",
+					"ig_datasource": "exec",
+					"ig_node": "node1",
+					"ig_key": "prod/nc",
+					"team": "security"
+				},
+				"annotations": {
+					"summary": "nc executed in prod",
+					"proc_comm": "nc",
+					"proc_pid": "42",
+					"k8s_namespace": "prod"
+				},
+				"startsAt": "2026-01-02T03:04:05Z"
+			}]`,
+		},
+		{
+			typ:  DestinationSlack,
+			url:  "/services/T000/B000/XXX",
+			path: "/services/T000/B000/XXX",
+			expected: `{
+				"text": "*[CRITICAL] nc executed in prod*",
+				"attachments": [{
+					"color": "danger",
+					"fields": [
+						{"title": "k8s.namespace", "value": "prod", "short": true},
+						{"title": "proc.comm", "value": "nc", "short": true},
+						{"title": "proc.pid", "value": "42", "short": true}
+					],
+					"footer": "Inspektor Gadget | trace_exec | node1",
+					"ts": 1767323045
+				}]
+			}`,
+		},
+	} {
+		t.Run(tc.typ, func(t *testing.T) {
+			r := newRecorder(t)
+			cfg := &destinationConfig{
+				Type:    tc.typ,
+				URL:     r.URL + tc.url,
+				Headers: map[string]string{"Authorization": "Bearer secret"},
+			}
+			require.NoError(t, cfg.validate())
+
+			require.NoError(t, newNotifier(cfg).Notify(context.Background(), testAlert()))
+			require.Len(t, r.bodies, 1)
+			assert.Equal(t, tc.path, r.paths[0])
+			assert.Equal(t, "Bearer secret", r.headers[0].Get("Authorization"))
+			assert.Equal(t, "application/json", r.headers[0].Get("Content-Type"))
+			assert.JSONEq(t, tc.expected, r.bodies[0])
+		})
+	}
+}
+
+func TestNotifierError(t *testing.T) {
+	r := newRecorder(t)
+	r.failures = 1
+
+	cfg := &destinationConfig{Type: DestinationWebhook, URL: r.URL}
+	require.NoError(t, cfg.validate())
+	err := newNotifier(cfg).Notify(context.Background(), testAlert())
+	require.ErrorContains(t, err, "503")
+	require.ErrorContains(t, err, "try again later")
+}
+
+func newTestOperator(t *testing.T, url string, rules map[string]*ruleConfig) *alertOperator {
+	t.Helper()
+
+	op := &alertOperator{
+		destinations: map[string]*destinationConfig{
+			"hook": {Type: DestinationWebhook, URL: url},
+		},
+		rules: rules,
+	}
+	require.NoError(t, op.destinations["hook"].validate())
+	for _, rule := range rules {
+		require.NoError(t, rule.validate(op.destinations))
+	}
+	return op
+}
+
+func TestAlertOperator(t *testing.T) {
+	r := newRecorder(t)
+	// The first alert is sent on the second attempt
+	r.failures = 1
+
+	ds, err := datasource.New(datasource.TypeSingle, "exec")
+	require.NoError(t, err)
+	commField, err := ds.AddField("comm", api.Kind_String)
+	require.NoError(t, err)
+	nsField, err := ds.AddField("namespace", api.Kind_String)
+	require.NoError(t, err)
+
+	op := newTestOperator(t, r.URL, map[string]*ruleConfig{
+		"netcat": {
+			Expr:        `comm == "nc" && namespace startsWith "prod"`,
+			Key:         "{{.Fields.namespace}}",
+			Destination: "hook",
+			Summary:     "{{.Fields.comm}} executed in {{.Fields.namespace}}",
+		},
+		"unused": {Expr: "true", Destination: "hook"},
+	})
+	gadgetCtx := &gadgetcontext.MockGadgetContext{
+		Ctx:         context.Background(),
+		DataSources: map[string]datasource.DataSource{"exec": ds},
+	}
+
+	_, err = op.InstantiateDataOperator(gadgetCtx, api.ParamValues{ParamAlert: "missing"})
+	require.ErrorContains(t, err, "alert rule not found")
+
+	inst, err := op.InstantiateDataOperator(gadgetCtx, api.ParamValues{ParamAlert: "netcat"})
+	require.NoError(t, err)
+	alertInst := inst.(*alertOperatorInstance)
+	alertInst.backoff = time.Millisecond
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	alertInst.now = func() time.Time { return now }
+
+	require.NoError(t, alertInst.PreStart(gadgetCtx))
+
+	emit := func(comm, namespace string) {
+		packet, err := ds.NewPacketSingle()
+		require.NoError(t, err)
+		require.NoError(t, commField.PutString(packet, comm))
+		require.NoError(t, nsField.PutString(packet, namespace))
+		require.NoError(t, ds.EmitAndRelease(packet))
+	}
+
+	emit("nc", "prod-eu")
+	emit("ls", "prod-eu")
+	emit("nc", "staging")
+	// Deduplicated during the cooldown
+	emit("nc", "prod-eu")
+	emit("nc", "prod-us")
+
+	now = now.Add(DefaultCooldown)
+	emit("nc", "prod-eu")
+
+	require.NoError(t, alertInst.Close(gadgetCtx))
+
+	bodies := r.requests()
+	require.Len(t, bodies, 3)
+
+	var keys []string
+	for _, body := range bodies {
+		var a alert
+		require.NoError(t, json.Unmarshal([]byte(body), &a))
+		assert.Equal(t, "netcat", a.Rule)
+		assert.Equal(t, "exec", a.DataSource)
+		assert.Equal(t, "test-image", a.Gadget)
+		assert.Equal(t, "test-id", a.InstanceID)
+		assert.Equal(t, "nc", a.Event["comm"])
+		assert.Equal(t, "nc executed in "+a.Key, a.Summary)
+		keys = append(keys, a.Key)
+	}
+	assert.Equal(t, []string{"prod-eu", "prod-us", "prod-eu"}, keys)
+}
+
+func TestAlertOperatorDataSource(t *testing.T) {
+	ds, err := datasource.New(datasource.TypeSingle, "exec")
+	require.NoError(t, err)
+	_, err = ds.AddField("comm", api.Kind_String)
+	require.NoError(t, err)
+	gadgetCtx := &gadgetcontext.MockGadgetContext{
+		Ctx:         context.Background(),
+		DataSources: map[string]datasource.DataSource{"exec": ds},
+	}
+
+	op := newTestOperator(t, "http://127.0.0.1", map[string]*ruleConfig{
+		"other":   {DataSource: "dns", Expr: "true", Destination: "hook"},
+		"badexpr": {Expr: "qtype == 1", Destination: "hook"},
+	})
+
+	_, err = op.InstantiateDataOperator(gadgetCtx, api.ParamValues{ParamAlert: "other"})
+	require.ErrorContains(t, err, `data source "dns" not found`)
+	_, err = op.InstantiateDataOperator(gadgetCtx, api.ParamValues{ParamAlert: "badexpr"})
+	require.ErrorContains(t, err, "compiling expression")
+
+	inst, err := op.InstantiateDataOperator(gadgetCtx, api.ParamValues{})
+	require.NoError(t, err)
+	require.Nil(t, inst)
+}
+
+func TestAlertOperatorExpressionErrors(t *testing.T) {
+	ds, err := datasource.New(datasource.TypeSingle, "exec")
+	require.NoError(t, err)
+	commField, err := ds.AddField("comm", api.Kind_String)
+	require.NoError(t, err)
+
+	log, hook := logrustest.NewNullLogger()
+	gadgetCtx := &gadgetcontext.MockGadgetContext{
+		Ctx:         context.Background(),
+		DataSources: map[string]datasource.DataSource{"exec": ds},
+		Log:         log,
+	}
+
+	// The expression compiles but fails for every event
+	op := newTestOperator(t, "http://127.0.0.1", map[string]*ruleConfig{
+		"badint": {Expr: "int(comm) > 0", Destination: "hook"},
+	})
+	inst, err := op.InstantiateDataOperator(gadgetCtx, api.ParamValues{ParamAlert: "badint"})
+	require.NoError(t, err)
+	alertInst := inst.(*alertOperatorInstance)
+	require.NoError(t, alertInst.PreStart(gadgetCtx))
+
+	for i := 0; i < 10; i++ {
+		packet, err := ds.NewPacketSingle()
+		require.NoError(t, err)
+		require.NoError(t, commField.PutString(packet, "nc"))
+		require.NoError(t, ds.EmitAndRelease(packet))
+	}
+	require.NoError(t, alertInst.Close(gadgetCtx))
+
+	// Only the first error is logged
+	var warnings int
+	for _, entry := range hook.AllEntries() {
+		if entry.Level == logrus.WarnLevel {
+			warnings++
+		}
+	}
+	assert.Equal(t, 1, warnings)
+	assert.Equal(t, uint64(10), alertInst.rules[ds][0].errors.Load())
+}
@@ -0,0 +1,258 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alert
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const maxErrorBody = 512
+
+// alert is a fired alert, passed to the notifiers
+type alert struct {
+	Rule       string            `json:"rule"`
+	Key        string            `json:"key"`
+	Severity   string            `json:"severity"`
+	Summary    string            `json:"summary"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	DataSource string            `json:"dataSource"`
+	Gadget     string            `json:"gadget"`
+	Instance   string            `json:"instance,omitempty"`
+	InstanceID string            `json:"instanceID,omitempty"`
+	Node       string            `json:"node"`
+	Timestamp  time.Time         `json:"timestamp"`
+
+	// Event contains the fields of the matching event, as printed by -o json
+	Event map[string]any `json:"event"`
+}
+
+// notifier sends alerts to a destination
+type notifier interface {
+	Notify(ctx context.Context, a *alert) error
+}
+
+func newNotifier(cfg *destinationConfig) notifier {
+	client := &http.Client{Timeout: cfg.Timeout}
+	if cfg.InsecureSkipVerify {
+		client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}
+	}
+
+	n := &httpNotifier{
+		client:  client,
+		url:     cfg.URL,
+		headers: cfg.Headers,
+	}
+	switch cfg.Type {
+	case DestinationAlertmanager:
+		n.url = strings.TrimSuffix(cfg.URL, "/")
+		if !strings.HasSuffix(n.url, alertmanagerAlertsPath) {
+			n.url += alertmanagerAlertsPath
+		}
+		n.encode = encodeAlertmanager
+	case DestinationSlack:
+		n.encode = encodeSlack
+	default:
+		n.encode = encodeWebhook
+	}
+	return n
+}
+
+// httpNotifier posts alerts as JSON, encoded by encode
+type httpNotifier struct {
+	client  *http.Client
+	url     string
+	headers map[string]string
+	encode  func(a *alert) any
+}
+
+func (n *httpNotifier) Notify(ctx context.Context, a *alert) error {
+	body, err := json.Marshal(n.encode(a))
+	if err != nil {
+		return fmt.Errorf("encoding alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range n.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending alert: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("sending alert: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func encodeWebhook(a *alert) any {
+	return a
+}
+
+const alertmanagerAlertsPath = "/api/v2/alerts"
+
+// alertmanagerAlert is an alert of the Alertmanager v2 API
+type alertmanagerAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+}
+
+// encodeAlertmanager returns the alert for the Alertmanager v2 API. Only
+// low-cardinality values are used as labels, the fields of the event are
+// added as annotations.
+func encodeAlertmanager(a *alert) any {
+	labels := map[string]string{
+		"alertname":     a.Rule,
+		"severity":      a.Severity,
+		"ig_gadget":     a.Gadget,
+		"ig_datasource": a.DataSource,
+		"ig_node":       a.Node,
+		"ig_key":        a.Key,
+	}
+	if a.Instance != "" {
+		labels["ig_instance"] = a.Instance
+	}
+	for k, v := range a.Labels {
+		labels[labelName(k)] = v
+	}
+
+	annotations := map[string]string{
+		"summary": a.Summary,
+	}
+	for k, v := range flatten(a.Event) {
+		annotations[labelName(k)] = v
+	}
+
+	return []alertmanagerAlert{{
+		Labels:      labels,
+		Annotations: annotations,
+		StartsAt:    a.Timestamp,
+	}}
+}
+
+// labelName returns a valid Prometheus label name for a field name, e.g.
+// "k8s_namespace" for "k8s.namespace"
+func labelName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+type slackMessage struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments,omitempty"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color,omitempty"`
+	Fields []slackField `json:"fields,omitempty"`
+	Footer string       `json:"footer,omitempty"`
+	TS     int64        `json:"ts,omitempty"`
+}
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+var slackColors = map[string]string{
+	"critical": "danger",
+	"error":    "danger",
+	"warning":  "warning",
+	"info":     "good",
+}
+
+// encodeSlack returns a message for Slack incoming webhooks, also accepted
+// by compatible services like Mattermost or Rocket.Chat
+func encodeSlack(a *alert) any {
+	fields := flatten(a.Event)
+
+	attachment := slackAttachment{
+		Color:  slackColors[a.Severity],
+		Footer: fmt.Sprintf("Inspektor Gadget | %s | %s", a.Gadget, a.Node),
+		TS:     a.Timestamp.Unix(),
+	}
+	for _, k := range slices.Sorted(maps.Keys(fields)) {
+		attachment.Fields = append(attachment.Fields, slackField{
+			Title: k,
+			Value: fields[k],
+			Short: len(fields[k]) <= 40,
+		})
+	}
+
+	return &slackMessage{
+		Text:        fmt.Sprintf("*[%s] %s*", strings.ToUpper(a.Severity), a.Summary),
+		Attachments: []slackAttachment{attachment},
+	}
+}
+
+// flatten returns the values of a JSON event using the full names of the
+// fields as keys, e.g. "k8s.namespace"
+func flatten(event map[string]any) map[string]string {
+	ret := make(map[string]string)
+	var walk func(prefix string, v any)
+	walk = func(prefix string, v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for k, sv := range v {
+				if prefix != "" {
+					k = prefix + "." + k
+				}
+				walk(k, sv)
+			}
+		case string:
+			ret[prefix] = v
+		case nil:
+			ret[prefix] = ""
+		default:
+			b, _ := json.Marshal(v)
+			ret[prefix] = string(b)
+		}
+	}
+	walk("", event)
+	return ret
+}
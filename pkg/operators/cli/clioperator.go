@@ -15,15 +15,22 @@
 package clioperator
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/hex"
+	gojson "encoding/json"
 	"fmt"
 	"io"
 	"net/url"
 	"os"
+	"reflect"
 	"runtime"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"github.com/gopacket/gopacket"
@@ -56,6 +63,11 @@ const (
 	ModeNone       = "none"
 	ModeRaw        = "raw"
 	ModePCAPNG     = "pcap-ng"
+	ModeArrow      = "arrow"
+	ModeParquet    = "parquet"
+	ModeTUI        = "tui"
+	ModeTemplate   = "template"
+	ModeCSV        = "csv"
 
 	DefaultOutputMode = ModeColumns
 
@@ -89,10 +101,32 @@ const (
 )
 
 var (
-	DefaultSupportedOutputModes = []string{ModeColumns, ModeJSON, ModeJSONPretty, ModeNone, ModeYAML}
+	DefaultSupportedOutputModes = []string{ModeColumns, ModeCSV, ModeJSON, ModeJSONPretty, ModeNone, ModeTemplate, ModeYAML}
 	cliWriteMutex               = sync.Mutex{}
+
+	newColumnarWriter NewColumnarWriterFunc
 )
 
+// ColumnarWriter writes the data of a data source in a binary columnar format
+type ColumnarWriter interface {
+	AppendData(data datasource.Data) error
+	AppendArray(dataArray datasource.DataArray) error
+	// Close writes the buffered data; further data is ignored
+	Close() error
+}
+
+// NewColumnarWriterFunc creates a ColumnarWriter for the given mode writing to
+// w. lock must be held while writing to w.
+type NewColumnarWriterFunc func(ds datasource.DataSource, mode string, w io.Writer, lock sync.Locker) (ColumnarWriter, error)
+
+// RegisterColumnarWriter enables the arrow and parquet output modes. It's
+// called by the columnar package, which isn't imported by default to keep
+// their dependencies out of the binaries that don't need them.
+func RegisterColumnarWriter(f NewColumnarWriterFunc) {
+	newColumnarWriter = f
+	DefaultSupportedOutputModes = append(DefaultSupportedOutputModes, ModeArrow, ModeParquet)
+}
+
 type cliOperator struct{}
 
 func (o *cliOperator) Name() string {
@@ -131,6 +165,9 @@ type cliOperatorInstance struct {
 	supportedOutputModes map[string][]string
 	// key: datasource name, value: default output mode
 	defaultOutputMode map[string]string
+	// columnarWriters are flushed and closed when stopping
+	columnarWriters []ColumnarWriter
+	tuiTerminal     *tuiTerminal
 }
 
 func (o *cliOperatorInstance) Name() string {
@@ -213,9 +250,12 @@ func (o *cliOperatorInstance) ExtraParams(gadgetCtx operators.GadgetContext) api
 		fieldsDescriptions = append(fieldsDescriptions, sb.String())
 
 		// Supported output modes
-		supportedOutputs := DefaultSupportedOutputModes
+		supportedOutputs := slices.Clone(DefaultSupportedOutputModes)
 		if supportedOutputsAnnotated, ok := ds.Annotations()[AnnotationSupportedOutputModes]; ok {
 			supportedOutputs = strings.Split(supportedOutputsAnnotated, ",")
+		} else if ds.Type() == datasource.TypeArray {
+			// The TUI shows snapshots, so it's only useful for array data sources
+			supportedOutputs = append(supportedOutputs, ModeTUI)
 		}
 		sort.Strings(supportedOutputs)
 		o.supportedOutputModes[ds.Name()] = supportedOutputs
@@ -264,7 +304,10 @@ func (o *cliOperatorInstance) ExtraParams(gadgetCtx operators.GadgetContext) api
 	return api.Params{fields, mode}
 }
 
-func parseFields(fieldsString string, defaultFields []string) []string {
+// ParseFields returns the list of fields to show from a comma-separated list of
+// fields; fields prefixed with '+' or '-' are added to or removed from
+// defaultFields, otherwise the list replaces defaultFields
+func ParseFields(fieldsString string, defaultFields []string) []string {
 	fields := strings.Split(fieldsString, ",")
 
 	addedFields := make([]string, 0, len(fields))
@@ -305,6 +348,55 @@ func parseFields(fieldsString string, defaultFields []string) []string {
 	return result
 }
 
+// ParseOutputModes parses the value of the output param, like "mode" or "datasource:mode,datasource2:mode2", and
+// returns the output mode per data source. The template mode takes the template as value, like
+// "template={{.proc.comm}}"; as templates can contain any character, the template needs to be the last entry. The
+// templates are returned separately, using the same keys as the output modes.
+func ParseOutputModes(s string) (modes map[string]string, templates map[string]string, err error) {
+	templates = make(map[string]string)
+
+	var tmplText, templateDs string
+	hasTemplate := false
+	if idx := strings.Index(s, ModeTemplate+"="); idx >= 0 {
+		hasTemplate = true
+		tmplText = s[idx+len(ModeTemplate)+1:]
+		s = s[:idx]
+		// s now ends with "", "datasource:", "mode," or "mode,datasource:"
+		if sep := strings.LastIndex(s, ","); sep >= 0 {
+			templateDs = s[sep+1:]
+			s = s[:sep]
+		} else {
+			templateDs = s
+			s = ""
+		}
+		templateDs = strings.TrimSuffix(templateDs, ":")
+		if tmplText == "" {
+			return nil, nil, fmt.Errorf("empty template")
+		}
+	}
+
+	modes, err = apihelpers.GetStringValuesPerDataSource(s)
+	if err != nil {
+		return nil, nil, err
+	}
+	for dsName, mode := range modes {
+		if mode == ModeTemplate {
+			return nil, nil, fmt.Errorf("output mode %q requires a template, like %s='{{.field}}'", ModeTemplate, ModeTemplate)
+		}
+		if hasTemplate && (dsName == "") != (templateDs == "") {
+			return nil, nil, fmt.Errorf("mixed values with and without specifying data source")
+		}
+	}
+	if hasTemplate {
+		if _, ok := modes[templateDs]; ok {
+			return nil, nil, fmt.Errorf("multiple output modes for data source %q", templateDs)
+		}
+		modes[templateDs] = ModeTemplate
+		templates[templateDs] = tmplText
+	}
+	return modes, templates, nil
+}
+
 func (o *cliOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
 	params := apihelpers.ToParamDescs(o.ExtraParams(gadgetCtx)).ToParams()
 	params.CopyFromMap(o.paramValues, "")
@@ -322,11 +414,14 @@ func (o *cliOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error
 		fieldLookup[dsName] = dsFields
 	}
 
-	modes, err := apihelpers.GetStringValuesPerDataSource(params.Get(ParamMode).AsString())
+	modes, templates, err := ParseOutputModes(params.Get(ParamMode).AsString())
 	if err != nil {
 		return fmt.Errorf("parsing default output modes: %w", err)
 	}
 
+	// Binary output modes write a stream with its own framing to stdout, so only one data source can use them
+	columnarDataSource := ""
+
 	for _, ds := range gadgetCtx.GetDataSources() {
 		gadgetCtx.Logger().Debugf("subscribing to %s", ds.Name())
 
@@ -380,7 +475,7 @@ func (o *cliOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error
 			formatter := p.GetTextColumnsFormatter()
 
 			if hasFields {
-				parsedFields := parseFields(fields, defCols)
+				parsedFields := ParseFields(fields, defCols)
 				err = formatter.SetShowColumns(parsedFields)
 				if err != nil {
 					gadgetCtx.Logger().Warnf("failed to set fields: %v; skipping data source %q", err, ds.Name())
@@ -606,6 +701,98 @@ func (o *cliOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error
 				wr.Flush()
 				return nil
 			}, Priority)
+		case ModeTemplate:
+			tmplText, ok := templates[ds.Name()]
+			if !ok {
+				tmplText = templates[""]
+			}
+			tmpl, err := newTemplate(ds, tmplText)
+			if err != nil {
+				return fmt.Errorf("parsing template for data source %q: %w", ds.Name(), err)
+			}
+			ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+				if err := templateDataFn(ds, data, tmpl, os.Stdout); err != nil {
+					gadgetCtx.Logger().Warnf("failed to execute template for data source %q: %v", ds.Name(), err)
+				}
+				return nil
+			}, Priority)
+		case ModeCSV:
+			p, err := ds.Parser()
+			if err != nil {
+				gadgetCtx.Logger().Warnf("failed to get parser: %v; skipping data source %q", err, ds.Name())
+				continue
+			}
+			csvFields := p.GetDefaultColumns()
+			if hasFields {
+				csvFields = ParseFields(fields, csvFields)
+			}
+			csvFormatter, err := newCSVFormatter(ds, csvFields)
+			if err != nil {
+				return fmt.Errorf("creating CSV formatter for data source %q: %w", ds.Name(), err)
+			}
+			csvHeaderFn(csvFormatter, os.Stdout)
+			ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+				csvDataFn(data, csvFormatter, os.Stdout)
+				return nil
+			}, Priority)
+		case ModeTUI:
+			if ds.Type() != datasource.TypeArray {
+				return fmt.Errorf("output mode %q can only be used for array data sources", mode)
+			}
+			if o.tuiTerminal != nil {
+				return fmt.Errorf("output mode %q can only be used for a single data source", mode)
+			}
+
+			t, err := newTUI(ds, fields, hasFields, gadgetCtx.Cancel)
+			if err != nil {
+				return fmt.Errorf("creating TUI for data source %q: %w", ds.Name(), err)
+			}
+			tt, err := newTUITerminal(t)
+			if err != nil {
+				return err
+			}
+			o.tuiTerminal = tt
+
+			ds.SubscribeArray(func(ds datasource.DataSource, dataArray datasource.DataArray) error {
+				if err := t.update(dataArray); err != nil {
+					gadgetCtx.Logger().Warnf("failed to update TUI: %v", err)
+					return nil
+				}
+				tt.render()
+				return nil
+			}, Priority)
+		case ModeArrow, ModeParquet:
+			if newColumnarWriter == nil {
+				return fmt.Errorf("output mode %q is not available in this binary", mode)
+			}
+			if columnarDataSource != "" {
+				return fmt.Errorf("output mode %q can only be used for a single data source; %q and %q use a binary output mode",
+					mode, columnarDataSource, ds.Name())
+			}
+			columnarDataSource = ds.Name()
+
+			cw, err := newColumnarWriter(ds, mode, os.Stdout, &cliWriteMutex)
+			if err != nil {
+				return fmt.Errorf("creating %s writer for data source %q: %w", mode, ds.Name(), err)
+			}
+			o.columnarWriters = append(o.columnarWriters, cw)
+
+			switch ds.Type() {
+			case datasource.TypeSingle:
+				ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+					if err := cw.AppendData(data); err != nil {
+						gadgetCtx.Logger().Warnf("failed to write data of data source %q: %v", ds.Name(), err)
+					}
+					return nil
+				}, Priority)
+			case datasource.TypeArray:
+				ds.SubscribeArray(func(ds datasource.DataSource, dataArray datasource.DataArray) error {
+					if err := cw.AppendArray(dataArray); err != nil {
+						gadgetCtx.Logger().Warnf("failed to write data of data source %q: %v", ds.Name(), err)
+					}
+					return nil
+				}, Priority)
+			}
 		}
 
 	}
@@ -644,11 +831,233 @@ func jsonArrayDataFn(dataArray datasource.DataArray, jsonFormatter *json.Formatt
 	fmt.Fprintln(w, string(jsonFormatter.MarshalArray(dataArray)))
 }
 
+// fieldValue returns the value of a field as a Go value: numbers and booleans keep their type, arrays of numbers
+// become slices and bytes are encoded as hex, like in the JSON output
+func fieldValue(f datasource.FieldAccessor, data datasource.Data) any {
+	var v any
+	var err error
+	switch f.Type() {
+	case api.Kind_Bool:
+		v, err = f.Bool(data)
+	case api.Kind_Int8:
+		v, err = f.Int8(data)
+	case api.Kind_Int16:
+		v, err = f.Int16(data)
+	case api.Kind_Int32:
+		v, err = f.Int32(data)
+	case api.Kind_Int64:
+		v, err = f.Int64(data)
+	case api.Kind_Uint8:
+		v, err = f.Uint8(data)
+	case api.Kind_Uint16:
+		v, err = f.Uint16(data)
+	case api.Kind_Uint32:
+		v, err = f.Uint32(data)
+	case api.Kind_Uint64:
+		v, err = f.Uint64(data)
+	case api.Kind_Float32:
+		v, err = f.Float32(data)
+	case api.Kind_Float64:
+		v, err = f.Float64(data)
+	case api.Kind_String, api.Kind_CString:
+		v, err = f.String(data)
+	case api.ArrayOf(api.Kind_Int8):
+		v, err = f.Int8Array(data)
+	case api.ArrayOf(api.Kind_Int16):
+		v, err = f.Int16Array(data)
+	case api.ArrayOf(api.Kind_Int32):
+		v, err = f.Int32Array(data)
+	case api.ArrayOf(api.Kind_Int64):
+		v, err = f.Int64Array(data)
+	case api.ArrayOf(api.Kind_Uint8):
+		v, err = f.Uint8Array(data)
+	case api.ArrayOf(api.Kind_Uint16):
+		v, err = f.Uint16Array(data)
+	case api.ArrayOf(api.Kind_Uint32):
+		v, err = f.Uint32Array(data)
+	case api.ArrayOf(api.Kind_Uint64):
+		v, err = f.Uint64Array(data)
+	case api.ArrayOf(api.Kind_Float32):
+		v, err = f.Float32Array(data)
+	case api.ArrayOf(api.Kind_Float64):
+		v, err = f.Float64Array(data)
+	default:
+		v = hex.EncodeToString(f.Get(data))
+	}
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+// newTemplate parses a Go template for the data of ds; fields are accessed by their full name, like
+// {{.proc.comm}}, and fields that aren't valid identifiers with index, like {{index . "my-field"}}
+func newTemplate(ds datasource.DataSource, text string) (*template.Template, error) {
+	// Data is written line by line
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return template.New(ds.Name()).Option("missingkey=error").Parse(text)
+}
+
+// templateFields holds the values of the sub-fields of a field. Fields replaced by another one when printing them,
+// like endpoints by their text representation, are printed like that field.
+type templateFields map[string]any
+
+// templateReplacementKey stores the value of the replacement field; field names can't be empty, so it doesn't clash
+// with them
+const templateReplacementKey = ""
+
+func (m templateFields) String() string {
+	if v, ok := m[templateReplacementKey]; ok {
+		return fmt.Sprint(v)
+	}
+	return fmt.Sprint(map[string]any(m))
+}
+
+// templateValues returns the values of the given fields and all their sub-fields as nested maps
+func templateValues(ds datasource.DataSource, accessors []datasource.FieldAccessor, data datasource.Data) templateFields {
+	values := make(templateFields, len(accessors))
+	for _, f := range accessors {
+		if datasource.FieldFlagUnreferenced.In(f.Flags()) {
+			continue
+		}
+		if subFields := f.SubFields(); len(subFields) > 0 {
+			subValues := templateValues(ds, subFields, data)
+			if replacement := replacementField(ds, f); replacement != f {
+				subValues[templateReplacementKey] = fieldValue(replacement, data)
+			}
+			values[f.Name()] = subValues
+			continue
+		}
+		if datasource.FieldFlagEmpty.In(f.Flags()) {
+			continue
+		}
+		values[f.Name()] = fieldValue(f, data)
+	}
+	return values
+}
+
+// replacementField returns the field printed instead of f, like in the columns output
+func replacementField(ds datasource.DataSource, f datasource.FieldAccessor) datasource.FieldAccessor {
+	if name, ok := f.Annotations()[datasource.ColumnsReplaceAnnotation]; ok {
+		if replacement := ds.GetField(name); replacement != nil {
+			return replacement
+		}
+	}
+	return f
+}
+
+func templateDataFn(ds datasource.DataSource, data datasource.Data, tmpl *template.Template, w io.Writer) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, templateValues(ds, ds.Accessors(true), data)); err != nil {
+		return err
+	}
+	cliWriteMutex.Lock()
+	defer cliWriteMutex.Unlock()
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+type csvFormatter struct {
+	header []string
+	fields []datasource.FieldAccessor
+	record []string
+	buf    bytes.Buffer
+	writer *csv.Writer
+}
+
+// newCSVFormatter returns a formatter writing the given fields in the given order; like in the columns output, fields
+// replaced by another one are written like that field
+func newCSVFormatter(ds datasource.DataSource, fields []string) (*csvFormatter, error) {
+	f := &csvFormatter{
+		header: fields,
+		record: make([]string, len(fields)),
+	}
+	for _, name := range fields {
+		acc := ds.GetField(name)
+		if acc == nil {
+			return nil, fmt.Errorf("field %q not found", name)
+		}
+		f.fields = append(f.fields, replacementField(ds, acc))
+	}
+	f.writer = csv.NewWriter(&f.buf)
+	return f, nil
+}
+
+// csvString returns the value of a field as used in CSV; arrays are written like in JSON, e.g. [1,2,3], and integers
+// annotated with columns.hex like in the columns output, e.g. 0x1F
+func csvString(f datasource.FieldAccessor, data datasource.Data) string {
+	asHex := f.Annotations()[metadatav1.ColumnsHexAnnotation] == "true"
+	switch v := fieldValue(f, data).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int8, int16, int32, int64:
+		if asHex {
+			return "0x" + strings.ToUpper(strconv.FormatInt(reflect.ValueOf(v).Int(), 16))
+		}
+		return fmt.Sprint(v)
+	case uint8, uint16, uint32, uint64:
+		if asHex {
+			return "0x" + strings.ToUpper(strconv.FormatUint(reflect.ValueOf(v).Uint(), 16))
+		}
+		return fmt.Sprint(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case []uint8:
+		// encoding/json would encode it as base64
+		return strings.ReplaceAll(fmt.Sprint(v), " ", ",")
+	default:
+		if api.IsArrayKind(f.Type()) {
+			b, _ := gojson.Marshal(v)
+			return string(b)
+		}
+		return fmt.Sprint(v)
+	}
+}
+
+func (f *csvFormatter) format(values []string) []byte {
+	f.buf.Reset()
+	f.writer.Write(values)
+	f.writer.Flush()
+	return f.buf.Bytes()
+}
+
+func csvHeaderFn(csvFormatter *csvFormatter, w io.Writer) {
+	cliWriteMutex.Lock()
+	defer cliWriteMutex.Unlock()
+	w.Write(csvFormatter.format(csvFormatter.header))
+}
+
+func csvDataFn(data datasource.Data, csvFormatter *csvFormatter, w io.Writer) {
+	cliWriteMutex.Lock()
+	defer cliWriteMutex.Unlock()
+	for i, f := range csvFormatter.fields {
+		csvFormatter.record[i] = csvString(f, data)
+	}
+	w.Write(csvFormatter.format(csvFormatter.record))
+}
+
 func (o *cliOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
+	if o.tuiTerminal != nil {
+		return o.tuiTerminal.start()
+	}
 	return nil
 }
 
 func (o *cliOperatorInstance) Stop(gadgetCtx operators.GadgetContext) error {
+	if o.tuiTerminal != nil {
+		o.tuiTerminal.stop()
+	}
+	for _, cw := range o.columnarWriters {
+		if err := cw.Close(); err != nil {
+			gadgetCtx.Logger().Warnf("failed to close output: %v", err)
+		}
+	}
 	return nil
 }
 
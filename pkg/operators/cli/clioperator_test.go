@@ -16,6 +16,7 @@ package clioperator
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
 	"strings"
 	"testing"
@@ -23,6 +24,7 @@ import (
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/formatters/json"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	metadatav1 "github.com/inspektor-gadget/inspektor-gadget/pkg/metadata/v1"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -482,3 +484,211 @@ func removeNewLineAndTabs(s string) string {
 	s = strings.ReplaceAll(s, "\t", "")
 	return s + "\n"
 }
+
+func TestParseOutputModes(t *testing.T) {
+	type testCase struct {
+		name              string
+		input             string
+		expectedModes     map[string]string
+		expectedTemplates map[string]string
+		expectedErr       bool
+	}
+	testCases := []testCase{
+		{
+			name:              "single mode",
+			input:             "json",
+			expectedModes:     map[string]string{"": "json"},
+			expectedTemplates: map[string]string{},
+		},
+		{
+			name:              "modes per data source",
+			input:             "ds1:json,ds2:csv",
+			expectedModes:     map[string]string{"ds1": "json", "ds2": "csv"},
+			expectedTemplates: map[string]string{},
+		},
+		{
+			name:              "template",
+			input:             "template={{.proc.comm}}: {{.a}},{{.b}}",
+			expectedModes:     map[string]string{"": "template"},
+			expectedTemplates: map[string]string{"": "{{.proc.comm}}: {{.a}},{{.b}}"},
+		},
+		{
+			name:              "template per data source",
+			input:             "ds1:json,ds2:template={{.a}},{{.b}}",
+			expectedModes:     map[string]string{"ds1": "json", "ds2": "template"},
+			expectedTemplates: map[string]string{"ds2": "{{.a}},{{.b}}"},
+		},
+		{
+			name:        "template without value",
+			input:       "template",
+			expectedErr: true,
+		},
+		{
+			name:        "empty template",
+			input:       "template=",
+			expectedErr: true,
+		},
+		{
+			name:        "template mixed with and without data source",
+			input:       "json,ds2:template={{.a}}",
+			expectedErr: true,
+		},
+		{
+			name:        "template and other mode for the same data source",
+			input:       "ds1:json,ds1:template={{.a}}",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			modes, templates, err := ParseOutputModes(tc.input)
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedModes, modes)
+			assert.Equal(t, tc.expectedTemplates, templates)
+		})
+	}
+}
+
+type textTestFields struct {
+	comm  datasource.FieldAccessor
+	pid   datasource.FieldAccessor
+	fname datasource.FieldAccessor
+	ratio datasource.FieldAccessor
+	raw   datasource.FieldAccessor
+	addrs datasource.FieldAccessor
+	port  datasource.FieldAccessor
+	dst   datasource.FieldAccessor
+	flags datasource.FieldAccessor
+}
+
+func newTextTestDataSource(t *testing.T) (datasource.DataSource, datasource.Data) {
+	ds, err := datasource.New(datasource.TypeSingle, "test")
+	require.NoError(t, err)
+
+	f := &textTestFields{}
+	proc, err := ds.AddField("proc", api.Kind_Invalid, datasource.WithFlags(datasource.FieldFlagEmpty))
+	require.NoError(t, err)
+	f.comm, err = proc.AddSubField("comm", api.Kind_String)
+	require.NoError(t, err)
+	f.pid, err = proc.AddSubField("pid", api.Kind_Uint32)
+	require.NoError(t, err)
+	f.fname, err = ds.AddField("fname", api.Kind_String)
+	require.NoError(t, err)
+	f.ratio, err = ds.AddField("ratio", api.Kind_Float32)
+	require.NoError(t, err)
+	f.raw, err = ds.AddField("raw", api.Kind_Bytes)
+	require.NoError(t, err)
+	f.addrs, err = ds.AddField("addrs", api.ArrayOf(api.Kind_Uint16))
+	require.NoError(t, err)
+
+	// Like the fields added by the formatters operator for endpoints and flags
+	endpoint, err := ds.AddField("endpoint", api.Kind_Invalid,
+		datasource.WithFlags(datasource.FieldFlagEmpty),
+		datasource.WithAnnotations(map[string]string{
+			datasource.ColumnsReplaceAnnotation: "endpoint.dst",
+		}))
+	require.NoError(t, err)
+	f.port, err = endpoint.AddSubField("port", api.Kind_Uint16)
+	require.NoError(t, err)
+	f.dst, err = endpoint.AddSubField("dst", api.Kind_String, datasource.WithFlags(datasource.FieldFlagHidden))
+	require.NoError(t, err)
+	f.flags, err = ds.AddField("flags", api.Kind_Uint32, datasource.WithAnnotations(map[string]string{
+		metadatav1.ColumnsHexAnnotation: "true",
+	}))
+	require.NoError(t, err)
+
+	data, err := ds.NewPacketSingle()
+	require.NoError(t, err)
+	require.NoError(t, f.comm.PutString(data, "cat"))
+	require.NoError(t, f.pid.PutUint32(data, 42))
+	require.NoError(t, f.fname.PutString(data, `/tmp/a "b", c`))
+	require.NoError(t, f.ratio.PutFloat32(data, 0.25))
+	require.NoError(t, f.raw.PutBytes(data, []byte{0xca, 0xfe}))
+	addrs := binary.NativeEndian.AppendUint16(nil, 80)
+	addrs = binary.NativeEndian.AppendUint16(addrs, 443)
+	require.NoError(t, f.addrs.Set(data, addrs))
+	require.NoError(t, f.port.PutUint16(data, 443))
+	require.NoError(t, f.dst.PutString(data, "1.2.3.4:443"))
+	require.NoError(t, f.flags.PutUint32(data, 0x8042))
+	return ds, data
+}
+
+func TestTemplate(t *testing.T) {
+	ds, data := newTextTestDataSource(t)
+
+	type testCase struct {
+		name        string
+		template    string
+		expected    string
+		expectedErr bool
+	}
+	testCases := []testCase{
+		{
+			name:     "nested fields",
+			template: "{{.proc.comm}}({{.proc.pid}}) {{.fname}}",
+			expected: "cat(42) /tmp/a \"b\", c\n",
+		},
+		{
+			name:     "typed values",
+			template: "{{if gt .proc.pid 10}}big{{end}} {{.ratio}} {{.raw}} {{range .addrs}}{{.}};{{end}}\n",
+			expected: "big 0.25 cafe 80;443;\n",
+		},
+		{
+			name:     "replaced field",
+			template: "{{.endpoint}} {{.endpoint.port}} {{.flags}}",
+			expected: "1.2.3.4:443 443 32834\n",
+		},
+		{
+			name:     "index",
+			template: `{{index . "fname"}}`,
+			expected: "/tmp/a \"b\", c\n",
+		},
+		{
+			name:        "missing field",
+			template:    "{{.proc.nope}}",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpl, err := newTemplate(ds, tc.template)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			err = templateDataFn(ds, data, tmpl, &buf)
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestCSV(t *testing.T) {
+	ds, data := newTextTestDataSource(t)
+
+	csvFormatter, err := newCSVFormatter(ds, []string{"proc.comm", "fname", "proc.pid", "ratio", "raw", "addrs", "endpoint", "flags"})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	csvHeaderFn(csvFormatter, &buf)
+	csvDataFn(data, csvFormatter, &buf)
+	csvDataFn(data, csvFormatter, &buf)
+
+	// endpoint is written like the field replacing it and flags as hex
+	expected := "proc.comm,fname,proc.pid,ratio,raw,addrs,endpoint,flags\n" +
+		"cat,\"/tmp/a \"\"b\"\", c\",42,0.25,cafe,\"[80,443]\",1.2.3.4:443,0x8042\n" +
+		"cat,\"/tmp/a \"\"b\"\", c\",42,0.25,cafe,\"[80,443]\",1.2.3.4:443,0x8042\n"
+	assert.Equal(t, expected, buf.String())
+
+	_, err = newCSVFormatter(ds, []string{"proc.comm", "nope"})
+	require.Error(t, err)
+}
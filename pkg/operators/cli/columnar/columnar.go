@@ -0,0 +1,390 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package columnar adds the arrow and parquet output modes to the cli operator.
+// It's kept separate as it adds a lot of dependencies; binaries import it to
+// support them.
+package columnar
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/formatters/json"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	clioperator "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/cli"
+)
+
+const (
+	// arrowBatchSize is the maximum number of events of a data source of type single that are buffered before
+	// writing them as a record batch
+	arrowBatchSize = 1024
+
+	// parquetBatchSize is like arrowBatchSize, but for Parquet; each batch becomes a row group, so it should not
+	// be too small
+	parquetBatchSize = 64 * 1024
+)
+
+// column describes how a field is mapped to an Arrow column
+type column struct {
+	field arrow.Field
+	// bind returns a function that appends the value of the field to the given builder, which must have been
+	// created for field.Type
+	bind func(b array.Builder) func(datasource.Data)
+}
+
+func init() {
+	clioperator.RegisterColumnarWriter(func(ds datasource.DataSource, mode string, w io.Writer, lock sync.Locker) (clioperator.ColumnarWriter, error) {
+		return NewWriter(ds, mode, w, lock)
+	})
+}
+
+// Writer writes the data of a data source as Arrow record batches, either as an Arrow IPC stream or as a
+// Parquet file
+type Writer struct {
+	mu sync.Mutex
+	// lock is held while writing to the output, which may be shared with other writers
+	lock      sync.Locker
+	schema    *arrow.Schema
+	builder   *array.RecordBuilder
+	appendFns []func(datasource.Data)
+	rows      int
+	batchSize int
+	write     func(arrow.RecordBatch) error
+	close     func() error
+	closed    bool
+}
+
+// NewWriter creates a Writer for the given mode (arrow or parquet) writing to w while holding lock
+func NewWriter(ds datasource.DataSource, mode string, w io.Writer, lock sync.Locker) (*Writer, error) {
+	columns := newColumns(ds.Accessors(true))
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("data source %q has no fields", ds.Name())
+	}
+
+	fields := make([]arrow.Field, 0, len(columns))
+	for _, col := range columns {
+		fields = append(fields, col.field)
+	}
+	schemaMetadata := newMetadata(ds.Annotations())
+	schema := arrow.NewSchema(fields, &schemaMetadata)
+
+	cw := &Writer{
+		lock:    lock,
+		schema:  schema,
+		builder: array.NewRecordBuilder(memory.DefaultAllocator, schema),
+	}
+	for i, col := range columns {
+		cw.appendFns = append(cw.appendFns, col.bind(cw.builder.Field(i)))
+	}
+
+	switch mode {
+	case clioperator.ModeArrow:
+		iw := ipc.NewWriter(w, ipc.WithSchema(schema), ipc.WithAllocator(memory.DefaultAllocator))
+		cw.batchSize = arrowBatchSize
+		cw.write = iw.Write
+		cw.close = iw.Close
+	case clioperator.ModeParquet:
+		props := parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Zstd))
+		fw, err := pqarrow.NewFileWriter(schema, w, props, pqarrow.NewArrowWriterProperties(pqarrow.WithStoreSchema()))
+		if err != nil {
+			cw.builder.Release()
+			return nil, fmt.Errorf("creating parquet writer: %w", err)
+		}
+		cw.batchSize = parquetBatchSize
+		cw.write = fw.Write
+		cw.close = fw.Close
+	default:
+		cw.builder.Release()
+		return nil, fmt.Errorf("unsupported columnar output mode %q", mode)
+	}
+	return cw, nil
+}
+
+// newColumns returns a column for each of the given accessors; like the JSON formatter, fields are sorted by name,
+// and fields with sub-fields become structs
+func newColumns(accessors []datasource.FieldAccessor) []*column {
+	accessors = slices.Clone(accessors)
+	slices.SortFunc(accessors, func(i datasource.FieldAccessor, j datasource.FieldAccessor) int {
+		return strings.Compare(i.Name(), j.Name())
+	})
+
+	columns := make([]*column, 0, len(accessors))
+	for _, acc := range accessors {
+		if datasource.FieldFlagUnreferenced.In(acc.Flags()) {
+			continue
+		}
+		if val, ok := acc.Annotations()[json.SkipFieldAnnotation]; ok && val == "true" {
+			continue
+		}
+		col := newColumn(acc)
+		if col == nil {
+			continue
+		}
+		columns = append(columns, col)
+	}
+	return columns
+}
+
+func newColumn(acc datasource.FieldAccessor) *column {
+	field := arrow.Field{
+		Name:     acc.Name(),
+		Nullable: true,
+		Metadata: newMetadata(acc.Annotations()),
+	}
+
+	if subFields := acc.SubFields(); len(subFields) > 0 {
+		children := newColumns(subFields)
+		if len(children) == 0 {
+			return nil
+		}
+		structFields := make([]arrow.Field, 0, len(children))
+		for _, child := range children {
+			structFields = append(structFields, child.field)
+		}
+		field.Type = arrow.StructOf(structFields...)
+		return &column{
+			field: field,
+			bind: func(b array.Builder) func(datasource.Data) {
+				sb := b.(*array.StructBuilder)
+				childFns := make([]func(datasource.Data), 0, len(children))
+				for i, child := range children {
+					childFns = append(childFns, child.bind(sb.FieldBuilder(i)))
+				}
+				return func(data datasource.Data) {
+					sb.Append(true)
+					for _, fn := range childFns {
+						fn(data)
+					}
+				}
+			},
+		}
+	}
+
+	if datasource.FieldFlagEmpty.In(acc.Flags()) || acc.Type() == api.Kind_Invalid {
+		return nil
+	}
+
+	var bind func(b array.Builder) func(datasource.Data)
+	switch acc.Type() {
+	case api.Kind_Bool:
+		field.Type = arrow.FixedWidthTypes.Boolean
+		bind = bindValue[*array.BooleanBuilder](acc.Bool)
+	case api.Kind_Int8:
+		field.Type = arrow.PrimitiveTypes.Int8
+		bind = bindValue[*array.Int8Builder](acc.Int8)
+	case api.Kind_Int16:
+		field.Type = arrow.PrimitiveTypes.Int16
+		bind = bindValue[*array.Int16Builder](acc.Int16)
+	case api.Kind_Int32:
+		field.Type = arrow.PrimitiveTypes.Int32
+		bind = bindValue[*array.Int32Builder](acc.Int32)
+	case api.Kind_Int64:
+		field.Type = arrow.PrimitiveTypes.Int64
+		bind = bindValue[*array.Int64Builder](acc.Int64)
+	case api.Kind_Uint8:
+		field.Type = arrow.PrimitiveTypes.Uint8
+		bind = bindValue[*array.Uint8Builder](acc.Uint8)
+	case api.Kind_Uint16:
+		field.Type = arrow.PrimitiveTypes.Uint16
+		bind = bindValue[*array.Uint16Builder](acc.Uint16)
+	case api.Kind_Uint32:
+		field.Type = arrow.PrimitiveTypes.Uint32
+		bind = bindValue[*array.Uint32Builder](acc.Uint32)
+	case api.Kind_Uint64:
+		field.Type = arrow.PrimitiveTypes.Uint64
+		bind = bindValue[*array.Uint64Builder](acc.Uint64)
+	case api.Kind_Float32:
+		field.Type = arrow.PrimitiveTypes.Float32
+		bind = bindValue[*array.Float32Builder](acc.Float32)
+	case api.Kind_Float64:
+		field.Type = arrow.PrimitiveTypes.Float64
+		bind = bindValue[*array.Float64Builder](acc.Float64)
+	case api.Kind_Bytes:
+		field.Type = arrow.BinaryTypes.Binary
+		bind = bindValue[*array.BinaryBuilder](acc.Bytes)
+	case api.ArrayOf(api.Kind_Int8):
+		field.Type = arrow.ListOf(arrow.PrimitiveTypes.Int8)
+		bind = bindList[*array.Int8Builder](acc.Int8Array)
+	case api.ArrayOf(api.Kind_Int16):
+		field.Type = arrow.ListOf(arrow.PrimitiveTypes.Int16)
+		bind = bindList[*array.Int16Builder](acc.Int16Array)
+	case api.ArrayOf(api.Kind_Int32):
+		field.Type = arrow.ListOf(arrow.PrimitiveTypes.Int32)
+		bind = bindList[*array.Int32Builder](acc.Int32Array)
+	case api.ArrayOf(api.Kind_Int64):
+		field.Type = arrow.ListOf(arrow.PrimitiveTypes.Int64)
+		bind = bindList[*array.Int64Builder](acc.Int64Array)
+	case api.ArrayOf(api.Kind_Uint8):
+		field.Type = arrow.ListOf(arrow.PrimitiveTypes.Uint8)
+		bind = bindList[*array.Uint8Builder](acc.Uint8Array)
+	case api.ArrayOf(api.Kind_Uint16):
+		field.Type = arrow.ListOf(arrow.PrimitiveTypes.Uint16)
+		bind = bindList[*array.Uint16Builder](acc.Uint16Array)
+	case api.ArrayOf(api.Kind_Uint32):
+		field.Type = arrow.ListOf(arrow.PrimitiveTypes.Uint32)
+		bind = bindList[*array.Uint32Builder](acc.Uint32Array)
+	case api.ArrayOf(api.Kind_Uint64):
+		field.Type = arrow.ListOf(arrow.PrimitiveTypes.Uint64)
+		bind = bindList[*array.Uint64Builder](acc.Uint64Array)
+	case api.ArrayOf(api.Kind_Float32):
+		field.Type = arrow.ListOf(arrow.PrimitiveTypes.Float32)
+		bind = bindList[*array.Float32Builder](acc.Float32Array)
+	case api.ArrayOf(api.Kind_Float64):
+		field.Type = arrow.ListOf(arrow.PrimitiveTypes.Float64)
+		bind = bindList[*array.Float64Builder](acc.Float64Array)
+	default:
+		// Strings and all kinds without a native representation are stored as their string representation
+		field.Type = arrow.BinaryTypes.String
+		bind = bindValue[*array.StringBuilder](acc.String)
+	}
+	return &column{field: field, bind: bind}
+}
+
+// valueBuilder is implemented by all builders of primitive Arrow types
+type valueBuilder[T any] interface {
+	array.Builder
+	Append(T)
+}
+
+// bindValue returns a bind function for a field with a single value; values that can't be read are stored as null
+func bindValue[B valueBuilder[T], T any](get func(datasource.Data) (T, error)) func(array.Builder) func(datasource.Data) {
+	return func(b array.Builder) func(datasource.Data) {
+		vb := b.(B)
+		return func(data datasource.Data) {
+			v, err := get(data)
+			if err != nil {
+				vb.AppendNull()
+				return
+			}
+			vb.Append(v)
+		}
+	}
+}
+
+// bindList is like bindValue, but for fields with an array kind
+func bindList[B valueBuilder[T], T any](get func(datasource.Data) ([]T, error)) func(array.Builder) func(datasource.Data) {
+	return func(b array.Builder) func(datasource.Data) {
+		lb := b.(*array.ListBuilder)
+		vb := lb.ValueBuilder().(B)
+		return func(data datasource.Data) {
+			vals, err := get(data)
+			if err != nil {
+				lb.AppendNull()
+				return
+			}
+			lb.Append(true)
+			for _, v := range vals {
+				vb.Append(v)
+			}
+		}
+	}
+}
+
+// newMetadata converts annotations like the description, units or columns.* to Arrow metadata
+func newMetadata(annotations map[string]string) arrow.Metadata {
+	keys := make([]string, 0, len(annotations))
+	for k := range annotations {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	values := make([]string, 0, len(keys))
+	for _, k := range keys {
+		values = append(values, annotations[k])
+	}
+	return arrow.NewMetadata(keys, values)
+}
+
+// AppendData adds data as a new row and writes a record batch once batchSize rows are buffered
+func (cw *Writer) AppendData(data datasource.Data) error {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	if cw.closed {
+		return nil
+	}
+	cw.appendLocked(data)
+	if cw.rows < cw.batchSize {
+		return nil
+	}
+	return cw.flushLocked()
+}
+
+// AppendArray adds all elements of dataArray as rows and writes them as a single record batch
+func (cw *Writer) AppendArray(dataArray datasource.DataArray) error {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	if cw.closed {
+		return nil
+	}
+	for i := 0; i < dataArray.Len(); i++ {
+		cw.appendLocked(dataArray.Get(i))
+	}
+	return cw.flushLocked()
+}
+
+func (cw *Writer) appendLocked(data datasource.Data) {
+	for _, fn := range cw.appendFns {
+		fn(data)
+	}
+	cw.rows++
+}
+
+func (cw *Writer) flushLocked() error {
+	if cw.rows == 0 {
+		return nil
+	}
+	rec := cw.builder.NewRecordBatch()
+	defer rec.Release()
+	cw.rows = 0
+
+	cw.lock.Lock()
+	defer cw.lock.Unlock()
+	if err := cw.write(rec); err != nil {
+		return fmt.Errorf("writing record batch: %w", err)
+	}
+	return nil
+}
+
+// Close writes all buffered rows and the footer of the output; further data is ignored
+func (cw *Writer) Close() error {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	if cw.closed {
+		return nil
+	}
+	cw.closed = true
+	defer cw.builder.Release()
+
+	if err := cw.flushLocked(); err != nil {
+		return err
+	}
+	cw.lock.Lock()
+	defer cw.lock.Unlock()
+	return cw.close()
+}
@@ -0,0 +1,277 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package columnar
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"sync"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet/file"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	metadatav1 "github.com/inspektor-gadget/inspektor-gadget/pkg/metadata/v1"
+	clioperator "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/cli"
+)
+
+type columnarTestFields struct {
+	comm  datasource.FieldAccessor
+	pid   datasource.FieldAccessor
+	delta datasource.FieldAccessor
+	ratio datasource.FieldAccessor
+	ok    datasource.FieldAccessor
+	raw   datasource.FieldAccessor
+	stack datasource.FieldAccessor
+}
+
+func newColumnarTestDataSource(t *testing.T, typ datasource.Type) (datasource.DataSource, *columnarTestFields) {
+	ds, err := datasource.New(typ, "test")
+	require.NoError(t, err)
+	ds.AddAnnotation("description", "test data source")
+
+	f := &columnarTestFields{}
+	proc, err := ds.AddField("proc", api.Kind_Invalid, datasource.WithFlags(datasource.FieldFlagEmpty))
+	require.NoError(t, err)
+	f.comm, err = proc.AddSubField("comm", api.Kind_String, datasource.WithAnnotations(map[string]string{
+		metadatav1.ColumnsWidthAnnotation: "16",
+	}))
+	require.NoError(t, err)
+	f.pid, err = proc.AddSubField("pid", api.Kind_Uint32)
+	require.NoError(t, err)
+	f.delta, err = ds.AddField("delta", api.Kind_Int64, datasource.WithAnnotations(map[string]string{
+		metadatav1.DescriptionAnnotation: "time since the last event",
+		"metrics.unit":                   "ns",
+	}))
+	require.NoError(t, err)
+	f.ratio, err = ds.AddField("ratio", api.Kind_Float64)
+	require.NoError(t, err)
+	f.ok, err = ds.AddField("ok", api.Kind_Bool)
+	require.NoError(t, err)
+	f.raw, err = ds.AddField("raw", api.Kind_Bytes)
+	require.NoError(t, err)
+	f.stack, err = ds.AddField("stack", api.ArrayOf(api.Kind_Uint64))
+	require.NoError(t, err)
+	return ds, f
+}
+
+func fillColumnarTestData(t *testing.T, f *columnarTestFields, data datasource.Data, i int) {
+	require.NoError(t, f.comm.PutString(data, "cat"))
+	require.NoError(t, f.pid.PutUint32(data, uint32(100+i)))
+	require.NoError(t, f.delta.PutInt64(data, int64(-i)))
+	require.NoError(t, f.ratio.PutFloat64(data, float64(i)/2))
+	require.NoError(t, f.ok.PutBool(data, i%2 == 0))
+	require.NoError(t, f.raw.PutBytes(data, []byte{0, byte(i)}))
+	stack := make([]byte, 0, 16)
+	for j := 0; j <= i; j++ {
+		stack = binary.NativeEndian.AppendUint64(stack, uint64(j))
+	}
+	require.NoError(t, f.stack.Set(data, stack))
+}
+
+func TestColumnarSchema(t *testing.T) {
+	ds, _ := newColumnarTestDataSource(t, datasource.TypeSingle)
+
+	cw, err := NewWriter(ds, clioperator.ModeArrow, &bytes.Buffer{}, &sync.Mutex{})
+	require.NoError(t, err)
+	defer cw.Close()
+
+	type expectedField struct {
+		name     string
+		typ      arrow.DataType
+		metadata map[string]string
+	}
+	expected := []expectedField{
+		{
+			name: "delta",
+			typ:  arrow.PrimitiveTypes.Int64,
+			metadata: map[string]string{
+				metadatav1.DescriptionAnnotation: "time since the last event",
+				"metrics.unit":                   "ns",
+			},
+		},
+		{name: "ok", typ: arrow.FixedWidthTypes.Boolean},
+		{
+			name: "proc",
+			typ: arrow.StructOf(
+				arrow.Field{Name: "comm", Type: arrow.BinaryTypes.String, Nullable: true},
+				arrow.Field{Name: "pid", Type: arrow.PrimitiveTypes.Uint32, Nullable: true},
+			),
+		},
+		{name: "ratio", typ: arrow.PrimitiveTypes.Float64},
+		{name: "raw", typ: arrow.BinaryTypes.Binary},
+		{name: "stack", typ: arrow.ListOf(arrow.PrimitiveTypes.Uint64)},
+	}
+
+	require.Equal(t, len(expected), cw.schema.NumFields())
+	for i, field := range expected {
+		actual := cw.schema.Field(i)
+		assert.Equal(t, field.name, actual.Name)
+		assert.True(t, arrow.TypeEqual(field.typ, actual.Type), "type of %q: %s", field.name, actual.Type)
+		for k, v := range field.metadata {
+			value, ok := actual.Metadata.GetValue(k)
+			assert.True(t, ok, "metadata %q of %q", k, field.name)
+			assert.Equal(t, v, value)
+		}
+	}
+
+	comm := cw.schema.Field(2).Type.(*arrow.StructType).Field(0)
+	width, ok := comm.Metadata.GetValue(metadatav1.ColumnsWidthAnnotation)
+	require.True(t, ok)
+	assert.Equal(t, "16", width)
+
+	desc, ok := cw.schema.Metadata().GetValue("description")
+	require.True(t, ok)
+	assert.Equal(t, "test data source", desc)
+}
+
+func assertColumnarTestRecord(t *testing.T, rec arrow.RecordBatch) {
+	for i := 0; i < int(rec.NumRows()); i++ {
+		assert.Equal(t, int64(-i), rec.Column(0).(*array.Int64).Value(i))
+		assert.Equal(t, i%2 == 0, rec.Column(1).(*array.Boolean).Value(i))
+		proc := rec.Column(2).(*array.Struct)
+		assert.Equal(t, "cat", proc.Field(0).(*array.String).Value(i))
+		assert.Equal(t, uint32(100+i), proc.Field(1).(*array.Uint32).Value(i))
+		assert.Equal(t, float64(i)/2, rec.Column(3).(*array.Float64).Value(i))
+		assert.Equal(t, []byte{0, byte(i)}, rec.Column(4).(*array.Binary).Value(i))
+
+		stack := rec.Column(5).(*array.List)
+		start, end := stack.ValueOffsets(i)
+		values := stack.ListValues().(*array.Uint64).Uint64Values()[start:end]
+		expectedStack := make([]uint64, 0, i+1)
+		for j := 0; j <= i; j++ {
+			expectedStack = append(expectedStack, uint64(j))
+		}
+		assert.Equal(t, expectedStack, values)
+	}
+}
+
+func TestColumnarArrowSingle(t *testing.T) {
+	ds, f := newColumnarTestDataSource(t, datasource.TypeSingle)
+
+	var buf bytes.Buffer
+	cw, err := NewWriter(ds, clioperator.ModeArrow, &buf, &sync.Mutex{})
+	require.NoError(t, err)
+
+	events := arrowBatchSize + 10
+	for i := 0; i < events; i++ {
+		data, err := ds.NewPacketSingle()
+		require.NoError(t, err)
+		fillColumnarTestData(t, f, data, i%20)
+		require.NoError(t, cw.AppendData(data))
+	}
+	require.NoError(t, cw.Close())
+
+	// Data after closing is ignored
+	data, err := ds.NewPacketSingle()
+	require.NoError(t, err)
+	require.NoError(t, cw.AppendData(data))
+
+	r, err := ipc.NewReader(&buf)
+	require.NoError(t, err)
+	defer r.Release()
+
+	batches := 0
+	rows := 0
+	for r.Next() {
+		rec := r.RecordBatch()
+		for row := 0; row < int(rec.NumRows()); row++ {
+			assert.Equal(t, int64(-((rows + row) % 20)), rec.Column(0).(*array.Int64).Value(row))
+		}
+		batches++
+		rows += int(rec.NumRows())
+	}
+	require.NoError(t, r.Err())
+	assert.Equal(t, 2, batches)
+	assert.Equal(t, events, rows)
+}
+
+func TestColumnarArrowArray(t *testing.T) {
+	ds, f := newColumnarTestDataSource(t, datasource.TypeArray)
+
+	var buf bytes.Buffer
+	cw, err := NewWriter(ds, clioperator.ModeArrow, &buf, &sync.Mutex{})
+	require.NoError(t, err)
+
+	// Each array is written as a record batch
+	for range 3 {
+		dataArray, err := ds.NewPacketArray()
+		require.NoError(t, err)
+		for i := 0; i < 5; i++ {
+			data := dataArray.New()
+			fillColumnarTestData(t, f, data, i)
+			dataArray.Append(data)
+		}
+		require.NoError(t, cw.AppendArray(dataArray))
+	}
+	require.NoError(t, cw.Close())
+
+	r, err := ipc.NewReader(&buf)
+	require.NoError(t, err)
+	defer r.Release()
+
+	batches := 0
+	for r.Next() {
+		rec := r.RecordBatch()
+		require.EqualValues(t, 5, rec.NumRows())
+		assertColumnarTestRecord(t, rec)
+		batches++
+	}
+	require.NoError(t, r.Err())
+	assert.Equal(t, 3, batches)
+}
+
+func TestColumnarParquet(t *testing.T) {
+	ds, f := newColumnarTestDataSource(t, datasource.TypeSingle)
+
+	var buf bytes.Buffer
+	cw, err := NewWriter(ds, clioperator.ModeParquet, &buf, &sync.Mutex{})
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		data, err := ds.NewPacketSingle()
+		require.NoError(t, err)
+		fillColumnarTestData(t, f, data, i)
+		require.NoError(t, cw.AppendData(data))
+	}
+	require.NoError(t, cw.Close())
+
+	pf, err := file.NewParquetReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	defer pf.Close()
+	fr, err := pqarrow.NewFileReader(pf, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	require.NoError(t, err)
+	table, err := fr.ReadTable(context.Background())
+	require.NoError(t, err)
+	defer table.Release()
+
+	require.EqualValues(t, 10, table.NumRows())
+	unit, ok := table.Schema().Field(0).Metadata.GetValue("metrics.unit")
+	require.True(t, ok)
+	assert.Equal(t, "ns", unit)
+
+	tr := array.NewTableReader(table, -1)
+	defer tr.Release()
+	require.True(t, tr.Next())
+	assertColumnarTestRecord(t, tr.RecordBatch())
+}
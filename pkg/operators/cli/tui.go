@@ -0,0 +1,712 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clioperator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/filter/filterfunc"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/sort/sortfunc"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/parser"
+)
+
+const (
+	// tuiHeaderLines is the number of lines above the rows: status line and column header
+	tuiHeaderLines = 2
+	// tuiFooterLines is the number of lines below the rows: prompt or message
+	tuiFooterLines = 1
+
+	ansiHome          = "\033[H"
+	ansiClearLine     = "\033[K"
+	ansiClearBelow    = "\033[J"
+	ansiReverse       = "\033[7m"
+	ansiReset         = "\033[0m"
+	ansiEnterAltBuf   = "\033[?1049h\033[?25l"
+	ansiLeaveAltBuf   = "\033[?25h\033[?1049l"
+	tuiDefaultWidth   = 80
+	tuiDefaultHeight  = 24
+	tuiColumnSelected = "[x] "
+	tuiColumnHidden   = "[ ] "
+)
+
+type tuiKey int
+
+const (
+	keyRune tuiKey = iota
+	keyUp
+	keyDown
+	keyPageUp
+	keyPageDown
+	keyHome
+	keyEnd
+	keyEnter
+	keyEsc
+	keyBackspace
+	keyCtrlC
+)
+
+type tuiKeyEvent struct {
+	key tuiKey
+	r   rune
+}
+
+type tuiView int
+
+const (
+	viewTable tuiView = iota
+	viewEditSort
+	viewEditFilter
+	viewColumns
+	viewHelp
+)
+
+var tuiHelp = []string{
+	"Keys:",
+	"  q, Ctrl-C       quit",
+	"  p, space        pause / resume",
+	"  < >             sort by the previous / next column",
+	"  r               reverse the sort order",
+	"  s               edit sort fields, like '-cpu,pid' (see --sort)",
+	"  f, /            edit filter, like 'comm~^ba,pid>100' (see --filter)",
+	"  c               choose columns",
+	"  up, down, pgup, pgdown, home, end   scroll",
+	"",
+	"Press any key to return",
+}
+
+// entryFormatter is implemented by the text columns formatter of data sources; it's not part of
+// parser.TextColumnsFormatter as that interface hides the type of the entries
+type entryFormatter interface {
+	FormatEntry(*datasource.DataTuple) string
+	RecalculateWidths(maxWidth int, force bool)
+}
+
+// tui renders the snapshots of an array data source like top(1). Sorting and filtering are applied to a copy of
+// the last snapshot, so they can be changed while paused and don't affect other operators.
+type tui struct {
+	mu sync.Mutex
+
+	ds        datasource.DataSource
+	formatter parser.TextColumnsFormatter
+	entries   entryFormatter
+
+	// allColumns contains the names of all available columns, columns the shown ones
+	allColumns []string
+	columns    []string
+
+	sortBy    []string
+	sortFn    func(datasource.DataArray)
+	sortIndex int
+
+	filter   string
+	filterFn func(datasource.DataSource, datasource.Data) bool
+
+	paused   bool
+	snapshot datasource.PacketArray
+	rows     []int
+	offset   int
+
+	view         tuiView
+	input        []rune
+	columnCursor int
+	message      string
+
+	width  int
+	height int
+	quit   func()
+}
+
+func newTUI(ds datasource.DataSource, fields string, hasFields bool, quit func()) (*tui, error) {
+	p, err := ds.Parser()
+	if err != nil {
+		return nil, fmt.Errorf("getting parser: %w", err)
+	}
+	formatter := p.GetTextColumnsFormatter()
+	entries, ok := formatter.(entryFormatter)
+	if !ok {
+		return nil, fmt.Errorf("invalid formatter: got %T", formatter)
+	}
+
+	t := &tui{
+		ds:        ds,
+		formatter: formatter,
+		entries:   entries,
+		sortIndex: -1,
+		width:     tuiDefaultWidth,
+		height:    tuiDefaultHeight,
+		quit:      quit,
+	}
+	for _, attr := range p.GetColumnAttributes() {
+		t.allColumns = append(t.allColumns, attr.Name)
+	}
+
+	t.columns = p.GetDefaultColumns()
+	if hasFields {
+		t.columns = ParseFields(fields, t.columns)
+	}
+	if err := t.setColumns(t.columns); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *tui) setColumns(columns []string) error {
+	if len(columns) == 0 {
+		return errors.New("at least one column must be shown")
+	}
+	if err := t.formatter.SetShowColumns(columns); err != nil {
+		return err
+	}
+	t.columns = columns
+	t.entries.RecalculateWidths(t.width, false)
+	return nil
+}
+
+// setSort sets the fields to sort by using the syntax of the sort operator
+func (t *tui) setSort(sortBy []string) error {
+	if len(sortBy) == 0 {
+		t.sortBy = nil
+		t.sortFn = nil
+		t.sortIndex = -1
+		return nil
+	}
+	sortFn, err := sortfunc.New(t.ds, sortBy)
+	if err != nil {
+		return err
+	}
+	t.sortBy = sortBy
+	t.sortFn = sortFn
+	t.sortIndex = slices.Index(t.columns, strings.TrimPrefix(sortBy[0], "-"))
+	t.applySort()
+	return nil
+}
+
+// setFilter sets the filter using the syntax of the filter operator
+func (t *tui) setFilter(filterStr string) error {
+	if filterStr == "" {
+		t.filter = ""
+		t.filterFn = nil
+		t.applyFilter()
+		return nil
+	}
+	filterFn, err := filterfunc.New(t.ds, filterStr)
+	if err != nil {
+		return err
+	}
+	t.filter = filterStr
+	t.filterFn = filterFn
+	t.applyFilter()
+	return nil
+}
+
+func (t *tui) applySort() {
+	if t.snapshot == nil || t.sortFn == nil {
+		return
+	}
+	t.sortFn(t.snapshot)
+	t.applyFilter()
+}
+
+func (t *tui) applyFilter() {
+	t.rows = t.rows[:0]
+	if t.snapshot == nil {
+		return
+	}
+	for i := 0; i < t.snapshot.Len(); i++ {
+		if t.filterFn == nil || t.filterFn(t.ds, t.snapshot.Get(i)) {
+			t.rows = append(t.rows, i)
+		}
+	}
+	t.scroll(0)
+}
+
+// update replaces the shown snapshot with a copy of dataArray, unless the TUI is paused
+func (t *tui) update(dataArray datasource.DataArray) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.paused {
+		return nil
+	}
+
+	packet, ok := dataArray.(datasource.PacketArray)
+	if !ok {
+		return fmt.Errorf("invalid data array: got %T", dataArray)
+	}
+	// The data array can't be used after returning, so keep a copy
+	b, err := proto.Marshal(packet.Raw())
+	if err != nil {
+		return fmt.Errorf("copying data array: %w", err)
+	}
+	snapshot, err := t.ds.NewPacketArrayFromRaw(b)
+	if err != nil {
+		return fmt.Errorf("copying data array: %w", err)
+	}
+	if t.snapshot != nil {
+		t.ds.Release(t.snapshot)
+	}
+	t.snapshot = snapshot
+	if t.sortFn != nil {
+		t.sortFn(t.snapshot)
+	}
+	t.applyFilter()
+	return nil
+}
+
+func (t *tui) tableHeight() int {
+	return max(t.height-tuiHeaderLines-tuiFooterLines, 1)
+}
+
+// scroll moves the first shown row by delta rows, keeping it in the valid range
+func (t *tui) scroll(delta int) {
+	t.offset = min(t.offset+delta, len(t.rows)-t.tableHeight())
+	t.offset = max(t.offset, 0)
+}
+
+// sortByColumn sorts by the shown column at index; the sort order is kept
+func (t *tui) sortByColumn(index int) {
+	if len(t.columns) == 0 {
+		return
+	}
+	index = (index + len(t.columns)) % len(t.columns)
+	prefix := ""
+	if len(t.sortBy) > 0 && strings.HasPrefix(t.sortBy[0], "-") {
+		prefix = "-"
+	}
+	if err := t.setSort([]string{prefix + t.columns[index]}); err != nil {
+		t.message = err.Error()
+		t.sortIndex = index
+	}
+}
+
+func (t *tui) reverseSort() {
+	if len(t.sortBy) == 0 {
+		return
+	}
+	sortBy := slices.Clone(t.sortBy)
+	for i, field := range sortBy {
+		if f, ok := strings.CutPrefix(field, "-"); ok {
+			sortBy[i] = f
+		} else {
+			sortBy[i] = "-" + field
+		}
+	}
+	if err := t.setSort(sortBy); err != nil {
+		t.message = err.Error()
+	}
+}
+
+func (t *tui) toggleColumn(name string) {
+	columns := slices.Clone(t.columns)
+	if i := slices.Index(columns, name); i >= 0 {
+		columns = slices.Delete(columns, i, i+1)
+	} else {
+		columns = append(columns, name)
+	}
+	if err := t.setColumns(columns); err != nil {
+		t.message = err.Error()
+		return
+	}
+	t.sortIndex = -1
+	if len(t.sortBy) > 0 {
+		t.sortIndex = slices.Index(t.columns, strings.TrimPrefix(t.sortBy[0], "-"))
+	}
+}
+
+// handleKey applies a key press to the state of the TUI
+func (t *tui) handleKey(ev tuiKeyEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if ev.key == keyCtrlC {
+		t.quit()
+		return
+	}
+
+	switch t.view {
+	case viewHelp:
+		t.view = viewTable
+	case viewEditSort, viewEditFilter:
+		t.handleEditKey(ev)
+	case viewColumns:
+		t.handleColumnsKey(ev)
+	default:
+		t.handleTableKey(ev)
+	}
+}
+
+func (t *tui) handleTableKey(ev tuiKeyEvent) {
+	t.message = ""
+	switch ev.key {
+	case keyUp:
+		t.scroll(-1)
+	case keyDown:
+		t.scroll(1)
+	case keyPageUp:
+		t.scroll(-t.tableHeight())
+	case keyPageDown:
+		t.scroll(t.tableHeight())
+	case keyHome:
+		t.scroll(-len(t.rows))
+	case keyEnd:
+		t.scroll(len(t.rows))
+	case keyEsc:
+		t.paused = false
+	case keyRune:
+		switch ev.r {
+		case 'q':
+			t.quit()
+		case 'p', ' ':
+			t.paused = !t.paused
+		case '<':
+			t.sortByColumn(t.sortIndex - 1)
+		case '>':
+			t.sortByColumn(t.sortIndex + 1)
+		case 'r':
+			t.reverseSort()
+		case 's':
+			t.view = viewEditSort
+			t.input = []rune(strings.Join(t.sortBy, ","))
+		case 'f', '/':
+			t.view = viewEditFilter
+			t.input = []rune(t.filter)
+		case 'c':
+			t.view = viewColumns
+		case 'h', '?':
+			t.view = viewHelp
+		}
+	}
+}
+
+func (t *tui) handleEditKey(ev tuiKeyEvent) {
+	switch ev.key {
+	case keyEsc:
+		t.view = viewTable
+	case keyBackspace:
+		if len(t.input) > 0 {
+			t.input = t.input[:len(t.input)-1]
+		}
+	case keyRune:
+		t.input = append(t.input, ev.r)
+	case keyEnter:
+		var err error
+		input := strings.TrimSpace(string(t.input))
+		if t.view == viewEditSort {
+			var sortBy []string
+			for _, field := range strings.Split(input, ",") {
+				if field = strings.TrimSpace(field); field != "" {
+					sortBy = append(sortBy, field)
+				}
+			}
+			err = t.setSort(sortBy)
+		} else {
+			err = t.setFilter(input)
+		}
+		if err != nil {
+			// Keep editing, so the user can fix the input
+			t.message = err.Error()
+			return
+		}
+		t.message = ""
+		t.view = viewTable
+	}
+}
+
+func (t *tui) handleColumnsKey(ev tuiKeyEvent) {
+	t.message = ""
+	switch ev.key {
+	case keyUp:
+		t.columnCursor = max(t.columnCursor-1, 0)
+	case keyDown:
+		t.columnCursor = min(t.columnCursor+1, len(t.allColumns)-1)
+	case keyHome:
+		t.columnCursor = 0
+	case keyEnd:
+		t.columnCursor = len(t.allColumns) - 1
+	case keyEsc, keyEnter:
+		t.view = viewTable
+	case keyRune:
+		switch ev.r {
+		case ' ', 'x':
+			if t.columnCursor < len(t.allColumns) {
+				t.toggleColumn(t.allColumns[t.columnCursor])
+			}
+		case 'q', 'c':
+			t.view = viewTable
+		}
+	}
+}
+
+// truncate shortens s to at most width runes
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	return string([]rune(s)[:width])
+}
+
+// render writes a full frame of the TUI to w
+func (t *tui) render(w io.Writer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var buf bytes.Buffer
+	line := func(s string, highlight bool) {
+		s = truncate(s, t.width)
+		if highlight {
+			s = ansiReverse + s + strings.Repeat(" ", t.width-utf8.RuneCountInString(s)) + ansiReset
+		}
+		buf.WriteString(s)
+		buf.WriteString(ansiClearLine)
+		buf.WriteString("\r\n")
+	}
+
+	total := 0
+	if t.snapshot != nil {
+		total = t.snapshot.Len()
+	}
+	status := fmt.Sprintf("%s: %d/%d rows", t.ds.Name(), len(t.rows), total)
+	if len(t.sortBy) > 0 {
+		status += " | sort: " + strings.Join(t.sortBy, ",")
+	}
+	if t.filter != "" {
+		status += " | filter: " + t.filter
+	}
+	if t.paused {
+		status += " | PAUSED"
+	}
+	status += " | h: help"
+	line(status, false)
+
+	height := t.tableHeight()
+	switch t.view {
+	case viewHelp:
+		line("", false)
+		for i := 0; i < height && i < len(tuiHelp); i++ {
+			line(tuiHelp[i], false)
+		}
+	case viewColumns:
+		line("Columns (space: toggle, enter: close)", true)
+		start := max(t.columnCursor-height+1, 0)
+		for i := start; i < len(t.allColumns) && i < start+height; i++ {
+			prefix := tuiColumnHidden
+			if slices.Contains(t.columns, t.allColumns[i]) {
+				prefix = tuiColumnSelected
+			}
+			line(prefix+t.allColumns[i], i == t.columnCursor)
+		}
+	default:
+		line(t.formatter.FormatHeader(), true)
+		for i := t.offset; i < len(t.rows) && i < t.offset+height; i++ {
+			line(t.entries.FormatEntry(datasource.NewDataTuple(t.ds, t.snapshot.Get(t.rows[i]))), false)
+		}
+	}
+
+	buf.WriteString(ansiClearBelow)
+
+	// Footer in the last line
+	fmt.Fprintf(&buf, "\033[%d;1H", t.height)
+	switch t.view {
+	case viewEditSort:
+		buf.WriteString(truncate("sort: "+string(t.input), t.width))
+	case viewEditFilter:
+		buf.WriteString(truncate("filter: "+string(t.input), t.width))
+	}
+	if t.message != "" {
+		buf.WriteString(truncate("  "+t.message, t.width))
+	}
+	buf.WriteString(ansiClearLine)
+
+	cliWriteMutex.Lock()
+	defer cliWriteMutex.Unlock()
+	w.Write([]byte(ansiHome))
+	w.Write(buf.Bytes())
+}
+
+// resize updates the size of the TUI
+func (t *tui) resize(width, height int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if width <= 0 || height <= 0 || (width == t.width && height == t.height) {
+		return
+	}
+	t.width = width
+	t.height = height
+	t.entries.RecalculateWidths(width, false)
+	t.scroll(0)
+}
+
+// parseKeys converts the input read from a terminal in raw mode to key events
+func parseKeys(b []byte) []tuiKeyEvent {
+	var events []tuiKeyEvent
+	for len(b) > 0 {
+		if b[0] == 0x1b {
+			if len(b) >= 3 && b[1] == '[' {
+				key, n := parseEscapeSequence(b[2:])
+				if n > 0 {
+					if key != keyRune {
+						events = append(events, tuiKeyEvent{key: key})
+					}
+					b = b[2+n:]
+					continue
+				}
+			}
+			events = append(events, tuiKeyEvent{key: keyEsc})
+			b = b[1:]
+			continue
+		}
+
+		r, size := utf8.DecodeRune(b)
+		b = b[size:]
+		switch r {
+		case 3:
+			events = append(events, tuiKeyEvent{key: keyCtrlC})
+		case '\r', '\n':
+			events = append(events, tuiKeyEvent{key: keyEnter})
+		case 127, 8:
+			events = append(events, tuiKeyEvent{key: keyBackspace})
+		default:
+			if r >= 0x20 && r != utf8.RuneError {
+				events = append(events, tuiKeyEvent{key: keyRune, r: r})
+			}
+		}
+	}
+	return events
+}
+
+// parseEscapeSequence parses the part of a CSI sequence after "ESC [" and returns the key and the number of bytes
+// it used; unknown sequences are returned as keyRune, so they can be skipped
+func parseEscapeSequence(b []byte) (tuiKey, int) {
+	// Parameters and intermediate bytes are followed by the final byte
+	end := bytes.IndexFunc(b, func(r rune) bool { return r >= 0x40 && r <= 0x7e })
+	if end < 0 {
+		return keyRune, 0
+	}
+	params := string(b[:end])
+	switch b[end] {
+	case 'A':
+		return keyUp, end + 1
+	case 'B':
+		return keyDown, end + 1
+	case 'H':
+		return keyHome, end + 1
+	case 'F':
+		return keyEnd, end + 1
+	case '~':
+		switch params {
+		case "1", "7":
+			return keyHome, end + 1
+		case "4", "8":
+			return keyEnd, end + 1
+		case "5":
+			return keyPageUp, end + 1
+		case "6":
+			return keyPageDown, end + 1
+		}
+	}
+	return keyRune, end + 1
+}
+
+// tuiTerminal connects a tui to the terminal of the process
+type tuiTerminal struct {
+	t        *tui
+	out      *os.File
+	in       *os.File
+	oldState *term.State
+	done     chan struct{}
+	once     sync.Once
+}
+
+func newTUITerminal(t *tui) (*tuiTerminal, error) {
+	if !term.IsTerminal(int(os.Stdout.Fd())) || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("output mode %q requires a terminal", ModeTUI)
+	}
+	return &tuiTerminal{
+		t:    t,
+		out:  os.Stdout,
+		in:   os.Stdin,
+		done: make(chan struct{}),
+	}, nil
+}
+
+func (tt *tuiTerminal) render() {
+	select {
+	case <-tt.done:
+		return
+	default:
+	}
+	if width, height, err := term.GetSize(int(tt.out.Fd())); err == nil {
+		tt.t.resize(width, height)
+	}
+	tt.t.render(tt.out)
+}
+
+// start switches the terminal to raw mode and handles key presses until stop is called
+func (tt *tuiTerminal) start() error {
+	oldState, err := term.MakeRaw(int(tt.in.Fd()))
+	if err != nil {
+		return fmt.Errorf("setting terminal to raw mode: %w", err)
+	}
+	tt.oldState = oldState
+	tt.out.WriteString(ansiEnterAltBuf)
+	tt.render()
+
+	go func() {
+		buf := make([]byte, 256)
+		for {
+			n, err := tt.in.Read(buf)
+			select {
+			case <-tt.done:
+				return
+			default:
+			}
+			if err != nil {
+				return
+			}
+			for _, ev := range parseKeys(buf[:n]) {
+				tt.t.handleKey(ev)
+			}
+			tt.render()
+		}
+	}()
+	return nil
+}
+
+// stop restores the terminal
+func (tt *tuiTerminal) stop() {
+	tt.once.Do(func() {
+		close(tt.done)
+		if tt.oldState == nil {
+			return
+		}
+		cliWriteMutex.Lock()
+		defer cliWriteMutex.Unlock()
+		tt.out.WriteString(ansiLeaveAltBuf)
+		term.Restore(int(tt.in.Fd()), tt.oldState)
+	})
+}
@@ -0,0 +1,255 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clioperator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+type tuiTestProcess struct {
+	comm string
+	pid  uint32
+	cpu  float64
+}
+
+func newTUITest(t *testing.T, procs []tuiTestProcess) (*tui, func([]tuiTestProcess), *bool) {
+	ds, err := datasource.New(datasource.TypeArray, "processes")
+	require.NoError(t, err)
+	comm, err := ds.AddField("comm", api.Kind_String)
+	require.NoError(t, err)
+	pid, err := ds.AddField("pid", api.Kind_Uint32)
+	require.NoError(t, err)
+	cpu, err := ds.AddField("cpu", api.Kind_Float64)
+	require.NoError(t, err)
+	_, err = ds.AddField("hidden", api.Kind_String, datasource.WithFlags(datasource.FieldFlagHidden))
+	require.NoError(t, err)
+
+	quit := false
+	tu, err := newTUI(ds, "", false, func() { quit = true })
+	require.NoError(t, err)
+
+	update := func(procs []tuiTestProcess) {
+		dataArray, err := ds.NewPacketArray()
+		require.NoError(t, err)
+		for _, p := range procs {
+			data := dataArray.New()
+			require.NoError(t, comm.PutString(data, p.comm))
+			require.NoError(t, pid.PutUint32(data, p.pid))
+			require.NoError(t, cpu.PutFloat64(data, p.cpu))
+			dataArray.Append(data)
+		}
+		require.NoError(t, tu.update(dataArray))
+	}
+	update(procs)
+	return tu, update, &quit
+}
+
+func typeKeys(tu *tui, s string) {
+	for _, ev := range parseKeys([]byte(s)) {
+		tu.handleKey(ev)
+	}
+}
+
+// shownComms returns the value of the comm column of the shown rows
+func shownComms(t *testing.T, tu *tui) []string {
+	var buf bytes.Buffer
+	tu.render(&buf)
+	lines := strings.Split(buf.String(), "\r\n")
+	require.Greater(t, len(lines), tuiHeaderLines)
+
+	comms := []string{}
+	for _, line := range lines[tuiHeaderLines:] {
+		fields := strings.Fields(strings.ReplaceAll(line, ansiClearLine, ""))
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "\033") {
+			continue
+		}
+		comms = append(comms, fields[0])
+	}
+	return comms
+}
+
+var tuiTestProcesses = []tuiTestProcess{
+	{comm: "bash", pid: 10, cpu: 1.5},
+	{comm: "sshd", pid: 3, cpu: 0.5},
+	{comm: "make", pid: 42, cpu: 90},
+	{comm: "cc", pid: 43, cpu: 5},
+}
+
+func TestTUISort(t *testing.T) {
+	tu, update, _ := newTUITest(t, tuiTestProcesses)
+	assert.Equal(t, []string{"bash", "sshd", "make", "cc"}, shownComms(t, tu))
+
+	typeKeys(tu, "s-cpu\r")
+	assert.Equal(t, []string{"-cpu"}, tu.sortBy)
+	assert.Equal(t, []string{"make", "cc", "bash", "sshd"}, shownComms(t, tu))
+
+	// New snapshots are sorted as well
+	update(append([]tuiTestProcess{{comm: "top", pid: 100, cpu: 50}}, tuiTestProcesses...))
+	assert.Equal(t, []string{"make", "top", "cc", "bash", "sshd"}, shownComms(t, tu))
+
+	typeKeys(tu, "r")
+	assert.Equal(t, []string{"cpu"}, tu.sortBy)
+	assert.Equal(t, []string{"sshd", "bash", "cc", "top", "make"}, shownComms(t, tu))
+
+	// cpu is the last column, so the next one is comm
+	typeKeys(tu, ">")
+	assert.Equal(t, []string{"comm"}, tu.sortBy)
+	assert.Equal(t, []string{"bash", "cc", "make", "sshd", "top"}, shownComms(t, tu))
+
+	typeKeys(tu, "<")
+	assert.Equal(t, []string{"cpu"}, tu.sortBy)
+
+	// Invalid fields are reported and keep the editor open
+	typeKeys(tu, "s\x7f\x7f\x7fnope\r")
+	assert.Equal(t, viewEditSort, tu.view)
+	assert.Contains(t, tu.message, "field nope not found")
+	assert.Equal(t, []string{"cpu"}, tu.sortBy)
+	typeKeys(tu, "\x1b")
+	assert.Equal(t, viewTable, tu.view)
+}
+
+func TestTUIFilter(t *testing.T) {
+	tu, update, _ := newTUITest(t, tuiTestProcesses)
+
+	typeKeys(tu, "fpid>5\r")
+	assert.Equal(t, "pid>5", tu.filter)
+	assert.Equal(t, []string{"bash", "make", "cc"}, shownComms(t, tu))
+
+	typeKeys(tu, "/\x7f\x7f\x7f\x7f\x7fcomm~^(make|sshd)$,pid>5\r")
+	assert.Equal(t, []string{"make"}, shownComms(t, tu))
+
+	update(append(tuiTestProcesses, tuiTestProcess{comm: "make", pid: 99}))
+	assert.Equal(t, []string{"make", "make"}, shownComms(t, tu))
+
+	typeKeys(tu, "fxyz=1\r")
+	assert.Equal(t, viewEditFilter, tu.view)
+	assert.NotEmpty(t, tu.message)
+	typeKeys(tu, "\x1b")
+
+	// Clearing the filter shows all rows again
+	typeKeys(tu, "f"+strings.Repeat("\x7f", 40)+"\r")
+	assert.Empty(t, tu.filter)
+	assert.Len(t, shownComms(t, tu), 5)
+}
+
+func TestTUIColumns(t *testing.T) {
+	tu, _, _ := newTUITest(t, tuiTestProcesses)
+	assert.Equal(t, []string{"comm", "pid", "cpu"}, tu.columns)
+	assert.Equal(t, []string{"comm", "pid", "cpu", "hidden"}, tu.allColumns)
+
+	// Hide comm and show hidden
+	typeKeys(tu, "c ")
+	typeKeys(tu, "\x1b[B\x1b[B\x1b[B ")
+	typeKeys(tu, "\r")
+	assert.Equal(t, viewTable, tu.view)
+	assert.Equal(t, []string{"pid", "cpu", "hidden"}, tu.columns)
+
+	var buf bytes.Buffer
+	tu.render(&buf)
+	assert.Contains(t, buf.String(), "HIDDEN")
+	assert.NotContains(t, buf.String(), "COMM")
+
+	// The last column can't be hidden
+	typeKeys(tu, "c\x1b[H\x1b[B \x1b[B \x1b[B ")
+	assert.Equal(t, []string{"hidden"}, tu.columns)
+	assert.NotEmpty(t, tu.message)
+}
+
+func TestTUIPauseAndScroll(t *testing.T) {
+	var procs []tuiTestProcess
+	for i := range 100 {
+		procs = append(procs, tuiTestProcess{comm: "p" + strings.Repeat("x", i%3), pid: uint32(i)})
+	}
+	tu, update, quit := newTUITest(t, procs)
+	tu.resize(80, 10)
+	require.Equal(t, 7, tu.tableHeight())
+	assert.Len(t, shownComms(t, tu), 7)
+
+	typeKeys(tu, "\x1b[B\x1b[B")
+	assert.Equal(t, 2, tu.offset)
+	typeKeys(tu, "\x1b[6~")
+	assert.Equal(t, 9, tu.offset)
+	typeKeys(tu, "\x1b[F")
+	assert.Equal(t, 93, tu.offset)
+	typeKeys(tu, "\x1b[H\x1b[A")
+	assert.Equal(t, 0, tu.offset)
+
+	typeKeys(tu, "p")
+	assert.True(t, tu.paused)
+	update(procs[:1])
+	assert.Equal(t, 100, tu.snapshot.Len())
+
+	// Sorting works while paused
+	typeKeys(tu, "s-pid\r")
+	assert.Equal(t, "p", shownComms(t, tu)[0])
+
+	typeKeys(tu, " ")
+	assert.False(t, tu.paused)
+	update(procs[:1])
+	assert.Equal(t, 1, tu.snapshot.Len())
+	assert.Equal(t, 0, tu.offset)
+
+	typeKeys(tu, "q")
+	assert.True(t, *quit)
+}
+
+func TestParseKeys(t *testing.T) {
+	type testCase struct {
+		name     string
+		input    string
+		expected []tuiKeyEvent
+	}
+	testCases := []testCase{
+		{
+			name:     "runes",
+			input:    "aö<",
+			expected: []tuiKeyEvent{{key: keyRune, r: 'a'}, {key: keyRune, r: 'ö'}, {key: keyRune, r: '<'}},
+		},
+		{
+			name:     "control keys",
+			input:    "\r\x7f\x03",
+			expected: []tuiKeyEvent{{key: keyEnter}, {key: keyBackspace}, {key: keyCtrlC}},
+		},
+		{
+			name:     "arrows",
+			input:    "\x1b[A\x1b[B",
+			expected: []tuiKeyEvent{{key: keyUp}, {key: keyDown}},
+		},
+		{
+			name:     "paging",
+			input:    "\x1b[5~\x1b[6~\x1b[1~\x1b[4~",
+			expected: []tuiKeyEvent{{key: keyPageUp}, {key: keyPageDown}, {key: keyHome}, {key: keyEnd}},
+		},
+		{
+			name:     "escape and unknown sequences",
+			input:    "\x1b\x1b[1;5Cx",
+			expected: []tuiKeyEvent{{key: keyEsc}, {key: keyRune, r: 'x'}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, parseKeys([]byte(tc.input)))
+		})
+	}
+}
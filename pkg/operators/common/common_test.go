@@ -360,3 +360,9 @@ func constructService(name, namespace, clusterIP string) *v1.Service {
 		},
 	}
 }
+
+func TestGadgetShortName(t *testing.T) {
+	assert.Equal(t, "trace_exec", GadgetShortName("ghcr.io/inspektor-gadget/gadget/trace_exec:latest"))
+	assert.Equal(t, "trace_dns", GadgetShortName("trace_dns"))
+	assert.Equal(t, "trace_tcp", GadgetShortName("localhost:5000/trace_tcp@sha256:abcd"))
+}
@@ -0,0 +1,240 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+)
+
+// DeliveryQueueConfig configures a DeliveryQueue
+type DeliveryQueueConfig struct {
+	// Name prefixes the log messages, usually the name of the operator
+	Name string
+
+	// Size is the number of items that can wait in the queue
+	Size int
+
+	// Block makes Enqueue wait until there is space in the queue, slowing
+	// down the caller, instead of dropping the item
+	Block bool
+
+	// BatchSize is the maximum number of items delivered at once; 0 delivers
+	// them one by one. Incomplete batches are delivered after BatchTimeout.
+	BatchSize    int
+	BatchTimeout time.Duration
+
+	// MaxAttempts is the number of times a batch is delivered before it's
+	// dropped. Retries wait RetryBackoff, doubled on each retry up to
+	// MaxRetryBackoff when it's set.
+	MaxAttempts     int
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+
+	// FlushTimeout is the time Close waits for the queued items to be
+	// delivered before dropping them
+	FlushTimeout time.Duration
+}
+
+// DeliverFunc delivers a batch of items. It must only return once all of them
+// have been delivered, or return an error if any of them wasn't. ctx is
+// cancelled once the flush timeout expires after closing the queue.
+type DeliverFunc[T any] func(ctx context.Context, items []T) error
+
+// DeliveryQueue decouples data sources from slow destinations like remote
+// services: items are queued and delivered in batches by a single goroutine.
+// Failed batches are retried with an exponential backoff until they are
+// delivered, MaxAttempts is reached or the queue is closed and the flush
+// timeout expires.
+type DeliveryQueue[T any] struct {
+	cfg     DeliveryQueueConfig
+	deliver DeliverFunc[T]
+	logger  logger.Logger
+
+	queue chan T
+	done  chan struct{}
+	wg    sync.WaitGroup
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	closeOnce sync.Once
+
+	dropped   atomic.Uint64
+	delivered atomic.Uint64
+}
+
+// NewDeliveryQueue creates a DeliveryQueue and starts delivering the items
+// using deliver
+func NewDeliveryQueue[T any](cfg DeliveryQueueConfig, deliver DeliverFunc[T], logger logger.Logger) *DeliveryQueue[T] {
+	cfg.BatchSize = max(cfg.BatchSize, 1)
+	cfg.MaxAttempts = max(cfg.MaxAttempts, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &DeliveryQueue[T]{
+		cfg:     cfg,
+		deliver: deliver,
+		logger:  logger,
+		queue:   make(chan T, cfg.Size),
+		done:    make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	q.wg.Add(1)
+	go q.run()
+	return q
+}
+
+// Enqueue queues an item. If the queue is full, it either blocks until there
+// is space or drops the item, depending on the Block setting. Items queued
+// after closing are dropped.
+func (q *DeliveryQueue[T]) Enqueue(item T) {
+	select {
+	case <-q.done:
+		q.dropped.Add(1)
+		return
+	default:
+	}
+
+	if !q.cfg.Block {
+		select {
+		case q.queue <- item:
+		default:
+			if dropped := q.dropped.Add(1); dropped%1000 == 1 {
+				q.logger.Warnf("%s: queue full, dropped %d items so far", q.cfg.Name, dropped)
+			}
+		}
+		return
+	}
+
+	select {
+	case q.queue <- item:
+	case <-q.done:
+		q.dropped.Add(1)
+	}
+}
+
+// Len returns the number of queued items
+func (q *DeliveryQueue[T]) Len() int {
+	return len(q.queue)
+}
+
+// Dropped returns the number of items that were dropped
+func (q *DeliveryQueue[T]) Dropped() uint64 {
+	return q.dropped.Load()
+}
+
+func (q *DeliveryQueue[T]) run() {
+	defer q.wg.Done()
+
+	batch := make([]T, 0, q.cfg.BatchSize)
+
+	// Without batches, there is nothing to flush after a timeout
+	var timeout <-chan time.Time
+	var timer *time.Timer
+	if q.cfg.BatchSize > 1 {
+		timer = time.NewTimer(q.cfg.BatchTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	flush := func() {
+		if len(batch) > 0 {
+			q.send(batch)
+			batch = batch[:0]
+		}
+		if timer != nil {
+			timer.Reset(q.cfg.BatchTimeout)
+		}
+	}
+
+	for {
+		select {
+		case item := <-q.queue:
+			batch = append(batch, item)
+			if len(batch) >= q.cfg.BatchSize {
+				flush()
+			}
+		case <-timeout:
+			flush()
+		case <-q.done:
+			// Deliver what's left in the queue
+			for {
+				select {
+				case item := <-q.queue:
+					batch = append(batch, item)
+					if len(batch) >= q.cfg.BatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// send delivers a batch, retrying with an exponential backoff
+func (q *DeliveryQueue[T]) send(batch []T) {
+	backoff := q.cfg.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := q.deliver(q.ctx, batch)
+		if err == nil {
+			q.delivered.Add(uint64(len(batch)))
+			return
+		}
+
+		if attempt >= q.cfg.MaxAttempts {
+			q.logger.Warnf("%s: dropping %d items after %d attempts: %v", q.cfg.Name, len(batch), attempt, err)
+			q.dropped.Add(uint64(len(batch)))
+			return
+		}
+		q.logger.Debugf("%s: delivering %d items (attempt %d): %v", q.cfg.Name, len(batch), attempt, err)
+
+		select {
+		case <-time.After(backoff):
+		case <-q.ctx.Done():
+			q.logger.Warnf("%s: dropping %d items not delivered before the flush timeout: %v", q.cfg.Name, len(batch), err)
+			q.dropped.Add(uint64(len(batch)))
+			return
+		}
+		backoff *= 2
+		if q.cfg.MaxRetryBackoff > 0 {
+			backoff = min(backoff, q.cfg.MaxRetryBackoff)
+		}
+	}
+}
+
+// Close stops accepting items and delivers the queued ones. Items not
+// delivered before the flush timeout are dropped.
+func (q *DeliveryQueue[T]) Close() {
+	q.closeOnce.Do(func() {
+		close(q.done)
+
+		timer := time.AfterFunc(q.cfg.FlushTimeout, q.cancel)
+		q.wg.Wait()
+		timer.Stop()
+		q.cancel()
+
+		if dropped := q.dropped.Load(); dropped > 0 {
+			q.logger.Warnf("%s: %d items delivered, %d dropped", q.cfg.Name, q.delivered.Load(), dropped)
+		}
+	})
+}
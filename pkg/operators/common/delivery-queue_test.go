@@ -0,0 +1,171 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+)
+
+// fakeDestination records the delivered batches. The first failures calls to
+// deliver fail. If release is set, deliver blocks until it's closed.
+type fakeDestination struct {
+	mu       sync.Mutex
+	batches  [][]string
+	failures int
+	attempts int
+	release  chan struct{}
+}
+
+func (d *fakeDestination) deliver(ctx context.Context, items []string) error {
+	if d.release != nil {
+		<-d.release
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.attempts++
+	if d.failures > 0 {
+		d.failures--
+		return errors.New("destination not available")
+	}
+	d.batches = append(d.batches, append([]string(nil), items...))
+	return nil
+}
+
+func (d *fakeDestination) items() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var ret []string
+	for _, batch := range d.batches {
+		ret = append(ret, batch...)
+	}
+	return ret
+}
+
+func newTestQueue(d *fakeDestination, cfg DeliveryQueueConfig) *DeliveryQueue[string] {
+	cfg.Name = "test"
+	if cfg.Size == 0 {
+		cfg.Size = 100
+	}
+	if cfg.MaxAttempts == 0 {
+		cfg.MaxAttempts = 10
+	}
+	if cfg.FlushTimeout == 0 {
+		cfg.FlushTimeout = 10 * time.Second
+	}
+	cfg.RetryBackoff = time.Millisecond
+	return NewDeliveryQueue(cfg, d.deliver, logger.DefaultLogger())
+}
+
+func TestDeliveryQueueBatchSize(t *testing.T) {
+	d := &fakeDestination{}
+	q := newTestQueue(d, DeliveryQueueConfig{BatchSize: 2, BatchTimeout: time.Hour})
+
+	for _, v := range []string{"1", "2", "3"} {
+		q.Enqueue(v)
+	}
+	require.Eventually(t, func() bool {
+		return len(d.items()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The last item is delivered when closing
+	q.Close()
+	assert.Equal(t, []string{"1", "2", "3"}, d.items())
+	assert.Len(t, d.batches, 2)
+}
+
+func TestDeliveryQueueBatchTimeout(t *testing.T) {
+	d := &fakeDestination{}
+	q := newTestQueue(d, DeliveryQueueConfig{BatchSize: 100, BatchTimeout: 10 * time.Millisecond})
+	defer q.Close()
+
+	q.Enqueue("1")
+	require.Eventually(t, func() bool {
+		return len(d.items()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestDeliveryQueueRetry(t *testing.T) {
+	d := &fakeDestination{failures: 3}
+	q := newTestQueue(d, DeliveryQueueConfig{})
+
+	q.Enqueue("1")
+	q.Close()
+
+	assert.Equal(t, []string{"1"}, d.items())
+	assert.Equal(t, 4, d.attempts)
+	assert.Zero(t, q.Dropped())
+}
+
+func TestDeliveryQueueMaxAttempts(t *testing.T) {
+	d := &fakeDestination{failures: 3}
+	q := newTestQueue(d, DeliveryQueueConfig{MaxAttempts: 2})
+
+	q.Enqueue("1")
+	q.Enqueue("2")
+	q.Close()
+
+	// The first item is dropped after two attempts, the second one is
+	// delivered on the fourth
+	assert.Equal(t, []string{"2"}, d.items())
+	assert.Equal(t, uint64(1), q.Dropped())
+}
+
+func TestDeliveryQueueFlushTimeout(t *testing.T) {
+	d := &fakeDestination{failures: 1 << 30}
+	q := newTestQueue(d, DeliveryQueueConfig{MaxAttempts: 1 << 30, FlushTimeout: 50 * time.Millisecond})
+
+	q.Enqueue("1")
+	start := time.Now()
+	q.Close()
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Empty(t, d.items())
+	assert.Equal(t, uint64(1), q.Dropped())
+}
+
+func TestDeliveryQueueDrop(t *testing.T) {
+	d := &fakeDestination{release: make(chan struct{})}
+	q := newTestQueue(d, DeliveryQueueConfig{Size: 2})
+
+	// The first item is being delivered, two wait in the queue and the
+	// others are dropped without blocking
+	q.Enqueue("1")
+	require.Eventually(t, func() bool {
+		return q.Len() == 0
+	}, 5*time.Second, time.Millisecond)
+	for _, v := range []string{"2", "3", "4", "5"} {
+		q.Enqueue(v)
+	}
+	assert.Equal(t, uint64(2), q.Dropped())
+
+	close(d.release)
+	q.Close()
+	assert.Equal(t, []string{"1", "2", "3"}, d.items())
+
+	// Items queued after closing are dropped
+	q.Enqueue("6")
+	assert.Equal(t, uint64(3), q.Dropped())
+}
@@ -0,0 +1,40 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"os"
+	"strings"
+)
+
+// GadgetShortName returns the short name of a gadget image, e.g. "trace_exec"
+// for "ghcr.io/inspektor-gadget/gadget/trace_exec:latest"
+func GadgetShortName(imageName string) string {
+	name := imageName[strings.LastIndex(imageName, "/")+1:]
+	if i := strings.IndexAny(name, ":@"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// NodeName returns the name of the node the gadget runs on: the NODE_NAME
+// environment variable on Kubernetes or the hostname otherwise
+func NodeName() string {
+	if node := os.Getenv("NODE_NAME"); node != "" {
+		return node
+	}
+	hostname, _ := os.Hostname()
+	return hostname
+}
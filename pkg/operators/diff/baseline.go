@@ -0,0 +1,104 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// baselineFile is the content of a baseline file. Rows contain the raw values
+// of the fields by their full name, so they can be compared and emitted
+// exactly like the rows of a fetch.
+type baselineFile struct {
+	Version     int                            `json:"version"`
+	DataSources map[string]*baselineDataSource `json:"dataSources"`
+}
+
+type baselineDataSource struct {
+	Keys []string            `json:"keys"`
+	Rows []map[string][]byte `json:"rows"`
+}
+
+func loadBaseline(path string) (*baselineFile, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading baseline: %w", err)
+	}
+	baseline := &baselineFile{}
+	if err := json.Unmarshal(b, baseline); err != nil {
+		return nil, fmt.Errorf("decoding baseline %q: %w", path, err)
+	}
+	if baseline.Version != baselineVersion {
+		return nil, fmt.Errorf("unsupported version %d of baseline %q", baseline.Version, path)
+	}
+	return baseline, nil
+}
+
+// fromBaseline returns the rows of a baseline by key. Fields missing in the
+// baseline, e.g. because it was saved with another version of the gadget,
+// keep their zero value.
+func (d *differ) fromBaseline(rows []map[string][]byte) map[string]row {
+	ret := make(map[string]row, len(rows))
+	for _, values := range rows {
+		r := make(row, len(d.fields))
+		for i, f := range d.fields {
+			r[i] = values[f.FullName()]
+		}
+		ret[d.key(r)] = r
+	}
+	return ret
+}
+
+// toBaseline returns the rows of the last fetch
+func (d *differ) toBaseline() *baselineDataSource {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	rows := &baselineDataSource{
+		Rows: make([]map[string][]byte, 0, len(d.last)),
+	}
+	for _, idx := range d.keys {
+		rows.Keys = append(rows.Keys, d.fields[idx].FullName())
+	}
+	for _, r := range d.last {
+		values := make(map[string][]byte, len(d.fields))
+		for i, f := range d.fields {
+			values[f.FullName()] = r[i]
+		}
+		rows.Rows = append(rows.Rows, values)
+	}
+	return rows
+}
+
+func (d *diffOperatorInstance) saveBaseline(path string) error {
+	baseline := &baselineFile{
+		Version:     baselineVersion,
+		DataSources: make(map[string]*baselineDataSource),
+	}
+	for _, differ := range d.differs {
+		baseline.DataSources[differ.ds.Name()] = differ.toBaseline()
+	}
+
+	b, err := json.Marshal(baseline)
+	if err != nil {
+		return fmt.Errorf("encoding baseline: %w", err)
+	}
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		return fmt.Errorf("saving baseline: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,440 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package diff is a data operator that compares the snapshots of array data
+// sources, like the ones of snapshot_process or snapshot_socket. Rows are
+// identified by key fields and only the rows added, removed or changed since
+// the previous fetch, or since a baseline saved to a file, are emitted.
+package diff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
+	metadatav1 "github.com/inspektor-gadget/inspektor-gadget/pkg/metadata/v1"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+)
+
+const (
+	name = "diff"
+
+	ParamDiffKeys         = "diff-keys"
+	ParamDiffIgnore       = "diff-ignore"
+	ParamDiffBaseline     = "diff-baseline"
+	ParamDiffSaveBaseline = "diff-save-baseline"
+
+	// FieldDiff contains the kind of change of a row: added, removed or
+	// changed
+	FieldDiff = "diff"
+	// FieldDiffChanges describes the fields that changed, e.g.
+	// "state: LISTEN -> CLOSE"
+	FieldDiffChanges = "diff_changes"
+
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+
+	// Priority is after the filter and combiner operators, so rows are
+	// diffed once combined and filtered, and before the sort and limiter
+	// operators
+	Priority = 9400
+
+	baselineVersion = 1
+)
+
+type diffOperator struct{}
+
+func (d *diffOperator) Name() string {
+	return name
+}
+
+func (d *diffOperator) Init(params *params.Params) error {
+	return nil
+}
+
+func (d *diffOperator) GlobalParams() api.Params {
+	return nil
+}
+
+func (d *diffOperator) InstanceParams() api.Params {
+	return api.Params{
+		{
+			Key:   ParamDiffKeys,
+			Title: "Diff Keys",
+			Description: "Fields identifying the rows of array data sources; only rows added, removed or changed between fetches are emitted. " +
+				"Join multiple fields with ','. If using multiple data sources, prefix fields with 'datasourcename:' and separate with ';'",
+			Tags: []string{api.TagGroupDataFiltering},
+		},
+		{
+			Key:   ParamDiffIgnore,
+			Title: "Diff Ignore",
+			Description: "Fields that are not compared to detect changed rows, like counters. " +
+				"Join multiple fields with ','. If using multiple data sources, prefix fields with 'datasourcename:' and separate with ';'",
+			Tags: []string{api.TagGroupDataFiltering},
+		},
+		{
+			Key:         ParamDiffBaseline,
+			Title:       "Diff Baseline",
+			Description: "Baseline file saved with --diff-save-baseline to compare all fetches to, instead of the previous fetch",
+			TypeHint:    api.TypeString,
+			Tags:        []string{api.TagGroupDataFiltering},
+		},
+		{
+			Key:         ParamDiffSaveBaseline,
+			Title:       "Diff Save Baseline",
+			Description: "File to save the last fetch to when the gadget stops, to be used later with --diff-baseline",
+			TypeHint:    api.TypeString,
+			Tags:        []string{api.TagGroupDataFiltering},
+		},
+	}
+}
+
+// getFieldsByDs parses a list of fields in the format of the sort operator:
+// "field1,field2" or "ds1:field1,field2;ds2:field3"
+func getFieldsByDs(s string) (map[string][]string, error) {
+	listsByDs, err := apihelpers.GetListValuesPerDataSource(s)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string][]string, len(listsByDs))
+	for dsName, list := range listsByDs {
+		if fields := apihelpers.SplitList(list); len(fields) > 0 {
+			res[dsName] = fields
+		}
+	}
+	return res, nil
+}
+
+func lookup(m map[string][]string, dsName string) []string {
+	if v, ok := m[""]; ok {
+		return v
+	}
+	return m[dsName]
+}
+
+func (d *diffOperator) InstantiateDataOperator(gadgetCtx operators.GadgetContext, instanceParamValues api.ParamValues) (operators.DataOperatorInstance, error) {
+	if instanceParamValues[ParamDiffKeys] == "" {
+		return nil, nil
+	}
+
+	// Only diff once, on the client if the gadget runs remotely
+	if gadgetCtx.IsRemoteCall() {
+		return nil, nil
+	}
+
+	keysByDs, err := getFieldsByDs(instanceParamValues[ParamDiffKeys])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamDiffKeys, err)
+	}
+	ignoreByDs, err := getFieldsByDs(instanceParamValues[ParamDiffIgnore])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamDiffIgnore, err)
+	}
+
+	var baseline *baselineFile
+	if path := instanceParamValues[ParamDiffBaseline]; path != "" {
+		baseline, err = loadBaseline(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	inst := &diffOperatorInstance{
+		savePath: instanceParamValues[ParamDiffSaveBaseline],
+	}
+
+	for _, ds := range gadgetCtx.GetDataSources() {
+		keys := lookup(keysByDs, ds.Name())
+		if len(keys) == 0 {
+			continue
+		}
+		if ds.Type() != datasource.TypeArray {
+			if _, ok := keysByDs[""]; ok {
+				continue
+			}
+			return nil, fmt.Errorf("diff can only be used on array data sources, %q isn't", ds.Name())
+		}
+
+		differ, err := newDiffer(ds, keys, lookup(ignoreByDs, ds.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("data source %q: %w", ds.Name(), err)
+		}
+		if baseline != nil {
+			rows, ok := baseline.DataSources[ds.Name()]
+			if !ok {
+				return nil, fmt.Errorf("data source %q not found in baseline", ds.Name())
+			}
+			if !slices.Equal(rows.Keys, keys) {
+				return nil, fmt.Errorf("baseline of data source %q uses keys %q instead of %q", ds.Name(),
+					strings.Join(rows.Keys, ","), strings.Join(keys, ","))
+			}
+			differ.baseline = differ.fromBaseline(rows.Rows)
+		} else if ds.Annotations()[api.FetchIntervalAnnotation] == "0" && ds.Annotations()[api.FetchCountAnnotation] == "1" &&
+			inst.savePath == "" {
+			gadgetCtx.Logger().Warnf("diff: data source %q is fetched only once; use --%s to compare it to a baseline, "+
+				"or set the %s annotation", ds.Name(), ParamDiffBaseline, api.FetchIntervalAnnotation)
+		}
+		inst.differs = append(inst.differs, differ)
+	}
+	if len(inst.differs) == 0 {
+		return nil, fmt.Errorf("no array data source found for %s", ParamDiffKeys)
+	}
+	return inst, nil
+}
+
+func (d *diffOperator) Priority() int {
+	return Priority
+}
+
+func (d *diffOperator) SupportsReplay() bool {
+	return true
+}
+
+// row contains the raw values of the fields of a row
+type row [][]byte
+
+// differ computes the differences between the fetches of a data source
+type differ struct {
+	ds datasource.DataSource
+
+	// fields are the fields holding a value, excluding the ones of the
+	// operator
+	fields  []datasource.FieldAccessor
+	keys    []int
+	compare []bool
+
+	diffField    datasource.FieldAccessor
+	changesField datasource.FieldAccessor
+
+	// baseline, when set, is compared to every fetch; otherwise the previous
+	// fetch is used
+	baseline map[string]row
+
+	mu       sync.Mutex
+	previous map[string]row
+	last     []row
+}
+
+func newDiffer(ds datasource.DataSource, keys, ignore []string) (*differ, error) {
+	d := &differ{ds: ds}
+
+	for _, f := range ds.Accessors(false) {
+		flags := f.Flags()
+		if datasource.FieldFlagEmpty.In(flags) || datasource.FieldFlagContainer.In(flags) ||
+			datasource.FieldFlagUnreferenced.In(flags) {
+			continue
+		}
+		d.fields = append(d.fields, f)
+		d.compare = append(d.compare, !slices.Contains(ignore, f.FullName()))
+	}
+
+	for _, key := range keys {
+		idx := slices.IndexFunc(d.fields, func(f datasource.FieldAccessor) bool {
+			return f.FullName() == key
+		})
+		if idx == -1 {
+			return nil, fmt.Errorf("key field %q not found", key)
+		}
+		d.keys = append(d.keys, idx)
+	}
+	for _, f := range ignore {
+		if ds.GetField(f) == nil {
+			return nil, fmt.Errorf("ignored field %q not found", f)
+		}
+	}
+
+	var err error
+	d.diffField, err = ds.AddField(FieldDiff, api.Kind_String,
+		datasource.WithOrder(-1000),
+		datasource.WithAnnotations(map[string]string{
+			metadatav1.DescriptionAnnotation:     "Kind of change of the row: added, removed or changed",
+			metadatav1.ValueOneOfAnnotation:      DiffAdded + "," + DiffRemoved + "," + DiffChanged,
+			metadatav1.ColumnsMaxWidthAnnotation: "7",
+		}))
+	if err != nil {
+		return nil, fmt.Errorf("adding %s field: %w", FieldDiff, err)
+	}
+	d.changesField, err = ds.AddField(FieldDiffChanges, api.Kind_String,
+		datasource.WithAnnotations(map[string]string{
+			metadatav1.DescriptionAnnotation: "Fields of the row that changed, with their previous and current values",
+		}))
+	if err != nil {
+		return nil, fmt.Errorf("adding %s field: %w", FieldDiffChanges, err)
+	}
+	return d, nil
+}
+
+// readRow copies the values of the fields of data
+func (d *differ) readRow(data datasource.Data) row {
+	r := make(row, len(d.fields))
+	for i, f := range d.fields {
+		r[i] = bytes.Clone(f.Get(data))
+	}
+	return r
+}
+
+// writeRow sets the fields of data to the values of r
+func (d *differ) writeRow(data datasource.Data, r row) {
+	for i, f := range d.fields {
+		if r[i] != nil {
+			f.Set(data, r[i])
+		}
+	}
+}
+
+// key returns the key of a row, made of the length prefixed values of its
+// key fields
+func (d *differ) key(r row) string {
+	var b []byte
+	for _, idx := range d.keys {
+		b = binary.AppendUvarint(b, uint64(len(r[idx])))
+		b = append(b, r[idx]...)
+	}
+	return string(b)
+}
+
+// changes returns the description of the compared fields whose values differ
+// between old and cur, or an empty string if they are equal. scratch is used
+// to print the values of old.
+func (d *differ) changes(old, cur row, curData, scratch datasource.Data) string {
+	var changes []string
+	for i, f := range d.fields {
+		if !d.compare[i] || bytes.Equal(old[i], cur[i]) {
+			continue
+		}
+		f.Set(scratch, old[i])
+		changes = append(changes, fmt.Sprintf("%s: %s -> %s", f.FullName(), datasource.ValueString(f, scratch), datasource.ValueString(f, curData)))
+	}
+	return strings.Join(changes, ", ")
+}
+
+// diff replaces the rows of a fetch with the ones that were added, removed or
+// changed
+func (d *differ) diff(arr datasource.DataArray) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	current := make(map[string]row, arr.Len())
+	d.last = d.last[:0]
+	rows := make([]row, arr.Len())
+	for i := range arr.Len() {
+		rows[i] = d.readRow(arr.Get(i))
+		current[d.key(rows[i])] = rows[i]
+		d.last = append(d.last, rows[i])
+	}
+
+	reference := d.baseline
+	if reference == nil {
+		reference = d.previous
+		d.previous = current
+	}
+	if reference == nil {
+		// Nothing to compare the first fetch to
+		return arr.Resize(0)
+	}
+
+	scratch := arr.New()
+	defer arr.Release(scratch)
+
+	// Keep the added and changed rows at the beginning of the array
+	kept := 0
+	for i, r := range rows {
+		data := arr.Get(i)
+		old, ok := reference[d.key(r)]
+		if !ok {
+			d.diffField.PutString(data, DiffAdded)
+			d.changesField.PutString(data, "")
+		} else {
+			changes := d.changes(old, r, data, scratch)
+			if changes == "" {
+				continue
+			}
+			d.diffField.PutString(data, DiffChanged)
+			d.changesField.PutString(data, changes)
+		}
+		arr.Swap(kept, i)
+		rows[kept], rows[i] = rows[i], rows[kept]
+		kept++
+	}
+	if err := arr.Resize(kept); err != nil {
+		return err
+	}
+
+	var removed []string
+	for k := range reference {
+		if _, ok := current[k]; !ok {
+			removed = append(removed, k)
+		}
+	}
+	// Keep a stable order for removed rows
+	slices.Sort(removed)
+	for _, k := range removed {
+		data := arr.New()
+		d.writeRow(data, reference[k])
+		d.diffField.PutString(data, DiffRemoved)
+		arr.Append(data)
+	}
+	return nil
+}
+
+type diffOperatorInstance struct {
+	differs  []*differ
+	savePath string
+}
+
+func (d *diffOperatorInstance) Name() string {
+	return name
+}
+
+func (d *diffOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
+	for _, differ := range d.differs {
+		differ.ds.SubscribeArray(func(ds datasource.DataSource, arr datasource.DataArray) error {
+			return differ.diff(arr)
+		}, Priority)
+	}
+	return nil
+}
+
+func (d *diffOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func (d *diffOperatorInstance) Stop(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func (d *diffOperatorInstance) Close(gadgetCtx operators.GadgetContext) error {
+	if d.savePath == "" {
+		return nil
+	}
+	if err := d.saveBaseline(d.savePath); err != nil {
+		return err
+	}
+	gadgetCtx.Logger().Infof("diff: baseline saved to %s", d.savePath)
+	return nil
+}
+
+var Operator = &diffOperator{}
+
+func init() {
+	operators.RegisterDataOperator(Operator)
+}
@@ -0,0 +1,201 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/testing/gadget-context"
+)
+
+type socket struct {
+	port  uint16
+	state string
+	rx    uint64
+}
+
+// snapshotDs is an array data source like the one of snapshot_socket
+type snapshotDs struct {
+	ds        datasource.DataSource
+	gadgetCtx *gadgetcontext.MockGadgetContext
+
+	portField  datasource.FieldAccessor
+	stateField datasource.FieldAccessor
+	rxField    datasource.FieldAccessor
+
+	// output contains the rows emitted by the last fetch
+	output []string
+}
+
+func newSnapshotDs(t *testing.T) *snapshotDs {
+	t.Helper()
+
+	s := &snapshotDs{}
+	var err error
+	s.ds, err = datasource.New(datasource.TypeArray, "sockets")
+	require.NoError(t, err)
+	s.portField, err = s.ds.AddField("port", api.Kind_Uint16)
+	require.NoError(t, err)
+	s.stateField, err = s.ds.AddField("state", api.Kind_String)
+	require.NoError(t, err)
+	s.rxField, err = s.ds.AddField("rx", api.Kind_Uint64)
+	require.NoError(t, err)
+
+	s.gadgetCtx = &gadgetcontext.MockGadgetContext{
+		Ctx:         context.Background(),
+		DataSources: map[string]datasource.DataSource{"sockets": s.ds},
+	}
+	return s
+}
+
+func (s *snapshotDs) start(t *testing.T, paramValues api.ParamValues) *diffOperatorInstance {
+	t.Helper()
+
+	inst, err := Operator.InstantiateDataOperator(s.gadgetCtx, paramValues)
+	require.NoError(t, err)
+	diffInst := inst.(*diffOperatorInstance)
+	require.NoError(t, diffInst.PreStart(s.gadgetCtx))
+
+	diffField := s.ds.GetField(FieldDiff)
+	changesField := s.ds.GetField(FieldDiffChanges)
+	s.ds.SubscribeArray(func(ds datasource.DataSource, arr datasource.DataArray) error {
+		s.output = nil
+		for i := range arr.Len() {
+			data := arr.Get(i)
+			diff, _ := diffField.String(data)
+			port, _ := s.portField.Uint16(data)
+			state, _ := s.stateField.String(data)
+			row := fmt.Sprintf("%s %d %s", diff, port, state)
+			if changes, _ := changesField.String(data); changes != "" {
+				row += " (" + changes + ")"
+			}
+			s.output = append(s.output, row)
+		}
+		return nil
+	}, Priority+1)
+
+	return diffInst
+}
+
+func (s *snapshotDs) fetch(t *testing.T, sockets ...socket) []string {
+	t.Helper()
+
+	arr, err := s.ds.NewPacketArray()
+	require.NoError(t, err)
+	for _, sock := range sockets {
+		data := arr.New()
+		require.NoError(t, s.portField.PutUint16(data, sock.port))
+		require.NoError(t, s.stateField.PutString(data, sock.state))
+		require.NoError(t, s.rxField.PutUint64(data, sock.rx))
+		arr.Append(data)
+	}
+	require.NoError(t, s.ds.EmitAndRelease(arr))
+	return s.output
+}
+
+func TestGetFieldsByDs(t *testing.T) {
+	fields, err := getFieldsByDs("pid, comm")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"": {"pid", "comm"}}, fields)
+
+	fields, err = getFieldsByDs("processes:pid;sockets:netns,port")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"processes": {"pid"}, "sockets": {"netns", "port"}}, fields)
+
+	_, err = getFieldsByDs("pid;sockets:port")
+	require.Error(t, err)
+}
+
+func TestDiffConsecutiveFetches(t *testing.T) {
+	s := newSnapshotDs(t)
+	s.start(t, api.ParamValues{ParamDiffKeys: "port", ParamDiffIgnore: "rx"})
+
+	// The first fetch is the reference for the next one
+	assert.Empty(t, s.fetch(t,
+		socket{22, "LISTEN", 0},
+		socket{80, "LISTEN", 10},
+		socket{443, "LISTEN", 0},
+	))
+
+	assert.Equal(t, []string{
+		"changed 80 ESTABLISHED (state: LISTEN -> ESTABLISHED)",
+		"added 8080 LISTEN",
+		"removed 443 LISTEN",
+	}, s.fetch(t,
+		// rx is ignored
+		socket{22, "LISTEN", 100},
+		socket{80, "ESTABLISHED", 10},
+		socket{8080, "LISTEN", 0},
+	))
+
+	assert.Empty(t, s.fetch(t,
+		socket{22, "LISTEN", 200},
+		socket{80, "ESTABLISHED", 10},
+		socket{8080, "LISTEN", 0},
+	))
+}
+
+func TestDiffBaseline(t *testing.T) {
+	baselinePath := filepath.Join(t.TempDir(), "baseline.json")
+
+	s := newSnapshotDs(t)
+	inst := s.start(t, api.ParamValues{ParamDiffKeys: "port", ParamDiffSaveBaseline: baselinePath})
+	s.fetch(t, socket{22, "LISTEN", 0}, socket{80, "LISTEN", 0})
+	require.NoError(t, inst.Close(s.gadgetCtx))
+
+	// Every fetch is compared to the baseline
+	s = newSnapshotDs(t)
+	s.start(t, api.ParamValues{ParamDiffKeys: "port", ParamDiffBaseline: baselinePath})
+	for range 2 {
+		assert.Equal(t, []string{
+			"changed 80 CLOSE (state: LISTEN -> CLOSE, rx: 0 -> 5)",
+			"removed 22 LISTEN",
+		}, s.fetch(t, socket{80, "CLOSE", 5}))
+	}
+
+	// The keys must be the same as the ones of the baseline
+	s = newSnapshotDs(t)
+	_, err := Operator.InstantiateDataOperator(s.gadgetCtx, api.ParamValues{
+		ParamDiffKeys:     "port,state",
+		ParamDiffBaseline: baselinePath,
+	})
+	require.ErrorContains(t, err, "uses keys")
+}
+
+func TestDiffInvalidParams(t *testing.T) {
+	for _, paramValues := range []api.ParamValues{
+		{ParamDiffKeys: "inode"},
+		{ParamDiffKeys: "port", ParamDiffIgnore: "bytes"},
+		{ParamDiffKeys: "other:port"},
+		{ParamDiffKeys: "port", ParamDiffBaseline: filepath.Join(t.TempDir(), "missing.json")},
+	} {
+		s := newSnapshotDs(t)
+		_, err := Operator.InstantiateDataOperator(s.gadgetCtx, paramValues)
+		require.Error(t, err, "%v", paramValues)
+	}
+
+	s := newSnapshotDs(t)
+	inst, err := Operator.InstantiateDataOperator(s.gadgetCtx, api.ParamValues{})
+	require.NoError(t, err)
+	require.Nil(t, inst)
+}
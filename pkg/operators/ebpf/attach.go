@@ -46,13 +46,50 @@ const (
 	disabledProgram = "gadget_program_disabled"
 )
 
-func (i *ebpfInstance) attachProgram(gadgetCtx operators.GadgetContext, p *ebpf.ProgramSpec, prog *ebpf.Program) (link.Link, error) {
-	attachTo := p.AttachTo
-
+// programAttachTo returns where the program is attached to, taking into account
+// the overrides of the gadget configuration.
+func (i *ebpfInstance) programAttachTo(p *ebpf.ProgramSpec) string {
 	if attachToCfg := i.config.GetString("programs." + p.Name + ".attach_to"); attachToCfg != "" {
 		i.logger.Debugf("Overriding attachTo with %q for program %q", attachToCfg, p.Name)
-		attachTo = attachToCfg
+		return attachToCfg
 	}
+	return p.AttachTo
+}
+
+// programHook returns the kind of hook attachProgram() attaches the program to,
+// or an empty string if it's not supported.
+func programHook(p *ebpf.ProgramSpec) string {
+	switch p.Type {
+	case ebpf.Kprobe:
+		for _, prefix := range []string{kprobePrefix, kretprobePrefix, uprobePrefix, uretprobePrefix, usdtPrefix} {
+			if strings.HasPrefix(p.SectionName, prefix) {
+				return strings.TrimSuffix(prefix, "/")
+			}
+		}
+	case ebpf.TracePoint:
+		return "tracepoint"
+	case ebpf.SocketFilter:
+		return "socket"
+	case ebpf.Tracing:
+		for _, prefix := range []string{iterPrefix, fentryPrefix, fexitPrefix, tpBtfPrefix} {
+			if strings.HasPrefix(p.SectionName, prefix) {
+				return strings.TrimSuffix(prefix, "/")
+			}
+		}
+	case ebpf.RawTracepoint:
+		return "raw_tracepoint"
+	case ebpf.SchedCLS:
+		return "tc"
+	case ebpf.LSM:
+		return "lsm"
+	case ebpf.PerfEvent:
+		return "perf_event"
+	}
+	return ""
+}
+
+func (i *ebpfInstance) attachProgram(gadgetCtx operators.GadgetContext, p *ebpf.ProgramSpec, prog *ebpf.Program) (link.Link, error) {
+	attachTo := i.programAttachTo(p)
 
 	if attachTo == disabledProgram {
 		i.logger.Debugf("Skipping program %q as it is disabled", p.Name)
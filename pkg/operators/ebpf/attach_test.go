@@ -0,0 +1,53 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebpfoperator
+
+import (
+	"testing"
+
+	"github.com/cilium/ebpf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgramHook(t *testing.T) {
+	for _, tc := range []struct {
+		typ     ebpf.ProgramType
+		section string
+		hook    string
+	}{
+		{ebpf.Kprobe, "kprobe/do_unlinkat", "kprobe"},
+		{ebpf.Kprobe, "kretprobe/do_unlinkat", "kretprobe"},
+		{ebpf.Kprobe, "uprobe/libc:malloc", "uprobe"},
+		{ebpf.Kprobe, "uretprobe/libc:malloc", "uretprobe"},
+		{ebpf.Kprobe, "usdt/libc:libc:setjmp", "usdt"},
+		{ebpf.Kprobe, "foo/bar", ""},
+		{ebpf.TracePoint, "tracepoint/syscalls/sys_enter_open", "tracepoint"},
+		{ebpf.SocketFilter, "socket1", "socket"},
+		{ebpf.Tracing, "iter/task", "iter"},
+		{ebpf.Tracing, "fentry/do_unlinkat", "fentry"},
+		{ebpf.Tracing, "fexit/do_unlinkat", "fexit"},
+		{ebpf.Tracing, "tp_btf/sched_switch", "tp_btf"},
+		{ebpf.RawTracepoint, "raw_tracepoint/sched_switch", "raw_tracepoint"},
+		{ebpf.SchedCLS, "classifier/ingress/drop", "tc"},
+		{ebpf.LSM, "lsm/bpf", "lsm"},
+		{ebpf.PerfEvent, "perf_event/sampler", "perf_event"},
+		{ebpf.XDP, "xdp", ""},
+	} {
+		t.Run(tc.section, func(t *testing.T) {
+			p := &ebpf.ProgramSpec{Type: tc.typ, SectionName: tc.section}
+			assert.Equal(t, tc.hook, programHook(p))
+		})
+	}
+}
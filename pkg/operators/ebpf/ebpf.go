@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net"
 	"os"
 	"reflect"
@@ -41,6 +42,7 @@ import (
 	"oras.land/oras-go/v2"
 
 	"github.com/inspektor-gadget/inspektor-gadget/internal/version"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/bpfstats"
 	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
@@ -475,9 +477,29 @@ func (i *ebpfInstance) init(gadgetCtx operators.GadgetContext) error {
 		gadgetCtx.SetVar(operators.MapSpecPrefix+name, m)
 	}
 
+	gadgetCtx.SetVar(operators.ProgramsVar, i.programInfos())
+
 	return nil
 }
 
+// programInfos describes the programs of the gadget to other operators, sorted
+// by name
+func (i *ebpfInstance) programInfos() []operators.ProgramInfo {
+	programs := make([]operators.ProgramInfo, 0, len(i.collectionSpec.Programs))
+	for _, name := range slices.Sorted(maps.Keys(i.collectionSpec.Programs)) {
+		p := i.collectionSpec.Programs[name]
+		programs = append(programs, operators.ProgramInfo{
+			Name:        p.Name,
+			SectionName: p.SectionName,
+			Type:        p.Type.String(),
+			AttachType:  p.AttachType.String(),
+			AttachTo:    i.programAttachTo(p),
+			Hook:        programHook(p),
+		})
+	}
+	return programs
+}
+
 func (i *ebpfInstance) addDataSource(
 	gadgetCtx operators.GadgetContext,
 	dsType datasource.Type,
@@ -889,6 +911,11 @@ func (i *ebpfInstance) Start(gadgetCtx operators.GadgetContext) error {
 	i.bpfOperator.gadgetObjs[gadgetCtx] = gadgetObjs
 	i.bpfOperator.mu.Unlock()
 
+	gadgetCtx.SetVar(bpfstats.ObjectsVar, &bpfstats.Objects{
+		ProgramIDs: gadgetObjs.programIDs,
+		MapIDs:     gadgetObjs.mapIDs,
+	})
+
 	for name, m := range i.collection.Maps {
 		gadgetCtx.SetVar(operators.MapPrefix+name, m)
 
@@ -898,6 +925,11 @@ func (i *ebpfInstance) Start(gadgetCtx operators.GadgetContext) error {
 		if name == ebpftypes.UserStackMapName {
 			i.userStackMap = m
 		}
+		if name == ebpftypes.SampledOutMapName {
+			if err := i.reportSampledOut(gadgetCtx, m); err != nil {
+				return fmt.Errorf("reporting sampled out events: %w", err)
+			}
+		}
 	}
 
 	for _, tracer := range i.tracers {
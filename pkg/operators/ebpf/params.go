@@ -146,6 +146,24 @@ var wellKnownParams = map[wellKnownParamKey]wellKnownParamVal{
 		Description: "Collect build IDs",
 		Tags:        []string{TagGroupProcess},
 	},
+	{TypeName: "__u32", VarName: "gadget_sample_ratio"}: {
+		Key:         "sample-ratio",
+		Title:       "Sample Ratio",
+		Description: "Keep only 1 in N events; events are dropped in the kernel before being sent to user space",
+		Tags:        []string{api.TagGroupDataFiltering},
+	},
+	{TypeName: "__u32", VarName: "gadget_rate_limit"}: {
+		Key:         "kernel-rate-limit",
+		Title:       "Kernel Rate Limit",
+		Description: "Maximum number of events per second; events exceeding it are dropped in the kernel. 0 disables the rate limit",
+		Tags:        []string{api.TagGroupDataFiltering},
+	},
+	{TypeName: "__u32", VarName: "gadget_rate_limit_burst"}: {
+		Key:         "kernel-rate-limit-burst",
+		Title:       "Kernel Rate Limit Burst",
+		Description: "Number of events that can exceed the kernel rate limit in a short burst; 0 uses the rate limit",
+		Tags:        []string{api.TagGroupDataFiltering},
+	},
 }
 
 func handleWellKnownParam(btfVar *btf.Var, p *param) {
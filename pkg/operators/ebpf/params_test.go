@@ -0,0 +1,71 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebpfoperator
+
+import (
+	"testing"
+
+	"github.com/cilium/ebpf/btf"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+func TestHandleWellKnownParam(t *testing.T) {
+	u32 := &btf.Typedef{
+		Name: "__u32",
+		Type: &btf.Int{Name: "unsigned int", Size: 4, Encoding: btf.Unsigned},
+	}
+	constVolatile := func(typ btf.Type) btf.Type {
+		return &btf.Const{Type: &btf.Volatile{Type: typ}}
+	}
+
+	type testCase struct {
+		name        string
+		btfVar      *btf.Var
+		expectedKey string
+	}
+
+	testCases := []testCase{
+		{
+			name:        "sample ratio",
+			btfVar:      &btf.Var{Name: "gadget_sample_ratio", Type: constVolatile(u32)},
+			expectedKey: "sample-ratio",
+		},
+		{
+			name:        "kernel rate limit",
+			btfVar:      &btf.Var{Name: "gadget_rate_limit", Type: constVolatile(u32)},
+			expectedKey: "kernel-rate-limit",
+		},
+		{
+			name:        "kernel rate limit burst",
+			btfVar:      &btf.Var{Name: "gadget_rate_limit_burst", Type: constVolatile(u32)},
+			expectedKey: "kernel-rate-limit-burst",
+		},
+		{
+			name:        "unknown variable",
+			btfVar:      &btf.Var{Name: "my_param", Type: constVolatile(u32)},
+			expectedKey: "my_param",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &param{Param: &api.Param{Key: tc.btfVar.Name}}
+			handleWellKnownParam(tc.btfVar, p)
+			assert.Equal(t, tc.expectedKey, p.Key)
+		})
+	}
+}
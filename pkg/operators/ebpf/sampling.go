@@ -0,0 +1,116 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebpfoperator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cilium/ebpf"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	ebpftypes "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/ebpf/types"
+)
+
+// samplingReportInterval is the interval in which the number of events
+// dropped by the helpers of include/gadget/sampling.h is reported
+const samplingReportInterval = 10 * time.Second
+
+// sampledOutCounter reads a per-CPU counter of the sampling map. The counter
+// isn't reset in the map, as the eBPF programs could increment it between
+// reading and resetting it; the per-CPU totals of the last read are kept
+// instead.
+type sampledOutCounter struct {
+	m    *ebpf.Map
+	key  uint32
+	last []uint64
+}
+
+func newSampledOutCounter(m *ebpf.Map, key uint32, cpus int) *sampledOutCounter {
+	return &sampledOutCounter{m: m, key: key, last: make([]uint64, cpus)}
+}
+
+// read returns how often the counter was incremented since the last read
+func (c *sampledOutCounter) read() (uint64, error) {
+	values := make([]uint64, len(c.last))
+	if err := c.m.Lookup(&c.key, values); err != nil {
+		return 0, fmt.Errorf("getting counter %d: %w", c.key, err)
+	}
+	delta := counterDelta(c.last, values)
+	c.last = values
+	return delta, nil
+}
+
+// counterDelta returns the sum of the per-CPU increments from last to current
+func counterDelta(last, current []uint64) uint64 {
+	sum := uint64(0)
+	for cpu, v := range current {
+		sum += v - last[cpu]
+	}
+	return sum
+}
+
+// reportSampledOut periodically reports the number of events dropped in the
+// kernel by sampling or rate limiting. These events are intentionally dropped
+// and are therefore reported separately from the lost samples of the tracers.
+func (i *ebpfInstance) reportSampledOut(gadgetCtx operators.GadgetContext, m *ebpf.Map) error {
+	cpus, err := ebpf.PossibleCPU()
+	if err != nil {
+		return fmt.Errorf("getting eBPF possibles CPUs: %w", err)
+	}
+
+	sampledCounter := newSampledOutCounter(m, ebpftypes.SampledOutRatio, cpus)
+	limitedCounter := newSampledOutCounter(m, ebpftypes.SampledOutRateLimit, cpus)
+
+	report := func(interval time.Duration) {
+		sampled, err := sampledCounter.read()
+		if err != nil {
+			i.logger.Warnf("reading sampled out events: %v", err)
+			return
+		}
+		limited, err := limitedCounter.read()
+		if err != nil {
+			i.logger.Warnf("reading rate limited events: %v", err)
+			return
+		}
+		interval = interval.Round(time.Second)
+		if sampled > 0 {
+			gadgetCtx.Logger().Infof("sampled out %d events in the last %s", sampled, interval)
+		}
+		if limited > 0 {
+			gadgetCtx.Logger().Warnf("dropped %d events exceeding the kernel rate limit in the last %s", limited, interval)
+		}
+	}
+
+	i.wg.Add(1)
+	go func() {
+		defer i.wg.Done()
+
+		last := time.Now()
+		ticker := time.NewTicker(samplingReportInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-i.done:
+				report(time.Since(last))
+				return
+			case now := <-ticker.C:
+				report(now.Sub(last))
+				last = now
+			}
+		}
+	}()
+	return nil
+}
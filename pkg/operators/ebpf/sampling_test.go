@@ -0,0 +1,30 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebpfoperator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounterDelta(t *testing.T) {
+	assert.Equal(t, uint64(0), counterDelta([]uint64{0, 0}, []uint64{0, 0}))
+	assert.Equal(t, uint64(7), counterDelta([]uint64{0, 0}, []uint64{3, 4}))
+	assert.Equal(t, uint64(5), counterDelta([]uint64{3, 4}, []uint64{5, 7}))
+	// Per-CPU counters wrap around independently
+	assert.Equal(t, uint64(3), counterDelta([]uint64{math.MaxUint64, 4}, []uint64{1, 5}))
+}
@@ -60,6 +60,10 @@ const (
 	UserStackMapName      = "ig_ustack"
 	BuildIdMapName        = "ig_build_id"
 	UserPerfMaxStackDepth = 127
+	// Keep in sync with `include/gadget/sampling.h`
+	SampledOutMapName   = "ig_sampled_out"
+	SampledOutRatio     = 0
+	SampledOutRateLimit = 1
 )
 
 // L3Endpoint is the Golang representation of struct gadget_l3endpoint_t
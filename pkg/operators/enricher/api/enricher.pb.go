@@ -0,0 +1,265 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.31.1
+// source: api/enricher.proto
+
+package api
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type LookupRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// enricher is the name of the enricher in the configuration
+	Enricher string `protobuf:"bytes,1,opt,name=enricher,proto3" json:"enricher,omitempty"`
+	// keys are the values of the tagged fields, like IP addresses
+	Keys          []string `protobuf:"bytes,2,rep,name=keys,proto3" json:"keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupRequest) Reset() {
+	*x = LookupRequest{}
+	mi := &file_api_enricher_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupRequest) ProtoMessage() {}
+
+func (x *LookupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_enricher_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupRequest.ProtoReflect.Descriptor instead.
+func (*LookupRequest) Descriptor() ([]byte, []int) {
+	return file_api_enricher_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *LookupRequest) GetEnricher() string {
+	if x != nil {
+		return x.Enricher
+	}
+	return ""
+}
+
+func (x *LookupRequest) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+type LookupResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// found is false if there is no data for the key
+	Found bool `protobuf:"varint,1,opt,name=found,proto3" json:"found,omitempty"`
+	// values are added to the events as fields; only the fields listed in
+	// the configuration of the enricher are used
+	Values        map[string]string `protobuf:"bytes,2,rep,name=values,proto3" json:"values,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupResult) Reset() {
+	*x = LookupResult{}
+	mi := &file_api_enricher_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupResult) ProtoMessage() {}
+
+func (x *LookupResult) ProtoReflect() protoreflect.Message {
+	mi := &file_api_enricher_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupResult.ProtoReflect.Descriptor instead.
+func (*LookupResult) Descriptor() ([]byte, []int) {
+	return file_api_enricher_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *LookupResult) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
+func (x *LookupResult) GetValues() map[string]string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type LookupResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// results contains a result for each key of the request, in the same
+	// order
+	Results       []*LookupResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupResponse) Reset() {
+	*x = LookupResponse{}
+	mi := &file_api_enricher_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupResponse) ProtoMessage() {}
+
+func (x *LookupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_enricher_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupResponse.ProtoReflect.Descriptor instead.
+func (*LookupResponse) Descriptor() ([]byte, []int) {
+	return file_api_enricher_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *LookupResponse) GetResults() []*LookupResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+var File_api_enricher_proto protoreflect.FileDescriptor
+
+const file_api_enricher_proto_rawDesc = "" +
+	"\n" +
+	"\x12api/enricher.proto\x12\benricher\"?\n" +
+	"\rLookupRequest\x12\x1a\n" +
+	"\benricher\x18\x01 \x01(\tR\benricher\x12\x12\n" +
+	"\x04keys\x18\x02 \x03(\tR\x04keys\"\x9b\x01\n" +
+	"\fLookupResult\x12\x14\n" +
+	"\x05found\x18\x01 \x01(\bR\x05found\x12:\n" +
+	"\x06values\x18\x02 \x03(\v2\".enricher.LookupResult.ValuesEntryR\x06values\x1a9\n" +
+	"\vValuesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"B\n" +
+	"\x0eLookupResponse\x120\n" +
+	"\aresults\x18\x01 \x03(\v2\x16.enricher.LookupResultR\aresults2I\n" +
+	"\bEnricher\x12=\n" +
+	"\x06Lookup\x12\x17.enricher.LookupRequest\x1a\x18.enricher.LookupResponse\"\x00BMZKgithub.com/inspektor-gadget/inspektor-gadget/pkg/operators/enricher/api;apib\x06proto3"
+
+var (
+	file_api_enricher_proto_rawDescOnce sync.Once
+	file_api_enricher_proto_rawDescData []byte
+)
+
+func file_api_enricher_proto_rawDescGZIP() []byte {
+	file_api_enricher_proto_rawDescOnce.Do(func() {
+		file_api_enricher_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_enricher_proto_rawDesc), len(file_api_enricher_proto_rawDesc)))
+	})
+	return file_api_enricher_proto_rawDescData
+}
+
+var file_api_enricher_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_api_enricher_proto_goTypes = []any{
+	(*LookupRequest)(nil),  // 0: enricher.LookupRequest
+	(*LookupResult)(nil),   // 1: enricher.LookupResult
+	(*LookupResponse)(nil), // 2: enricher.LookupResponse
+	nil,                    // 3: enricher.LookupResult.ValuesEntry
+}
+var file_api_enricher_proto_depIdxs = []int32{
+	3, // 0: enricher.LookupResult.values:type_name -> enricher.LookupResult.ValuesEntry
+	1, // 1: enricher.LookupResponse.results:type_name -> enricher.LookupResult
+	0, // 2: enricher.Enricher.Lookup:input_type -> enricher.LookupRequest
+	2, // 3: enricher.Enricher.Lookup:output_type -> enricher.LookupResponse
+	3, // [3:4] is the sub-list for method output_type
+	2, // [2:3] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_api_enricher_proto_init() }
+func file_api_enricher_proto_init() {
+	if File_api_enricher_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_enricher_proto_rawDesc), len(file_api_enricher_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_enricher_proto_goTypes,
+		DependencyIndexes: file_api_enricher_proto_depIdxs,
+		MessageInfos:      file_api_enricher_proto_msgTypes,
+	}.Build()
+	File_api_enricher_proto = out.File
+	file_api_enricher_proto_goTypes = nil
+	file_api_enricher_proto_depIdxs = nil
+}
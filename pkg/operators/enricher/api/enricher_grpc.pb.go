@@ -0,0 +1,143 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v6.31.1
+// source: api/enricher.proto
+
+package api
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Enricher_Lookup_FullMethodName = "/enricher.Enricher/Lookup"
+)
+
+// EnricherClient is the client API for Enricher service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Enricher is implemented by external services to enrich the events of
+// Inspektor Gadget with data like the owner or the service of an IP address.
+type EnricherClient interface {
+	// Lookup returns the values for a batch of keys
+	Lookup(ctx context.Context, in *LookupRequest, opts ...grpc.CallOption) (*LookupResponse, error)
+}
+
+type enricherClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEnricherClient(cc grpc.ClientConnInterface) EnricherClient {
+	return &enricherClient{cc}
+}
+
+func (c *enricherClient) Lookup(ctx context.Context, in *LookupRequest, opts ...grpc.CallOption) (*LookupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LookupResponse)
+	err := c.cc.Invoke(ctx, Enricher_Lookup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EnricherServer is the server API for Enricher service.
+// All implementations must embed UnimplementedEnricherServer
+// for forward compatibility.
+//
+// Enricher is implemented by external services to enrich the events of
+// Inspektor Gadget with data like the owner or the service of an IP address.
+type EnricherServer interface {
+	// Lookup returns the values for a batch of keys
+	Lookup(context.Context, *LookupRequest) (*LookupResponse, error)
+	mustEmbedUnimplementedEnricherServer()
+}
+
+// UnimplementedEnricherServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedEnricherServer struct{}
+
+func (UnimplementedEnricherServer) Lookup(context.Context, *LookupRequest) (*LookupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Lookup not implemented")
+}
+func (UnimplementedEnricherServer) mustEmbedUnimplementedEnricherServer() {}
+func (UnimplementedEnricherServer) testEmbeddedByValue()                  {}
+
+// UnsafeEnricherServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to EnricherServer will
+// result in compilation errors.
+type UnsafeEnricherServer interface {
+	mustEmbedUnimplementedEnricherServer()
+}
+
+func RegisterEnricherServer(s grpc.ServiceRegistrar, srv EnricherServer) {
+	// If the following call pancis, it indicates UnimplementedEnricherServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Enricher_ServiceDesc, srv)
+}
+
+func _Enricher_Lookup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LookupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnricherServer).Lookup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Enricher_Lookup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnricherServer).Lookup(ctx, req.(*LookupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Enricher_ServiceDesc is the grpc.ServiceDesc for Enricher service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Enricher_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "enricher.Enricher",
+	HandlerType: (*EnricherServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Lookup",
+			Handler:    _Enricher_Lookup_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/enricher.proto",
+}
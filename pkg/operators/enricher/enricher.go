@@ -0,0 +1,513 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package enricher is a data operator that enriches fields with values
+// returned by user-provided services. Enrichers are defined in the
+// configuration file; each of them applies to the fields having a given tag
+// and is either a local gRPC service implementing the Enricher API or a WASM
+// module. Lookups are batched and run asynchronously: events are never
+// blocked waiting for an enricher, they are enriched once the result of the
+// lookup of their key is in the cache.
+package enricher
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/cachedmap"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/config"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/common"
+	enricherapi "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/enricher/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+)
+
+const (
+	name = "enricher"
+
+	TypeGRPC = "grpc"
+	TypeWASM = "wasm"
+
+	DefaultTTL          = 5 * time.Minute
+	DefaultBatchSize    = 100
+	DefaultBatchTimeout = 100 * time.Millisecond
+	DefaultTimeout      = time.Second
+	DefaultQueueSize    = 10000
+
+	// Priority runs the operator after the Kubernetes resolvers and before
+	// the filter operator, so enriched fields can be used in filters
+	Priority = 20
+
+	endpointL4Type = "gadget_l4endpoint_t"
+	ipAddrType     = "gadget_ip_addr_t"
+)
+
+var supportedTypes = []string{TypeGRPC, TypeWASM}
+
+type enricherConfig struct {
+	Type string `json:"type" yaml:"type"`
+	// Address of the gRPC service, e.g. unix:///run/enricher.sock
+	Address string `json:"address" yaml:"address"`
+	// Path of the WASM module
+	Path string `json:"path" yaml:"path"`
+	// Tag selects the fields to enrich, e.g. type:gadget_l4endpoint_t
+	Tag string `json:"tag" yaml:"tag"`
+	// Fields are the names of the values returned by the enricher that are
+	// added to the data sources; other values are ignored
+	Fields []string `json:"fields" yaml:"fields"`
+
+	TTL          time.Duration `json:"ttl" yaml:"ttl"`
+	BatchSize    int           `json:"batchSize" yaml:"batchSize"`
+	BatchTimeout time.Duration `json:"batchTimeout" yaml:"batchTimeout"`
+	Timeout      time.Duration `json:"timeout" yaml:"timeout"`
+	QueueSize    int           `json:"queueSize" yaml:"queueSize"`
+}
+
+// validate checks the configuration and sets the defaults
+func (c *enricherConfig) validate() error {
+	switch c.Type {
+	case TypeGRPC:
+		if c.Address == "" {
+			return fmt.Errorf("address is required")
+		}
+	case TypeWASM:
+		if c.Path == "" {
+			return fmt.Errorf("path is required")
+		}
+	default:
+		return fmt.Errorf("unsupported enricher type %q; expected one of %s", c.Type,
+			strings.Join(supportedTypes, ", "))
+	}
+	if c.Tag == "" {
+		return fmt.Errorf("tag is required")
+	}
+	if len(c.Fields) == 0 {
+		return fmt.Errorf("fields are required")
+	}
+	for _, f := range c.Fields {
+		if f == "" || strings.Contains(f, ".") {
+			return fmt.Errorf("invalid field name %q", f)
+		}
+	}
+	if c.TTL <= 0 {
+		c.TTL = DefaultTTL
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = DefaultBatchSize
+	}
+	if c.BatchTimeout <= 0 {
+		c.BatchTimeout = DefaultBatchTimeout
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = DefaultQueueSize
+	}
+	return nil
+}
+
+type enricherOperator struct {
+	enrichers map[string]*enricherConfig
+}
+
+func (e *enricherOperator) Name() string {
+	return name
+}
+
+func (e *enricherOperator) Init(params *params.Params) error {
+	e.enrichers = make(map[string]*enricherConfig)
+
+	if config.Config == nil {
+		return nil
+	}
+
+	enrichers := make(map[string]*enricherConfig)
+	log.Debugf("loading enrichers")
+	err := config.Config.UnmarshalKey("operator.enricher.enrichers", &enrichers)
+	if err != nil {
+		log.Warnf("failed to load operator.enricher.enrichers: %v", err)
+	}
+	for k, v := range enrichers {
+		if err := v.validate(); err != nil {
+			return fmt.Errorf("enricher %q: %w", k, err)
+		}
+		e.enrichers[k] = v
+		log.Debugf("> enricher %q of type %q loaded", k, v.Type)
+	}
+	return nil
+}
+
+func (e *enricherOperator) GlobalParams() api.Params {
+	return nil
+}
+
+func (e *enricherOperator) InstanceParams() api.Params {
+	return nil
+}
+
+// target is a field enriched by an enricher
+type target struct {
+	key    func(datasource.Data) (string, error)
+	fields map[string]datasource.FieldAccessor
+}
+
+func (e *enricherOperator) InstantiateDataOperator(gadgetCtx operators.GadgetContext, instanceParamValues api.ParamValues) (operators.DataOperatorInstance, error) {
+	// Sort names to add fields in a stable order
+	names := make([]string, 0, len(e.enrichers))
+	for enricherName := range e.enrichers {
+		names = append(names, enricherName)
+	}
+	slices.Sort(names)
+
+	var enrichers []*enricher
+	for _, enricherName := range names {
+		cfg := e.enrichers[enricherName]
+
+		targets := make(map[datasource.DataSource][]*target)
+		for _, ds := range gadgetCtx.GetDataSources() {
+			for _, f := range ds.GetFieldsWithTag(cfg.Tag) {
+				t, err := newTarget(ds, f, enricherName, cfg.Fields)
+				if err != nil {
+					return nil, fmt.Errorf("enricher %q: data source %q: %w", enricherName, ds.Name(), err)
+				}
+				targets[ds] = append(targets[ds], t)
+			}
+		}
+		if len(targets) == 0 {
+			continue
+		}
+
+		enrichers = append(enrichers, &enricher{
+			name:    enricherName,
+			cfg:     cfg,
+			targets: targets,
+			pending: make(map[string]struct{}),
+			queue:   make(chan string, cfg.QueueSize),
+			now:     time.Now,
+		})
+	}
+
+	// No tagged fields, nothing to do
+	if len(enrichers) == 0 {
+		return nil, nil
+	}
+
+	return &enricherOperatorInstance{
+		enrichers: enrichers,
+	}, nil
+}
+
+// newTarget returns the target for a tagged field. Values returned by the
+// enricher are added as subfields of a new "<enricher>" field: below the
+// tagged field for endpoints and next to it otherwise.
+func newTarget(ds datasource.DataSource, f datasource.FieldAccessor, enricherName string, fields []string) (*target, error) {
+	t := &target{
+		fields: make(map[string]datasource.FieldAccessor, len(fields)),
+	}
+
+	var container datasource.FieldAccessor
+	var err error
+	if f.HasAllTagsOf("type:" + endpointL4Type) {
+		ips := f.GetSubFieldsWithTag("type:" + ipAddrType)
+		if len(ips) != 1 {
+			return nil, fmt.Errorf("%s: expected %d %q field, got %d", f.Name(), 1, ipAddrType, len(ips))
+		}
+		versions := f.GetSubFieldsWithTag("name:version")
+		if len(versions) != 1 {
+			return nil, fmt.Errorf("%s: expected %d %q field, got %d", f.Name(), 1, "version", len(versions))
+		}
+		t.key = func(data datasource.Data) (string, error) {
+			return common.GetIPForVersion(data, versions[0], ips[0])
+		}
+		container, err = f.AddSubField(enricherName, api.Kind_Invalid, datasource.WithFlags(datasource.FieldFlagEmpty))
+	} else {
+		if f.Type() == api.Kind_Invalid {
+			return nil, fmt.Errorf("%s: field has no value", f.Name())
+		}
+		t.key = func(data datasource.Data) (string, error) {
+			return datasource.ValueString(f, data), nil
+		}
+		containerName := f.Name() + "_" + enricherName
+		if parent := f.Parent(); parent != nil {
+			container, err = parent.AddSubField(containerName, api.Kind_Invalid, datasource.WithFlags(datasource.FieldFlagEmpty))
+		} else {
+			container, err = ds.AddField(containerName, api.Kind_Invalid, datasource.WithFlags(datasource.FieldFlagEmpty))
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("adding field %q: %w", enricherName, err)
+	}
+
+	for _, fieldName := range fields {
+		acc, err := container.AddSubField(fieldName, api.Kind_String)
+		if err != nil {
+			return nil, fmt.Errorf("adding field %q: %w", fieldName, err)
+		}
+		t.fields[fieldName] = acc
+	}
+	return t, nil
+}
+
+func (e *enricherOperator) Priority() int {
+	return Priority
+}
+
+// cacheEntry contains the values returned for a key. Keys unknown to the
+// enricher are cached with empty values to avoid looking them up again.
+type cacheEntry struct {
+	values  map[string]string
+	expires time.Time
+}
+
+// enricher runs the lookups of an enricher for a gadget instance
+type enricher struct {
+	name     string
+	cfg      *enricherConfig
+	targets  map[datasource.DataSource][]*target
+	lookuper lookuper
+
+	// cache contains the results of the lookups. sweep removes expired
+	// entries up to a TTL after they expire, but the cached map still returns
+	// removed entries for one to two TTLs, so expired values are used while
+	// they are refreshed.
+	cache cachedmap.CachedMap[string, *cacheEntry]
+
+	// pending contains the keys that are queued or being looked up; the
+	// queue is closed holding pendingMu
+	pendingMu sync.Mutex
+	pending   map[string]struct{}
+	closed    bool
+
+	queue chan string
+	wg    sync.WaitGroup
+
+	now    func() time.Time
+	logger logger.Logger
+}
+
+// enrich sets the fields of the target from the cache and queues a lookup if
+// the key isn't cached or expired
+func (e *enricher) enrich(t *target, data datasource.Data) error {
+	key, err := t.key(data)
+	if err != nil || key == "" {
+		return nil
+	}
+
+	entry, ok := e.cache.Get(key)
+	if !ok || !e.now().Before(entry.expires) {
+		e.enqueue(key)
+	}
+	if !ok {
+		return nil
+	}
+
+	for fieldName, value := range entry.values {
+		if err := t.fields[fieldName].PutString(data, value); err != nil {
+			return fmt.Errorf("setting field %q: %w", fieldName, err)
+		}
+	}
+	return nil
+}
+
+func (e *enricher) enqueue(key string) {
+	e.pendingMu.Lock()
+	defer e.pendingMu.Unlock()
+
+	if _, ok := e.pending[key]; ok || e.closed {
+		return
+	}
+	select {
+	case e.queue <- key:
+		e.pending[key] = struct{}{}
+	default:
+		// Try again with a later event
+	}
+}
+
+func (e *enricher) clearPending(keys []string) {
+	e.pendingMu.Lock()
+	defer e.pendingMu.Unlock()
+
+	for _, key := range keys {
+		delete(e.pending, key)
+	}
+}
+
+// run batches the queued keys and looks them up until the queue is closed
+func (e *enricher) run() {
+	defer e.wg.Done()
+
+	batchTimer := time.NewTimer(e.cfg.BatchTimeout)
+	batchTimer.Stop()
+	sweepTicker := time.NewTicker(e.cfg.TTL)
+	defer sweepTicker.Stop()
+
+	var batch []string
+	for {
+		select {
+		case key, ok := <-e.queue:
+			if !ok {
+				return
+			}
+			if len(batch) == 0 {
+				batchTimer.Reset(e.cfg.BatchTimeout)
+			}
+			batch = append(batch, key)
+			if len(batch) < e.cfg.BatchSize {
+				continue
+			}
+			batchTimer.Stop()
+		case <-batchTimer.C:
+		case <-sweepTicker.C:
+			e.sweep()
+			continue
+		}
+
+		e.lookup(batch)
+		batch = nil
+	}
+}
+
+func (e *enricher) lookup(keys []string) {
+	defer e.clearPending(keys)
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.cfg.Timeout)
+	defer cancel()
+
+	results, err := e.lookuper.Lookup(ctx, &enricherapi.LookupRequest{
+		Enricher: e.name,
+		Keys:     keys,
+	})
+	if err != nil {
+		e.logger.Warnf("enricher %q: looking up %d keys: %v", e.name, len(keys), err)
+		return
+	}
+	if len(results) != len(keys) {
+		e.logger.Warnf("enricher %q: got %d results for %d keys", e.name, len(results), len(keys))
+		return
+	}
+
+	expires := e.now().Add(e.cfg.TTL)
+	for i, key := range keys {
+		entry := &cacheEntry{
+			values:  make(map[string]string, len(e.cfg.Fields)),
+			expires: expires,
+		}
+		if results[i].GetFound() {
+			for _, fieldName := range e.cfg.Fields {
+				if value, ok := results[i].Values[fieldName]; ok {
+					entry.values[fieldName] = value
+				}
+			}
+		}
+		e.cache.Add(key, entry)
+	}
+}
+
+// sweep removes the expired entries from the cache. See the cache field for
+// how long removed entries are still used.
+func (e *enricher) sweep() {
+	now := e.now()
+	for _, key := range e.cache.Keys() {
+		if entry, ok := e.cache.Get(key); ok && !now.Before(entry.expires) {
+			e.cache.Remove(key)
+		}
+	}
+}
+
+type enricherOperatorInstance struct {
+	enrichers []*enricher
+	closeOnce sync.Once
+}
+
+func (e *enricherOperatorInstance) Name() string {
+	return name
+}
+
+func (e *enricherOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
+	for _, enr := range e.enrichers {
+		l, err := newLookuper(gadgetCtx.Context(), enr.cfg)
+		if err != nil {
+			e.close()
+			return fmt.Errorf("enricher %q: %w", enr.name, err)
+		}
+		enr.lookuper = l
+		enr.logger = gadgetCtx.Logger()
+		enr.cache = cachedmap.NewCachedMap[string, *cacheEntry](enr.cfg.TTL)
+
+		enr.wg.Add(1)
+		go enr.run()
+
+		for ds, targets := range enr.targets {
+			ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+				for _, t := range targets {
+					if err := enr.enrich(t, data); err != nil {
+						return fmt.Errorf("enricher %q: %w", enr.name, err)
+					}
+				}
+				return nil
+			}, Priority)
+		}
+	}
+	return nil
+}
+
+func (e *enricherOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func (e *enricherOperatorInstance) Stop(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func (e *enricherOperatorInstance) Close(gadgetCtx operators.GadgetContext) error {
+	e.close()
+	return nil
+}
+
+func (e *enricherOperatorInstance) close() {
+	e.closeOnce.Do(func() {
+		for _, enr := range e.enrichers {
+			if enr.lookuper == nil {
+				continue
+			}
+			enr.pendingMu.Lock()
+			enr.closed = true
+			close(enr.queue)
+			enr.pendingMu.Unlock()
+
+			enr.wg.Wait()
+			enr.cache.Close()
+			if err := enr.lookuper.Close(); err != nil {
+				enr.logger.Warnf("enricher %q: closing: %v", enr.name, err)
+			}
+		}
+	})
+}
+
+var Operator = &enricherOperator{}
+
+func init() {
+	operators.RegisterDataOperator(Operator)
+}
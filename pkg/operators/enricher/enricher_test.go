@@ -0,0 +1,358 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enricher
+
+import (
+	"context"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	enricherapi "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/enricher/api"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/testing/gadget-context"
+)
+
+// ownerService returns the owner of IP addresses. It blocks until release is
+// closed to simulate a slow enricher.
+type ownerService struct {
+	enricherapi.UnimplementedEnricherServer
+
+	release chan struct{}
+
+	mu       sync.Mutex
+	requests [][]string
+}
+
+func (s *ownerService) Lookup(ctx context.Context, req *enricherapi.LookupRequest) (*enricherapi.LookupResponse, error) {
+	<-s.release
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req.Keys)
+	s.mu.Unlock()
+
+	resp := &enricherapi.LookupResponse{}
+	for _, key := range req.Keys {
+		if key != "10.0.0.1" {
+			resp.Results = append(resp.Results, &enricherapi.LookupResult{})
+			continue
+		}
+		resp.Results = append(resp.Results, &enricherapi.LookupResult{
+			Found:  true,
+			Values: map[string]string{"owner": "team-a", "ignored": "value"},
+		})
+	}
+	return resp, nil
+}
+
+func (s *ownerService) lookups() [][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]string(nil), s.requests...)
+}
+
+func startOwnerService(t *testing.T) (*ownerService, string) {
+	t.Helper()
+
+	socketPath := filepath.Join(t.TempDir(), "enricher.sock")
+	lis, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	svc := &ownerService{release: make(chan struct{})}
+	server := grpc.NewServer()
+	enricherapi.RegisterEnricherServer(server, svc)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	return svc, "unix://" + socketPath
+}
+
+func TestEnricherConfigValidate(t *testing.T) {
+	cfg := &enricherConfig{Type: TypeGRPC, Address: "unix:///run/enricher.sock", Tag: "enrich", Fields: []string{"owner"}}
+	require.NoError(t, cfg.validate())
+	assert.Equal(t, DefaultTTL, cfg.TTL)
+	assert.Equal(t, DefaultBatchSize, cfg.BatchSize)
+	assert.Equal(t, DefaultQueueSize, cfg.QueueSize)
+
+	for _, cfg := range []*enricherConfig{
+		{Type: "http", Address: "localhost:80", Tag: "enrich", Fields: []string{"owner"}},
+		{Type: TypeGRPC, Tag: "enrich", Fields: []string{"owner"}},
+		{Type: TypeWASM, Address: "localhost:80", Tag: "enrich", Fields: []string{"owner"}},
+		{Type: TypeGRPC, Address: "localhost:80", Fields: []string{"owner"}},
+		{Type: TypeGRPC, Address: "localhost:80", Tag: "enrich"},
+		{Type: TypeGRPC, Address: "localhost:80", Tag: "enrich", Fields: []string{"a.b"}},
+	} {
+		require.Error(t, cfg.validate(), "%+v", cfg)
+	}
+}
+
+// endpointDs is a data source with an endpoint like the ones of network
+// gadgets
+type endpointDs struct {
+	ds        datasource.DataSource
+	gadgetCtx *gadgetcontext.MockGadgetContext
+
+	ipField      datasource.FieldAccessor
+	versionField datasource.FieldAccessor
+
+	// owner is the owner set by the enricher on the last event
+	owner string
+}
+
+func newEndpointDs(t *testing.T) *endpointDs {
+	t.Helper()
+
+	e := &endpointDs{}
+	var err error
+	e.ds, err = datasource.New(datasource.TypeSingle, "connect")
+	require.NoError(t, err)
+	ep, err := e.ds.AddField("dst", api.Kind_Invalid, datasource.WithTags("type:"+endpointL4Type))
+	require.NoError(t, err)
+	e.ipField, err = ep.AddSubField("addr_raw", api.Kind_Bytes, datasource.WithTags("type:"+ipAddrType))
+	require.NoError(t, err)
+	e.versionField, err = ep.AddSubField("version", api.Kind_Uint8, datasource.WithTags("name:version"))
+	require.NoError(t, err)
+
+	e.gadgetCtx = &gadgetcontext.MockGadgetContext{
+		Ctx:         context.Background(),
+		DataSources: map[string]datasource.DataSource{"connect": e.ds},
+	}
+	return e
+}
+
+// start starts the enricher operator on the data source
+func (e *endpointDs) start(t *testing.T, inst *enricherOperatorInstance) {
+	t.Helper()
+
+	require.NoError(t, inst.PreStart(e.gadgetCtx))
+	t.Cleanup(func() { inst.Close(e.gadgetCtx) })
+
+	ownerField := e.ds.GetField("dst.owners.owner")
+	require.NotNil(t, ownerField)
+	e.ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+		e.owner, _ = ownerField.String(data)
+		return nil
+	}, Priority+1)
+}
+
+// emit emits an event and returns the owner set by the enricher
+func (e *endpointDs) emit(t *testing.T, ip string) string {
+	t.Helper()
+
+	packet, err := e.ds.NewPacketSingle()
+	require.NoError(t, err)
+	// IPv4 addresses are in the first 4 bytes
+	raw := make([]byte, 16)
+	copy(raw, net.ParseIP(ip).To4())
+	require.NoError(t, e.ipField.Set(packet, raw))
+	require.NoError(t, e.versionField.PutUint8(packet, 4))
+	require.NoError(t, e.ds.EmitAndRelease(packet))
+	return e.owner
+}
+
+func TestEnricherGRPC(t *testing.T) {
+	svc, address := startOwnerService(t)
+
+	op := &enricherOperator{
+		enrichers: map[string]*enricherConfig{
+			"owners": {
+				Type:         TypeGRPC,
+				Address:      address,
+				Tag:          "type:" + endpointL4Type,
+				Fields:       []string{"owner"},
+				BatchTimeout: 10 * time.Millisecond,
+			},
+			"unused": {Type: TypeGRPC, Address: address, Tag: "enrich", Fields: []string{"owner"}},
+		},
+	}
+	for _, cfg := range op.enrichers {
+		require.NoError(t, cfg.validate())
+	}
+
+	e := newEndpointDs(t)
+	inst, err := op.InstantiateDataOperator(e.gadgetCtx, api.ParamValues{})
+	require.NoError(t, err)
+	enricherInst := inst.(*enricherOperatorInstance)
+	require.Len(t, enricherInst.enrichers, 1)
+	e.start(t, enricherInst)
+
+	// Events aren't blocked while the enricher is busy
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 3 {
+			assert.Empty(t, e.emit(t, "10.0.0.1"))
+			assert.Empty(t, e.emit(t, "10.0.0.2"))
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("events blocked by the enricher")
+	}
+	close(svc.release)
+
+	require.Eventually(t, func() bool {
+		return e.emit(t, "10.0.0.1") == "team-a"
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Empty(t, e.emit(t, "10.0.0.2"))
+
+	// Keys are looked up once, in a single batch
+	assert.Equal(t, [][]string{{"10.0.0.1", "10.0.0.2"}}, svc.lookups())
+
+	// Only the configured fields are added
+	assert.Nil(t, e.ds.GetField("dst.owners.ignored"))
+}
+
+func TestEnricherExpiredEntries(t *testing.T) {
+	svc, address := startOwnerService(t)
+	close(svc.release)
+
+	cfg := &enricherConfig{Type: TypeGRPC, Address: address, Tag: "type:" + endpointL4Type, Fields: []string{"owner"}}
+	require.NoError(t, cfg.validate())
+	op := &enricherOperator{enrichers: map[string]*enricherConfig{"owners": cfg}}
+
+	e := newEndpointDs(t)
+	inst, err := op.InstantiateDataOperator(e.gadgetCtx, api.ParamValues{})
+	require.NoError(t, err)
+	enricherInst := inst.(*enricherOperatorInstance)
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var nowMu sync.Mutex
+	enr := enricherInst.enrichers[0]
+	enr.now = func() time.Time {
+		nowMu.Lock()
+		defer nowMu.Unlock()
+		return now
+	}
+
+	e.start(t, enricherInst)
+
+	require.Eventually(t, func() bool {
+		return e.emit(t, "10.0.0.1") == "team-a"
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Len(t, svc.lookups(), 1)
+
+	nowMu.Lock()
+	now = now.Add(DefaultTTL)
+	nowMu.Unlock()
+
+	// Expired entries are used until they are refreshed
+	assert.Equal(t, "team-a", e.emit(t, "10.0.0.1"))
+	require.Eventually(t, func() bool {
+		return len(svc.lookups()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestEnricherInvalidFields(t *testing.T) {
+	ds, err := datasource.New(datasource.TypeSingle, "connect")
+	require.NoError(t, err)
+	ep, err := ds.AddField("dst", api.Kind_Invalid, datasource.WithTags("type:"+endpointL4Type))
+	require.NoError(t, err)
+	_, err = ep.AddSubField("addr_raw", api.Kind_Bytes, datasource.WithTags("type:"+ipAddrType))
+	require.NoError(t, err)
+	gadgetCtx := &gadgetcontext.MockGadgetContext{
+		Ctx:         context.Background(),
+		DataSources: map[string]datasource.DataSource{"connect": ds},
+	}
+
+	cfg := &enricherConfig{Type: TypeGRPC, Address: "localhost:1", Tag: "type:" + endpointL4Type, Fields: []string{"owner"}}
+	require.NoError(t, cfg.validate())
+	op := &enricherOperator{enrichers: map[string]*enricherConfig{"owners": cfg}}
+	_, err = op.InstantiateDataOperator(gadgetCtx, api.ParamValues{})
+	require.ErrorContains(t, err, "version")
+
+	op = &enricherOperator{enrichers: map[string]*enricherConfig{}}
+	inst, err := op.InstantiateDataOperator(gadgetCtx, api.ParamValues{})
+	require.NoError(t, err)
+	require.Nil(t, inst)
+}
+
+func buildWASMEnricher(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "enricher.wasm")
+	cmd := exec.Command("go", "build", "-buildmode=c-shared", "-o", path, ".")
+	cmd.Dir = filepath.Join("testdata", "wasm")
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("building wasm enricher: %v: %s", err, out)
+	}
+	return path
+}
+
+func TestEnricherWASM(t *testing.T) {
+	path := buildWASMEnricher(t)
+
+	ds, err := datasource.New(datasource.TypeSingle, "exec")
+	require.NoError(t, err)
+	proc, err := ds.AddField("proc", api.Kind_Invalid, datasource.WithFlags(datasource.FieldFlagEmpty))
+	require.NoError(t, err)
+	commField, err := proc.AddSubField("comm", api.Kind_String, datasource.WithTags("enrich"))
+	require.NoError(t, err)
+	gadgetCtx := &gadgetcontext.MockGadgetContext{
+		Ctx:         context.Background(),
+		DataSources: map[string]datasource.DataSource{"exec": ds},
+	}
+
+	cfg := &enricherConfig{
+		Type:         TypeWASM,
+		Path:         path,
+		Tag:          "enrich",
+		Fields:       []string{"upper"},
+		BatchTimeout: 10 * time.Millisecond,
+	}
+	require.NoError(t, cfg.validate())
+	op := &enricherOperator{enrichers: map[string]*enricherConfig{"names": cfg}}
+
+	inst, err := op.InstantiateDataOperator(gadgetCtx, api.ParamValues{})
+	require.NoError(t, err)
+	enricherInst := inst.(*enricherOperatorInstance)
+	require.NoError(t, enricherInst.PreStart(gadgetCtx))
+	defer enricherInst.Close(gadgetCtx)
+
+	// Values of other fields are added next to them
+	upperField := ds.GetField("proc.comm_names.upper")
+	require.NotNil(t, upperField)
+
+	var upper string
+	ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+		upper, _ = upperField.String(data)
+		return nil
+	}, Priority+1)
+
+	emit := func(comm string) string {
+		packet, err := ds.NewPacketSingle()
+		require.NoError(t, err)
+		require.NoError(t, commField.PutString(packet, comm))
+		require.NoError(t, ds.EmitAndRelease(packet))
+		return upper
+	}
+
+	emit("xargs")
+	require.Eventually(t, func() bool {
+		return emit("curl") == "CURL"
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Empty(t, emit("xargs"))
+}
@@ -0,0 +1,181 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enricher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+	wapi "github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/enricher/api"
+)
+
+const (
+	// Functions exported by WASM enrichers
+	wasmAllocFunc  = "enricherAlloc"
+	wasmLookupFunc = "enricherLookup"
+)
+
+// lookuper looks up the values of a batch of keys. It must return a result
+// per key, in the same order.
+type lookuper interface {
+	Lookup(ctx context.Context, req *api.LookupRequest) ([]*api.LookupResult, error)
+	Close() error
+}
+
+func newLookuper(ctx context.Context, cfg *enricherConfig) (lookuper, error) {
+	switch cfg.Type {
+	case TypeGRPC:
+		return newGRPCLookuper(cfg)
+	case TypeWASM:
+		return newWASMLookuper(ctx, cfg)
+	}
+	return nil, fmt.Errorf("unsupported enricher type %q", cfg.Type)
+}
+
+// grpcLookuper calls a service implementing the Enricher gRPC API
+type grpcLookuper struct {
+	conn   *grpc.ClientConn
+	client api.EnricherClient
+}
+
+func newGRPCLookuper(cfg *enricherConfig) (*grpcLookuper, error) {
+	conn, err := grpc.NewClient(cfg.Address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("creating gRPC client for %q: %w", cfg.Address, err)
+	}
+	return &grpcLookuper{
+		conn:   conn,
+		client: api.NewEnricherClient(conn),
+	}, nil
+}
+
+func (l *grpcLookuper) Lookup(ctx context.Context, req *api.LookupRequest) ([]*api.LookupResult, error) {
+	resp, err := l.client.Lookup(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Results, nil
+}
+
+func (l *grpcLookuper) Close() error {
+	return l.conn.Close()
+}
+
+// wasmLookuper calls a WASM module exporting the following functions:
+//
+//	enricherAlloc(size uint32) uint32
+//	enricherLookup(request uint64) uint64
+//
+// enricherAlloc returns the address of a buffer of the given size in the
+// memory of the module, where the request is written. Pointers to buffers
+// are passed as uint64 with the address in the lower 32 bits and the length
+// in the upper 32 bits. Requests and responses are LookupRequest and
+// LookupResponse messages encoded as JSON. enricherLookup returns 0 on
+// errors.
+type wasmLookuper struct {
+	rt     wazero.Runtime
+	mod    wapi.Module
+	alloc  wapi.Function
+	lookup wapi.Function
+
+	// WASM modules can't be called concurrently
+	mu sync.Mutex
+}
+
+func newWASMLookuper(ctx context.Context, cfg *enricherConfig) (*wasmLookuper, error) {
+	program, err := os.ReadFile(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("reading wasm module: %w", err)
+	}
+
+	rtConfig := wazero.NewRuntimeConfig().
+		WithCloseOnContextDone(true).
+		WithMemoryLimitPages(256) // 16MB (64KB per page)
+	rt := wazero.NewRuntimeWithConfig(ctx, rtConfig)
+
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, rt); err != nil {
+		rt.Close(ctx)
+		return nil, fmt.Errorf("instantiating WASI: %w", err)
+	}
+
+	mod, err := rt.InstantiateWithConfig(ctx, program, wazero.NewModuleConfig().WithStartFunctions("_initialize"))
+	if err != nil {
+		rt.Close(ctx)
+		return nil, fmt.Errorf("instantiating wasm module %q: %w", cfg.Path, err)
+	}
+
+	l := &wasmLookuper{
+		rt:     rt,
+		mod:    mod,
+		alloc:  mod.ExportedFunction(wasmAllocFunc),
+		lookup: mod.ExportedFunction(wasmLookupFunc),
+	}
+	if l.alloc == nil || l.lookup == nil {
+		rt.Close(ctx)
+		return nil, fmt.Errorf("wasm module %q doesn't export %s and %s", cfg.Path, wasmAllocFunc, wasmLookupFunc)
+	}
+	return l, nil
+}
+
+func (l *wasmLookuper) Lookup(ctx context.Context, req *api.LookupRequest) ([]*api.LookupResult, error) {
+	in, err := protojson.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("encoding request: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ret, err := l.alloc.Call(ctx, uint64(len(in)))
+	if err != nil {
+		return nil, fmt.Errorf("calling %s: %w", wasmAllocFunc, err)
+	}
+	addr := uint32(ret[0])
+	if addr == 0 || !l.mod.Memory().Write(addr, in) {
+		return nil, fmt.Errorf("writing request to wasm memory at %#x", addr)
+	}
+
+	ret, err = l.lookup.Call(ctx, uint64(len(in))<<32|uint64(addr))
+	if err != nil {
+		return nil, fmt.Errorf("calling %s: %w", wasmLookupFunc, err)
+	}
+	if ret[0] == 0 {
+		return nil, errors.New("wasm enricher failed")
+	}
+	out, ok := l.mod.Memory().Read(uint32(ret[0]), uint32(ret[0]>>32))
+	if !ok {
+		return nil, errors.New("reading response from wasm memory: invalid pointer")
+	}
+
+	resp := &api.LookupResponse{}
+	if err := protojson.Unmarshal(out, resp); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	return resp.Results, nil
+}
+
+func (l *wasmLookuper) Close() error {
+	return l.rt.Close(context.Background())
+}
@@ -0,0 +1,3 @@
+module enricher
+
+go 1.25.7
@@ -0,0 +1,84 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This is an example WASM enricher returning the upper case version of keys.
+// Keys starting with "x" aren't found. Build it with:
+//
+//	GOOS=wasip1 GOARCH=wasm go build -buildmode=c-shared -o enricher.wasm .
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"unsafe"
+)
+
+type lookupRequest struct {
+	Enricher string   `json:"enricher"`
+	Keys     []string `json:"keys"`
+}
+
+type lookupResult struct {
+	Found  bool              `json:"found,omitempty"`
+	Values map[string]string `json:"values,omitempty"`
+}
+
+type lookupResponse struct {
+	Results []lookupResult `json:"results"`
+}
+
+var (
+	// request and response keep the buffers shared with the host alive
+	request  []byte
+	response []byte
+)
+
+func pointer(b []byte) uint32 {
+	return uint32(uintptr(unsafe.Pointer(unsafe.SliceData(b))))
+}
+
+//go:wasmexport enricherAlloc
+func enricherAlloc(size uint32) uint32 {
+	request = make([]byte, size)
+	return pointer(request)
+}
+
+//go:wasmexport enricherLookup
+func enricherLookup(req uint64) uint64 {
+	var r lookupRequest
+	if err := json.Unmarshal(request[:req>>32], &r); err != nil {
+		return 0
+	}
+
+	resp := lookupResponse{Results: make([]lookupResult, 0, len(r.Keys))}
+	for _, key := range r.Keys {
+		if strings.HasPrefix(key, "x") {
+			resp.Results = append(resp.Results, lookupResult{})
+			continue
+		}
+		resp.Results = append(resp.Results, lookupResult{
+			Found:  true,
+			Values: map[string]string{"upper": strings.ToUpper(key)},
+		})
+	}
+
+	var err error
+	response, err = json.Marshal(resp)
+	if err != nil {
+		return 0
+	}
+	return uint64(len(response))<<32 | uint64(pointer(response))
+}
+
+func main() {}
@@ -30,122 +30,6 @@ import (
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/simple"
 )
 
-func TestFilterRuleExtractor(t *testing.T) {
-	type testCase struct {
-		filter    string
-		fieldName string
-		op        comparisonType
-		negate    bool
-		value     string
-		error     bool
-	}
-	testCases := []testCase{
-		{
-			filter:    "abc==def",
-			fieldName: "abc",
-			op:        comparisonTypeMatch,
-			negate:    false,
-			value:     "def",
-		},
-		{
-			filter:    "abc!=def",
-			fieldName: "abc",
-			op:        comparisonTypeMatch,
-			negate:    true,
-			value:     "def",
-		},
-		{
-			filter:    "abc<=def",
-			fieldName: "abc",
-			op:        comparisonTypeLte,
-			negate:    false,
-			value:     "def",
-		},
-		{
-			filter:    "abc<def",
-			fieldName: "abc",
-			op:        comparisonTypeLt,
-			negate:    false,
-			value:     "def",
-		},
-		{
-			filter:    "abc>=def",
-			fieldName: "abc",
-			op:        comparisonTypeGte,
-			negate:    false,
-			value:     "def",
-		},
-		{
-			filter:    "abc>def",
-			fieldName: "abc",
-			op:        comparisonTypeGt,
-			negate:    false,
-			value:     "def",
-		},
-		{
-			filter:    "abc~def",
-			fieldName: "abc",
-			op:        comparisonTypeRegex,
-			negate:    false,
-			value:     "def",
-		},
-		{
-			filter:    "abc!~def",
-			fieldName: "abc",
-			op:        comparisonTypeRegex,
-			negate:    true,
-			value:     "def",
-		},
-		{
-			filter: "incomplete",
-			error:  true,
-		},
-		{
-			filter: "abc==",
-			error:  true,
-		},
-		{
-			filter: "abc===def",
-			error:  true,
-		},
-		{
-			filter: "abc!==def",
-			error:  true,
-		},
-		{
-			filter: "abc!def",
-			error:  true,
-		},
-		{
-			filter: "abc!",
-			error:  true,
-		},
-		{
-			filter: "abc:",
-			error:  true,
-		},
-		{
-			filter: ":",
-			error:  true,
-		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.filter, func(t *testing.T) {
-			fieldName, op, negate, value, err := extractFilter(tc.filter)
-			if tc.error {
-				require.Error(t, err)
-			} else {
-				require.NoError(t, err)
-			}
-			assert.Equal(t, tc.fieldName, fieldName)
-			assert.Equal(t, tc.op, op)
-			assert.Equal(t, tc.negate, negate)
-			assert.Equal(t, tc.value, value)
-		})
-	}
-}
-
 func TestFilter(t *testing.T) {
 	testCaseData := struct {
 		stringValue        string
@@ -0,0 +1,147 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filterfunc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterRuleExtractor(t *testing.T) {
+	type testCase struct {
+		filter    string
+		fieldName string
+		op        comparisonType
+		negate    bool
+		value     string
+		error     bool
+	}
+	testCases := []testCase{
+		{
+			filter:    "abc==def",
+			fieldName: "abc",
+			op:        comparisonTypeMatch,
+			negate:    false,
+			value:     "def",
+		},
+		{
+			filter:    "abc!=def",
+			fieldName: "abc",
+			op:        comparisonTypeMatch,
+			negate:    true,
+			value:     "def",
+		},
+		{
+			filter:    "abc<=def",
+			fieldName: "abc",
+			op:        comparisonTypeLte,
+			negate:    false,
+			value:     "def",
+		},
+		{
+			filter:    "abc<def",
+			fieldName: "abc",
+			op:        comparisonTypeLt,
+			negate:    false,
+			value:     "def",
+		},
+		{
+			filter:    "abc>=def",
+			fieldName: "abc",
+			op:        comparisonTypeGte,
+			negate:    false,
+			value:     "def",
+		},
+		{
+			filter:    "abc>def",
+			fieldName: "abc",
+			op:        comparisonTypeGt,
+			negate:    false,
+			value:     "def",
+		},
+		{
+			filter:    "abc~def",
+			fieldName: "abc",
+			op:        comparisonTypeRegex,
+			negate:    false,
+			value:     "def",
+		},
+		{
+			filter:    "abc!~def",
+			fieldName: "abc",
+			op:        comparisonTypeRegex,
+			negate:    true,
+			value:     "def",
+		},
+		{
+			filter: "incomplete",
+			error:  true,
+		},
+		{
+			filter: "abc==",
+			error:  true,
+		},
+		{
+			filter: "abc===def",
+			error:  true,
+		},
+		{
+			filter: "abc!==def",
+			error:  true,
+		},
+		{
+			filter: "abc!def",
+			error:  true,
+		},
+		{
+			filter: "abc!",
+			error:  true,
+		},
+		{
+			filter: "abc:",
+			error:  true,
+		},
+		{
+			filter: ":",
+			error:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.filter, func(t *testing.T) {
+			fieldName, op, negate, value, err := extractFilter(tc.filter)
+			if tc.error {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.fieldName, fieldName)
+			assert.Equal(t, tc.op, op)
+			assert.Equal(t, tc.negate, negate)
+			assert.Equal(t, tc.value, value)
+		})
+	}
+}
+
+func TestFields(t *testing.T) {
+	fields, err := Fields(`comm==cat,pid>1000,args~\,`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"comm", "pid", "args"}, fields)
+
+	_, err = Fields("incomplete")
+	require.Error(t, err)
+}
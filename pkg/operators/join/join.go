@@ -0,0 +1,293 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package join is a data operator that correlates the events of two data
+// sources of type single by a list of key fields. Events that match within a
+// given time window are emitted as a single event on a new data source that
+// contains the fields of both sides. This is useful for gadgets that emit
+// related events on separate data sources, like enter/exit or request/response
+// events.
+package join
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+)
+
+const (
+	name             = "join"
+	ParamJoin        = "join"
+	ParamKeys        = "join-keys"
+	ParamWindow      = "join-window"
+	ParamUnmatched   = "join-unmatched"
+	ParamLatency     = "join-latency"
+	DataSourcePrefix = "join"
+
+	UnmatchedDrop  = "drop"
+	UnmatchedLeft  = "left"
+	UnmatchedRight = "right"
+	UnmatchedAll   = "all"
+
+	// Priority needs to be higher than the one of the filter operator (9000),
+	// so only filtered events are joined, and lower than the one of the
+	// aggregate operator (9100), so joined events can be aggregated
+	Priority = 9050
+)
+
+type joinOperator struct{}
+
+func (j *joinOperator) Name() string {
+	return name
+}
+
+func (j *joinOperator) Init(params *params.Params) error {
+	return nil
+}
+
+func (j *joinOperator) GlobalParams() api.Params {
+	return nil
+}
+
+func (j *joinOperator) InstanceParams() api.Params {
+	return api.Params{
+		{
+			Key:         ParamJoin,
+			Title:       "Join",
+			Description: "Names of the two data sources to join, separated by ','",
+			TypeHint:    api.TypeString,
+			Tags:        []string{api.TagGroupDataManipulation},
+		},
+		{
+			Key:   ParamKeys,
+			Title: "Join Keys",
+			Description: "Fields that need to be equal for events to match. Join multiple fields with ','. " +
+				"Use 'leftfield=rightfield' if the fields have different names on both data sources",
+			TypeHint: api.TypeString,
+			Tags:     []string{api.TagGroupDataManipulation},
+		},
+		{
+			Key:          ParamWindow,
+			Title:        "Join Window",
+			Description:  "Maximum time to wait for the matching event of the other data source",
+			DefaultValue: "1s",
+			TypeHint:     api.TypeDuration,
+			Tags:         []string{api.TagGroupDataManipulation},
+		},
+		{
+			Key:   ParamUnmatched,
+			Title: "Join Unmatched",
+			Description: "What to do with events that didn't find a match within the window: drop them or emit " +
+				"them without the fields of the other data source for the left, right or all data sources",
+			DefaultValue:   UnmatchedDrop,
+			TypeHint:       api.TypeString,
+			PossibleValues: []string{UnmatchedDrop, UnmatchedLeft, UnmatchedRight, UnmatchedAll},
+			Tags:           []string{api.TagGroupDataManipulation},
+		},
+		{
+			Key:   ParamLatency,
+			Title: "Join Latency",
+			Description: "Numeric fields of both data sources, like timestamps, in the form 'leftfield=rightfield'; " +
+				"the difference between both is added as latency field",
+			TypeHint: api.TypeString,
+			Tags:     []string{api.TagGroupDataManipulation},
+		},
+	}
+}
+
+// parseFieldPairs parses a list like `pid,tid=id` into names of fields of the
+// left and right data sources
+func parseFieldPairs(s string) (left []string, right []string) {
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		l, r, ok := strings.Cut(v, "=")
+		if !ok {
+			r = l
+		}
+		left = append(left, strings.TrimSpace(l))
+		right = append(right, strings.TrimSpace(r))
+	}
+	return left, right
+}
+
+func (j *joinOperator) InstantiateDataOperator(gadgetCtx operators.GadgetContext, instanceParamValues api.ParamValues) (operators.DataOperatorInstance, error) {
+	if instanceParamValues[ParamJoin] == "" {
+		return nil, nil
+	}
+
+	params := apihelpers.ToParamDescs(j.InstanceParams()).ToParams()
+	if err := params.CopyFromMap(instanceParamValues, ""); err != nil {
+		return nil, err
+	}
+
+	dsNames := params.Get(ParamJoin).AsStringSlice()
+	if len(dsNames) != 2 {
+		return nil, fmt.Errorf("expected two data sources for %s, got %q", ParamJoin, params.Get(ParamJoin).AsString())
+	}
+
+	var sources [2]datasource.DataSource
+	dataSources := gadgetCtx.GetDataSources()
+	for i, dsName := range dsNames {
+		ds, ok := dataSources[dsName]
+		if !ok {
+			return nil, fmt.Errorf("data source %q not found", dsName)
+		}
+		if ds.Type() != datasource.TypeSingle {
+			return nil, fmt.Errorf("data source %q can't be joined: only data sources of type single are supported", dsName)
+		}
+		sources[i] = ds
+	}
+	if sources[0] == sources[1] {
+		return nil, fmt.Errorf("can't join data source %q with itself", dsNames[0])
+	}
+
+	leftKeys, rightKeys := parseFieldPairs(params.Get(ParamKeys).AsString())
+	if len(leftKeys) == 0 {
+		return nil, fmt.Errorf("%s is required to join data sources", ParamKeys)
+	}
+
+	window := params.Get(ParamWindow).AsDuration()
+	if window <= 0 {
+		return nil, fmt.Errorf("invalid value for %s: %s", ParamWindow, window)
+	}
+
+	cfg := &joinerConfig{
+		left:      sources[0],
+		right:     sources[1],
+		leftKeys:  leftKeys,
+		rightKeys: rightKeys,
+		window:    window,
+		unmatched: params.Get(ParamUnmatched).AsString(),
+	}
+
+	if latency := params.Get(ParamLatency).AsString(); latency != "" {
+		l, r := parseFieldPairs(latency)
+		if len(l) != 1 {
+			return nil, fmt.Errorf("invalid value for %s: expected exactly one pair of fields, got %q", ParamLatency, latency)
+		}
+		cfg.leftLatency, cfg.rightLatency = l[0], r[0]
+	}
+
+	// Register a new data source that will emit the joined events
+	outDs, err := gadgetCtx.RegisterDataSource(
+		datasource.TypeSingle,
+		fmt.Sprintf("%s-%s-%s", DataSourcePrefix, dsNames[0], dsNames[1]),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("registering join data source: %w", err)
+	}
+	cfg.out = outDs
+
+	jn, err := newJoiner(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("joining data sources %q and %q: %w", dsNames[0], dsNames[1], err)
+	}
+
+	// Disable original data sources to avoid other operators subscribing to them
+	sources[0].Unreference()
+	sources[1].Unreference()
+
+	gadgetCtx.Logger().Debugf("join: joining %q and %q into %q", dsNames[0], dsNames[1], outDs.Name())
+
+	return &joinOperatorInstance{
+		joiner: jn,
+	}, nil
+}
+
+func (j *joinOperator) Priority() int {
+	return Priority
+}
+
+func (j *joinOperator) SupportsReplay() bool {
+	return true
+}
+
+type joinOperatorInstance struct {
+	joiner *joiner
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func (j *joinOperatorInstance) Name() string {
+	return name
+}
+
+func (j *joinOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
+	logger := gadgetCtx.Logger()
+	j.joiner.cfg.left.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+		if err := j.joiner.add(sideLeft, data, time.Now()); err != nil {
+			logger.Warnf("join: %v", err)
+		}
+		return nil
+	}, Priority)
+	j.joiner.cfg.right.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+		if err := j.joiner.add(sideRight, data, time.Now()); err != nil {
+			logger.Warnf("join: %v", err)
+		}
+		return nil
+	}, Priority)
+	return nil
+}
+
+func (j *joinOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
+	j.done = make(chan struct{})
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+
+		// Check for expired events a few times per window
+		ticker := time.NewTicker(max(j.joiner.cfg.window/4, 10*time.Millisecond))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-j.done:
+				return
+			case now := <-ticker.C:
+				if err := j.joiner.expire(now); err != nil {
+					gadgetCtx.Logger().Warnf("join: %v", err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (j *joinOperatorInstance) Stop(gadgetCtx operators.GadgetContext) error {
+	if j.done != nil {
+		close(j.done)
+		j.wg.Wait()
+		j.done = nil
+	}
+	return nil
+}
+
+func (j *joinOperatorInstance) Close(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+var Operator = &joinOperator{}
+
+func init() {
+	operators.RegisterDataOperator(Operator)
+}
@@ -0,0 +1,317 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package join
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/simple"
+	testds "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/testing/datasource"
+)
+
+func TestParseFieldPairs(t *testing.T) {
+	left, right := parseFieldPairs("pid, tid=id ,")
+	assert.Equal(t, []string{"pid", "tid"}, left)
+	assert.Equal(t, []string{"pid", "id"}, right)
+}
+
+type testDataSources struct {
+	query, answer      datasource.DataSource
+	queryID, queryName datasource.FieldAccessor
+	queryTs            datasource.FieldAccessor
+	answerID, answerIP datasource.FieldAccessor
+	answerTs           datasource.FieldAccessor
+}
+
+func newTestDataSources(t *testing.T, register testds.RegisterFunc) *testDataSources {
+	tds := &testDataSources{}
+	var fields []datasource.FieldAccessor
+
+	tds.query, fields = testds.New(t, register, "query",
+		testds.Field{Name: "id", Kind: api.Kind_Uint16},
+		testds.Field{Name: "name", Kind: api.Kind_String},
+		testds.Field{Name: "timestamp", Kind: api.Kind_Uint64},
+	)
+	tds.queryID, tds.queryName, tds.queryTs = fields[0], fields[1], fields[2]
+
+	tds.answer, fields = testds.New(t, register, "answer",
+		testds.Field{Name: "query_id", Kind: api.Kind_Uint32},
+		testds.Field{Name: "ip", Kind: api.Kind_String},
+		testds.Field{Name: "timestamp", Kind: api.Kind_Uint64},
+	)
+	tds.answerID, tds.answerIP, tds.answerTs = fields[0], fields[1], fields[2]
+	return tds
+}
+
+func (tds *testDataSources) newQuery(t *testing.T, id uint16, name string, ts uint64) datasource.PacketSingle {
+	p, err := tds.query.NewPacketSingle()
+	require.NoError(t, err)
+	require.NoError(t, tds.queryID.PutUint16(p, id))
+	require.NoError(t, tds.queryName.PutString(p, name))
+	require.NoError(t, tds.queryTs.PutUint64(p, ts))
+	return p
+}
+
+func (tds *testDataSources) newAnswer(t *testing.T, id uint32, ip string, ts uint64) datasource.PacketSingle {
+	p, err := tds.answer.NewPacketSingle()
+	require.NoError(t, err)
+	require.NoError(t, tds.answerID.PutUint32(p, id))
+	require.NoError(t, tds.answerIP.PutString(p, ip))
+	require.NoError(t, tds.answerTs.PutUint64(p, ts))
+	return p
+}
+
+type joinedEvent struct {
+	name    string
+	ip      string
+	latency int64
+	matched bool
+}
+
+func newTestJoiner(t *testing.T, unmatched string) (*testDataSources, *joiner, *[]joinedEvent) {
+	tds := newTestDataSources(t, testds.Unregistered)
+	out, err := datasource.New(datasource.TypeSingle, "join-query-answer")
+	require.NoError(t, err)
+
+	j, err := newJoiner(&joinerConfig{
+		left:         tds.query,
+		right:        tds.answer,
+		out:          out,
+		leftKeys:     []string{"id"},
+		rightKeys:    []string{"query_id"},
+		window:       time.Second,
+		unmatched:    unmatched,
+		leftLatency:  "timestamp",
+		rightLatency: "timestamp",
+	})
+	require.NoError(t, err)
+
+	var events []joinedEvent
+	name := out.GetField("query.name")
+	ip := out.GetField("answer.ip")
+	latency := out.GetField(latencyField)
+	matched := out.GetField(matchedField)
+	out.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+		ev := joinedEvent{}
+		ev.name, _ = name.String(data)
+		ev.ip, _ = ip.String(data)
+		ev.latency, _ = latency.Int64(data)
+		if matched != nil {
+			ev.matched, _ = matched.Bool(data)
+		} else {
+			ev.matched = true
+		}
+		events = append(events, ev)
+		return nil
+	}, 0)
+	return tds, j, &events
+}
+
+func TestJoiner(t *testing.T) {
+	tds, j, events := newTestJoiner(t, UnmatchedDrop)
+	now := time.Now()
+
+	require.NoError(t, j.add(sideLeft, tds.newQuery(t, 1, "example.com", 100), now))
+	require.NoError(t, j.add(sideLeft, tds.newQuery(t, 2, "example.org", 110), now))
+	require.Empty(t, *events)
+
+	// answers can arrive in any order
+	require.NoError(t, j.add(sideRight, tds.newAnswer(t, 2, "5.6.7.8", 150), now))
+	require.NoError(t, j.add(sideRight, tds.newAnswer(t, 1, "1.2.3.4", 300), now))
+	// no query for this one
+	require.NoError(t, j.add(sideRight, tds.newAnswer(t, 3, "9.9.9.9", 300), now))
+
+	assert.Equal(t, []joinedEvent{
+		{name: "example.org", ip: "5.6.7.8", latency: 40, matched: true},
+		{name: "example.com", ip: "1.2.3.4", latency: 200, matched: true},
+	}, *events)
+
+	// unmatched events are dropped
+	require.NoError(t, j.expire(now.Add(2*time.Second)))
+	assert.Len(t, *events, 2)
+	assert.Empty(t, j.pending[sideLeft])
+	assert.Empty(t, j.pending[sideRight])
+}
+
+func TestJoinerUnmatched(t *testing.T) {
+	type testCase struct {
+		unmatched string
+		expected  []joinedEvent
+	}
+
+	testCases := []testCase{
+		{
+			unmatched: UnmatchedLeft,
+			expected: []joinedEvent{
+				{name: "example.com"},
+			},
+		},
+		{
+			unmatched: UnmatchedRight,
+			expected: []joinedEvent{
+				{ip: "9.9.9.9"},
+			},
+		},
+		{
+			unmatched: UnmatchedAll,
+			expected: []joinedEvent{
+				{name: "example.com"},
+				{ip: "9.9.9.9"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.unmatched, func(t *testing.T) {
+			tds, j, events := newTestJoiner(t, tc.unmatched)
+			now := time.Now()
+
+			require.NoError(t, j.add(sideLeft, tds.newQuery(t, 1, "example.com", 100), now))
+			require.NoError(t, j.add(sideRight, tds.newAnswer(t, 3, "9.9.9.9", 300), now))
+
+			// not expired yet
+			require.NoError(t, j.expire(now.Add(500*time.Millisecond)))
+			require.Empty(t, *events)
+
+			require.NoError(t, j.expire(now.Add(time.Second)))
+			assert.Equal(t, tc.expected, *events)
+		})
+	}
+}
+
+func TestJoinerExpiredBeforeMatch(t *testing.T) {
+	tds, j, events := newTestJoiner(t, UnmatchedDrop)
+	now := time.Now()
+
+	require.NoError(t, j.add(sideLeft, tds.newQuery(t, 1, "example.com", 100), now))
+	require.NoError(t, j.add(sideRight, tds.newAnswer(t, 1, "1.2.3.4", 300), now.Add(2*time.Second)))
+	assert.Empty(t, *events)
+}
+
+func TestNewJoinerErrors(t *testing.T) {
+	type testCase struct {
+		name string
+		cfg  func(cfg *joinerConfig)
+	}
+
+	testCases := []testCase{
+		{
+			name: "unknown left key",
+			cfg:  func(cfg *joinerConfig) { cfg.leftKeys = []string{"foo"} },
+		},
+		{
+			name: "unknown right key",
+			cfg:  func(cfg *joinerConfig) { cfg.rightKeys = []string{"foo"} },
+		},
+		{
+			name: "incompatible keys",
+			cfg:  func(cfg *joinerConfig) { cfg.leftKeys = []string{"name"} },
+		},
+		{
+			name: "invalid unmatched",
+			cfg:  func(cfg *joinerConfig) { cfg.unmatched = "foo" },
+		},
+		{
+			name: "non-numeric latency",
+			cfg:  func(cfg *joinerConfig) { cfg.leftLatency, cfg.rightLatency = "name", "ip" },
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tds := newTestDataSources(t, testds.Unregistered)
+			out, err := datasource.New(datasource.TypeSingle, "join-query-answer")
+			require.NoError(t, err)
+			cfg := &joinerConfig{
+				left:      tds.query,
+				right:     tds.answer,
+				out:       out,
+				leftKeys:  []string{"id"},
+				rightKeys: []string{"query_id"},
+				window:    time.Second,
+				unmatched: UnmatchedDrop,
+			}
+			tc.cfg(cfg)
+			_, err = newJoiner(cfg)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestJoinOperator(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	var tds *testDataSources
+
+	producer := simple.New("producer",
+		simple.WithPriority(Priority-1),
+		simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+			tds = newTestDataSources(t, gadgetCtx.RegisterDataSource)
+			return nil
+		}),
+		simple.OnStart(func(gadgetCtx operators.GadgetContext) error {
+			require.NoError(t, tds.query.EmitAndRelease(tds.newQuery(t, 1, "example.com", 100)))
+			require.NoError(t, tds.answer.EmitAndRelease(tds.newAnswer(t, 1, "1.2.3.4", 250)))
+			return nil
+		}),
+	)
+
+	var once sync.Once
+	var result joinedEvent
+	verifier := simple.New("verifier",
+		simple.WithPriority(Priority+1),
+		simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+			dataSources := gadgetCtx.GetDataSources()
+			assert.NotContains(t, dataSources, "query")
+			assert.NotContains(t, dataSources, "answer")
+			out, ok := dataSources["join-query-answer"]
+			require.True(t, ok)
+
+			name := out.GetField("query.name")
+			ip := out.GetField("answer.ip")
+			latency := out.GetField(latencyField)
+			return out.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+				once.Do(func() {
+					result.name, _ = name.String(data)
+					result.ip, _ = ip.String(data)
+					result.latency, _ = latency.Int64(data)
+					result.matched = true
+					cancel()
+				})
+				return nil
+			}, Priority+1)
+		}),
+	)
+
+	gadgetCtx := gadgetcontext.New(ctx, "", gadgetcontext.WithDataOperators(Operator, producer, verifier))
+	err := gadgetCtx.Run(api.ParamValues{
+		"operator.join.join":         "query,answer",
+		"operator.join.join-keys":    "id=query_id",
+		"operator.join.join-latency": "timestamp=timestamp",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, joinedEvent{name: "example.com", ip: "1.2.3.4", latency: 150, matched: true}, result)
+}
@@ -0,0 +1,375 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package join
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+type side int
+
+const (
+	sideLeft side = iota
+	sideRight
+)
+
+const (
+	latencyField = "latency"
+	matchedField = "matched"
+
+	// maxPending is the maximum number of events per side waiting for a
+	// match; if reached, the oldest events are handled as unmatched
+	maxPending = 65536
+)
+
+type joinerConfig struct {
+	left      datasource.DataSource
+	right     datasource.DataSource
+	out       datasource.DataSource
+	leftKeys  []string
+	rightKeys []string
+	window    time.Duration
+	unmatched string
+
+	leftLatency  string
+	rightLatency string
+}
+
+type keyFunc func(buf []byte, data datasource.Data) []byte
+
+// fieldCopy copies the value of a field of one of the joined data sources to
+// the joined data source
+type fieldCopy struct {
+	src datasource.FieldAccessor
+	dst datasource.FieldAccessor
+}
+
+type pendingEvent struct {
+	key     string
+	ts      time.Time
+	values  [][]byte
+	latency int64
+	done    bool
+}
+
+type joiner struct {
+	cfg *joinerConfig
+
+	keys      [2][]keyFunc
+	fields    [2][]fieldCopy
+	latencies [2]func(datasource.Data) int64
+	emitSides [2]bool
+
+	outLatency datasource.FieldAccessor
+	outMatched datasource.FieldAccessor
+
+	mu      sync.Mutex
+	keyBuf  []byte
+	pending [2]map[string][]*pendingEvent
+	// queue holds all pending events in the order they were received to
+	// efficiently expire them
+	queue [2][]*pendingEvent
+}
+
+// keyCategory makes sure that only compatible fields are compared, e.g. an
+// uint32 with an int64
+func keyCategory(kind api.Kind) string {
+	switch kind {
+	case api.Kind_Uint8, api.Kind_Uint16, api.Kind_Uint32, api.Kind_Uint64,
+		api.Kind_Int8, api.Kind_Int16, api.Kind_Int32, api.Kind_Int64:
+		return "integer"
+	case api.Kind_String, api.Kind_CString:
+		return "string"
+	}
+	return kind.String()
+}
+
+func newKeyFunc(f datasource.FieldAccessor) keyFunc {
+	switch keyCategory(f.Type()) {
+	case "integer":
+		intFn, _ := datasource.AsInt64(f) // error can't happen
+		return func(buf []byte, data datasource.Data) []byte {
+			return binary.AppendVarint(buf, intFn(data))
+		}
+	case "string":
+		return func(buf []byte, data datasource.Data) []byte {
+			s, _ := f.String(data)
+			buf = binary.AppendUvarint(buf, uint64(len(s)))
+			return append(buf, s...)
+		}
+	}
+	return func(buf []byte, data datasource.Data) []byte {
+		b := f.Get(data)
+		buf = binary.AppendUvarint(buf, uint64(len(b)))
+		return append(buf, b...)
+	}
+}
+
+func getField(ds datasource.DataSource, name string) (datasource.FieldAccessor, error) {
+	f := ds.GetField(name)
+	if f == nil {
+		return nil, fmt.Errorf("field %q not found in data source %q", name, ds.Name())
+	}
+	return f, nil
+}
+
+func newJoiner(cfg *joinerConfig) (*joiner, error) {
+	j := &joiner{
+		cfg: cfg,
+		pending: [2]map[string][]*pendingEvent{
+			make(map[string][]*pendingEvent),
+			make(map[string][]*pendingEvent),
+		},
+	}
+
+	switch cfg.unmatched {
+	case UnmatchedDrop:
+	case UnmatchedLeft:
+		j.emitSides[sideLeft] = true
+	case UnmatchedRight:
+		j.emitSides[sideRight] = true
+	case UnmatchedAll:
+		j.emitSides = [2]bool{true, true}
+	default:
+		return nil, fmt.Errorf("invalid value for %s: %q", ParamUnmatched, cfg.unmatched)
+	}
+
+	if len(cfg.leftKeys) != len(cfg.rightKeys) {
+		return nil, fmt.Errorf("number of keys doesn't match")
+	}
+	for i := range cfg.leftKeys {
+		lf, err := getField(cfg.left, cfg.leftKeys[i])
+		if err != nil {
+			return nil, err
+		}
+		rf, err := getField(cfg.right, cfg.rightKeys[i])
+		if err != nil {
+			return nil, err
+		}
+		if keyCategory(lf.Type()) != keyCategory(rf.Type()) {
+			return nil, fmt.Errorf("key fields %q (%s) and %q (%s) are not comparable",
+				lf.FullName(), lf.Type(), rf.FullName(), rf.Type())
+		}
+		j.keys[sideLeft] = append(j.keys[sideLeft], newKeyFunc(lf))
+		j.keys[sideRight] = append(j.keys[sideRight], newKeyFunc(rf))
+	}
+
+	for s, ds := range []datasource.DataSource{cfg.left, cfg.right} {
+		for _, f := range ds.Accessors(false) {
+			flags := f.Flags()
+			if datasource.FieldFlagEmpty.In(flags) ||
+				datasource.FieldFlagContainer.In(flags) ||
+				datasource.FieldFlagUnreferenced.In(flags) {
+				continue
+			}
+			opts := []datasource.FieldOption{
+				datasource.WithTags(f.Tags()...),
+				datasource.WithAnnotations(f.Annotations()),
+			}
+			if datasource.FieldFlagHidden.In(flags) {
+				opts = append(opts, datasource.WithFlags(datasource.FieldFlagHidden))
+			}
+			dst, err := cfg.out.AddField(ds.Name()+"."+f.FullName(), f.Type(), opts...)
+			if err != nil {
+				return nil, fmt.Errorf("adding field %q: %w", f.FullName(), err)
+			}
+			j.fields[s] = append(j.fields[s], fieldCopy{src: f, dst: dst})
+		}
+	}
+
+	if cfg.leftLatency != "" {
+		for s, fieldName := range []string{cfg.leftLatency, cfg.rightLatency} {
+			ds := []datasource.DataSource{cfg.left, cfg.right}[s]
+			f, err := getField(ds, fieldName)
+			if err != nil {
+				return nil, err
+			}
+			fn, err := datasource.AsInt64(f)
+			if err != nil {
+				return nil, fmt.Errorf("using field %q for latency: %w", f.FullName(), err)
+			}
+			j.latencies[s] = fn
+		}
+		var err error
+		j.outLatency, err = cfg.out.AddField(latencyField, api.Kind_Int64)
+		if err != nil {
+			return nil, fmt.Errorf("adding field %q: %w", latencyField, err)
+		}
+	}
+
+	if j.emitSides[sideLeft] || j.emitSides[sideRight] {
+		var err error
+		j.outMatched, err = cfg.out.AddField(matchedField, api.Kind_Bool)
+		if err != nil {
+			return nil, fmt.Errorf("adding field %q: %w", matchedField, err)
+		}
+	}
+
+	return j, nil
+}
+
+// add adds an event of the given side and emits all events that either
+// matched or expired in the meantime
+func (j *joiner) add(s side, data datasource.Data, now time.Time) error {
+	ev := &pendingEvent{
+		ts:     now,
+		values: make([][]byte, 0, len(j.fields[s])),
+	}
+	for _, fc := range j.fields[s] {
+		ev.values = append(ev.values, slices.Clone(fc.src.Get(data)))
+	}
+	if fn := j.latencies[s]; fn != nil {
+		ev.latency = fn(data)
+	}
+
+	var matches [][2]*pendingEvent
+
+	j.mu.Lock()
+	expired := j.collectExpired(now)
+
+	j.keyBuf = j.keyBuf[:0]
+	for _, fn := range j.keys[s] {
+		j.keyBuf = fn(j.keyBuf, data)
+	}
+	ev.key = string(j.keyBuf)
+
+	other := 1 - s
+	if candidates := j.pending[other][ev.key]; len(candidates) > 0 {
+		match := candidates[0]
+		match.done = true
+		j.removePending(other, match)
+
+		pair := [2]*pendingEvent{}
+		pair[s] = ev
+		pair[other] = match
+		matches = append(matches, pair)
+	} else {
+		j.pending[s][ev.key] = append(j.pending[s][ev.key], ev)
+		j.queue[s] = append(j.queue[s], ev)
+		if len(j.queue[s]) > maxPending {
+			expired = append(expired, j.popOldest(s))
+		}
+	}
+	j.mu.Unlock()
+
+	return errors.Join(j.emitUnmatched(expired), j.emit(matches))
+}
+
+// expire emits (or drops) all events that didn't find a match within the
+// window
+func (j *joiner) expire(now time.Time) error {
+	j.mu.Lock()
+	expired := j.collectExpired(now)
+	j.mu.Unlock()
+	return j.emitUnmatched(expired)
+}
+
+// collectExpired removes all expired events; it returns them as pairs with
+// one side being empty. j.mu must be held.
+func (j *joiner) collectExpired(now time.Time) [][2]*pendingEvent {
+	var expired [][2]*pendingEvent
+	for s := range j.queue {
+		for len(j.queue[s]) > 0 {
+			ev := j.queue[s][0]
+			if !ev.done && now.Sub(ev.ts) < j.cfg.window {
+				break
+			}
+			if pair := j.popOldest(side(s)); pair[s] != nil {
+				expired = append(expired, pair)
+			}
+		}
+	}
+	return expired
+}
+
+// popOldest removes the oldest event of the given side. j.mu must be held.
+func (j *joiner) popOldest(s side) [2]*pendingEvent {
+	ev := j.queue[s][0]
+	j.queue[s][0] = nil
+	j.queue[s] = j.queue[s][1:]
+
+	var pair [2]*pendingEvent
+	if ev.done {
+		// already matched
+		return pair
+	}
+	ev.done = true
+	j.removePending(s, ev)
+	pair[s] = ev
+	return pair
+}
+
+// removePending removes ev from the pending events of its key. j.mu must be
+// held.
+func (j *joiner) removePending(s side, ev *pendingEvent) {
+	candidates := slices.DeleteFunc(j.pending[s][ev.key], func(e *pendingEvent) bool {
+		return e == ev
+	})
+	if len(candidates) == 0 {
+		delete(j.pending[s], ev.key)
+		return
+	}
+	j.pending[s][ev.key] = candidates
+}
+
+func (j *joiner) emitUnmatched(expired [][2]*pendingEvent) error {
+	var errs []error
+	for _, pair := range expired {
+		if (pair[sideLeft] != nil && j.emitSides[sideLeft]) ||
+			(pair[sideRight] != nil && j.emitSides[sideRight]) {
+			errs = append(errs, j.emit([][2]*pendingEvent{pair}))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (j *joiner) emit(pairs [][2]*pendingEvent) error {
+	for _, pair := range pairs {
+		p, err := j.cfg.out.NewPacketSingle()
+		if err != nil {
+			return fmt.Errorf("creating packet: %w", err)
+		}
+		for s, ev := range pair {
+			if ev == nil {
+				continue
+			}
+			for i, fc := range j.fields[s] {
+				if err := fc.dst.Set(p, ev.values[i]); err != nil {
+					j.cfg.out.Release(p)
+					return fmt.Errorf("setting field %q: %w", fc.dst.Name(), err)
+				}
+			}
+		}
+		matched := pair[sideLeft] != nil && pair[sideRight] != nil
+		if j.outLatency != nil && matched {
+			j.outLatency.PutInt64(p, pair[sideRight].latency-pair[sideLeft].latency)
+		}
+		if j.outMatched != nil {
+			j.outMatched.PutBool(p, matched)
+		}
+		if err := j.cfg.out.EmitAndRelease(p); err != nil {
+			return fmt.Errorf("emitting joined event: %w", err)
+		}
+	}
+	return nil
+}
@@ -19,9 +19,11 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"net"
 	"os"
 	"path/filepath"
 	"regexp"
+	"time"
 
 	nriv1 "github.com/containerd/nri/types/v1"
 	log "github.com/sirupsen/logrus"
@@ -37,16 +39,22 @@ const (
 	hookModeAuto         = "auto"
 	hookModeCrio         = "crio"
 	hookModeNRI          = "nri"
+	hookModeNRIPlugin    = "nri-plugin"
 	hookModePodInformer  = "podinformer"
 	hookModeFanotifyEbpf = "fanotify+ebpf"
 )
 
+// nriDialTimeout is how long to wait for the container runtime to accept a
+// connection on its NRI socket when detecting the hook mode
+const nriDialTimeout = time.Second
+
 var crioRegex = regexp.MustCompile(`1:name=systemd:.*/crio-[0-9a-f]*\.scope`)
 
 var supportedHookModes = []string{
 	hookModeAuto,
 	hookModeCrio,
 	hookModeNRI,
+	hookModeNRIPlugin,
 	hookModePodInformer,
 	hookModeFanotifyEbpf,
 }
@@ -171,7 +179,26 @@ func installNRIHooks() error {
 	return nil
 }
 
-func parseHookMode(hookMode string) (string, error) {
+// nriSocketAvailable returns whether the container runtime of the host
+// accepts connections on the NRI socket at socketPath; the socket file can be
+// left over when NRI was disabled in the runtime
+func nriSocketAvailable(socketPath string) bool {
+	conn, err := net.DialTimeout("unix", filepath.Join(host.HostRoot, socketPath), nriDialTimeout)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+func parseHookMode(hookMode, nriSocketPath string) (string, error) {
+	// The NRI plugin doesn't need to install anything on the host, prefer it
+	// when the runtime supports NRI and detect the other modes otherwise
+	if hookMode == hookModeAuto && nriSocketAvailable(nriSocketPath) {
+		log.Infof("NRI socket detected at %s", nriSocketPath)
+		hookMode = hookModeNRIPlugin
+	}
+
 	path := "/proc/self/cgroup"
 	content, err := os.ReadFile(path)
 	if err != nil {
@@ -206,7 +233,7 @@ func parseHookMode(hookMode string) (string, error) {
 	switch hookMode {
 	case hookModeCrio, hookModeNRI:
 		parsedHookMode = hookModeNone
-	case hookModeFanotifyEbpf, hookModePodInformer:
+	case hookModeFanotifyEbpf, hookModePodInformer, hookModeNRIPlugin:
 		parsedHookMode = hookMode
 	}
 
@@ -215,7 +242,7 @@ func parseHookMode(hookMode string) (string, error) {
 	return parsedHookMode, nil
 }
 
-func hookMode2ccOpts(node, hookMode string, fallbackPodInformer bool) ([]containercollection.ContainerCollectionOption, error) {
+func hookMode2ccOpts(node, hookMode, nriSocketPath string, fallbackPodInformer bool) ([]containercollection.ContainerCollectionOption, error) {
 	var ccOpts []containercollection.ContainerCollectionOption
 
 	podInformerUsed := false
@@ -240,6 +267,11 @@ func hookMode2ccOpts(node, hookMode string, fallbackPodInformer bool) ([]contain
 		log.Infof("KubeManager: hook mode: podinformer")
 		ccOpts = append(ccOpts, containercollection.WithPodInformer(node))
 		podInformerUsed = true
+	case "nri-plugin":
+		// The initial containers are provided by the runtime when the plugin
+		// is registered
+		log.Infof("KubeManager: hook mode: nri-plugin")
+		ccOpts = append(ccOpts, containercollection.WithNRIPlugin(filepath.Join(host.HostRoot, nriSocketPath)))
 	case "fanotify+ebpf":
 		log.Infof("KubeManager: hook mode: fanotify+ebpf")
 		ccOpts = append(ccOpts, containercollection.WithContainerFanotifyEbpf())
@@ -0,0 +1,81 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubemanager
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	nriapi "github.com/containerd/nri/pkg/api"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/utils/host"
+)
+
+func TestParseHookMode(t *testing.T) {
+	content, err := os.ReadFile("/proc/self/cgroup")
+	require.NoError(t, err)
+	if crioRegex.Match(content) {
+		t.Skip("auto selects the crio hook mode on CRI-O hosts")
+	}
+
+	oldHostRoot := host.HostRoot
+	host.HostRoot = t.TempDir()
+	t.Cleanup(func() { host.HostRoot = oldHostRoot })
+
+	socketPath := filepath.Join(host.HostRoot, nriapi.DefaultSocketPath)
+	require.NoError(t, os.MkdirAll(filepath.Dir(socketPath), 0o755))
+
+	parse := func(t *testing.T, hookMode string) string {
+		t.Helper()
+		parsed, err := parseHookMode(hookMode, nriapi.DefaultSocketPath)
+		require.NoError(t, err)
+		return parsed
+	}
+
+	t.Run("no socket", func(t *testing.T) {
+		require.Equal(t, hookModeAuto, parse(t, hookModeAuto))
+	})
+
+	t.Run("stale socket", func(t *testing.T) {
+		l, err := net.Listen("unix", socketPath)
+		require.NoError(t, err)
+		l.(*net.UnixListener).SetUnlinkOnClose(false)
+		l.Close()
+		t.Cleanup(func() { os.Remove(socketPath) })
+
+		require.Equal(t, hookModeAuto, parse(t, hookModeAuto))
+	})
+
+	t.Run("socket", func(t *testing.T) {
+		l, err := net.Listen("unix", socketPath)
+		require.NoError(t, err)
+		t.Cleanup(func() { l.Close() })
+
+		for _, tc := range []struct {
+			hookMode string
+			expected string
+		}{
+			{hookMode: hookModeAuto, expected: hookModeNRIPlugin},
+			{hookMode: hookModeNRIPlugin, expected: hookModeNRIPlugin},
+			{hookMode: hookModeFanotifyEbpf, expected: hookModeFanotifyEbpf},
+			{hookMode: hookModePodInformer, expected: hookModePodInformer},
+		} {
+			require.Equal(t, tc.expected, parse(t, tc.hookMode), tc.hookMode)
+		}
+	})
+}
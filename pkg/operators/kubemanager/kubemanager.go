@@ -22,6 +22,7 @@ import (
 
 	"github.com/cilium/ebpf"
 	"github.com/cilium/ebpf/rlimit"
+	nriapi "github.com/containerd/nri/pkg/api"
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -52,6 +53,7 @@ const (
 	ParamHookMode               = "hook-mode"
 	ParamFallbackPodInformer    = "fallback-podinformer"
 	ParamHookLivenessSocketFile = "hook-liveness-socketfile"
+	ParamNRISocketPath          = "nri-socketpath"
 
 	// Instance parameter keys
 	ParamAllNamespaces = "all-namespaces"
@@ -112,6 +114,12 @@ func (k *KubeManager) GlobalParamDescs() params.ParamDescs {
 			Description:  "Path to the socket file for serving hook's requests for adding/removing containers and for liveness checks",
 			TypeHint:     params.TypeString,
 		},
+		{
+			Key:          ParamNRISocketPath,
+			DefaultValue: nriapi.DefaultSocketPath,
+			Description:  "Path on the host to the NRI socket of the container runtime, used by the nri-plugin hook mode",
+			TypeHint:     params.TypeString,
+		},
 	}
 }
 
@@ -134,14 +142,15 @@ func (k *KubeManager) Init(params *params.Params) error {
 	hookMode := params.Get(ParamHookMode).AsString()
 	fallbackPodInformer := params.Get(ParamFallbackPodInformer).AsBool()
 	socketPath := params.Get(ParamHookLivenessSocketFile).AsString()
+	nriSocketPath := params.Get(ParamNRISocketPath).AsString()
 
 	var err error
-	hookMode, err = parseHookMode(hookMode)
+	hookMode, err = parseHookMode(hookMode, nriSocketPath)
 	if err != nil {
 		return fmt.Errorf("parsing hook mode: %w", err)
 	}
 
-	if err := k.initCollections(hookMode, fallbackPodInformer); err != nil {
+	if err := k.initCollections(hookMode, nriSocketPath, fallbackPodInformer); err != nil {
 		return fmt.Errorf("initializing collections: %w", err)
 	}
 
@@ -168,7 +177,7 @@ func (k *KubeManager) Init(params *params.Params) error {
 }
 
 // initCollections initializes the container collection and tracer collection.
-func (k *KubeManager) initCollections(hookMode string, fallbackPodInformer bool) error {
+func (k *KubeManager) initCollections(hookMode, nriSocketPath string, fallbackPodInformer bool) error {
 	var cc containercollection.ContainerCollection
 
 	if err := rlimit.RemoveMemlock(); err != nil {
@@ -197,7 +206,7 @@ func (k *KubeManager) initCollections(hookMode string, fallbackPodInformer bool)
 		containercollection.WithProcEnrichment(),
 	}
 
-	hookModeOpts, err := hookMode2ccOpts(node, hookMode, fallbackPodInformer)
+	hookModeOpts, err := hookMode2ccOpts(node, hookMode, nriSocketPath, fallbackPodInformer)
 	if err != nil {
 		return fmt.Errorf("getting extra container collection options: %w", err)
 	}
@@ -101,6 +101,10 @@ func (l *limiterOperator) Priority() int {
 	return Priority
 }
 
+func (l *limiterOperator) SupportsReplay() bool {
+	return true
+}
+
 type limiterOperatorInstance struct {
 	limitsPerDs map[string]int
 }
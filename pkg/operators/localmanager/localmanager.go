@@ -57,6 +57,8 @@ const (
 	ContainerdSocketPath   = "containerd-socketpath"
 	CrioSocketPath         = "crio-socketpath"
 	PodmanSocketPath       = "podman-socketpath"
+	IncusSocketPath        = "incus-socketpath"
+	NspawnSocketPath       = "nspawn-socketpath"
 	ContainerdNamespace    = "containerd-namespace"
 	RuntimeProtocol        = "runtime-protocol"
 	EnrichWithK8sApiserver = "enrich-with-k8s-apiserver"
@@ -129,6 +131,16 @@ func (l *localManager) GlobalParamDescs() params.ParamDescs {
 			DefaultValue: runtimeclient.PodmanDefaultSocketPath,
 			Description:  "Podman Unix socket path",
 		},
+		{
+			Key:          IncusSocketPath,
+			DefaultValue: runtimeclient.IncusDefaultSocketPath,
+			Description:  "Incus or LXD Unix socket path",
+		},
+		{
+			Key:          NspawnSocketPath,
+			DefaultValue: runtimeclient.NspawnDefaultSocketPath,
+			Description:  "D-Bus system bus Unix socket path used to reach systemd-machined",
+		},
 		{
 			Key:          ContainerdNamespace,
 			DefaultValue: constants.K8sContainerdNamespace,
@@ -194,6 +206,10 @@ func (l *localManager) Init(operatorParams *params.Params) error {
 			socketPathParam = operatorParams.Get(CrioSocketPath)
 		case types.RuntimeNamePodman:
 			socketPathParam = operatorParams.Get(PodmanSocketPath)
+		case types.RuntimeNameIncus:
+			socketPathParam = operatorParams.Get(IncusSocketPath)
+		case types.RuntimeNameNspawn:
+			socketPathParam = operatorParams.Get(NspawnSocketPath)
 		default:
 			return commonutils.WrapInErrInvalidArg("--runtime / -r",
 				fmt.Errorf("runtime %q is not supported", runtime))
@@ -708,6 +724,10 @@ func isDefaultContainerRuntimeConfig(runtimes []*containerutilsTypes.RuntimeConf
 			customSocketPath = runtime.SocketPath != runtimeclient.CrioDefaultSocketPath
 		case types.RuntimeNamePodman:
 			customSocketPath = runtime.SocketPath != runtimeclient.PodmanDefaultSocketPath
+		case types.RuntimeNameIncus:
+			customSocketPath = runtime.SocketPath != runtimeclient.IncusDefaultSocketPath
+		case types.RuntimeNameNspawn:
+			customSocketPath = runtime.SocketPath != runtimeclient.NspawnDefaultSocketPath
 		default:
 			customSocketPath = true
 		}
@@ -16,15 +16,18 @@ package ocihandler
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/distribution/reference"
+	spb "github.com/in-toto/attestation/go/v1"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"google.golang.org/protobuf/encoding/protojson"
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
 )
 
-func addExtraInfo(gadgetCtx operators.GadgetContext, metadata []byte, manifest *ocispec.Manifest) error {
+func addExtraInfo(gadgetCtx operators.GadgetContext, metadata []byte, manifest *ocispec.Manifest, attestations []*spb.Statement) error {
 	parsed, err := reference.Parse(gadgetCtx.ImageName())
 	if err != nil {
 		return err
@@ -73,6 +76,23 @@ func addExtraInfo(gadgetCtx operators.GadgetContext, metadata []byte, manifest *
 		Content:     []byte(created),
 	}
 
+	statements := make([]json.RawMessage, 0, len(attestations))
+	for _, attestation := range attestations {
+		statement, err := protojson.Marshal(attestation)
+		if err != nil {
+			return fmt.Errorf("marshalling attestation: %w", err)
+		}
+		statements = append(statements, statement)
+	}
+	attestationsJson, err := json.Marshal(statements)
+	if err != nil {
+		return fmt.Errorf("marshalling attestations: %w", err)
+	}
+	ociInfo.Data["oci.attestations"] = &api.GadgetInspectAddendum{
+		ContentType: "application/json",
+		Content:     attestationsJson,
+	}
+
 	gadgetCtx.SetVar("extraInfo.oci", ociInfo)
 
 	return nil
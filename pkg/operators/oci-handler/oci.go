@@ -42,6 +42,7 @@ import (
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/signature/verifier"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/signature/verifier/cosign"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/signature/verifier/notation"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/signature/verifier/sigstore"
 )
 
 const (
@@ -56,9 +57,17 @@ const (
 	publicKeys              = "public-keys"
 	certificates            = "notation-certificates"
 	policyDocument          = "notation-policy-document"
+	sigstoreTrustedRoot     = "sigstore-trusted-root"
+	sigstorePolicyDocument  = "sigstore-policy-document"
 	allowedGadgets          = "allowed-gadgets"
+	requireAttestations     = "require-attestations"
+	gadgetPolicyDocument    = "gadget-policy-document"
 
 	TagGroupOCI = "group:OCI"
+
+	// ImageDigestVar is the name of the gadget context variable holding the
+	// digest of the gadget image
+	ImageDigestVar = "oci.digest"
 )
 
 const (
@@ -68,6 +77,7 @@ const (
 type ociHandler struct {
 	globalParams *params.Params
 	verifyOpts   oci.VerifyOptions
+	policyRules  []*policyRule
 }
 
 func New() *ociHandler {
@@ -98,6 +108,10 @@ func (o *ociHandler) Init(params *params.Params) error {
 					Certificates:   o.globalParams.Get(certificates).AsStringSlice(),
 					PolicyDocument: o.globalParams.Get(policyDocument).AsString(),
 				},
+				SigstoreVerifierOpts: sigstore.VerifierOptions{
+					TrustedRoot:    o.globalParams.Get(sigstoreTrustedRoot).AsString(),
+					PolicyDocument: o.globalParams.Get(sigstorePolicyDocument).AsString(),
+				},
 			},
 		)
 		if err != nil {
@@ -106,8 +120,21 @@ func (o *ociHandler) Init(params *params.Params) error {
 		verifyOptions.Verifier = verifier
 	}
 
+	verifyOptions.RequiredAttestations = o.globalParams.Get(requireAttestations).AsStringSlice()
+	if err := oci.ValidateAttestationKinds(verifyOptions.RequiredAttestations); err != nil {
+		return fmt.Errorf("validating %s: %w", requireAttestations, err)
+	}
+
 	o.verifyOpts = verifyOptions
 
+	if document := o.globalParams.Get(gadgetPolicyDocument).AsString(); document != "" {
+		rules, err := parsePolicyDocument(document)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", gadgetPolicyDocument, err)
+		}
+		o.policyRules = rules
+	}
+
 	return nil
 }
 
@@ -139,6 +166,30 @@ func (o *ociHandler) GlobalParams() api.Params {
 			Description: "Policy Document used to verify the gadgets with notation",
 			TypeHint:    api.TypeString,
 		},
+		{
+			Key:         sigstoreTrustedRoot,
+			Title:       "Sigstore trusted root",
+			Description: "Sigstore trusted root (trusted_root.json) used to verify the gadgets signed keylessly",
+			TypeHint:    api.TypeString,
+		},
+		{
+			Key:         sigstorePolicyDocument,
+			Title:       "Sigstore policy Document",
+			Description: "Policy Document with the identities allowed to sign the gadgets keylessly",
+			TypeHint:    api.TypeString,
+		},
+		{
+			Key:         requireAttestations,
+			Title:       "Require attestations",
+			Description: "Attestations the gadgets must have to be run. Possible values are provenance and sbom",
+			TypeHint:    api.TypeStringSlice,
+		},
+		{
+			Key:         gadgetPolicyDocument,
+			Title:       "Gadget policy Document",
+			Description: "Policy Document with the rules the gadgets, their eBPF programs and params must satisfy to be run",
+			TypeHint:    api.TypeString,
+		},
 		{
 			Key:         allowedGadgets,
 			Title:       "Allowed Gadgets",
@@ -415,14 +466,28 @@ func (o *OciHandlerInstance) init(gadgetCtx operators.GadgetContext) error {
 		}
 	}
 
+	err := oci.VerifyGadgetAttestations(gadgetCtx.Context(), target, gadgetCtx.ImageName(), imgOpts)
+	if err != nil {
+		return fmt.Errorf("verifying attestations: %w", err)
+	}
+
 	manifest, err := oci.GetManifestForHost(gadgetCtx.Context(), target, gadgetCtx.ImageName())
 	if err != nil {
 		return fmt.Errorf("getting manifest: %w", err)
 	}
 
+	o.manifest = manifest
+
 	log := gadgetCtx.Logger()
 	checkBuilderVersion(manifest, log, version.Version())
 
+	digest, err := oci.GetImageDigest(gadgetCtx.Context(), target, gadgetCtx.ImageName())
+	if err != nil {
+		log.Debugf("getting image digest: %v", err)
+	} else {
+		gadgetCtx.SetVar(ImageDigestVar, digest)
+	}
+
 	r, err := oci.GetContentFromDescriptor(gadgetCtx.Context(), target, manifest.Config)
 	if err != nil {
 		return fmt.Errorf("getting metadata: %w", err)
@@ -499,7 +564,11 @@ func (o *OciHandlerInstance) init(gadgetCtx operators.GadgetContext) error {
 
 	// add extra info if requested
 	if gadgetCtx.ExtraInfo() {
-		err := addExtraInfo(gadgetCtx, metadata, manifest)
+		attestations, err := oci.GetGadgetAttestations(gadgetCtx.Context(), target, gadgetCtx.ImageName())
+		if err != nil {
+			log.Debugf("getting attestations: %v", err)
+		}
+		err = addExtraInfo(gadgetCtx, metadata, manifest, attestations)
 		if err != nil {
 			return fmt.Errorf("adding extra info: %w", err)
 		}
@@ -518,6 +587,18 @@ func (o *OciHandlerInstance) init(gadgetCtx operators.GadgetContext) error {
 }
 
 func (o *OciHandlerInstance) PreStart(gadgetCtx operators.GadgetContext) error {
+	// All operators are instantiated at this point, so the policy sees the
+	// params and eBPF programs of all of them.
+	if len(o.ociHandler.policyRules) > 0 {
+		input, err := policyInput(gadgetCtx, o.manifest)
+		if err != nil {
+			return fmt.Errorf("preparing policy input: %w", err)
+		}
+		if err := evaluatePolicy(o.ociHandler.policyRules, input); err != nil {
+			return err
+		}
+	}
+
 	for _, opInst := range o.imageOperatorInstances {
 		if preStart, ok := opInst.(operators.PreStart); ok {
 			err := preStart.PreStart(gadgetCtx)
@@ -607,6 +688,7 @@ type OciHandlerInstance struct {
 	ociHandler             *ociHandler
 	gadgetCtx              operators.GadgetContext
 	imageOperatorInstances []operators.ImageOperatorInstance
+	manifest               *v1.Manifest
 	extraParams            api.Params
 	paramValues            api.ParamValues
 	globalParams           *params.Params
@@ -0,0 +1,234 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ocihandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/distribution/reference"
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"sigs.k8s.io/yaml"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/oci"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+)
+
+// PolicyRule is a rule the gadgets must satisfy to be run. Match and Expression
+// are expr expressions (https://expr-lang.org) evaluating to a boolean, see
+// policyInput() for the variables they can use.
+type PolicyRule struct {
+	Name string `json:"name"`
+	// Match selects the runs the rule applies to. If empty, the rule applies
+	// to all of them.
+	Match string `json:"match,omitempty"`
+	// Expression must be true for the run to be allowed.
+	Expression string `json:"expression"`
+	// Message explains why the run is denied when Expression is false.
+	Message string `json:"message,omitempty"`
+}
+
+type PolicyDocument struct {
+	Rules []PolicyRule `json:"rules"`
+}
+
+type policyRule struct {
+	PolicyRule
+	match      *vm.Program
+	expression *vm.Program
+}
+
+func parsePolicyDocument(document string) ([]*policyRule, error) {
+	doc := &PolicyDocument{}
+	if err := yaml.UnmarshalStrict([]byte(document), doc); err != nil {
+		return nil, fmt.Errorf("decoding policy document: %w", err)
+	}
+
+	if len(doc.Rules) == 0 {
+		return nil, errors.New("policy document has no rule")
+	}
+
+	names := map[string]struct{}{}
+	rules := make([]*policyRule, 0, len(doc.Rules))
+	for _, r := range doc.Rules {
+		if r.Name == "" {
+			return nil, errors.New("rule without name")
+		}
+		if _, ok := names[r.Name]; ok {
+			return nil, fmt.Errorf("duplicated rule %q", r.Name)
+		}
+		names[r.Name] = struct{}{}
+
+		rule := &policyRule{PolicyRule: r}
+
+		if r.Match != "" {
+			match, err := expr.Compile(r.Match, expr.AsBool())
+			if err != nil {
+				return nil, fmt.Errorf("compiling match of rule %q: %w", r.Name, err)
+			}
+			rule.match = match
+		}
+
+		if r.Expression == "" {
+			return nil, fmt.Errorf("rule %q has no expression", r.Name)
+		}
+		expression, err := expr.Compile(r.Expression, expr.AsBool())
+		if err != nil {
+			return nil, fmt.Errorf("compiling expression of rule %q: %w", r.Name, err)
+		}
+		rule.expression = expression
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func runRuleProgram(program *vm.Program, input map[string]any) (bool, error) {
+	out, err := expr.Run(program, input)
+	if err != nil {
+		return false, err
+	}
+	ret, ok := out.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected bool result, got %T", out)
+	}
+	return ret, nil
+}
+
+// evaluatePolicy returns an error listing the rules denying the run. Rules
+// failing to be evaluated deny it as well.
+func evaluatePolicy(rules []*policyRule, input map[string]any) error {
+	var errs []error
+
+	for _, rule := range rules {
+		if rule.match != nil {
+			match, err := runRuleProgram(rule.match, input)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("rule %q: evaluating match: %w", rule.Name, err))
+				continue
+			}
+			if !match {
+				continue
+			}
+		}
+
+		allowed, err := runRuleProgram(rule.expression, input)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rule %q: evaluating expression: %w", rule.Name, err))
+			continue
+		}
+		if allowed {
+			continue
+		}
+
+		message := rule.Message
+		if message == "" {
+			message = fmt.Sprintf("%q is false", rule.Expression)
+		}
+		errs = append(errs, fmt.Errorf("rule %q: %s", rule.Name, message))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("denied by policy: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// policyInput returns the variables available to the policy rules:
+//   - image: name, repository, tag, digest and manifest annotations of the
+//     gadget image.
+//   - gadget: the gadget information, as returned by ig image inspect, with the
+//     metadata decoded.
+//   - programs: the eBPF programs of the gadget, see operators.ProgramInfo.
+//   - params: the values of all the params, with the defaults applied.
+func policyInput(gadgetCtx operators.GadgetContext, manifest *ocispec.Manifest) (map[string]any, error) {
+	named, err := oci.NormalizeImageName(gadgetCtx.ImageName())
+	if err != nil {
+		return nil, err
+	}
+
+	var tag string
+	if tagged, ok := named.(reference.Tagged); ok {
+		tag = tagged.Tag()
+	}
+
+	var digest string
+	if d, ok := gadgetCtx.GetVar(ImageDigestVar); ok {
+		digest, _ = d.(string)
+	}
+
+	annotations := map[string]any{}
+	for k, v := range manifest.Annotations {
+		annotations[k] = v
+	}
+
+	gadgetInfo, err := gadgetCtx.SerializeGadgetInfo(false)
+	if err != nil {
+		return nil, fmt.Errorf("serializing gadget info: %w", err)
+	}
+	gadgetInfoJSON, err := protojson.Marshal(gadgetInfo)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling gadget info: %w", err)
+	}
+	gadget := map[string]any{}
+	if err := json.Unmarshal(gadgetInfoJSON, &gadget); err != nil {
+		return nil, fmt.Errorf("unmarshaling gadget info: %w", err)
+	}
+	metadata := map[string]any{}
+	if err := yaml.Unmarshal(gadgetInfo.Metadata, &metadata); err != nil {
+		return nil, fmt.Errorf("unmarshaling metadata: %w", err)
+	}
+	gadget["metadata"] = metadata
+
+	programs := []any{}
+	if p, ok := gadgetCtx.GetVar(operators.ProgramsVar); ok {
+		for _, program := range p.([]operators.ProgramInfo) {
+			programs = append(programs, map[string]any{
+				"name":        program.Name,
+				"sectionName": program.SectionName,
+				"type":        program.Type,
+				"attachType":  program.AttachType,
+				"attachTo":    program.AttachTo,
+				"hook":        program.Hook,
+			})
+		}
+	}
+
+	params := map[string]any{}
+	for _, p := range gadgetCtx.Params() {
+		params[p.Prefix+p.Key] = p.DefaultValue
+	}
+	for k, v := range gadgetCtx.ParamValues() {
+		params[k] = v
+	}
+
+	return map[string]any{
+		"image": map[string]any{
+			"name":        named.String(),
+			"repository":  named.Name(),
+			"tag":         tag,
+			"digest":      digest,
+			"annotations": annotations,
+		},
+		"gadget":   gadget,
+		"programs": programs,
+		"params":   params,
+	}, nil
+}
@@ -0,0 +1,225 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ocihandler
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/testing/gadget-context"
+)
+
+const testPolicyDocument = `
+rules:
+- name: trace-ssl-namespaced
+  match: image.repository endsWith "/trace_ssl"
+  expression: params["operator.KubeManager.namespace"] != ""
+  message: trace_ssl can only be run with a namespace filter
+- name: no-iface
+  expression: (params["operator.oci.ebpf.iface"] ?? "") == ""
+  message: attaching to a network interface is not allowed
+- name: source-annotation
+  expression: '"org.opencontainers.image.source" in image.annotations'
+- name: no-lsm-nor-tc
+  expression: none(programs, .hook in ["lsm", "tc"])
+  message: LSM and tc programs are forbidden
+`
+
+type policyGadgetContext struct {
+	gadgetcontext.MockGadgetContext
+	imageName   string
+	vars        map[string]any
+	params      []*api.Param
+	paramValues api.ParamValues
+}
+
+func (c *policyGadgetContext) ImageName() string {
+	return c.imageName
+}
+
+func (c *policyGadgetContext) GetVar(key string) (any, bool) {
+	v, ok := c.vars[key]
+	return v, ok
+}
+
+func (c *policyGadgetContext) Params() []*api.Param {
+	return c.params
+}
+
+func (c *policyGadgetContext) ParamValues() api.ParamValues {
+	return c.paramValues
+}
+
+func (c *policyGadgetContext) SerializeGadgetInfo(requestExtraInfo bool) (*api.GadgetInfo, error) {
+	return &api.GadgetInfo{
+		ImageName: c.imageName,
+		Metadata:  []byte("name: test\n"),
+		Params:    c.params,
+	}, nil
+}
+
+func TestParsePolicyDocument(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		document    string
+		expectedErr string
+	}{
+		{"valid", testPolicyDocument, ""},
+		{"json", `{"rules": [{"name": "foo", "expression": "true"}]}`, ""},
+		{"empty", ``, "no rule"},
+		{"unknown field", `{"rules": [{"name": "foo", "expression": "true", "deny": "true"}]}`, "unknown field"},
+		{"no name", `{"rules": [{"expression": "true"}]}`, "rule without name"},
+		{"duplicated", `{"rules": [{"name": "foo", "expression": "true"}, {"name": "foo", "expression": "true"}]}`, "duplicated rule"},
+		{"no expression", `{"rules": [{"name": "foo"}]}`, "has no expression"},
+		{"invalid expression", `{"rules": [{"name": "foo", "expression": "1 +"}]}`, "compiling expression"},
+		{"invalid match", `{"rules": [{"name": "foo", "match": "1 +", "expression": "true"}]}`, "compiling match"},
+		{"not a bool", `{"rules": [{"name": "foo", "expression": "1 + 1"}]}`, "compiling expression"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parsePolicyDocument(tc.document)
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestEvaluatePolicy(t *testing.T) {
+	rules, err := parsePolicyDocument(testPolicyDocument)
+	require.NoError(t, err)
+
+	allowedManifest := &ocispec.Manifest{
+		Annotations: map[string]string{"org.opencontainers.image.source": "https://github.com/foo/bar"},
+	}
+
+	kprobe := operators.ProgramInfo{Name: "ig_open", Type: "Kprobe", Hook: "kprobe"}
+	classifier := operators.ProgramInfo{Name: "ig_dns", Type: "SchedCLS", Hook: "tc"}
+
+	for _, tc := range []struct {
+		name          string
+		imageName     string
+		manifest      *ocispec.Manifest
+		programs      []operators.ProgramInfo
+		params        []*api.Param
+		paramValues   api.ParamValues
+		expectedRules []string
+	}{
+		{
+			name:      "rule not matching",
+			imageName: "trace_open",
+			manifest:  allowedManifest,
+			programs:  []operators.ProgramInfo{kprobe},
+		},
+		{
+			name:      "matching rule allowing",
+			imageName: "trace_ssl:v1.0.0",
+			manifest:  allowedManifest,
+			programs:  []operators.ProgramInfo{kprobe},
+			params: []*api.Param{
+				{Prefix: "operator.KubeManager.", Key: "namespace", DefaultValue: ""},
+			},
+			paramValues: api.ParamValues{"operator.KubeManager.namespace": "default"},
+		},
+		{
+			name:      "matching rule denying",
+			imageName: "trace_ssl:v1.0.0",
+			manifest:  allowedManifest,
+			programs:  []operators.ProgramInfo{kprobe},
+			params: []*api.Param{
+				{Prefix: "operator.KubeManager.", Key: "namespace", DefaultValue: ""},
+			},
+			expectedRules: []string{"trace-ssl-namespaced"},
+		},
+		{
+			name:          "multiple rules denying",
+			imageName:     "ghcr.io/foo/trace_dns",
+			manifest:      &ocispec.Manifest{},
+			programs:      []operators.ProgramInfo{kprobe, classifier},
+			paramValues:   api.ParamValues{"operator.oci.ebpf.iface": "eth0"},
+			expectedRules: []string{"no-iface", "source-annotation", "no-lsm-nor-tc"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			gadgetCtx := &policyGadgetContext{
+				MockGadgetContext: gadgetcontext.MockGadgetContext{Ctx: context.Background()},
+				imageName:         tc.imageName,
+				vars: map[string]any{
+					ImageDigestVar:        "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+					operators.ProgramsVar: tc.programs,
+				},
+				params:      tc.params,
+				paramValues: tc.paramValues,
+			}
+
+			input, err := policyInput(gadgetCtx, tc.manifest)
+			require.NoError(t, err)
+
+			err = evaluatePolicy(rules, input)
+			if len(tc.expectedRules) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, "denied by policy")
+			for _, r := range rules {
+				if slices.Contains(tc.expectedRules, r.Name) {
+					assert.ErrorContains(t, err, `rule "`+r.Name+`"`)
+				} else {
+					assert.NotContains(t, err.Error(), `rule "`+r.Name+`"`)
+				}
+			}
+		})
+	}
+}
+
+func TestPolicyInput(t *testing.T) {
+	gadgetCtx := &policyGadgetContext{
+		MockGadgetContext: gadgetcontext.MockGadgetContext{Ctx: context.Background()},
+		imageName:         "trace_open:v1.0.0",
+		vars:              map[string]any{},
+		params: []*api.Param{
+			{Prefix: "operator.oci.ebpf.", Key: "iface", DefaultValue: ""},
+			{Prefix: "operator.filter.", Key: "filter", DefaultValue: "foo"},
+		},
+		paramValues: api.ParamValues{"operator.oci.ebpf.iface": "eth0"},
+	}
+
+	input, err := policyInput(gadgetCtx, &ocispec.Manifest{})
+	require.NoError(t, err)
+
+	image := input["image"].(map[string]any)
+	assert.Equal(t, "ghcr.io/inspektor-gadget/gadget/trace_open:v1.0.0", image["name"])
+	assert.Equal(t, "ghcr.io/inspektor-gadget/gadget/trace_open", image["repository"])
+	assert.Equal(t, "v1.0.0", image["tag"])
+	assert.Equal(t, "", image["digest"])
+
+	gadget := input["gadget"].(map[string]any)
+	assert.Equal(t, map[string]any{"name": "test"}, gadget["metadata"])
+	assert.Equal(t, "trace_open:v1.0.0", gadget["imageName"])
+
+	assert.Equal(t, []any{}, input["programs"])
+	assert.Equal(t, map[string]any{
+		"operator.oci.ebpf.iface": "eth0",
+		"operator.filter.filter":  "foo",
+	}, input["params"])
+}
@@ -43,6 +43,7 @@ type GadgetContext interface {
 	GetVar(string) (any, bool)
 	Params() []*api.Param
 	SetParams([]*api.Param)
+	ParamValues() api.ParamValues
 	SetMetadata([]byte) error
 	OrasTarget() oras.ReadOnlyTarget
 	IsRemoteCall() bool
@@ -55,8 +56,26 @@ const (
 	MapPrefix string = "map/"
 
 	MapSpecPrefix string = "mapspec/"
+
+	// ProgramsVar is the name of the gadget context variable holding the
+	// []ProgramInfo describing the eBPF programs of the gadget.
+	ProgramsVar string = "ebpf.programs"
 )
 
+// ProgramInfo describes an eBPF program of a gadget
+type ProgramInfo struct {
+	Name        string
+	SectionName string
+	// Type and AttachType are the names given by cilium/ebpf, e.g. "Kprobe" or
+	// "AttachTraceFEntry"
+	Type       string
+	AttachType string
+	AttachTo   string
+	// Hook is the kind of hook the program is attached to, e.g. "kprobe",
+	// "fentry" or "tc"
+	Hook string
+}
+
 type ImageOperator interface {
 	Name() string
 
@@ -122,6 +141,19 @@ type PostStop interface {
 	PostStop(gadgetCtx GadgetContext) error
 }
 
+// Replayable is implemented by data operators that only work on the data of
+// data sources and can therefore be used when replaying a recording; other
+// operators need access to the node or to the gadget image
+type Replayable interface {
+	SupportsReplay() bool
+}
+
+// SupportsReplay returns whether op can be used when replaying a recording
+func SupportsReplay(op DataOperator) bool {
+	r, ok := op.(Replayable)
+	return ok && r.SupportsReplay()
+}
+
 // ContainerInfoFromMountNSID is a typical kubernetes operator interface that adds node, pod, namespace and container
 // information given the MountNSID
 type ContainerInfoFromMountNSID interface {
@@ -0,0 +1,121 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otellogs
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// newExporter creates the exporter described by cfg
+func newExporter(cfg *logConfig) (sdklog.Exporter, error) {
+	switch cfg.Exporter {
+	case ExporterOTLPGRPC:
+		var options []otlploggrpc.Option
+
+		options = append(options, otlploggrpc.WithEndpoint(cfg.Endpoint))
+		if cfg.Insecure {
+			options = append(options, otlploggrpc.WithInsecure())
+		}
+		switch cfg.Compression {
+		default:
+			return nil, fmt.Errorf("unsupported log compression %q", cfg.Compression)
+		case "", CompressionNone:
+		case CompressionGZIP:
+			options = append(options, otlploggrpc.WithCompressor("gzip"))
+		}
+
+		exp, err := otlploggrpc.New(context.Background(), options...)
+		if err != nil {
+			return nil, fmt.Errorf("creating otlp exporter: %w", err)
+		}
+		return exp, nil
+	case ExporterOTLPHTTP:
+		var options []otlploghttp.Option
+
+		// Allow full URLs to use a custom path
+		if strings.Contains(cfg.Endpoint, "://") {
+			options = append(options, otlploghttp.WithEndpointURL(cfg.Endpoint))
+		} else {
+			options = append(options, otlploghttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			options = append(options, otlploghttp.WithInsecure())
+		}
+		switch cfg.Compression {
+		default:
+			return nil, fmt.Errorf("unsupported log compression %q", cfg.Compression)
+		case "", CompressionNone:
+		case CompressionGZIP:
+			options = append(options, otlploghttp.WithCompression(otlploghttp.GzipCompression))
+		}
+
+		exp, err := otlploghttp.New(context.Background(), options...)
+		if err != nil {
+			return nil, fmt.Errorf("creating otlp http exporter: %w", err)
+		}
+		return exp, nil
+	case ExporterJournald:
+		return newJournaldExporter(cfg), nil
+	case ExporterSyslog:
+		exp, err := newSyslogExporter(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("creating syslog exporter: %w", err)
+		}
+		return exp, nil
+	default:
+		return nil, fmt.Errorf("unsupported log exporter %q; expected one of %s", cfg.Exporter,
+			strings.Join(supportedExporters, ", "))
+	}
+}
+
+// valueString returns the textual representation of an attribute or body
+// value for the exporters that only handle text. Bytes are kept as is if they
+// are valid UTF-8 and base64 encoded otherwise.
+func valueString(v otellog.Value) string {
+	switch v.Kind() {
+	case otellog.KindEmpty:
+		return ""
+	case otellog.KindBytes:
+		b := v.AsBytes()
+		if utf8.Valid(b) {
+			return string(b)
+		}
+		return base64.StdEncoding.EncodeToString(b)
+	default:
+		return v.String()
+	}
+}
+
+// recordTimestamp returns the time of a record, falling back to the observed
+// timestamp and to the current time
+func recordTimestamp(r *sdklog.Record) time.Time {
+	if ts := r.Timestamp(); !ts.IsZero() {
+		return ts
+	}
+	if ts := r.ObservedTimestamp(); !ts.IsZero() {
+		return ts
+	}
+	return time.Now()
+}
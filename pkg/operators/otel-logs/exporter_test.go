@@ -0,0 +1,345 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otellogs
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// recordExporter keeps the records emitted by a logger to pass them to the
+// exporters under test
+type recordExporter struct {
+	records []sdklog.Record
+}
+
+func (e *recordExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	for _, r := range records {
+		e.records = append(e.records, r.Clone())
+	}
+	return nil
+}
+
+func (e *recordExporter) Shutdown(ctx context.Context) error   { return nil }
+func (e *recordExporter) ForceFlush(ctx context.Context) error { return nil }
+
+func newTestRecord(t *testing.T, body string, severity otellog.Severity, attrs ...otellog.KeyValue) sdklog.Record {
+	exp := &recordExporter{}
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exp)))
+
+	var rec otellog.Record
+	rec.SetBody(otellog.StringValue(body))
+	rec.SetSeverity(severity)
+	rec.SetTimestamp(time.Date(2026, 1, 2, 3, 4, 5, 6000, time.UTC))
+	rec.AddAttributes(attrs...)
+	provider.Logger("trace_open").Emit(context.Background(), rec)
+
+	require.Len(t, exp.records, 1)
+	return exp.records[0]
+}
+
+func TestParseSeverity(t *testing.T) {
+	for s, expected := range map[string]otellog.Severity{
+		"13":      otellog.SeverityWarn,
+		"WARN":    otellog.SeverityWarn,
+		"err":     otellog.SeverityError,
+		"notice":  otellog.SeverityInfo2,
+		" debug ": otellog.SeverityDebug,
+	} {
+		sv, err := parseSeverity(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, expected, sv, s)
+	}
+	_, err := parseSeverity("loud")
+	require.Error(t, err)
+}
+
+func TestSyslogSeverity(t *testing.T) {
+	// Converting the syslog names back gives the same syslog severity
+	for name, expected := range map[string]int{
+		"emerg":   syslogEmergency,
+		"alert":   syslogAlert,
+		"crit":    syslogCritical,
+		"err":     syslogError,
+		"warning": syslogWarning,
+		"notice":  syslogNotice,
+		"info":    syslogInfo,
+		"debug":   syslogDebug,
+	} {
+		sv, err := parseSeverity(name)
+		require.NoError(t, err)
+		assert.Equal(t, expected, syslogSeverity(sv), name)
+	}
+	assert.Equal(t, syslogInfo, syslogSeverity(otellog.SeverityUndefined))
+	assert.Equal(t, syslogDebug, syslogSeverity(otellog.SeverityTrace4))
+	assert.Equal(t, syslogEmergency, syslogSeverity(otellog.SeverityFatal4))
+}
+
+// syslogRegex matches the messages of the syslog exporter, see RFC 5424
+var syslogRegex = regexp.MustCompile(`^<(\d+)>1 (\S+) (\S+) (\S+) (\d+) (\S+) (-|\[.*\])(?: (.*))?$`)
+
+func checkSyslogMessage(t *testing.T, msg string) {
+	t.Helper()
+
+	m := syslogRegex.FindStringSubmatch(msg)
+	require.NotNil(t, m, "invalid message %q", msg)
+	// facility local0 (16), severity warning (4)
+	assert.Equal(t, "132", m[1])
+	assert.Equal(t, "2026-01-02T03:04:05.000006Z", m[2])
+	assert.Equal(t, "trace_open", m[4])
+	assert.Equal(t, strconv.Itoa(os.Getpid()), m[5])
+	assert.Equal(t, "-", m[6])
+	assert.Equal(t, `[ig@32473 comm="cat" fname="/etc/\"quoted\\\]" pid="42"]`, m[7])
+	assert.Equal(t, "file opened", m[8])
+}
+
+func testSyslogRecord(t *testing.T) sdklog.Record {
+	return newTestRecord(t, "file opened", otellog.SeverityWarn,
+		otellog.String("comm", "cat"),
+		otellog.String("fname", `/etc/"quoted\]`),
+		otellog.Int64("pid", 42),
+	)
+}
+
+func TestSyslogExporterUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	exp, err := newSyslogExporter(&logConfig{
+		Endpoint: "udp://" + conn.LocalAddr().String(),
+		Facility: "local0",
+	})
+	require.NoError(t, err)
+	defer exp.Shutdown(context.Background())
+
+	require.NoError(t, exp.Export(context.Background(), []sdklog.Record{testSyslogRecord(t)}))
+
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	checkSyslogMessage(t, string(buf[:n]))
+}
+
+func TestSyslogExporterTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	messages := make(chan string, 2)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// Octet-counting framing: "LEN SP MSG"
+		r := bufio.NewReader(conn)
+		for {
+			length, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+			if err != nil {
+				messages <- fmt.Sprintf("invalid frame length %q", length)
+				return
+			}
+			msg := make([]byte, n)
+			if _, err := io.ReadFull(r, msg); err != nil {
+				return
+			}
+			messages <- string(msg)
+		}
+	}()
+
+	exp, err := newSyslogExporter(&logConfig{
+		Endpoint: "tcp://" + l.Addr().String(),
+		Facility: "16",
+	})
+	require.NoError(t, err)
+	defer exp.Shutdown(context.Background())
+
+	rec := testSyslogRecord(t)
+	require.NoError(t, exp.Export(context.Background(), []sdklog.Record{rec, rec}))
+
+	for range 2 {
+		select {
+		case msg := <-messages:
+			checkSyslogMessage(t, msg)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for the message")
+		}
+	}
+}
+
+func TestSyslogExporterUnix(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "log")
+	conn, err := net.ListenPacket("unixgram", socketPath)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	exp, err := newSyslogExporter(&logConfig{
+		Exporter: ExporterSyslog,
+		Endpoint: "unix://" + socketPath,
+		Facility: "local0",
+	})
+	require.NoError(t, err)
+	defer exp.Shutdown(context.Background())
+
+	require.NoError(t, exp.Export(context.Background(), []sdklog.Record{testSyslogRecord(t)}))
+
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	checkSyslogMessage(t, string(buf[:n]))
+}
+
+func TestSyslogConfig(t *testing.T) {
+	for _, cfg := range []*logConfig{
+		{Endpoint: "http://127.0.0.1:514"},
+		{Endpoint: "udp://127.0.0.1"},
+		{Endpoint: "unix://"},
+		{Facility: "nope"},
+		{Facility: "24"},
+		{SDID: "with space"},
+	} {
+		_, err := newSyslogExporter(cfg)
+		require.Error(t, err, "%+v", cfg)
+	}
+
+	exp, err := newSyslogExporter(&logConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, "unix", exp.network)
+	assert.Equal(t, "/dev/log", exp.address)
+	assert.Equal(t, 1, exp.facility)
+}
+
+// parseJournalEntry parses an entry in the native protocol of journald
+func parseJournalEntry(t *testing.T, data []byte) map[string][]string {
+	t.Helper()
+
+	fields := make(map[string][]string)
+	for len(data) > 0 {
+		nl := strings.IndexByte(string(data), '\n')
+		require.NotEqual(t, -1, nl)
+		line := string(data[:nl])
+		data = data[nl+1:]
+
+		if name, value, ok := strings.Cut(line, "="); ok {
+			fields[name] = append(fields[name], value)
+			continue
+		}
+
+		// Binary safe format
+		require.GreaterOrEqual(t, len(data), 8)
+		size := binary.LittleEndian.Uint64(data)
+		data = data[8:]
+		require.GreaterOrEqual(t, uint64(len(data)), size+1)
+		fields[line] = append(fields[line], string(data[:size]))
+		require.Equal(t, byte('\n'), data[size])
+		data = data[size+1:]
+	}
+	return fields
+}
+
+func TestJournaldExporter(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "socket")
+	conn, err := net.ListenPacket("unixgram", socketPath)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	exp := newJournaldExporter(&logConfig{Endpoint: socketPath})
+	defer exp.Shutdown(context.Background())
+
+	rec := newTestRecord(t, "first line\nsecond line", otellog.SeverityError,
+		otellog.String("proc.comm", "cat"),
+		otellog.Int64("_pid", 42),
+		otellog.Bytes("data", []byte{0xff, 0x00}),
+	)
+	require.NoError(t, exp.Export(context.Background(), []sdklog.Record{rec}))
+
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string][]string{
+		"MESSAGE":           {"first line\nsecond line"},
+		"PRIORITY":          {"3"},
+		"SYSLOG_IDENTIFIER": {"trace_open"},
+		"PROC_COMM":         {"cat"},
+		"PID":               {"42"},
+		"DATA":              {"/wA="},
+	}, parseJournalEntry(t, buf[:n]))
+}
+
+func TestJournalFieldName(t *testing.T) {
+	for key, expected := range map[string]string{
+		"comm":                  "COMM",
+		"k8s.podName":           "K8S_PODNAME",
+		"__private":             "PRIVATE",
+		"1st":                   "F_1ST",
+		"":                      "F_",
+		strings.Repeat("a", 70): strings.Repeat("A", 64),
+	} {
+		assert.Equal(t, expected, journalFieldName(key), key)
+	}
+}
+
+func TestNewExporter(t *testing.T) {
+	for _, exporter := range []string{ExporterOTLPGRPC, ExporterOTLPHTTP, ExporterJournald, ExporterSyslog} {
+		exp, err := newExporter(&logConfig{Exporter: exporter, Endpoint: endpointFor(exporter), Insecure: true})
+		require.NoError(t, err, exporter)
+		require.NoError(t, exp.Shutdown(context.Background()))
+	}
+
+	_, err := newExporter(&logConfig{Exporter: ExporterOTLPHTTP, Endpoint: "127.0.0.1:4318", Compression: "zstd"})
+	require.Error(t, err)
+	_, err = newExporter(&logConfig{Exporter: "kafka"})
+	require.Error(t, err)
+}
+
+func endpointFor(exporter string) string {
+	switch exporter {
+	case ExporterOTLPGRPC:
+		return "127.0.0.1:4317"
+	case ExporterOTLPHTTP:
+		return "http://127.0.0.1:4318/custom/v1/logs"
+	case ExporterSyslog:
+		return "udp://127.0.0.1:514"
+	default:
+		return ""
+	}
+}
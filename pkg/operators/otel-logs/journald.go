@@ -0,0 +1,172 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otellogs
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+const (
+	defaultJournaldSocket = "/run/systemd/journal/socket"
+
+	journalMaxFieldName = 64
+)
+
+// journaldExporter sends the records to systemd-journald using its native
+// protocol. Attributes are sent as journal fields.
+type journaldExporter struct {
+	socketPath string
+
+	mu   sync.Mutex
+	conn *net.UnixConn
+}
+
+func newJournaldExporter(cfg *logConfig) *journaldExporter {
+	socketPath := cfg.Endpoint
+	if socketPath == "" {
+		socketPath = defaultJournaldSocket
+	}
+	return &journaldExporter{socketPath: socketPath}
+}
+
+func (e *journaldExporter) dial() error {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: e.socketPath, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("connecting to journald at %s: %w", e.socketPath, err)
+	}
+	e.conn = conn
+	return nil
+}
+
+func (e *journaldExporter) write(msg []byte) error {
+	if e.conn == nil {
+		if err := e.dial(); err != nil {
+			return err
+		}
+	}
+	_, err := e.conn.Write(msg)
+	if err != nil && isMsgSizeError(err) {
+		// Big entries have to be passed in a file descriptor
+		return sendJournalFd(e.conn, msg)
+	}
+	return err
+}
+
+func (e *journaldExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var errs []error
+	for i := range records {
+		msg := formatJournalEntry(&records[i])
+		if err := e.write(msg); err != nil {
+			// journald could have been restarted, try once more with a new
+			// connection
+			if e.conn != nil {
+				e.conn.Close()
+				e.conn = nil
+			}
+			if err := e.write(msg); err != nil {
+				errs = append(errs, fmt.Errorf("sending journal entry: %w", err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (e *journaldExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.conn != nil {
+		err := e.conn.Close()
+		e.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (e *journaldExporter) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// formatJournalEntry returns the journal entry for a record in the native
+// protocol of journald
+func formatJournalEntry(r *sdklog.Record) []byte {
+	var b bytes.Buffer
+
+	appendJournalField(&b, "MESSAGE", valueString(r.Body()))
+	appendJournalField(&b, "PRIORITY", strconv.Itoa(syslogSeverity(r.Severity())))
+	if name := r.InstrumentationScope().Name; name != "" {
+		appendJournalField(&b, "SYSLOG_IDENTIFIER", name)
+	}
+	r.WalkAttributes(func(kv otellog.KeyValue) bool {
+		appendJournalField(&b, journalFieldName(kv.Key), valueString(kv.Value))
+		return true
+	})
+	return b.Bytes()
+}
+
+// appendJournalField appends a field as "NAME=value\n", or using the binary
+// safe format "NAME\n<64 bit little endian size>value\n" when the value
+// contains new lines
+func appendJournalField(b *bytes.Buffer, name, value string) {
+	b.WriteString(name)
+	if !strings.Contains(value, "\n") {
+		b.WriteByte('=')
+		b.WriteString(value)
+		b.WriteByte('\n')
+		return
+	}
+	b.WriteByte('\n')
+	binary.Write(b, binary.LittleEndian, uint64(len(value)))
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
+
+// journalFieldName returns a valid journal field name for an attribute: only
+// uppercase letters, digits and underscores, not starting with an underscore
+// (reserved for trusted fields) or a digit
+func journalFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+	name = strings.TrimLeft(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "F_" + name
+	}
+	if len(name) > journalMaxFieldName {
+		name = name[:journalMaxFieldName]
+	}
+	return name
+}
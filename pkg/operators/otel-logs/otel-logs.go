@@ -17,12 +17,9 @@ package otellogs
 import (
 	"context"
 	"fmt"
-	"strconv"
-	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
-	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
 	otellog "go.opentelemetry.io/otel/log"
 	sdklog "go.opentelemetry.io/otel/sdk/log"
 	"go.opentelemetry.io/otel/sdk/resource"
@@ -51,6 +48,9 @@ const (
 	FieldNameSeverity  = "severity"
 
 	ExporterOTLPGRPC = "otlp-grpc"
+	ExporterOTLPHTTP = "otlp-http"
+	ExporterJournald = "journald"
+	ExporterSyslog   = "syslog"
 
 	CompressionNone = "none"
 	CompressionGZIP = "gzip"
@@ -58,13 +58,15 @@ const (
 	TagGroupOtelLogs = "group:OpenTelemetry Logs"
 )
 
-var supportedExporters = []string{ExporterOTLPGRPC}
+var supportedExporters = []string{ExporterOTLPGRPC, ExporterOTLPHTTP, ExporterJournald, ExporterSyslog}
 
 type logConfig struct {
 	Exporter    string `json:"exporter" yaml:"exporter"`
 	Endpoint    string `json:"endpoint" yaml:"endpoint"`
 	Insecure    bool   `json:"insecure" yaml:"insecure"`
 	Compression string `json:"compression" yaml:"compression"`
+	Facility    string `json:"facility" yaml:"facility"`
+	SDID        string `json:"sdid" yaml:"sdid"`
 }
 
 type otelLogsOperator struct {
@@ -94,32 +96,14 @@ func (o *otelLogsOperator) Init(params *params.Params) error {
 		log.Warnf("failed to load operator.otel-logs.exporters: %v", err)
 	}
 	for k, v := range configs {
-		if v.Exporter != ExporterOTLPGRPC {
-			return fmt.Errorf("unsupported log exporter %q; expected one of %s", v.Exporter,
-				strings.Join(supportedExporters, ", "))
-		}
-		var options []otlploggrpc.Option
-
-		options = append(options, otlploggrpc.WithEndpoint(v.Endpoint))
-		if v.Insecure {
-			options = append(options, otlploggrpc.WithInsecure())
-		}
-		switch v.Compression {
-		default:
-			return fmt.Errorf("unsupported log compression %q", v.Compression)
-		case "", CompressionNone:
-		case CompressionGZIP:
-			options = append(options, otlploggrpc.WithCompressor("gzip"))
-		}
-
-		exp, err := otlploggrpc.New(context.Background(), options...)
+		exp, err := newExporter(v)
 		if err != nil {
-			return fmt.Errorf("creating otlp exporter: %w", err)
+			return fmt.Errorf("creating log exporter %q: %w", k, err)
 		}
 		processor := sdklog.NewBatchProcessor(exp)
 		provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor), sdklog.WithResource(res))
 		o.providers[k] = provider
-		log.Debugf("> log exporter %q with endpoint %q loaded", k, v.Endpoint)
+		log.Debugf("> log exporter %q of type %q with endpoint %q loaded", k, v.Exporter, v.Endpoint)
 	}
 
 	return nil
@@ -165,6 +149,10 @@ func (o *otelLogsOperator) Priority() int {
 	return 9999
 }
 
+func (o *otelLogsOperator) SupportsReplay() bool {
+	return true
+}
+
 type otelLogsOperatorInstance struct {
 	o        *otelLogsOperator
 	mappings map[string]string
@@ -251,12 +239,12 @@ func (o *otelLogsOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) e
 
 		// fixed severity by annotation
 		if severity, ok := annotations[AnnotationLogsSeverity]; ok {
-			sv, err := strconv.ParseFloat(severity, 64)
+			sv, err := parseSeverity(severity)
 			if err != nil {
 				return fmt.Errorf("invalid log severity %q: %w", severity, err)
 			}
 			fns = append(fns, func(data datasource.Data, record *otellog.Record) {
-				record.SetSeverity(otellog.Severity(sv))
+				record.SetSeverity(sv)
 			})
 		}
 
@@ -314,6 +302,17 @@ func (o *otelLogsOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) e
 				})
 				continue
 			case FieldNameSeverity:
+				// Severity names like "warn" or "err" are accepted in string
+				// fields
+				if f.Type() == api.Kind_String || f.Type() == api.Kind_CString {
+					fns = append(fns, func(data datasource.Data, record *otellog.Record) {
+						str, _ := f.String(data)
+						if sv, err := parseSeverity(str); err == nil {
+							record.SetSeverity(sv)
+						}
+					})
+					continue
+				}
 				severity, err := datasource.AsInt64(f)
 				if err != nil {
 					return fmt.Errorf("using field %q as %q: %w", f.FullName(), name, err)
@@ -430,3 +430,49 @@ func TestPreStart_ParentWithChildren_NotEmitted(t *testing.T) {
 	// sibling should be present
 	require.Contains(t, attrMap, "other")
 }
+
+func TestPreStart_SeverityField(t *testing.T) {
+	ds, err := datasource.New(datasource.TypeSingle, "test-severity")
+	require.NoError(t, err)
+
+	levelField, err := ds.AddField("level", api.Kind_String)
+	require.NoError(t, err)
+	levelField.AddAnnotation("logs.name", "severity")
+
+	msgField, err := ds.AddField("msg", api.Kind_String)
+	require.NoError(t, err)
+	msgField.AddAnnotation("logs.name", "body")
+
+	exporter := &mockExporter{}
+	processor := sdklog.NewSimpleProcessor(exporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+
+	inst := &otelLogsOperatorInstance{
+		loggers: map[datasource.DataSource]otellog.Logger{
+			ds: provider.Logger("test-logger"),
+		},
+	}
+
+	gadgetCtx := &gadgetcontext.MockGadgetContext{
+		Ctx: context.Background(),
+		DataSources: map[string]datasource.DataSource{
+			"test-severity": ds,
+		},
+	}
+
+	require.NoError(t, inst.PreStart(gadgetCtx))
+
+	for _, level := range []string{"warning", "17"} {
+		packet, err := ds.NewPacketSingle()
+		require.NoError(t, err)
+		require.NoError(t, levelField.PutString(packet, level))
+		require.NoError(t, msgField.PutString(packet, "disk almost full"))
+		require.NoError(t, ds.EmitAndRelease(packet))
+	}
+
+	require.Len(t, exporter.records, 2)
+	// Severity names and numbers are both accepted in string fields
+	assert.Equal(t, otellog.SeverityWarn, exporter.records[0].Severity())
+	assert.Equal(t, otellog.SeverityError, exporter.records[1].Severity())
+	assert.Equal(t, otellog.StringValue("disk almost full"), exporter.records[0].Body())
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otellogs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	otellog "go.opentelemetry.io/otel/log"
+)
+
+// Syslog severities as defined by RFC 5424
+const (
+	syslogEmergency = iota
+	syslogAlert
+	syslogCritical
+	syslogError
+	syslogWarning
+	syslogNotice
+	syslogInfo
+	syslogDebug
+)
+
+// severityNames maps the OpenTelemetry and syslog severity names to
+// OpenTelemetry severities, following the mapping of the OpenTelemetry logs
+// data model
+var severityNames = map[string]otellog.Severity{
+	"trace":         otellog.SeverityTrace,
+	"debug":         otellog.SeverityDebug,
+	"info":          otellog.SeverityInfo,
+	"informational": otellog.SeverityInfo,
+	"notice":        otellog.SeverityInfo2,
+	"warn":          otellog.SeverityWarn,
+	"warning":       otellog.SeverityWarn,
+	"error":         otellog.SeverityError,
+	"err":           otellog.SeverityError,
+	"crit":          otellog.SeverityError2,
+	"critical":      otellog.SeverityError2,
+	"alert":         otellog.SeverityError3,
+	"fatal":         otellog.SeverityFatal,
+	"emerg":         otellog.SeverityFatal,
+	"emergency":     otellog.SeverityFatal,
+	"panic":         otellog.SeverityFatal,
+}
+
+// parseSeverity parses a severity given either as an OpenTelemetry severity
+// number or as a severity name like "warn" or "err"
+func parseSeverity(s string) (otellog.Severity, error) {
+	if sv, err := strconv.ParseFloat(s, 64); err == nil {
+		return otellog.Severity(sv), nil
+	}
+	if sv, ok := severityNames[strings.ToLower(strings.TrimSpace(s))]; ok {
+		return sv, nil
+	}
+	return otellog.SeverityUndefined, fmt.Errorf("unknown severity %q", s)
+}
+
+// syslogSeverity converts an OpenTelemetry severity to a syslog severity.
+// Events without severity are logged as informational.
+func syslogSeverity(sv otellog.Severity) int {
+	switch {
+	case sv == otellog.SeverityUndefined:
+		return syslogInfo
+	case sv < otellog.SeverityInfo:
+		return syslogDebug
+	case sv == otellog.SeverityInfo:
+		return syslogInfo
+	case sv < otellog.SeverityWarn:
+		return syslogNotice
+	case sv < otellog.SeverityError:
+		return syslogWarning
+	case sv == otellog.SeverityError:
+		return syslogError
+	case sv == otellog.SeverityError2:
+		return syslogCritical
+	case sv < otellog.SeverityFatal:
+		return syslogAlert
+	default:
+		return syslogEmergency
+	}
+}
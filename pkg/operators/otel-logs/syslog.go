@@ -0,0 +1,307 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otellogs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+const (
+	defaultSyslogEndpoint = "unix:///dev/log"
+	defaultSyslogFacility = "user"
+	// defaultSyslogSDID uses the private enterprise number reserved for
+	// documentation by RFC 5612
+	defaultSyslogSDID = "ig@32473"
+
+	syslogDialTimeout = 5 * time.Second
+
+	// Limits from RFC 5424
+	syslogMaxHostname = 255
+	syslogMaxAppName  = 48
+	syslogMaxSDName   = 32
+)
+
+var syslogFacilities = map[string]int{
+	"kern":     0,
+	"user":     1,
+	"mail":     2,
+	"daemon":   3,
+	"auth":     4,
+	"syslog":   5,
+	"lpr":      6,
+	"news":     7,
+	"uucp":     8,
+	"cron":     9,
+	"authpriv": 10,
+	"ftp":      11,
+	"local0":   16,
+	"local1":   17,
+	"local2":   18,
+	"local3":   19,
+	"local4":   20,
+	"local5":   21,
+	"local6":   22,
+	"local7":   23,
+}
+
+// syslogExporter sends the records as RFC 5424 messages to a syslog server.
+// Attributes are sent as the parameters of a single SD-ELEMENT.
+type syslogExporter struct {
+	network  string
+	address  string
+	facility int
+	sdID     string
+	hostname string
+	procID   string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	stream bool
+}
+
+func parseSyslogFacility(facility string) (int, error) {
+	if facility == "" {
+		facility = defaultSyslogFacility
+	}
+	if f, ok := syslogFacilities[strings.ToLower(facility)]; ok {
+		return f, nil
+	}
+	if f, err := strconv.Atoi(facility); err == nil && f >= 0 && f <= 23 {
+		return f, nil
+	}
+	return 0, fmt.Errorf("unknown syslog facility %q", facility)
+}
+
+// parseSyslogEndpoint parses endpoints like "udp://host:514", "tcp://host:601"
+// or "unix:///dev/log"
+func parseSyslogEndpoint(endpoint string) (network, address string, err error) {
+	if endpoint == "" {
+		endpoint = defaultSyslogEndpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", "", fmt.Errorf("parsing syslog endpoint %q: %w", endpoint, err)
+	}
+	switch u.Scheme {
+	case "udp", "tcp":
+		if u.Port() == "" {
+			return "", "", fmt.Errorf("syslog endpoint %q has no port", endpoint)
+		}
+		return u.Scheme, u.Host, nil
+	case "unix":
+		if u.Path == "" {
+			return "", "", fmt.Errorf("syslog endpoint %q has no path", endpoint)
+		}
+		return u.Scheme, u.Path, nil
+	default:
+		return "", "", fmt.Errorf("unsupported syslog endpoint %q; expected udp://, tcp:// or unix://", endpoint)
+	}
+}
+
+func newSyslogExporter(cfg *logConfig) (*syslogExporter, error) {
+	network, address, err := parseSyslogEndpoint(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	facility, err := parseSyslogFacility(cfg.Facility)
+	if err != nil {
+		return nil, err
+	}
+	sdID := cfg.SDID
+	if sdID == "" {
+		sdID = defaultSyslogSDID
+	}
+	if sdName(sdID) != sdID {
+		return nil, fmt.Errorf("invalid syslog SD-ID %q", sdID)
+	}
+
+	hostname, _ := os.Hostname()
+	return &syslogExporter{
+		network:  network,
+		address:  address,
+		facility: facility,
+		sdID:     sdID,
+		hostname: printUSASCII(hostname, syslogMaxHostname),
+		procID:   strconv.Itoa(os.Getpid()),
+	}, nil
+}
+
+// dial connects to the syslog server. Local sockets are usually datagram
+// sockets, but stream sockets are used as fallback like log/syslog does.
+func (e *syslogExporter) dial() error {
+	var err error
+	switch e.network {
+	case "unix":
+		e.conn, err = net.DialTimeout("unixgram", e.address, syslogDialTimeout)
+		e.stream = false
+		if err != nil {
+			e.conn, err = net.DialTimeout("unix", e.address, syslogDialTimeout)
+			e.stream = true
+		}
+	default:
+		e.conn, err = net.DialTimeout(e.network, e.address, syslogDialTimeout)
+		e.stream = e.network == "tcp"
+	}
+	if err != nil {
+		return fmt.Errorf("connecting to syslog server %s://%s: %w", e.network, e.address, err)
+	}
+	return nil
+}
+
+func (e *syslogExporter) write(msg []byte) error {
+	if e.conn == nil {
+		if err := e.dial(); err != nil {
+			return err
+		}
+	}
+	if e.stream {
+		// Octet-counting framing as defined by RFC 6587
+		msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	}
+	_, err := e.conn.Write(msg)
+	return err
+}
+
+func (e *syslogExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var errs []error
+	for i := range records {
+		msg := e.format(&records[i])
+		if err := e.write(msg); err != nil {
+			// The server could have been restarted, try once more with a new
+			// connection
+			if e.conn != nil {
+				e.conn.Close()
+				e.conn = nil
+			}
+			if err := e.write(msg); err != nil {
+				errs = append(errs, fmt.Errorf("sending syslog message: %w", err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (e *syslogExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.conn != nil {
+		err := e.conn.Close()
+		e.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (e *syslogExporter) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// format returns the RFC 5424 message for a record:
+// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+func (e *syslogExporter) format(r *sdklog.Record) []byte {
+	var b strings.Builder
+
+	pri := e.facility*8 + syslogSeverity(r.Severity())
+	b.WriteString("<" + strconv.Itoa(pri) + ">1 ")
+	b.WriteString(recordTimestamp(r).Format("2006-01-02T15:04:05.000000Z07:00"))
+	b.WriteString(" " + nilValue(e.hostname))
+	b.WriteString(" " + nilValue(appName(r.InstrumentationScope().Name)))
+	b.WriteString(" " + e.procID)
+	b.WriteString(" " + nilValue(printUSASCII(r.EventName(), 32)))
+	b.WriteString(" ")
+
+	if r.AttributesLen() == 0 {
+		b.WriteString("-")
+	} else {
+		b.WriteString("[" + e.sdID)
+		r.WalkAttributes(func(kv otellog.KeyValue) bool {
+			b.WriteString(" " + sdName(kv.Key) + `="`)
+			b.WriteString(sdParamEscaper.Replace(valueString(kv.Value)))
+			b.WriteString(`"`)
+			return true
+		})
+		b.WriteString("]")
+	}
+
+	if body := valueString(r.Body()); body != "" {
+		b.WriteString(" " + body)
+	}
+	return []byte(b.String())
+}
+
+// sdParamEscaper escapes the characters that must be escaped in a PARAM-VALUE
+var sdParamEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// printUSASCII keeps only the printable US-ASCII characters allowed in the
+// header fields of RFC 5424 and truncates s to maxLen characters
+func printUSASCII(s string, maxLen int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, s)
+	if len(s) > maxLen {
+		s = s[:maxLen]
+	}
+	return s
+}
+
+// appName returns the APP-NAME for a logger name. Logger names default to the
+// image name, keep only the last part of it when it's too long.
+func appName(name string) string {
+	name = printUSASCII(name, len(name))
+	if len(name) > syslogMaxAppName {
+		name = name[strings.LastIndex(name, "/")+1:]
+	}
+	return printUSASCII(name, syslogMaxAppName)
+}
+
+// sdName returns a valid SD-NAME, replacing the forbidden characters
+func sdName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > syslogMaxSDName {
+		s = s[:syslogMaxSDName]
+	}
+	return s
+}
+
+func nilValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -99,6 +99,14 @@ type metricsConfig struct {
 	Interval         time.Duration `json:"interval" yaml:"interval"`
 	CollectGoMetrics bool          `json:"collectGoMetrics" yaml:"collectGoMetrics"`
 	CollectIGMetrics bool          `json:"collectIGMetrics" yaml:"collectIGMetrics"`
+
+	// Settings only used by the prometheus-remote-write exporter
+	Headers        map[string]string `json:"headers" yaml:"headers"`
+	ExternalLabels map[string]string `json:"externalLabels" yaml:"externalLabels"`
+	BatchSize      int               `json:"batchSize" yaml:"batchSize"`
+	// MaxRetries is a pointer to tell 0, which disables retries, from unset
+	MaxRetries *int          `json:"maxRetries" yaml:"maxRetries"`
+	Timeout    time.Duration `json:"timeout" yaml:"timeout"`
 }
 
 func deltaSelector(kind sdkmetric.InstrumentKind) metricdata.Temporality {
@@ -145,10 +153,12 @@ func (m *otelMetricsOperator) Init(globalParams *params.Params) error {
 			log.Warnf("failed to load operator.otel-metrics.exporters: %v", err)
 		}
 		for k, v := range mc {
+			var exporter sdkmetric.Exporter
 			switch v.Exporter {
 			default:
 				log.Errorf("invalid metric exporter %q", v.Exporter)
-			case "otlp-grpc":
+				continue
+			case ExporterOTLPGRPC:
 				if v.Endpoint == "" {
 					return fmt.Errorf("endpoint required for otlp-grpc exporter")
 				}
@@ -169,40 +179,48 @@ func (m *otelMetricsOperator) Init(globalParams *params.Params) error {
 				if err != nil {
 					return fmt.Errorf("initializting otlp metrics collector")
 				}
-				var periodicReaderOptions []sdkmetric.PeriodicReaderOption
-				if v.Interval > 0 {
-					periodicReaderOptions = append(periodicReaderOptions, sdkmetric.WithInterval(v.Interval))
+				exporter = otlpcollector
+			case ExporterPrometheusRemoteWrite:
+				remoteWriteExporter, err := newRemoteWriteExporter(v)
+				if err != nil {
+					return fmt.Errorf("initializing prometheus remote write exporter %q: %w", k, err)
 				}
-				m.providers[k] = sdkmetric.NewMeterProvider(
-					sdkmetric.WithReader(
-						sdkmetric.NewPeriodicReader(otlpcollector, periodicReaderOptions...),
-					),
-				)
+				exporter = remoteWriteExporter
+			}
 
-				if v.CollectIGMetrics {
-					// Register with internal metrics
-					log.Debugf("registering internal metrics for provider %q", k)
-					err := metrics.RegisterProvider(m.providers[k])
-					if err != nil {
-						return fmt.Errorf("registering internal metrics for provider %q: %w", k, err)
-					}
+			var periodicReaderOptions []sdkmetric.PeriodicReaderOption
+			if v.Interval > 0 {
+				periodicReaderOptions = append(periodicReaderOptions, sdkmetric.WithInterval(v.Interval))
+			}
+			m.providers[k] = sdkmetric.NewMeterProvider(
+				sdkmetric.WithReader(
+					sdkmetric.NewPeriodicReader(exporter, periodicReaderOptions...),
+				),
+			)
+
+			if v.CollectIGMetrics {
+				// Register with internal metrics
+				log.Debugf("registering internal metrics for provider %q", k)
+				err := metrics.RegisterProvider(m.providers[k])
+				if err != nil {
+					return fmt.Errorf("registering internal metrics for provider %q: %w", k, err)
 				}
+			}
 
-				if v.CollectGoMetrics {
-					// Don't use deprecated runtime metrics
-					os.Setenv("OTEL_GO_X_DEPRECATED_RUNTIME_METRICS", "false")
-					// Also register go internal metrics
-					log.Debugf("registering go metrics for provider %q", k)
-					if err := runtime.Start(
-						runtime.WithMeterProvider(m.providers[k]),
-						runtime.WithMinimumReadMemStatsInterval(v.Interval),
-					); err != nil {
-						return fmt.Errorf("starting runtime instrumentation (internal Go metrics) for provider %q: %w", k, err)
-					}
+			if v.CollectGoMetrics {
+				// Don't use deprecated runtime metrics
+				os.Setenv("OTEL_GO_X_DEPRECATED_RUNTIME_METRICS", "false")
+				// Also register go internal metrics
+				log.Debugf("registering go metrics for provider %q", k)
+				if err := runtime.Start(
+					runtime.WithMeterProvider(m.providers[k]),
+					runtime.WithMinimumReadMemStatsInterval(v.Interval),
+				); err != nil {
+					return fmt.Errorf("starting runtime instrumentation (internal Go metrics) for provider %q: %w", k, err)
 				}
-
-				log.Debugf("initialized metric provider %q", k)
 			}
+
+			log.Debugf("initialized metric provider %q", k)
 		}
 	}
 
@@ -346,6 +364,10 @@ func (m *otelMetricsOperator) Priority() int {
 	return Priority
 }
 
+func (m *otelMetricsOperator) SupportsReplay() bool {
+	return true
+}
+
 type otelMetricsOperatorInstance struct {
 	op            *otelMetricsOperator
 	collectors    map[datasource.DataSource]*metricsCollector
@@ -0,0 +1,379 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelmetrics
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	ExporterOTLPGRPC              = "otlp-grpc"
+	ExporterPrometheusRemoteWrite = "prometheus-remote-write"
+
+	defaultRemoteWriteBatchSize    = 500
+	defaultRemoteWriteMaxRetries   = 3
+	defaultRemoteWriteTimeout      = 30 * time.Second
+	defaultRemoteWriteRetryBackoff = 500 * time.Millisecond
+
+	scopeNameLabel = "otel_scope_name"
+)
+
+// remoteWriteLabel and remoteWriteSeries mirror the Label and TimeSeries
+// messages of the Prometheus remote write protocol (v1)
+type remoteWriteLabel struct {
+	name  string
+	value string
+}
+
+type remoteWriteSeries struct {
+	labels    []remoteWriteLabel
+	value     float64
+	timestamp int64
+}
+
+// remoteWriteExporter is a metric exporter that pushes metrics to a Prometheus
+// remote write endpoint. Series are sent in batches of batchSize; failed
+// requests are retried with exponential backoff if the error is recoverable
+// (network errors, 5xx and 429 responses).
+type remoteWriteExporter struct {
+	endpoint       string
+	client         *http.Client
+	headers        map[string]string
+	externalLabels []remoteWriteLabel
+	batchSize      int
+	maxRetries     int
+	retryBackoff   time.Duration
+}
+
+func newRemoteWriteExporter(cfg *metricsConfig) (*remoteWriteExporter, error) {
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("endpoint required for %s exporter", ExporterPrometheusRemoteWrite)
+	}
+	if !strings.HasPrefix(cfg.Endpoint, "http://") && !strings.HasPrefix(cfg.Endpoint, "https://") {
+		return nil, fmt.Errorf("endpoint for %s exporter must be an http:// or https:// URL", ExporterPrometheusRemoteWrite)
+	}
+
+	e := &remoteWriteExporter{
+		endpoint:     cfg.Endpoint,
+		headers:      cfg.Headers,
+		batchSize:    cfg.BatchSize,
+		maxRetries:   defaultRemoteWriteMaxRetries,
+		retryBackoff: defaultRemoteWriteRetryBackoff,
+	}
+	if e.batchSize <= 0 {
+		e.batchSize = defaultRemoteWriteBatchSize
+	}
+	if cfg.MaxRetries != nil {
+		if *cfg.MaxRetries < 0 {
+			return nil, fmt.Errorf("invalid maxRetries %d", *cfg.MaxRetries)
+		}
+		e.maxRetries = *cfg.MaxRetries
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultRemoteWriteTimeout
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.Insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	e.client = &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+
+	for k, v := range cfg.ExternalLabels {
+		e.externalLabels = append(e.externalLabels, remoteWriteLabel{name: sanitizeLabelName(k), value: v})
+	}
+	return e, nil
+}
+
+// Temporality returns cumulative temporality for all instruments, as this is
+// what Prometheus expects
+func (e *remoteWriteExporter) Temporality(kind sdkmetric.InstrumentKind) metricdata.Temporality {
+	return sdkmetric.DefaultTemporalitySelector(kind)
+}
+
+func (e *remoteWriteExporter) Aggregation(kind sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+	return sdkmetric.DefaultAggregationSelector(kind)
+}
+
+func (e *remoteWriteExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	series := e.convert(rm)
+
+	var errs []error
+	for start := 0; start < len(series); start += e.batchSize {
+		end := min(start+e.batchSize, len(series))
+		if err := e.send(ctx, encodeWriteRequest(series[start:end])); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (e *remoteWriteExporter) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+func (e *remoteWriteExporter) Shutdown(ctx context.Context) error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+type recoverableError struct {
+	error
+}
+
+func (e *remoteWriteExporter) send(ctx context.Context, req []byte) error {
+	compressed := snappy.Encode(nil, req)
+
+	backoff := e.retryBackoff
+	var err error
+	for attempt := 0; attempt <= e.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("sending remote write request: %w (last error: %w)", ctx.Err(), err)
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		err = e.sendOnce(ctx, compressed)
+		if err == nil {
+			return nil
+		}
+		var recoverable recoverableError
+		if !errors.As(err, &recoverable) {
+			return err
+		}
+	}
+	return fmt.Errorf("sending remote write request after %d retries: %w", e.maxRetries, err)
+}
+
+func (e *remoteWriteExporter) sendOnce(ctx context.Context, body []byte) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating remote write request: %w", err)
+	}
+	for k, v := range e.headers {
+		httpReq.Header.Set(k, v)
+	}
+	httpReq.Header.Set("Content-Encoding", "snappy")
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	httpReq.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	resp, err := e.client.Do(httpReq)
+	if err != nil {
+		return recoverableError{fmt.Errorf("sending remote write request: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 == 2 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("remote write endpoint returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	if resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests {
+		return recoverableError{err}
+	}
+	return err
+}
+
+// convert flattens the given metrics to Prometheus time series following the
+// naming conventions of the Prometheus exporter: monotonic sums get a "_total"
+// suffix and histograms are split into "_bucket", "_sum" and "_count" series.
+func (e *remoteWriteExporter) convert(rm *metricdata.ResourceMetrics) []remoteWriteSeries {
+	var series []remoteWriteSeries
+	for _, sm := range rm.ScopeMetrics {
+		scopeLabel := remoteWriteLabel{name: scopeNameLabel, value: sm.Scope.Name}
+		for _, m := range sm.Metrics {
+			name := sanitizeMetricName(m.Name)
+			add := func(name string, attrs attribute.Set, t time.Time, value float64, extra ...remoteWriteLabel) {
+				labels := e.labels(name, attrs, append(extra, scopeLabel)...)
+				series = append(series, remoteWriteSeries{
+					labels:    labels,
+					value:     value,
+					timestamp: t.UnixMilli(),
+				})
+			}
+
+			switch data := m.Data.(type) {
+			case metricdata.Gauge[int64]:
+				for _, dp := range data.DataPoints {
+					add(name, dp.Attributes, dp.Time, float64(dp.Value))
+				}
+			case metricdata.Gauge[float64]:
+				for _, dp := range data.DataPoints {
+					add(name, dp.Attributes, dp.Time, dp.Value)
+				}
+			case metricdata.Sum[int64]:
+				sumName := name
+				if data.IsMonotonic {
+					sumName += "_total"
+				}
+				for _, dp := range data.DataPoints {
+					add(sumName, dp.Attributes, dp.Time, float64(dp.Value))
+				}
+			case metricdata.Sum[float64]:
+				sumName := name
+				if data.IsMonotonic {
+					sumName += "_total"
+				}
+				for _, dp := range data.DataPoints {
+					add(sumName, dp.Attributes, dp.Time, dp.Value)
+				}
+			case metricdata.Histogram[int64]:
+				for _, dp := range data.DataPoints {
+					addHistogram(add, name, dp.Attributes, dp.Time, dp.Bounds, dp.BucketCounts, dp.Count, float64(dp.Sum))
+				}
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					addHistogram(add, name, dp.Attributes, dp.Time, dp.Bounds, dp.BucketCounts, dp.Count, dp.Sum)
+				}
+			}
+		}
+	}
+	return series
+}
+
+func addHistogram(
+	add func(string, attribute.Set, time.Time, float64, ...remoteWriteLabel),
+	name string,
+	attrs attribute.Set,
+	t time.Time,
+	bounds []float64,
+	bucketCounts []uint64,
+	count uint64,
+	sum float64,
+) {
+	cumulative := uint64(0)
+	for i, bound := range bounds {
+		if i < len(bucketCounts) {
+			cumulative += bucketCounts[i]
+		}
+		add(name+"_bucket", attrs, t, float64(cumulative),
+			remoteWriteLabel{name: "le", value: strconv.FormatFloat(bound, 'f', -1, 64)})
+	}
+	add(name+"_bucket", attrs, t, float64(count), remoteWriteLabel{name: "le", value: "+Inf"})
+	add(name+"_sum", attrs, t, sum)
+	add(name+"_count", attrs, t, float64(count))
+}
+
+// labels returns the sorted label set for a series; labels of the data point
+// take precedence over external labels
+func (e *remoteWriteExporter) labels(name string, attrs attribute.Set, extra ...remoteWriteLabel) []remoteWriteLabel {
+	labelMap := make(map[string]string, attrs.Len()+len(extra)+len(e.externalLabels)+1)
+	for _, l := range e.externalLabels {
+		labelMap[l.name] = l.value
+	}
+	iter := attrs.Iter()
+	for iter.Next() {
+		kv := iter.Attribute()
+		labelMap[sanitizeLabelName(string(kv.Key))] = kv.Value.Emit()
+	}
+	for _, l := range extra {
+		labelMap[l.name] = l.value
+	}
+	labelMap["__name__"] = name
+
+	labels := make([]remoteWriteLabel, 0, len(labelMap))
+	for k, v := range labelMap {
+		labels = append(labels, remoteWriteLabel{name: k, value: v})
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].name < labels[j].name
+	})
+	return labels
+}
+
+func sanitize(s string, allowColon bool) string {
+	var sb strings.Builder
+	for i, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+		case r == ':' && allowColon:
+		case r >= '0' && r <= '9' && i > 0:
+		case r >= '0' && r <= '9':
+			sb.WriteRune('_')
+		default:
+			r = '_'
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func sanitizeMetricName(s string) string {
+	return sanitize(s, true)
+}
+
+func sanitizeLabelName(s string) string {
+	return sanitize(s, false)
+}
+
+// encodeWriteRequest encodes a prometheus.WriteRequest message:
+//
+//	message WriteRequest { repeated TimeSeries timeseries = 1; }
+//	message TimeSeries { repeated Label labels = 1; repeated Sample samples = 2; }
+//	message Label { string name = 1; string value = 2; }
+//	message Sample { double value = 1; int64 timestamp = 2; }
+func encodeWriteRequest(series []remoteWriteSeries) []byte {
+	var buf []byte
+	var ts, tmp []byte
+	for _, s := range series {
+		ts = ts[:0]
+		for _, l := range s.labels {
+			tmp = tmp[:0]
+			tmp = protowire.AppendTag(tmp, 1, protowire.BytesType)
+			tmp = protowire.AppendString(tmp, l.name)
+			tmp = protowire.AppendTag(tmp, 2, protowire.BytesType)
+			tmp = protowire.AppendString(tmp, l.value)
+			ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, tmp)
+		}
+		tmp = tmp[:0]
+		tmp = protowire.AppendTag(tmp, 1, protowire.Fixed64Type)
+		tmp = protowire.AppendFixed64(tmp, math.Float64bits(s.value))
+		tmp = protowire.AppendTag(tmp, 2, protowire.VarintType)
+		tmp = protowire.AppendVarint(tmp, uint64(s.timestamp))
+		ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, tmp)
+
+		buf = protowire.AppendTag(buf, 1, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, ts)
+	}
+	return buf
+}
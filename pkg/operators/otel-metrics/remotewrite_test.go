@@ -0,0 +1,343 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelmetrics
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// fakeRemoteWrite is a stand-in for a Prometheus remote write receiver; it
+// decodes all received requests
+type fakeRemoteWrite struct {
+	mu       sync.Mutex
+	requests int
+	series   []map[string]string
+	values   []float64
+	// statusCodes are returned (in order) for the first requests
+	statusCodes []int
+}
+
+func (f *fakeRemoteWrite) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests++
+	if len(f.statusCodes) > 0 {
+		code := f.statusCodes[0]
+		f.statusCodes = f.statusCodes[1:]
+		if code != http.StatusNoContent {
+			w.WriteHeader(code)
+			return
+		}
+	}
+
+	if r.Header.Get("Content-Encoding") != "snappy" ||
+		r.Header.Get("Content-Type") != "application/x-protobuf" ||
+		r.Header.Get("X-Prometheus-Remote-Write-Version") != "0.1.0" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	compressed, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	body, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err := f.decode(body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// consumeFields calls fn for every field of a protobuf message
+func consumeFields(b []byte, fn func(num protowire.Number, typ protowire.Type, b []byte) int) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		n = fn(num, typ, b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+func (f *fakeRemoteWrite) decode(b []byte) error {
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) int {
+		ts, n := protowire.ConsumeBytes(b)
+		labels := map[string]string{}
+		value := math.NaN()
+		consumeFields(ts, func(num protowire.Number, typ protowire.Type, b []byte) int {
+			msg, n := protowire.ConsumeBytes(b)
+			switch num {
+			case 1:
+				var name, value string
+				consumeFields(msg, func(num protowire.Number, typ protowire.Type, b []byte) int {
+					s, n := protowire.ConsumeString(b)
+					if num == 1 {
+						name = s
+					} else {
+						value = s
+					}
+					return n
+				})
+				labels[name] = value
+			case 2:
+				consumeFields(msg, func(num protowire.Number, typ protowire.Type, b []byte) int {
+					if num == 1 {
+						v, n := protowire.ConsumeFixed64(b)
+						value = math.Float64frombits(v)
+						return n
+					}
+					_, n := protowire.ConsumeVarint(b)
+					return n
+				})
+			}
+			return n
+		})
+		f.series = append(f.series, labels)
+		f.values = append(f.values, value)
+		return n
+	})
+}
+
+func (f *fakeRemoteWrite) find(labels map[string]string) (float64, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+outer:
+	for i, s := range f.series {
+		for k, v := range labels {
+			if s[k] != v {
+				continue outer
+			}
+		}
+		return f.values[i], true
+	}
+	return 0, false
+}
+
+func testResourceMetrics() *metricdata.ResourceMetrics {
+	now := time.Now()
+	attrs := attribute.NewSet(attribute.String("comm", "cat"))
+	return &metricdata.ResourceMetrics{
+		ScopeMetrics: []metricdata.ScopeMetrics{
+			{
+				Scope: instrumentation.Scope{Name: "mygadget"},
+				Metrics: []metricdata.Metrics{
+					{
+						Name: "calls",
+						Data: metricdata.Sum[int64]{
+							IsMonotonic: true,
+							DataPoints: []metricdata.DataPoint[int64]{
+								{Attributes: attrs, Time: now, Value: 42},
+							},
+						},
+					},
+					{
+						Name: "current.value",
+						Data: metricdata.Gauge[float64]{
+							DataPoints: []metricdata.DataPoint[float64]{
+								{Attributes: attrs, Time: now, Value: 1.5},
+							},
+						},
+					},
+					{
+						Name: "latency",
+						Data: metricdata.Histogram[int64]{
+							DataPoints: []metricdata.HistogramDataPoint[int64]{
+								{
+									Attributes:   attrs,
+									Time:         now,
+									Bounds:       []float64{10, 100},
+									BucketCounts: []uint64{1, 2, 3},
+									Count:        6,
+									Sum:          500,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestRemoteWriteExporter(t *testing.T) {
+	fake := &fakeRemoteWrite{}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	exporter, err := newRemoteWriteExporter(&metricsConfig{
+		Endpoint:       srv.URL,
+		ExternalLabels: map[string]string{"cluster": "test", "node-name": "node1"},
+	})
+	require.NoError(t, err)
+
+	err = exporter.Export(context.Background(), testResourceMetrics())
+	require.NoError(t, err)
+
+	require.Equal(t, 1, fake.requests)
+	// 1 counter + 1 gauge + 3 buckets + sum + count
+	require.Len(t, fake.series, 7)
+
+	v, ok := fake.find(map[string]string{
+		"__name__":     "calls_total",
+		"comm":         "cat",
+		"cluster":      "test",
+		"node_name":    "node1",
+		scopeNameLabel: "mygadget",
+	})
+	require.True(t, ok)
+	assert.Equal(t, 42.0, v)
+
+	v, ok = fake.find(map[string]string{"__name__": "current_value"})
+	require.True(t, ok)
+	assert.Equal(t, 1.5, v)
+
+	for le, expected := range map[string]float64{"10": 1, "100": 3, "+Inf": 6} {
+		v, ok = fake.find(map[string]string{"__name__": "latency_bucket", "le": le})
+		require.True(t, ok, "bucket %s", le)
+		assert.Equal(t, expected, v, "bucket %s", le)
+	}
+	v, ok = fake.find(map[string]string{"__name__": "latency_sum"})
+	require.True(t, ok)
+	assert.Equal(t, 500.0, v)
+	v, ok = fake.find(map[string]string{"__name__": "latency_count"})
+	require.True(t, ok)
+	assert.Equal(t, 6.0, v)
+}
+
+func TestRemoteWriteExporterBatching(t *testing.T) {
+	fake := &fakeRemoteWrite{}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	exporter, err := newRemoteWriteExporter(&metricsConfig{
+		Endpoint:  srv.URL,
+		BatchSize: 3,
+	})
+	require.NoError(t, err)
+
+	err = exporter.Export(context.Background(), testResourceMetrics())
+	require.NoError(t, err)
+
+	// 7 series in batches of 3
+	assert.Equal(t, 3, fake.requests)
+	assert.Len(t, fake.series, 7)
+}
+
+func TestRemoteWriteExporterRetries(t *testing.T) {
+	type testCase struct {
+		name             string
+		statusCodes      []int
+		maxRetries       int
+		expectedRequests int
+		expectError      bool
+	}
+
+	testCases := []testCase{
+		{
+			name:             "recoverable",
+			statusCodes:      []int{http.StatusInternalServerError, http.StatusTooManyRequests},
+			maxRetries:       3,
+			expectedRequests: 3,
+		},
+		{
+			name:             "exhausted",
+			statusCodes:      []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			maxRetries:       2,
+			expectedRequests: 3,
+			expectError:      true,
+		},
+		{
+			name:             "no retries",
+			statusCodes:      []int{http.StatusServiceUnavailable},
+			maxRetries:       0,
+			expectedRequests: 1,
+			expectError:      true,
+		},
+		{
+			name:             "not recoverable",
+			statusCodes:      []int{http.StatusBadRequest},
+			maxRetries:       3,
+			expectedRequests: 1,
+			expectError:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := &fakeRemoteWrite{statusCodes: tc.statusCodes}
+			srv := httptest.NewServer(fake)
+			defer srv.Close()
+
+			exporter, err := newRemoteWriteExporter(&metricsConfig{
+				Endpoint:   srv.URL,
+				MaxRetries: &tc.maxRetries,
+			})
+			require.NoError(t, err)
+			exporter.retryBackoff = time.Millisecond
+
+			err = exporter.Export(context.Background(), testResourceMetrics())
+			if tc.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedRequests, fake.requests)
+		})
+	}
+}
+
+func TestRemoteWriteExporterConfig(t *testing.T) {
+	_, err := newRemoteWriteExporter(&metricsConfig{})
+	require.Error(t, err)
+
+	_, err = newRemoteWriteExporter(&metricsConfig{Endpoint: "localhost:9090"})
+	require.Error(t, err)
+
+	maxRetries := -1
+	_, err = newRemoteWriteExporter(&metricsConfig{Endpoint: "http://localhost:9090/api/v1/write", MaxRetries: &maxRetries})
+	require.Error(t, err)
+
+	exporter, err := newRemoteWriteExporter(&metricsConfig{Endpoint: "http://localhost:9090/api/v1/write"})
+	require.NoError(t, err)
+	assert.Equal(t, defaultRemoteWriteMaxRetries, exporter.maxRetries)
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+)
+
+// maxKeys is the maximum number of keys tracked per data source; events of
+// further keys share a single bucket until idle keys are removed
+const maxKeys = 10000
+
+// bucket is a token bucket combined with a 1-in-N sampler
+type bucket struct {
+	tokens float64
+	last   time.Time
+	seen   uint64
+}
+
+// limiter rate limits and samples the events of a single data source
+type limiter struct {
+	rate   float64
+	burst  float64
+	sample uint64
+	keys   []datasource.FieldAccessor
+
+	mu       sync.Mutex
+	buckets  map[string]*bucket
+	overflow *bucket
+	keyBuf   []byte
+
+	// number of events dropped since the last report
+	limited uint64
+	sampled uint64
+}
+
+func newLimiter(ds datasource.DataSource, rate int, burst int, sample int, keys []string) (*limiter, error) {
+	if rate < 0 {
+		return nil, fmt.Errorf("invalid rate limit %d", rate)
+	}
+	if burst < 0 {
+		return nil, fmt.Errorf("invalid burst %d", burst)
+	}
+	if sample < 1 {
+		return nil, fmt.Errorf("invalid sample rate %d", sample)
+	}
+	if burst == 0 {
+		burst = rate
+	}
+	l := &limiter{
+		rate:    float64(rate),
+		burst:   float64(burst),
+		sample:  uint64(sample),
+		buckets: make(map[string]*bucket),
+	}
+	for _, name := range keys {
+		f := ds.GetField(name)
+		if f == nil {
+			return nil, fmt.Errorf("field %q not found", name)
+		}
+		if datasource.FieldFlagEmpty.In(f.Flags()) {
+			return nil, fmt.Errorf("field %q has no value to use as key", name)
+		}
+		l.keys = append(l.keys, f)
+	}
+	return l, nil
+}
+
+// bucketFor returns the bucket of the key of data; l.mu must be held
+func (l *limiter) bucketFor(data datasource.Data, now time.Time) *bucket {
+	l.keyBuf = l.keyBuf[:0]
+	for _, f := range l.keys {
+		b := f.Get(data)
+		l.keyBuf = binary.AppendUvarint(l.keyBuf, uint64(len(b)))
+		l.keyBuf = append(l.keyBuf, b...)
+	}
+	if b, ok := l.buckets[string(l.keyBuf)]; ok {
+		return b
+	}
+	if len(l.buckets) >= maxKeys {
+		if l.overflow == nil {
+			l.overflow = &bucket{tokens: l.burst, last: now}
+		}
+		return l.overflow
+	}
+	b := &bucket{tokens: l.burst, last: now}
+	l.buckets[string(l.keyBuf)] = b
+	return b
+}
+
+// allow returns whether the event should be kept
+func (l *limiter) allow(data datasource.Data, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.bucketFor(data, now)
+
+	b.seen++
+	if b.seen%l.sample != 0 {
+		l.sampled++
+		return false
+	}
+
+	if l.rate == 0 {
+		return true
+	}
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(l.burst, b.tokens+elapsed.Seconds()*l.rate)
+		b.last = now
+	}
+	if b.tokens < 1 {
+		l.limited++
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// reset returns the number of events dropped since the last call and removes
+// the buckets of keys that have been idle long enough to be full again
+func (l *limiter) reset(now time.Time) (limited uint64, sampled uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limited, sampled = l.limited, l.sampled
+	l.limited, l.sampled = 0, 0
+
+	// Without sampling, a full bucket is the same as a new one
+	if l.rate > 0 && l.sample == 1 {
+		for k, b := range l.buckets {
+			if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+				delete(l.buckets, k)
+			}
+		}
+	}
+	return limited, sampled
+}
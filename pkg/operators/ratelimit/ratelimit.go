@@ -0,0 +1,275 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ratelimit is a data operator that protects consumers of data sources
+// of type single from event storms. It drops events exceeding a token bucket
+// rate limit, optionally tracked per key like the container or the command,
+// and can sample 1 in N events. The number of dropped events is reported
+// periodically, so users know that data was lost.
+package ratelimit
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+)
+
+const (
+	name                  = "ratelimit"
+	ParamRateLimit        = "rate-limit"
+	ParamBurst            = "rate-limit-burst"
+	ParamKey              = "rate-limit-key"
+	ParamSample           = "sample"
+	ParamReportInterval   = "rate-limit-report-interval"
+	defaultReportInterval = "10s"
+
+	// Priority needs to be higher than the one of the filter operator (9000),
+	// so only events that would be shown count, and lower than the ones of the
+	// join (9050) and aggregate (9100) operators
+	Priority = 9010
+)
+
+type ratelimitOperator struct{}
+
+func (r *ratelimitOperator) Name() string {
+	return name
+}
+
+func (r *ratelimitOperator) Init(params *params.Params) error {
+	return nil
+}
+
+func (r *ratelimitOperator) GlobalParams() api.Params {
+	return nil
+}
+
+func (r *ratelimitOperator) InstanceParams() api.Params {
+	return api.Params{
+		{
+			Key:   ParamRateLimit,
+			Title: "Rate Limit",
+			Description: "Maximum number of events per second; events exceeding it are dropped. " +
+				"If using multiple data sources, prefix the value with 'datasourcename:' and separate with ','. " +
+				"Use 0 to disable the rate limit.",
+			DefaultValue: "0",
+			TypeHint:     api.TypeString,
+			Tags:         []string{api.TagGroupDataFiltering},
+		},
+		{
+			Key:   ParamBurst,
+			Title: "Rate Limit Burst",
+			Description: "Number of events that can exceed the rate limit in a short burst; defaults to the rate limit. " +
+				"If using multiple data sources, prefix the value with 'datasourcename:' and separate with ','.",
+			DefaultValue: "0",
+			TypeHint:     api.TypeString,
+			Tags:         []string{api.TagGroupDataFiltering},
+		},
+		{
+			Key:   ParamKey,
+			Title: "Rate Limit Key",
+			Description: "Fields to apply the rate limit and sampling to separately, like 'proc.comm' or 'k8s.containerName'. " +
+				"Join multiple fields with ','. If using multiple data sources, prefix fields with 'datasourcename:' and separate with ';'",
+			TypeHint: api.TypeString,
+			Tags:     []string{api.TagGroupDataFiltering},
+		},
+		{
+			Key:   ParamSample,
+			Title: "Sample",
+			Description: "Only keep 1 in N events. " +
+				"If using multiple data sources, prefix the value with 'datasourcename:' and separate with ','. " +
+				"Use 1 to keep all events.",
+			DefaultValue: "1",
+			TypeHint:     api.TypeString,
+			Tags:         []string{api.TagGroupDataFiltering},
+		},
+		{
+			Key:          ParamReportInterval,
+			Title:        "Rate Limit Report Interval",
+			Description:  "Interval in which the number of dropped events is reported",
+			DefaultValue: defaultReportInterval,
+			TypeHint:     api.TypeDuration,
+			Tags:         []string{api.TagGroupDataFiltering},
+		},
+	}
+}
+
+// valueForDs returns the value for the given data source, falling back to the
+// value for all data sources
+func valueForDs[T any](values map[string]T, ds datasource.DataSource) (T, bool, bool) {
+	if v, ok := values[ds.Name()]; ok {
+		return v, true, true
+	}
+	v, ok := values[""]
+	return v, ok, false
+}
+
+func (r *ratelimitOperator) InstantiateDataOperator(gadgetCtx operators.GadgetContext, instanceParamValues api.ParamValues) (operators.DataOperatorInstance, error) {
+	ratePerDs, err := apihelpers.GetIntValuesPerDataSource(instanceParamValues[ParamRateLimit])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamRateLimit, err)
+	}
+	burstPerDs, err := apihelpers.GetIntValuesPerDataSource(instanceParamValues[ParamBurst])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamBurst, err)
+	}
+	samplePerDs, err := apihelpers.GetIntValuesPerDataSource(instanceParamValues[ParamSample])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamSample, err)
+	}
+	keysPerDs, err := apihelpers.GetListValuesPerDataSource(instanceParamValues[ParamKey])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamKey, err)
+	}
+
+	params := apihelpers.ToParamDescs(r.InstanceParams()).ToParams()
+	if err := params.CopyFromMap(instanceParamValues, ""); err != nil {
+		return nil, err
+	}
+	reportInterval := params.Get(ParamReportInterval).AsDuration()
+	if reportInterval <= 0 {
+		return nil, fmt.Errorf("invalid value for %s: %s", ParamReportInterval, reportInterval)
+	}
+
+	instance := &ratelimitOperatorInstance{
+		reportInterval: reportInterval,
+		limiters:       make(map[datasource.DataSource]*limiter),
+	}
+
+	for _, ds := range gadgetCtx.GetDataSources() {
+		rate, _, rateSpecific := valueForDs(ratePerDs, ds)
+		sample, hasSample, sampleSpecific := valueForDs(samplePerDs, ds)
+		if !hasSample {
+			sample = 1
+		}
+		if rate == 0 && sample == 1 {
+			continue
+		}
+		if ds.Type() != datasource.TypeSingle {
+			if rateSpecific || sampleSpecific {
+				return nil, fmt.Errorf("rate limiting can only be used on data sources of type single, %q is not", ds.Name())
+			}
+			continue
+		}
+		burst, _, _ := valueForDs(burstPerDs, ds)
+		keys, _, _ := valueForDs(keysPerDs, ds)
+
+		l, err := newLimiter(ds, rate, burst, sample, apihelpers.SplitList(keys))
+		if err != nil {
+			return nil, fmt.Errorf("rate limiting data source %q: %w", ds.Name(), err)
+		}
+		gadgetCtx.Logger().Debugf("ratelimit: data source %q rate %d burst %d sample %d keys %q",
+			ds.Name(), rate, burst, sample, keys)
+		instance.limiters[ds] = l
+	}
+
+	if len(instance.limiters) == 0 {
+		return nil, nil
+	}
+	return instance, nil
+}
+
+func (r *ratelimitOperator) Priority() int {
+	return Priority
+}
+
+func (r *ratelimitOperator) SupportsReplay() bool {
+	return true
+}
+
+type ratelimitOperatorInstance struct {
+	reportInterval time.Duration
+	limiters       map[datasource.DataSource]*limiter
+	done           chan struct{}
+	wg             sync.WaitGroup
+}
+
+func (r *ratelimitOperatorInstance) Name() string {
+	return name
+}
+
+func (r *ratelimitOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
+	for ds, l := range r.limiters {
+		ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+			if !l.allow(data, time.Now()) {
+				return datasource.ErrDiscard
+			}
+			return nil
+		}, Priority)
+	}
+	return nil
+}
+
+// report logs the number of events dropped since the last report
+func (r *ratelimitOperatorInstance) report(gadgetCtx operators.GadgetContext, interval time.Duration) {
+	now := time.Now()
+	for ds, l := range r.limiters {
+		limited, sampled := l.reset(now)
+		if limited > 0 {
+			gadgetCtx.Logger().Warnf("ratelimit: dropped %d events of data source %q exceeding the rate limit in the last %s",
+				limited, ds.Name(), interval.Round(time.Second))
+		}
+		if sampled > 0 {
+			gadgetCtx.Logger().Infof("ratelimit: dropped %d events of data source %q by sampling in the last %s",
+				sampled, ds.Name(), interval.Round(time.Second))
+		}
+	}
+}
+
+func (r *ratelimitOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
+	r.done = make(chan struct{})
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		last := time.Now()
+		ticker := time.NewTicker(r.reportInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.done:
+				r.report(gadgetCtx, time.Since(last))
+				return
+			case now := <-ticker.C:
+				r.report(gadgetCtx, now.Sub(last))
+				last = now
+			}
+		}
+	}()
+	return nil
+}
+
+func (r *ratelimitOperatorInstance) Stop(gadgetCtx operators.GadgetContext) error {
+	if r.done != nil {
+		close(r.done)
+		r.wg.Wait()
+		r.done = nil
+	}
+	return nil
+}
+
+func (r *ratelimitOperatorInstance) Close(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+var Operator = &ratelimitOperator{}
+
+func init() {
+	operators.RegisterDataOperator(Operator)
+}
@@ -0,0 +1,229 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/simple"
+	testds "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/testing/datasource"
+)
+
+func newTestDataSource(t *testing.T, register testds.RegisterFunc) (datasource.DataSource, datasource.FieldAccessor) {
+	ds, fields := testds.New(t, register, "foo", testds.Field{Name: "proc.comm", Kind: api.Kind_String})
+	return ds, fields[0]
+}
+
+func TestNewLimiterErrors(t *testing.T) {
+	ds, _ := newTestDataSource(t, testds.Unregistered)
+
+	type testCase struct {
+		name   string
+		rate   int
+		burst  int
+		sample int
+		keys   []string
+	}
+	testCases := []testCase{
+		{name: "negative rate", rate: -1, sample: 1},
+		{name: "negative burst", rate: 1, burst: -1, sample: 1},
+		{name: "zero sample", sample: 0},
+		{name: "unknown key", rate: 1, sample: 1, keys: []string{"pid"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newLimiter(ds, tc.rate, tc.burst, tc.sample, tc.keys)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	type testCase struct {
+		name   string
+		rate   int
+		burst  int
+		sample int
+		keys   []string
+		// events are emitted with one comm per entry, all at the same time
+		// and then again one second later
+		comms           []string
+		expectedAllowed int
+		expectedLimited uint64
+		expectedSampled uint64
+	}
+
+	testCases := []testCase{
+		{
+			name:            "token bucket",
+			rate:            2,
+			sample:          1,
+			comms:           []string{"cat", "cat", "cat", "ls"},
+			expectedAllowed: 4,
+			expectedLimited: 4,
+		},
+		{
+			name:            "burst",
+			rate:            2,
+			burst:           3,
+			sample:          1,
+			comms:           []string{"cat", "cat", "cat", "ls"},
+			expectedAllowed: 5,
+			expectedLimited: 3,
+		},
+		{
+			name:            "per key",
+			rate:            2,
+			sample:          1,
+			keys:            []string{"proc.comm"},
+			comms:           []string{"cat", "cat", "cat", "ls"},
+			expectedAllowed: 6,
+			expectedLimited: 2,
+		},
+		{
+			name:            "sample",
+			sample:          2,
+			comms:           []string{"cat", "cat", "cat", "ls"},
+			expectedAllowed: 4,
+			expectedSampled: 4,
+		},
+		{
+			name:            "sample per key",
+			sample:          2,
+			keys:            []string{"proc.comm"},
+			comms:           []string{"cat", "ls", "ls", "ls"},
+			expectedAllowed: 4,
+			expectedSampled: 4,
+		},
+		{
+			name:            "sample and rate",
+			rate:            1,
+			sample:          2,
+			comms:           []string{"cat", "cat", "cat", "ls"},
+			expectedAllowed: 2,
+			expectedLimited: 2,
+			expectedSampled: 4,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ds, comm := newTestDataSource(t, testds.Unregistered)
+			l, err := newLimiter(ds, tc.rate, tc.burst, tc.sample, tc.keys)
+			require.NoError(t, err)
+
+			now := time.Now()
+			allowed := 0
+			for _, ts := range []time.Time{now, now.Add(time.Second)} {
+				for _, c := range tc.comms {
+					p, err := ds.NewPacketSingle()
+					require.NoError(t, err)
+					require.NoError(t, comm.PutString(p, c))
+					if l.allow(p, ts) {
+						allowed++
+					}
+					ds.Release(p)
+				}
+			}
+
+			limited, sampled := l.reset(now.Add(time.Second))
+			assert.Equal(t, tc.expectedAllowed, allowed)
+			assert.Equal(t, tc.expectedLimited, limited)
+			assert.Equal(t, tc.expectedSampled, sampled)
+
+			// Counters are reset
+			limited, sampled = l.reset(now.Add(time.Second))
+			assert.Zero(t, limited)
+			assert.Zero(t, sampled)
+		})
+	}
+}
+
+func TestLimiterRemovesIdleKeys(t *testing.T) {
+	ds, comm := newTestDataSource(t, testds.Unregistered)
+	l, err := newLimiter(ds, 10, 0, 1, []string{"proc.comm"})
+	require.NoError(t, err)
+
+	now := time.Now()
+	for _, c := range []string{"cat", "ls"} {
+		p, err := ds.NewPacketSingle()
+		require.NoError(t, err)
+		require.NoError(t, comm.PutString(p, c))
+		require.True(t, l.allow(p, now))
+		ds.Release(p)
+	}
+	require.Len(t, l.buckets, 2)
+
+	l.reset(now)
+	assert.Len(t, l.buckets, 2)
+
+	l.reset(now.Add(time.Second))
+	assert.Empty(t, l.buckets)
+}
+
+func TestRatelimitOperator(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	var ds datasource.DataSource
+	var comm datasource.FieldAccessor
+
+	producer := simple.New("producer",
+		simple.WithPriority(Priority-1),
+		simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+			ds, comm = newTestDataSource(t, gadgetCtx.RegisterDataSource)
+			return nil
+		}),
+		simple.OnStart(func(gadgetCtx operators.GadgetContext) error {
+			for range 100 {
+				p, err := ds.NewPacketSingle()
+				require.NoError(t, err)
+				comm.PutString(p, "cat")
+				require.NoError(t, ds.EmitAndRelease(p))
+			}
+			cancel()
+			return nil
+		}),
+	)
+
+	received := 0
+	verifier := simple.New("verifier",
+		simple.WithPriority(Priority+1),
+		simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+			return gadgetCtx.GetDataSources()["foo"].Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+				received++
+				return nil
+			}, Priority+1)
+		}),
+	)
+
+	gadgetCtx := gadgetcontext.New(ctx, "", gadgetcontext.WithDataOperators(Operator, producer, verifier))
+	err := gadgetCtx.Run(api.ParamValues{
+		"operator.ratelimit.rate-limit": "foo:10",
+		"operator.ratelimit.sample":     "2",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 10, received)
+}
@@ -0,0 +1,175 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package record is a data operator that persists the gadget info and all
+// packets emitted by the data sources of a gadget run to a file. Recordings
+// can be replayed later on using the replay parameter of the local runtime.
+// Packets are recorded before they reach the filter operator, so replays can
+// apply different filters, sorting and output modes.
+package record
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/recording"
+)
+
+const (
+	name        = "record"
+	ParamRecord = "record"
+
+	// Priority is used both for the operator and its subscriptions; it needs to
+	// be lower than the one of the filter operator (9000) to record all events
+	Priority = 8900
+)
+
+type recordOperator struct{}
+
+func (r *recordOperator) Name() string {
+	return name
+}
+
+func (r *recordOperator) Init(params *params.Params) error {
+	return nil
+}
+
+func (r *recordOperator) GlobalParams() api.Params {
+	return nil
+}
+
+func (r *recordOperator) InstanceParams() api.Params {
+	return api.Params{
+		{
+			Key:          ParamRecord,
+			Title:        "Record",
+			Description:  "Path of a file to record the gadget output to. Use the replay parameter to replay it later on.",
+			DefaultValue: "",
+			TypeHint:     api.TypeString,
+			Tags:         []string{api.TagGroupDataCollection},
+		},
+	}
+}
+
+func (r *recordOperator) InstantiateDataOperator(gadgetCtx operators.GadgetContext, instanceParamValues api.ParamValues) (operators.DataOperatorInstance, error) {
+	fileName := instanceParamValues[ParamRecord]
+	if fileName == "" {
+		return nil, nil
+	}
+
+	// The file would be created by the server, with its privileges, at a
+	// path chosen by the client
+	if gadgetCtx.IsRemoteCall() {
+		return nil, fmt.Errorf("%s isn't supported for remote runs", ParamRecord)
+	}
+
+	return &recordOperatorInstance{
+		fileName: fileName,
+	}, nil
+}
+
+func (r *recordOperator) Priority() int {
+	return Priority
+}
+
+type recordOperatorInstance struct {
+	fileName string
+	file     *os.File
+	writer   *recording.Writer
+}
+
+func (r *recordOperatorInstance) Name() string {
+	return name
+}
+
+func (r *recordOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
+	// All data sources and their fields are registered at this point
+	gi, err := gadgetCtx.SerializeGadgetInfo(false)
+	if err != nil {
+		return fmt.Errorf("serializing gadget info: %w", err)
+	}
+
+	// Use the same data source IDs as the gadget service does when sending
+	// events over gRPC
+	dsLookup := make(map[string]uint32)
+	for i, ds := range gi.DataSources {
+		ds.Id = uint32(i)
+		dsLookup[ds.Name] = ds.Id
+	}
+
+	f, err := os.Create(r.fileName)
+	if err != nil {
+		return fmt.Errorf("creating recording file: %w", err)
+	}
+
+	r.writer, err = recording.NewWriter(f)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("creating recording: %w", err)
+	}
+	r.file = f
+
+	if err := r.writer.WriteGadgetInfo(gi); err != nil {
+		return fmt.Errorf("recording gadget info: %w", err)
+	}
+
+	logger := gadgetCtx.Logger()
+	for _, ds := range gadgetCtx.GetDataSources() {
+		dsID := dsLookup[ds.Name()]
+		ds.SubscribePacket(func(ds datasource.DataSource, packet datasource.Packet) error {
+			d, err := proto.Marshal(packet.Raw())
+			if err != nil {
+				logger.Warnf("record: marshaling packet of data source %q: %v", ds.Name(), err)
+				return nil
+			}
+			if err := r.writer.WritePayload(dsID, d); err != nil {
+				logger.Warnf("record: writing packet of data source %q: %v", ds.Name(), err)
+			}
+			return nil
+		}, Priority)
+	}
+
+	logger.Debugf("record: recording to %q", r.fileName)
+	return nil
+}
+
+func (r *recordOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func (r *recordOperatorInstance) Stop(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func (r *recordOperatorInstance) Close(gadgetCtx operators.GadgetContext) error {
+	if r.file == nil {
+		return nil
+	}
+	err := errors.Join(r.writer.Close(), r.file.Close())
+	r.file = nil
+	return err
+}
+
+var Operator = &recordOperator{}
+
+func init() {
+	operators.RegisterDataOperator(Operator)
+}
@@ -0,0 +1,49 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package record
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/testing/gadget-context"
+)
+
+type remoteGadgetContext struct {
+	gadgetcontext.MockGadgetContext
+}
+
+func (c *remoteGadgetContext) IsRemoteCall() bool {
+	return true
+}
+
+func TestRecordRemoteCall(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "recording")
+	gadgetCtx := &remoteGadgetContext{
+		MockGadgetContext: gadgetcontext.MockGadgetContext{Ctx: context.Background()},
+	}
+
+	instance, err := Operator.InstantiateDataOperator(gadgetCtx, api.ParamValues{ParamRecord: fileName})
+	require.ErrorContains(t, err, "isn't supported for remote runs")
+	require.Nil(t, instance)
+
+	_, err = os.Stat(fileName)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
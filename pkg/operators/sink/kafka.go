@@ -0,0 +1,77 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+)
+
+type kafkaPublisher struct {
+	client *kgo.Client
+}
+
+func newKafkaPublisher(cfg *sinkConfig) (*kafkaPublisher, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, errors.New("no kafka brokers configured")
+	}
+
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(cfg.Brokers...),
+		// Wait for all in-sync replicas, retries are handled by the batcher
+		kgo.RequiredAcks(kgo.AllISRAcks()),
+		kgo.RecordRetries(1),
+		kgo.MaxBufferedRecords(cfg.BatchSize),
+		kgo.ProducerBatchCompression(kgo.SnappyCompression(), kgo.NoCompression()),
+	}
+	if cfg.TLS {
+		opts = append(opts, kgo.DialTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}))
+	}
+	if cfg.Username != "" {
+		opts = append(opts, kgo.SASL(plain.Auth{User: cfg.Username, Pass: cfg.Password}.AsMechanism()))
+	}
+
+	// The client connects lazily to the brokers
+	client, err := kgo.NewClient(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("creating kafka client: %w", err)
+	}
+	return &kafkaPublisher{client: client}, nil
+}
+
+func (p *kafkaPublisher) Publish(ctx context.Context, msgs []*message) error {
+	records := make([]*kgo.Record, 0, len(msgs))
+	for _, msg := range msgs {
+		r := &kgo.Record{
+			Topic: msg.topic,
+			Value: msg.value,
+		}
+		for k, v := range msg.headers {
+			r.Headers = append(r.Headers, kgo.RecordHeader{Key: k, Value: []byte(v)})
+		}
+		records = append(records, r)
+	}
+	return p.client.ProduceSync(ctx, records...).FirstErr()
+}
+
+func (p *kafkaPublisher) Close() error {
+	p.client.Close()
+	return nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// natsPublisher publishes to NATS subjects. Core NATS doesn't acknowledge
+// messages, so at-least-once delivery is only guaranteed with JetStream.
+type natsPublisher struct {
+	conn *nats.Conn
+	js   jetstream.JetStream
+}
+
+func newNATSPublisher(cfg *sinkConfig) (*natsPublisher, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("no nats url configured")
+	}
+
+	opts := []nats.Option{
+		nats.Name("inspektor-gadget"),
+		// Don't fail if the server isn't available yet, messages are retried
+		// by the batcher
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+	}
+	if cfg.TLS {
+		opts = append(opts, nats.Secure(&tls.Config{MinVersion: tls.VersionTLS12}))
+	}
+	if cfg.Username != "" {
+		opts = append(opts, nats.UserInfo(cfg.Username, cfg.Password))
+	}
+	if cfg.Token != "" {
+		opts = append(opts, nats.Token(cfg.Token))
+	}
+
+	conn, err := nats.Connect(cfg.URL, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to nats: %w", err)
+	}
+
+	p := &natsPublisher{conn: conn}
+	if cfg.JetStream {
+		p.js, err = jetstream.New(conn)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("creating jetstream context: %w", err)
+		}
+	}
+	return p, nil
+}
+
+func natsMsg(msg *message) *nats.Msg {
+	m := nats.NewMsg(msg.topic)
+	m.Data = msg.value
+	for k, v := range msg.headers {
+		m.Header.Set(k, v)
+	}
+	return m
+}
+
+func (p *natsPublisher) Publish(ctx context.Context, msgs []*message) error {
+	if p.js == nil {
+		for _, msg := range msgs {
+			if err := p.conn.PublishMsg(natsMsg(msg)); err != nil {
+				return err
+			}
+		}
+		// Make sure the server got the messages
+		return p.conn.FlushWithContext(ctx)
+	}
+
+	futures := make([]jetstream.PubAckFuture, 0, len(msgs))
+	for _, msg := range msgs {
+		f, err := p.js.PublishMsgAsync(natsMsg(msg))
+		if err != nil {
+			return err
+		}
+		futures = append(futures, f)
+	}
+	for _, f := range futures {
+		select {
+		case <-f.Ok():
+		case err := <-f.Err():
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (p *natsPublisher) Close() error {
+	if err := p.conn.Drain(); err != nil {
+		p.conn.Close()
+		return err
+	}
+	return nil
+}
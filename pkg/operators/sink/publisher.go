@@ -0,0 +1,69 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"context"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/common"
+)
+
+const maxRetryBackoff = 30 * time.Second
+
+// message is a serialized packet to be published
+type message struct {
+	topic   string
+	value   []byte
+	headers map[string]string
+}
+
+// publisher publishes batches of messages to a streaming platform. Publish
+// must only return once all messages have been acknowledged, or return an
+// error if any of them wasn't.
+type publisher interface {
+	Publish(ctx context.Context, msgs []*message) error
+	Close() error
+}
+
+// output queues the messages of a sink and publishes them in batches
+type output struct {
+	*common.DeliveryQueue[*message]
+	pub publisher
+}
+
+func newOutput(pub publisher, cfg *sinkConfig, logger logger.Logger) *output {
+	return &output{
+		DeliveryQueue: common.NewDeliveryQueue(common.DeliveryQueueConfig{
+			Name:            name,
+			Size:            cfg.BufferSize,
+			Block:           cfg.Backpressure == BackpressureBlock,
+			BatchSize:       cfg.BatchSize,
+			BatchTimeout:    cfg.BatchTimeout,
+			MaxAttempts:     *cfg.MaxRetries + 1,
+			RetryBackoff:    cfg.RetryBackoff,
+			MaxRetryBackoff: maxRetryBackoff,
+			FlushTimeout:    cfg.FlushTimeout,
+		}, pub.Publish, logger),
+		pub: pub,
+	}
+}
+
+// close publishes the queued messages and closes the publisher
+func (o *output) close() error {
+	o.Close()
+	return o.pub.Close()
+}
@@ -0,0 +1,422 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sink is a data operator that publishes the packets of data sources
+// to streaming platforms like Kafka or NATS. Sinks are defined in the
+// configuration file and selected per data source using instance parameters,
+// so they also work for gadget instances running unattended.
+package sink
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/config"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	jsonformatter "github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/formatters/json"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/common"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+)
+
+const (
+	name = "sink"
+
+	ParamSink      = "sink"
+	ParamSinkTopic = "sink-topic"
+
+	TypeKafka = "kafka"
+	TypeNATS  = "nats"
+
+	FormatJSON     = "json"
+	FormatProtobuf = "protobuf"
+
+	BackpressureBlock = "block"
+	BackpressureDrop  = "drop"
+
+	DefaultTopic = "ig.{{.GadgetName}}.{{.DataSource}}"
+
+	// DefaultMaxRetries bounds the time a batch can block the data sources
+	// when the backpressure policy is block and the server is unreachable
+	DefaultMaxRetries = 10
+
+	// Headers added to every message
+	HeaderDataSource  = "ig-datasource"
+	HeaderGadget      = "ig-gadget"
+	HeaderInstance    = "ig-instance"
+	HeaderNode        = "ig-node"
+	HeaderContentType = "content-type"
+
+	// Priority is used both for the operator and its subscriptions; it's
+	// higher than the one of the filter operator to only publish the
+	// filtered events
+	Priority = 9999
+)
+
+var (
+	supportedTypes        = []string{TypeKafka, TypeNATS}
+	supportedFormats      = []string{FormatJSON, FormatProtobuf}
+	supportedBackpressure = []string{BackpressureBlock, BackpressureDrop}
+)
+
+type sinkConfig struct {
+	Type string `json:"type" yaml:"type"`
+
+	// Kafka
+	Brokers []string `json:"brokers" yaml:"brokers"`
+
+	// NATS
+	URL       string `json:"url" yaml:"url"`
+	JetStream bool   `json:"jetstream" yaml:"jetstream"`
+	Token     string `json:"token" yaml:"token"`
+
+	Username string `json:"username" yaml:"username"`
+	Password string `json:"password" yaml:"password"`
+	TLS      bool   `json:"tls" yaml:"tls"`
+
+	Topic  string `json:"topic" yaml:"topic"`
+	Format string `json:"format" yaml:"format"`
+
+	BatchSize    int           `json:"batchSize" yaml:"batchSize"`
+	BatchTimeout time.Duration `json:"batchTimeout" yaml:"batchTimeout"`
+	BufferSize   int           `json:"bufferSize" yaml:"bufferSize"`
+	Backpressure string        `json:"backpressure" yaml:"backpressure"`
+	MaxRetries   *int          `json:"maxRetries" yaml:"maxRetries"`
+	RetryBackoff time.Duration `json:"retryBackoff" yaml:"retryBackoff"`
+	FlushTimeout time.Duration `json:"flushTimeout" yaml:"flushTimeout"`
+}
+
+// validate checks the configuration and sets the defaults
+func (c *sinkConfig) validate() error {
+	switch c.Type {
+	case TypeKafka, TypeNATS:
+	default:
+		return fmt.Errorf("unsupported sink type %q; expected one of %s", c.Type, strings.Join(supportedTypes, ", "))
+	}
+	switch c.Format {
+	case "":
+		c.Format = FormatJSON
+	case FormatJSON, FormatProtobuf:
+	default:
+		return fmt.Errorf("unsupported format %q; expected one of %s", c.Format, strings.Join(supportedFormats, ", "))
+	}
+	switch c.Backpressure {
+	case "":
+		c.Backpressure = BackpressureBlock
+	case BackpressureBlock, BackpressureDrop:
+	default:
+		return fmt.Errorf("unsupported backpressure policy %q; expected one of %s", c.Backpressure,
+			strings.Join(supportedBackpressure, ", "))
+	}
+	if c.Topic == "" {
+		c.Topic = DefaultTopic
+	}
+	if _, err := newTopicTemplate(c.Topic); err != nil {
+		return err
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+	}
+	if c.BatchTimeout <= 0 {
+		c.BatchTimeout = time.Second
+	}
+	if c.BufferSize <= 0 {
+		c.BufferSize = 10000
+	}
+	if c.MaxRetries == nil {
+		maxRetries := DefaultMaxRetries
+		c.MaxRetries = &maxRetries
+	} else if *c.MaxRetries < 0 {
+		return fmt.Errorf("invalid maxRetries %d", *c.MaxRetries)
+	}
+	if c.RetryBackoff <= 0 {
+		c.RetryBackoff = 100 * time.Millisecond
+	}
+	if c.FlushTimeout <= 0 {
+		c.FlushTimeout = 10 * time.Second
+	}
+	return nil
+}
+
+func newPublisher(cfg *sinkConfig) (publisher, error) {
+	switch cfg.Type {
+	case TypeKafka:
+		return newKafkaPublisher(cfg)
+	case TypeNATS:
+		return newNATSPublisher(cfg)
+	}
+	return nil, fmt.Errorf("unsupported sink type %q", cfg.Type)
+}
+
+// topicVars are the variables available in topic templates
+type topicVars struct {
+	DataSource string
+	Gadget     string
+	GadgetName string
+	Instance   string
+	InstanceID string
+	Node       string
+}
+
+func newTopicTemplate(topic string) (*template.Template, error) {
+	tmpl, err := template.New("topic").Option("missingkey=error").Parse(topic)
+	if err != nil {
+		return nil, fmt.Errorf("parsing topic template %q: %w", topic, err)
+	}
+	return tmpl, nil
+}
+
+func renderTopic(topic string, vars *topicVars) (string, error) {
+	tmpl, err := newTopicTemplate(topic)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, vars); err != nil {
+		return "", fmt.Errorf("rendering topic template %q: %w", topic, err)
+	}
+	if b.Len() == 0 {
+		return "", fmt.Errorf("topic template %q renders an empty topic", topic)
+	}
+	return b.String(), nil
+}
+
+type sinkOperator struct {
+	sinks map[string]*sinkConfig
+}
+
+func (s *sinkOperator) Name() string {
+	return name
+}
+
+func (s *sinkOperator) Init(params *params.Params) error {
+	s.sinks = make(map[string]*sinkConfig)
+
+	if config.Config == nil {
+		return nil
+	}
+
+	configs := make(map[string]*sinkConfig)
+	log.Debugf("loading sinks")
+	err := config.Config.UnmarshalKey("operator.sink.sinks", &configs)
+	if err != nil {
+		log.Warnf("failed to load operator.sink.sinks: %v", err)
+	}
+	for k, v := range configs {
+		if err := v.validate(); err != nil {
+			return fmt.Errorf("sink %q: %w", k, err)
+		}
+		s.sinks[k] = v
+		log.Debugf("> sink %q of type %q loaded", k, v.Type)
+	}
+	return nil
+}
+
+func (s *sinkOperator) GlobalParams() api.Params {
+	return nil
+}
+
+func (s *sinkOperator) InstanceParams() api.Params {
+	return api.Params{
+		{
+			Key:          ParamSink,
+			Title:        "Sink",
+			Description:  "Sinks from the configuration file to publish the events of the data sources to, like 'sink' or 'datasource1:sink1,datasource2:sink2'",
+			DefaultValue: "",
+			TypeHint:     api.TypeString,
+			Tags:         []string{api.TagGroupDataCollection},
+		},
+		{
+			Key:          ParamSinkTopic,
+			Title:        "Sink Topic",
+			Description:  "Topic template overriding the one of the sink, like 'topic' or 'datasource1:topic1,datasource2:topic2'",
+			DefaultValue: "",
+			TypeHint:     api.TypeString,
+			Tags:         []string{api.TagGroupDataCollection},
+		},
+	}
+}
+
+func (s *sinkOperator) InstantiateDataOperator(gadgetCtx operators.GadgetContext, instanceParamValues api.ParamValues) (operators.DataOperatorInstance, error) {
+	mappings, err := apihelpers.GetStringValuesPerDataSource(instanceParamValues[ParamSink])
+	if err != nil {
+		return nil, fmt.Errorf("parsing sink mappings: %w", err)
+	}
+	if len(mappings) == 0 {
+		return nil, nil
+	}
+	topics, err := apihelpers.GetStringValuesPerDataSource(instanceParamValues[ParamSinkTopic])
+	if err != nil {
+		return nil, fmt.Errorf("parsing sink topics: %w", err)
+	}
+	for _, sinkName := range mappings {
+		if _, ok := s.sinks[sinkName]; !ok {
+			return nil, fmt.Errorf("sink not found: %q", sinkName)
+		}
+	}
+
+	return &sinkOperatorInstance{
+		o:        s,
+		mappings: mappings,
+		topics:   topics,
+		outputs:  make(map[string]*output),
+	}, nil
+}
+
+func (s *sinkOperator) Priority() int {
+	return Priority
+}
+
+type sinkOperatorInstance struct {
+	o        *sinkOperator
+	mappings map[string]string
+	topics   map[string]string
+
+	// outputs contains an output per sink used by the instance
+	outputs map[string]*output
+}
+
+func (s *sinkOperatorInstance) Name() string {
+	return name
+}
+
+// lookup returns the value for a data source of a per data source parameter
+func lookup(m map[string]string, dsName string) (string, bool) {
+	if v, ok := m[dsName]; ok {
+		return v, true
+	}
+	v, ok := m[""]
+	return v, ok
+}
+
+// serializer returns the function to serialize the packets of a data source
+func serializer(ds datasource.DataSource, format string) (func(datasource.Packet) ([]byte, error), string, error) {
+	if format == FormatProtobuf {
+		return func(packet datasource.Packet) ([]byte, error) {
+			return proto.Marshal(packet.Raw())
+		}, "application/x-protobuf", nil
+	}
+
+	formatter, err := jsonformatter.New(ds, jsonformatter.WithShowAll(true))
+	if err != nil {
+		return nil, "", fmt.Errorf("creating json formatter: %w", err)
+	}
+	// The formatter reuses its buffer, messages are published asynchronously
+	return func(packet datasource.Packet) ([]byte, error) {
+		switch p := packet.(type) {
+		case datasource.PacketSingle:
+			return bytes.Clone(formatter.Marshal(p)), nil
+		case datasource.PacketArray:
+			return bytes.Clone(formatter.MarshalArray(p)), nil
+		}
+		return nil, fmt.Errorf("unsupported packet type %T", packet)
+	}, "application/json", nil
+}
+
+func (s *sinkOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
+	logger := gadgetCtx.Logger()
+	node := common.NodeName()
+
+	for _, ds := range gadgetCtx.GetDataSources() {
+		sinkName, ok := lookup(s.mappings, ds.Name())
+		if !ok {
+			continue
+		}
+		cfg := s.o.sinks[sinkName]
+
+		topic, ok := lookup(s.topics, ds.Name())
+		if !ok {
+			topic = cfg.Topic
+		}
+		topic, err := renderTopic(topic, &topicVars{
+			DataSource: ds.Name(),
+			Gadget:     gadgetCtx.ImageName(),
+			GadgetName: common.GadgetShortName(gadgetCtx.ImageName()),
+			Instance:   gadgetCtx.Name(),
+			InstanceID: gadgetCtx.ID(),
+			Node:       node,
+		})
+		if err != nil {
+			return fmt.Errorf("data source %q: %w", ds.Name(), err)
+		}
+
+		serialize, contentType, err := serializer(ds, cfg.Format)
+		if err != nil {
+			return fmt.Errorf("data source %q: %w", ds.Name(), err)
+		}
+
+		out, ok := s.outputs[sinkName]
+		if !ok {
+			pub, err := newPublisher(cfg)
+			if err != nil {
+				return fmt.Errorf("creating sink %q: %w", sinkName, err)
+			}
+			out = newOutput(pub, cfg, logger)
+			s.outputs[sinkName] = out
+		}
+
+		headers := map[string]string{
+			HeaderDataSource:  ds.Name(),
+			HeaderGadget:      gadgetCtx.ImageName(),
+			HeaderInstance:    gadgetCtx.ID(),
+			HeaderNode:        node,
+			HeaderContentType: contentType,
+		}
+
+		logger.Debugf("sink: publishing %q to sink %q with topic %q", ds.Name(), sinkName, topic)
+		ds.SubscribePacket(func(ds datasource.DataSource, packet datasource.Packet) error {
+			value, err := serialize(packet)
+			if err != nil {
+				logger.Warnf("sink: serializing packet of data source %q: %v", ds.Name(), err)
+				return nil
+			}
+			out.Enqueue(&message{topic: topic, value: value, headers: headers})
+			return nil
+		}, Priority)
+	}
+	return nil
+}
+
+func (s *sinkOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func (s *sinkOperatorInstance) Stop(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func (s *sinkOperatorInstance) Close(gadgetCtx operators.GadgetContext) error {
+	var errs []error
+	for sinkName, out := range s.outputs {
+		if err := out.close(); err != nil {
+			errs = append(errs, fmt.Errorf("closing sink %q: %w", sinkName, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+var Operator = &sinkOperator{}
+
+func init() {
+	operators.RegisterDataOperator(Operator)
+}
@@ -0,0 +1,248 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/testing/gadget-context"
+)
+
+// fakePublisher records the published batches. The first failures calls to
+// Publish fail.
+type fakePublisher struct {
+	mu       sync.Mutex
+	batches  [][]*message
+	failures int
+	attempts int
+	closed   bool
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, msgs []*message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.attempts++
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("broker not available")
+	}
+	p.batches = append(p.batches, append([]*message(nil), msgs...))
+	return nil
+}
+
+func (p *fakePublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	return nil
+}
+
+func (p *fakePublisher) messages() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var ret []string
+	for _, batch := range p.batches {
+		for _, msg := range batch {
+			ret = append(ret, string(msg.value))
+		}
+	}
+	return ret
+}
+
+func testConfig(t *testing.T, cfg *sinkConfig) *sinkConfig {
+	t.Helper()
+	if cfg.Type == "" {
+		cfg.Type = TypeKafka
+	}
+	cfg.RetryBackoff = time.Millisecond
+	require.NoError(t, cfg.validate())
+	return cfg
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := &sinkConfig{Type: TypeNATS}
+	require.NoError(t, cfg.validate())
+	assert.Equal(t, FormatJSON, cfg.Format)
+	assert.Equal(t, BackpressureBlock, cfg.Backpressure)
+	assert.Equal(t, DefaultTopic, cfg.Topic)
+	assert.Equal(t, 100, cfg.BatchSize)
+	assert.Equal(t, time.Second, cfg.BatchTimeout)
+	assert.Equal(t, DefaultMaxRetries, *cfg.MaxRetries)
+
+	noRetries := 0
+	cfg = &sinkConfig{Type: TypeKafka, MaxRetries: &noRetries}
+	require.NoError(t, cfg.validate())
+	assert.Equal(t, 0, *cfg.MaxRetries)
+
+	invalidRetries := -1
+
+	for _, cfg := range []*sinkConfig{
+		{Type: "redis"},
+		{Type: TypeKafka, Format: "xml"},
+		{Type: TypeKafka, Backpressure: "spill"},
+		{Type: TypeKafka, Topic: "{{.DataSource"},
+		{Type: TypeKafka, MaxRetries: &invalidRetries},
+	} {
+		require.Error(t, cfg.validate(), "%+v", cfg)
+	}
+}
+
+func TestRenderTopic(t *testing.T) {
+	vars := &topicVars{
+		DataSource: "exec",
+		Gadget:     "ghcr.io/inspektor-gadget/gadget/trace_exec:latest",
+		GadgetName: "trace_exec",
+		Instance:   "prod-exec",
+		Node:       "node1",
+	}
+
+	topic, err := renderTopic(DefaultTopic, vars)
+	require.NoError(t, err)
+	assert.Equal(t, "ig.trace_exec.exec", topic)
+
+	topic, err = renderTopic("security.{{.Node}}.{{.Instance}}", vars)
+	require.NoError(t, err)
+	assert.Equal(t, "security.node1.prod-exec", topic)
+
+	_, err = renderTopic("{{.Cluster}}", vars)
+	require.Error(t, err)
+	_, err = renderTopic("{{if false}}x{{end}}", vars)
+	require.Error(t, err)
+}
+
+func TestOutputMaxRetries(t *testing.T) {
+	// The server never comes back: with the default number of retries, the
+	// message is dropped instead of blocking the data source forever
+	pub := &fakePublisher{failures: 1 << 30}
+	out := newOutput(pub, testConfig(t, &sinkConfig{BatchSize: 1}), logger.DefaultLogger())
+
+	out.Enqueue(&message{value: []byte("1")})
+	require.NoError(t, out.close())
+	assert.Empty(t, pub.messages())
+	assert.Equal(t, DefaultMaxRetries+1, pub.attempts)
+	assert.Equal(t, uint64(1), out.Dropped())
+	assert.True(t, pub.closed)
+}
+
+func TestSinkOperator(t *testing.T) {
+	for _, tc := range []struct {
+		format      string
+		contentType string
+		check       func(t *testing.T, value []byte)
+	}{
+		{
+			format:      FormatJSON,
+			contentType: "application/json",
+			check: func(t *testing.T, value []byte) {
+				assert.JSONEq(t, `{"comm":"nc","pid":42}`, string(value))
+			},
+		},
+		{
+			format:      FormatProtobuf,
+			contentType: "application/x-protobuf",
+			check: func(t *testing.T, value []byte) {
+				var gd api.GadgetData
+				require.NoError(t, proto.Unmarshal(value, &gd))
+				require.NotNil(t, gd.Data)
+				assert.Equal(t, []byte("nc"), gd.Data.Payload[0])
+			},
+		},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			ds, err := datasource.New(datasource.TypeSingle, "exec")
+			require.NoError(t, err)
+			commField, err := ds.AddField("comm", api.Kind_String)
+			require.NoError(t, err)
+			pidField, err := ds.AddField("pid", api.Kind_Uint32)
+			require.NoError(t, err)
+
+			// Data sources not mapped to a sink aren't published
+			otherDs, err := datasource.New(datasource.TypeSingle, "other")
+			require.NoError(t, err)
+			otherField, err := otherDs.AddField("value", api.Kind_String)
+			require.NoError(t, err)
+
+			op := &sinkOperator{
+				sinks: map[string]*sinkConfig{
+					"lake": testConfig(t, &sinkConfig{Format: tc.format, BatchSize: 1}),
+				},
+			}
+			gadgetCtx := &gadgetcontext.MockGadgetContext{
+				Ctx: context.Background(),
+				DataSources: map[string]datasource.DataSource{
+					"exec":  ds,
+					"other": otherDs,
+				},
+			}
+
+			_, err = op.InstantiateDataOperator(gadgetCtx, api.ParamValues{ParamSink: "exec:unknown"})
+			require.ErrorContains(t, err, "sink not found")
+
+			inst, err := op.InstantiateDataOperator(gadgetCtx, api.ParamValues{
+				ParamSink:      "exec:lake",
+				ParamSinkTopic: "exec:security.{{.DataSource}}.{{.InstanceID}}",
+			})
+			require.NoError(t, err)
+			sinkInst := inst.(*sinkOperatorInstance)
+
+			pub := &fakePublisher{}
+			sinkInst.outputs["lake"] = newOutput(pub, op.sinks["lake"], logger.DefaultLogger())
+
+			require.NoError(t, sinkInst.PreStart(gadgetCtx))
+
+			packet, err := ds.NewPacketSingle()
+			require.NoError(t, err)
+			require.NoError(t, commField.PutString(packet, "nc"))
+			require.NoError(t, pidField.PutUint32(packet, 42))
+			require.NoError(t, ds.EmitAndRelease(packet))
+
+			otherPacket, err := otherDs.NewPacketSingle()
+			require.NoError(t, err)
+			require.NoError(t, otherField.PutString(otherPacket, "ignored"))
+			require.NoError(t, otherDs.EmitAndRelease(otherPacket))
+
+			require.NoError(t, sinkInst.Close(gadgetCtx))
+
+			require.Len(t, pub.batches, 1)
+			require.Len(t, pub.batches[0], 1)
+			msg := pub.batches[0][0]
+			assert.Equal(t, "security.exec.test-id", msg.topic)
+			assert.Equal(t, "exec", msg.headers[HeaderDataSource])
+			assert.Equal(t, "test-image", msg.headers[HeaderGadget])
+			assert.Equal(t, tc.contentType, msg.headers[HeaderContentType])
+			tc.check(t, msg.value)
+		})
+	}
+}
+
+func TestSinkOperatorNoMapping(t *testing.T) {
+	op := &sinkOperator{sinks: map[string]*sinkConfig{}}
+	inst, err := op.InstantiateDataOperator(&gadgetcontext.MockGadgetContext{}, api.ParamValues{})
+	require.NoError(t, err)
+	require.Nil(t, inst)
+}
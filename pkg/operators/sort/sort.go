@@ -16,13 +16,12 @@ package sort
 
 import (
 	"fmt"
-	"slices"
-	"sort"
 	"strings"
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/sort/sortfunc"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
 )
 
@@ -34,15 +33,6 @@ const (
 
 type sortOperator struct{}
 
-type arrSort struct {
-	datasource.DataArray
-	fn func(i, j datasource.Data) bool
-}
-
-func (s *arrSort) Less(i, j int) bool {
-	return s.fn(s.Get(i), s.Get(j))
-}
-
 func (s *sortOperator) Name() string {
 	return name
 }
@@ -95,85 +85,13 @@ func (s *sortOperator) Priority() int {
 	return Priority
 }
 
-type sortOperatorInstance struct {
-	sortBy  string
-	sorters map[datasource.DataSource][]func(i, j datasource.Data) bool
+func (s *sortOperator) SupportsReplay() bool {
+	return true
 }
 
-func getCompareFunc(f datasource.FieldAccessor, negate bool) func(i, j datasource.Data) bool {
-	switch f.Type() {
-	case api.Kind_Int8:
-		return func(i, j datasource.Data) bool {
-			v1, _ := f.Int8(i)
-			v2, _ := f.Int8(j)
-			return (v1 < v2) != negate
-		}
-	case api.Kind_Int16:
-		return func(i, j datasource.Data) bool {
-			v1, _ := f.Int16(i)
-			v2, _ := f.Int16(j)
-			return (v1 < v2) != negate
-		}
-	case api.Kind_Int32:
-		return func(i, j datasource.Data) bool {
-			v1, _ := f.Int32(i)
-			v2, _ := f.Int32(j)
-			return (v1 < v2) != negate
-		}
-	case api.Kind_Int64:
-		return func(i, j datasource.Data) bool {
-			v1, _ := f.Int64(i)
-			v2, _ := f.Int64(j)
-			return (v1 < v2) != negate
-		}
-	case api.Kind_Uint8:
-		return func(i, j datasource.Data) bool {
-			v1, _ := f.Uint8(i)
-			v2, _ := f.Uint8(j)
-			return (v1 < v2) != negate
-		}
-	case api.Kind_Uint16:
-		return func(i, j datasource.Data) bool {
-			v1, _ := f.Uint16(i)
-			v2, _ := f.Uint16(j)
-			return (v1 < v2) != negate
-		}
-	case api.Kind_Uint32:
-		return func(i, j datasource.Data) bool {
-			v1, _ := f.Uint32(i)
-			v2, _ := f.Uint32(j)
-			return (v1 < v2) != negate
-		}
-	case api.Kind_Uint64:
-		return func(i, j datasource.Data) bool {
-			v1, _ := f.Uint64(i)
-			v2, _ := f.Uint64(j)
-			return (v1 < v2) != negate
-		}
-	case api.Kind_Float32:
-		return func(i, j datasource.Data) bool {
-			v1, _ := f.Float32(i)
-			v2, _ := f.Float32(j)
-			return (v1 < v2) != negate
-		}
-	case api.Kind_Float64:
-		return func(i, j datasource.Data) bool {
-			v1, _ := f.Float64(i)
-			v2, _ := f.Float64(j)
-			return (v1 < v2) != negate
-		}
-	case api.Kind_String, api.Kind_CString:
-		return func(i, j datasource.Data) bool {
-			v1, _ := f.String(i)
-			v2, _ := f.String(j)
-			if strings.Compare(v1, v2) < 0 {
-				return !negate
-			}
-			return negate
-		}
-	default:
-		return nil
-	}
+type sortOperatorInstance struct {
+	sortBy  string
+	sorters map[datasource.DataSource]func(datasource.DataArray)
 }
 
 func (s *sortOperatorInstance) getFieldsByDs() map[string][]string {
@@ -195,7 +113,7 @@ func (s *sortOperatorInstance) getFieldsByDs() map[string][]string {
 }
 
 func (s *sortOperatorInstance) init(gadgetCtx operators.GadgetContext) error {
-	s.sorters = make(map[datasource.DataSource][]func(i datasource.Data, j datasource.Data) bool)
+	s.sorters = make(map[datasource.DataSource]func(datasource.DataArray))
 	dsSorts := s.getFieldsByDs()
 
 	// Check edge cases
@@ -221,24 +139,11 @@ func (s *sortOperatorInstance) init(gadgetCtx operators.GadgetContext) error {
 			return fmt.Errorf("sort can only be used on array data sources")
 		}
 
-		var sortFuncs []func(i, j datasource.Data) bool
-		for _, fieldName := range sortFields {
-			fieldName, negate := strings.CutPrefix(fieldName, "-")
-
-			field := ds.GetField(fieldName)
-			if field == nil {
-				return fmt.Errorf("field %s not found", fieldName)
-			}
-
-			cmp := getCompareFunc(field, negate)
-			if cmp == nil {
-				return fmt.Errorf("field %s cannot be used for sorting", fieldName)
-			}
-			sortFuncs = append(sortFuncs, cmp)
+		sortFn, err := sortfunc.New(ds, sortFields)
+		if err != nil {
+			return err
 		}
-
-		slices.Reverse(sortFuncs)
-		s.sorters[ds] = sortFuncs
+		s.sorters[ds] = sortFn
 	}
 	return nil
 }
@@ -252,11 +157,9 @@ func (s *sortOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error
 	if err != nil {
 		return err
 	}
-	for ds, fns := range s.sorters {
+	for ds, sortFn := range s.sorters {
 		ds.SubscribeArray(func(ds datasource.DataSource, data datasource.DataArray) error {
-			for _, s := range fns {
-				sort.Stable(&arrSort{DataArray: data, fn: s})
-			}
+			sortFn(data)
 			return nil
 		}, Priority)
 	}
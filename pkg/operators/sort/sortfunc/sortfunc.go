@@ -0,0 +1,139 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sortfunc sorts data arrays by fields, using the syntax of the sort operator. It doesn't register any
+// operator, so it can be used by operators that sort data on demand, like the TUI of the cli operator.
+package sortfunc
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+type arrSort struct {
+	datasource.DataArray
+	fn func(i, j datasource.Data) bool
+}
+
+func (s *arrSort) Less(i, j int) bool {
+	return s.fn(s.Get(i), s.Get(j))
+}
+
+func getCompareFunc(f datasource.FieldAccessor, negate bool) func(i, j datasource.Data) bool {
+	switch f.Type() {
+	case api.Kind_Int8:
+		return func(i, j datasource.Data) bool {
+			v1, _ := f.Int8(i)
+			v2, _ := f.Int8(j)
+			return (v1 < v2) != negate
+		}
+	case api.Kind_Int16:
+		return func(i, j datasource.Data) bool {
+			v1, _ := f.Int16(i)
+			v2, _ := f.Int16(j)
+			return (v1 < v2) != negate
+		}
+	case api.Kind_Int32:
+		return func(i, j datasource.Data) bool {
+			v1, _ := f.Int32(i)
+			v2, _ := f.Int32(j)
+			return (v1 < v2) != negate
+		}
+	case api.Kind_Int64:
+		return func(i, j datasource.Data) bool {
+			v1, _ := f.Int64(i)
+			v2, _ := f.Int64(j)
+			return (v1 < v2) != negate
+		}
+	case api.Kind_Uint8:
+		return func(i, j datasource.Data) bool {
+			v1, _ := f.Uint8(i)
+			v2, _ := f.Uint8(j)
+			return (v1 < v2) != negate
+		}
+	case api.Kind_Uint16:
+		return func(i, j datasource.Data) bool {
+			v1, _ := f.Uint16(i)
+			v2, _ := f.Uint16(j)
+			return (v1 < v2) != negate
+		}
+	case api.Kind_Uint32:
+		return func(i, j datasource.Data) bool {
+			v1, _ := f.Uint32(i)
+			v2, _ := f.Uint32(j)
+			return (v1 < v2) != negate
+		}
+	case api.Kind_Uint64:
+		return func(i, j datasource.Data) bool {
+			v1, _ := f.Uint64(i)
+			v2, _ := f.Uint64(j)
+			return (v1 < v2) != negate
+		}
+	case api.Kind_Float32:
+		return func(i, j datasource.Data) bool {
+			v1, _ := f.Float32(i)
+			v2, _ := f.Float32(j)
+			return (v1 < v2) != negate
+		}
+	case api.Kind_Float64:
+		return func(i, j datasource.Data) bool {
+			v1, _ := f.Float64(i)
+			v2, _ := f.Float64(j)
+			return (v1 < v2) != negate
+		}
+	case api.Kind_String, api.Kind_CString:
+		return func(i, j datasource.Data) bool {
+			v1, _ := f.String(i)
+			v2, _ := f.String(j)
+			if strings.Compare(v1, v2) < 0 {
+				return !negate
+			}
+			return negate
+		}
+	default:
+		return nil
+	}
+}
+
+// New returns a function that sorts a DataArray of ds by the given fields; fields prefixed with '-' are sorted in
+// descending order.
+func New(ds datasource.DataSource, sortFields []string) (func(datasource.DataArray), error) {
+	var sortFuncs []func(i, j datasource.Data) bool
+	for _, fieldName := range sortFields {
+		fieldName, negate := strings.CutPrefix(fieldName, "-")
+
+		field := ds.GetField(fieldName)
+		if field == nil {
+			return nil, fmt.Errorf("field %s not found", fieldName)
+		}
+
+		cmp := getCompareFunc(field, negate)
+		if cmp == nil {
+			return nil, fmt.Errorf("field %s cannot be used for sorting", fieldName)
+		}
+		sortFuncs = append(sortFuncs, cmp)
+	}
+
+	slices.Reverse(sortFuncs)
+	return func(data datasource.DataArray) {
+		for _, fn := range sortFuncs {
+			sort.Stable(&arrSort{DataArray: data, fn: fn})
+		}
+	}, nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package datasource provides helpers to create data sources in operator tests
+package datasource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+// RegisterFunc creates a data source; the RegisterDataSource method of a gadget context can be used as RegisterFunc
+type RegisterFunc func(typ datasource.Type, name string) (datasource.DataSource, error)
+
+// Unregistered is a RegisterFunc that creates data sources that don't belong to any gadget context
+func Unregistered(typ datasource.Type, name string) (datasource.DataSource, error) {
+	return datasource.New(typ, name)
+}
+
+// Field describes a field to add to a data source
+type Field struct {
+	Name string
+	Kind api.Kind
+}
+
+// New creates a single data source using register and adds the given fields to it. The accessors are returned in
+// the same order as the fields.
+func New(t testing.TB, register RegisterFunc, name string, fields ...Field) (datasource.DataSource, []datasource.FieldAccessor) {
+	ds, err := register(datasource.TypeSingle, name)
+	require.NoError(t, err)
+
+	accessors := make([]datasource.FieldAccessor, 0, len(fields))
+	for _, f := range fields {
+		acc, err := ds.AddField(f.Name, f.Kind)
+		require.NoError(t, err)
+		accessors = append(accessors, acc)
+	}
+	return ds, accessors
+}
@@ -84,6 +84,10 @@ func (m *MockGadgetContext) Params() []*api.Param {
 
 func (m *MockGadgetContext) SetParams(params []*api.Param) {}
 
+func (m *MockGadgetContext) ParamValues() api.ParamValues {
+	return nil
+}
+
 func (m *MockGadgetContext) SetMetadata(metadata []byte) error {
 	return nil
 }
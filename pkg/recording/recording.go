@@ -0,0 +1,192 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package recording implements a compact file format to persist the output of
+// a gadget run. A recording starts with the GadgetInfo of the run, followed by
+// all packets emitted by its data sources. Every entry is stored as an
+// api.GadgetEvent, using the same types and data source IDs that are used when
+// streaming events over gRPC, so recordings can be replayed through the
+// regular operator chain later on.
+//
+// The file consists of a short header (magic and version) followed by a gzip
+// compressed stream of length-prefixed (uvarint) protobuf messages.
+package recording
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+const (
+	Magic   = "IGREC"
+	Version = 1
+
+	// maxEventSize is the maximum size of a single event we accept when reading
+	// a recording; it protects against allocating huge buffers for corrupted
+	// files
+	maxEventSize = 64 * 1024 * 1024
+)
+
+var ErrInvalidRecording = errors.New("invalid recording")
+
+// Writer writes a recording. It is safe for concurrent use.
+type Writer struct {
+	mu     sync.Mutex
+	gz     *gzip.Writer
+	seq    uint32
+	lenBuf [binary.MaxVarintLen64]byte
+}
+
+// NewWriter writes the recording header to w and returns a Writer that can be
+// used to add events to it. WriteGadgetInfo has to be called before any
+// payload is written.
+func NewWriter(w io.Writer) (*Writer, error) {
+	if _, err := w.Write(append([]byte(Magic), Version)); err != nil {
+		return nil, fmt.Errorf("writing header: %w", err)
+	}
+	return &Writer{gz: gzip.NewWriter(w)}, nil
+}
+
+// WriteGadgetInfo stores the GadgetInfo of the recorded run. The IDs of its
+// data sources are used to reference them in WritePayload.
+func (w *Writer) WriteGadgetInfo(gi *api.GadgetInfo) error {
+	d, err := proto.Marshal(gi)
+	if err != nil {
+		return fmt.Errorf("marshaling gadget info: %w", err)
+	}
+	return w.writeEvent(&api.GadgetEvent{
+		Type:    api.EventTypeGadgetInfo,
+		Payload: d,
+	})
+}
+
+// WritePayload stores the raw (marshaled) packet of the data source with the
+// given ID.
+func (w *Writer) WritePayload(dsID uint32, payload []byte) error {
+	return w.writeEvent(&api.GadgetEvent{
+		Type:         api.EventTypeGadgetPayload,
+		Payload:      payload,
+		DataSourceID: dsID,
+	})
+}
+
+func (w *Writer) writeEvent(ev *api.GadgetEvent) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.seq++
+	ev.Seq = w.seq
+
+	d, err := proto.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("marshaling event: %w", err)
+	}
+	n := binary.PutUvarint(w.lenBuf[:], uint64(len(d)))
+	if _, err := w.gz.Write(w.lenBuf[:n]); err != nil {
+		return err
+	}
+	_, err = w.gz.Write(d)
+	return err
+}
+
+// Close flushes all pending data. It doesn't close the underlying writer.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.gz.Close()
+}
+
+// Reader reads a recording created by Writer.
+type Reader struct {
+	rd *bufio.Reader
+	gz *gzip.Reader
+}
+
+// NewReader verifies the header of the recording and returns a Reader for it.
+func NewReader(r io.Reader) (*Reader, error) {
+	hdr := make([]byte, len(Magic)+1)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return nil, fmt.Errorf("%w: reading header: %w", ErrInvalidRecording, err)
+	}
+	if string(hdr[:len(Magic)]) != Magic {
+		return nil, fmt.Errorf("%w: bad magic", ErrInvalidRecording)
+	}
+	if hdr[len(Magic)] != Version {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidRecording, hdr[len(Magic)])
+	}
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRecording, err)
+	}
+	return &Reader{
+		rd: bufio.NewReader(gz),
+		gz: gz,
+	}, nil
+}
+
+// Next returns the next event of the recording or io.EOF if there are no more
+// events.
+func (r *Reader) Next() (*api.GadgetEvent, error) {
+	size, err := binary.ReadUvarint(r.rd)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("%w: reading event size: %w", ErrInvalidRecording, err)
+	}
+	if size > maxEventSize {
+		return nil, fmt.Errorf("%w: event too large (%d bytes)", ErrInvalidRecording, size)
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r.rd, buf); err != nil {
+		return nil, fmt.Errorf("%w: reading event: %w", ErrInvalidRecording, err)
+	}
+	ev := &api.GadgetEvent{}
+	if err := proto.Unmarshal(buf, ev); err != nil {
+		return nil, fmt.Errorf("%w: unmarshaling event: %w", ErrInvalidRecording, err)
+	}
+	return ev, nil
+}
+
+// GadgetInfo reads the next event and expects it to be the GadgetInfo of the
+// recorded run; this is always the first event of a recording.
+func (r *Reader) GadgetInfo() (*api.GadgetInfo, error) {
+	ev, err := r.Next()
+	if err != nil {
+		return nil, err
+	}
+	if ev.Type != api.EventTypeGadgetInfo {
+		return nil, fmt.Errorf("%w: expected gadget info, got event type %d", ErrInvalidRecording, ev.Type)
+	}
+	gi := &api.GadgetInfo{}
+	if err := proto.Unmarshal(ev.Payload, gi); err != nil {
+		return nil, fmt.Errorf("%w: unmarshaling gadget info: %w", ErrInvalidRecording, err)
+	}
+	return gi, nil
+}
+
+// Close releases the resources of the reader. It doesn't close the underlying
+// reader.
+func (r *Reader) Close() error {
+	return r.gz.Close()
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recording
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+func TestRecording(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	w, err := NewWriter(buf)
+	require.NoError(t, err)
+
+	gi := &api.GadgetInfo{
+		ImageName: "trace_exec",
+		DataSources: []*api.DataSource{
+			{Id: 0, Name: "exec"},
+			{Id: 1, Name: "other"},
+		},
+	}
+	require.NoError(t, w.WriteGadgetInfo(gi))
+	require.NoError(t, w.WritePayload(0, []byte("foo")))
+	require.NoError(t, w.WritePayload(1, []byte("bar")))
+	require.NoError(t, w.Close())
+
+	r, err := NewReader(buf)
+	require.NoError(t, err)
+	defer r.Close()
+
+	readGi, err := r.GadgetInfo()
+	require.NoError(t, err)
+	require.Equal(t, gi.ImageName, readGi.ImageName)
+	require.Len(t, readGi.DataSources, 2)
+
+	ev, err := r.Next()
+	require.NoError(t, err)
+	require.Equal(t, api.EventTypeGadgetPayload, ev.Type)
+	require.Equal(t, uint32(0), ev.DataSourceID)
+	require.Equal(t, uint32(2), ev.Seq)
+	require.Equal(t, []byte("foo"), ev.Payload)
+
+	ev, err = r.Next()
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), ev.DataSourceID)
+	require.Equal(t, []byte("bar"), ev.Payload)
+
+	_, err = r.Next()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestInvalidRecording(t *testing.T) {
+	_, err := NewReader(bytes.NewReader([]byte("NOTAREC")))
+	require.ErrorIs(t, err, ErrInvalidRecording)
+
+	_, err = NewReader(bytes.NewReader(append([]byte(Magic), Version+1)))
+	require.ErrorIs(t, err, ErrInvalidRecording)
+
+	// Payload before gadget info
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(buf)
+	require.NoError(t, err)
+	require.NoError(t, w.WritePayload(0, []byte("foo")))
+	require.NoError(t, w.Close())
+
+	r, err := NewReader(buf)
+	require.NoError(t, err)
+	_, err = r.GadgetInfo()
+	require.ErrorIs(t, err, ErrInvalidRecording)
+}
@@ -51,15 +51,16 @@ const (
 )
 
 const (
-	ParamNode              = "node"
-	ParamRemoteAddress     = "remote-address"
-	ParamConnectionMethod  = "connection-method"
-	ParamConnectionTimeout = "connection-timeout"
-	ParamID                = "id"
-	ParamDetach            = "detach"
-	ParamTags              = "tags"
-	ParamName              = "name"
-	ParamEventBufferLength = "event-buffer-length"
+	ParamNode                 = "node"
+	ParamRemoteAddress        = "remote-address"
+	ParamConnectionMethod     = "connection-method"
+	ParamConnectionTimeout    = "connection-timeout"
+	ParamID                   = "id"
+	ParamDetach               = "detach"
+	ParamTags                 = "tags"
+	ParamName                 = "name"
+	ParamEventBufferLength    = "event-buffer-length"
+	ParamServerSideProjection = "server-side-projection"
 
 	ParamTLSKey        = "tls-key-file"
 	ParamTLSCert       = "tls-cert-file"
@@ -177,6 +178,13 @@ func (r *Runtime) ParamDescs() params.ParamDescs {
 			DefaultValue: "0",
 			Tags:         []string{"!attach"},
 		},
+		{
+			Key:          ParamServerSideProjection,
+			Description:  "Only transfer fields that are needed for the requested output from the nodes",
+			TypeHint:     params.TypeBool,
+			DefaultValue: "true",
+			Tags:         []string{"!attach"},
+		},
 	}...)
 	switch r.connectionMode {
 	case ConnectionModeDirect:
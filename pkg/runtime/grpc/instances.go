@@ -38,7 +38,7 @@ func (r *Runtime) RemoveGadgetInstance(ctx context.Context, runtimeParams *param
 	return r.runInstanceManagerClientForTargets(ctx, runtimeParams, false, func(target target, client api.GadgetInstanceManagerClient) error {
 		res, err := client.RemoveGadgetInstance(ctx, &api.GadgetInstanceId{Id: id})
 		if err != nil {
-			return err
+			return convertError(err)
 		}
 		if res.Result != 0 {
 			return errors.New(res.Message)
@@ -52,7 +52,7 @@ func (r *Runtime) GetGadgetInstances(ctx context.Context, runtimeParams *params.
 	err = r.runInstanceManagerClientForTargets(ctx, runtimeParams, true, func(target target, client api.GadgetInstanceManagerClient) error {
 		res, err := client.ListGadgetInstances(ctx, &api.ListGadgetInstancesRequest{})
 		if err != nil {
-			return err
+			return convertError(err)
 		}
 
 		// Merge results
@@ -86,7 +86,7 @@ func (r *Runtime) GetNodeInstanceStates(ctx context.Context, runtimeParams *para
 	err := r.runInstanceManagerClientForTargets(ctx, runtimeParams, true, func(target target, client api.GadgetInstanceManagerClient) error {
 		res, err := client.ListGadgetInstances(ctx, &api.ListGadgetInstancesRequest{})
 		if err != nil {
-			return err
+			return convertError(err)
 		}
 
 		mu.Lock()
@@ -204,7 +204,7 @@ func (r *Runtime) createGadgetInstance(gadgetCtx runtime.GadgetContext, runtimeP
 		gadgetCtx.Logger().Debugf("creating gadget on node %q", target.node)
 		res, err := client.CreateGadgetInstance(gadgetCtx.Context(), instanceRequest)
 		if err != nil {
-			return fmt.Errorf("creating gadget on node %q: %w", target.node, err)
+			return fmt.Errorf("creating gadget on node %q: %w", target.node, convertError(err))
 		}
 		listMutex.Lock()
 		nodeList = append(nodeList, target.node)
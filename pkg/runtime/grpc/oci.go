@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"sync"
 	"time"
 
@@ -57,7 +58,7 @@ func (r *Runtime) GetGadgetInfo(gadgetCtx runtime.GadgetContext, runtimeParams *
 
 	out, err := client.GetGadgetInfo(gadgetCtx.Context(), in)
 	if err != nil {
-		return nil, fmt.Errorf("getting gadget info: %w", err)
+		return nil, fmt.Errorf("getting gadget info: %w", convertError(err))
 	}
 	extraInfo := &api.ExtraInfo{}
 
@@ -94,6 +95,11 @@ func (r *Runtime) RunGadget(gadgetCtx runtime.GadgetContext, runtimeParams *para
 
 	gadgetCtx.SetVar(runtime.NumRunTargets, len(targets))
 
+	if p := runtimeParams.Get(ParamServerSideProjection); p != nil && p.AsBool() {
+		paramValues = maps.Clone(paramValues)
+		paramValues[api.ParamServerSideProjection] = "true"
+	}
+
 	_, err = r.runGadgetOnTargets(gadgetCtx, paramValues, targets)
 	return err
 }
@@ -297,7 +303,7 @@ func (r *Runtime) runGadget(gadgetCtx runtime.GadgetContext, target target, allP
 			}
 		}
 	}
-	return result, runErr
+	return result, convertError(runErr)
 }
 
 func (r *Runtime) IsClient() bool {
@@ -23,7 +23,17 @@ import (
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/utils/host"
 )
 
-type Runtime struct{}
+const (
+	// ParamReplay is the path to a recording created by the record operator;
+	// if set, the recorded events are replayed instead of running the gadget
+	ParamReplay = "replay"
+)
+
+type Runtime struct {
+	// replayOnly is set when the runtime was initialized with InitForReplay;
+	// it can then only replay recordings
+	replayOnly bool
+}
 
 func New() *Runtime {
 	return &Runtime{}
@@ -42,6 +52,13 @@ func (r *Runtime) Init(globalRuntimeParams *params.Params) error {
 	return nil
 }
 
+// InitForReplay initializes the runtime to only replay recordings, which
+// doesn't need any privileges. It's used instead of Init.
+func (r *Runtime) InitForReplay(globalRuntimeParams *params.Params) error {
+	r.replayOnly = true
+	return nil
+}
+
 func (r *Runtime) Close() error {
 	return nil
 }
@@ -51,7 +68,13 @@ func (r *Runtime) GlobalParamDescs() params.ParamDescs {
 }
 
 func (r *Runtime) ParamDescs() params.ParamDescs {
-	return nil
+	return params.ParamDescs{
+		{
+			Key:         ParamReplay,
+			Description: "Replay the events of a recording (see --record) instead of running the gadget",
+			TypeHint:    params.TypeString,
+		},
+	}
 }
 
 func (r *Runtime) SetDefaultValue(key params.ValueHint, value string) {
@@ -15,6 +15,7 @@
 package local
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
@@ -22,7 +23,16 @@ import (
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/runtime"
 )
 
+var errReplayOnly = errors.New("runtime was initialized to replay recordings only")
+
 func (r *Runtime) GetGadgetInfo(gadgetCtx runtime.GadgetContext, runtimeParams *params.Params, paramValues api.ParamValues) (*api.GadgetInfo, error) {
+	if replayFile := replayFileFromParams(runtimeParams); replayFile != "" {
+		return r.getReplayGadgetInfo(gadgetCtx, replayFile, paramValues)
+	}
+	if r.replayOnly {
+		return nil, errReplayOnly
+	}
+
 	err := gadgetCtx.PrepareGadgetInfo(paramValues)
 	if err != nil {
 		return nil, fmt.Errorf("initializing and preparing operators: %w", err)
@@ -31,6 +41,12 @@ func (r *Runtime) GetGadgetInfo(gadgetCtx runtime.GadgetContext, runtimeParams *
 }
 
 func (r *Runtime) RunGadget(gadgetCtx runtime.GadgetContext, runtimeParams *params.Params, paramValues api.ParamValues) error {
+	if replayFile := replayFileFromParams(runtimeParams); replayFile != "" {
+		return r.replayGadget(gadgetCtx, replayFile, paramValues)
+	}
+	if r.replayOnly {
+		return errReplayOnly
+	}
 	return gadgetCtx.Run(paramValues)
 }
 
@@ -0,0 +1,146 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/recording"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/runtime"
+)
+
+func replayFileFromParams(runtimeParams *params.Params) string {
+	if runtimeParams == nil {
+		return ""
+	}
+	if p := runtimeParams.Get(ParamReplay); p != nil {
+		return p.AsString()
+	}
+	return ""
+}
+
+func openRecording(fileName string) (*os.File, *recording.Reader, *api.GadgetInfo, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("opening recording: %w", err)
+	}
+	rd, err := recording.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, nil, fmt.Errorf("reading recording %q: %w", fileName, err)
+	}
+	gi, err := rd.GadgetInfo()
+	if err != nil {
+		rd.Close()
+		f.Close()
+		return nil, nil, nil, fmt.Errorf("reading recording %q: %w", fileName, err)
+	}
+	return f, rd, gi, nil
+}
+
+// getReplayGadgetInfo loads the gadget info stored in the recording instead of
+// fetching the gadget image
+func (r *Runtime) getReplayGadgetInfo(gadgetCtx runtime.GadgetContext, fileName string, paramValues api.ParamValues) (*api.GadgetInfo, error) {
+	f, rd, gi, err := openRecording(fileName)
+	if err != nil {
+		return nil, err
+	}
+	rd.Close()
+	f.Close()
+
+	err = gadgetCtx.LoadGadgetInfo(gi, paramValues, false, nil)
+	if err != nil {
+		return nil, fmt.Errorf("initializing local operators: %w", err)
+	}
+	return gadgetCtx.SerializeGadgetInfo(gadgetCtx.ExtraInfo())
+}
+
+// replayGadget emits all packets of a recording through the operators of
+// gadgetCtx, analogous to what the gRPC runtime does with packets received from
+// a remote node. No eBPF programs are loaded, so gadgetCtx should only contain
+// operators that work on the data of the data sources.
+func (r *Runtime) replayGadget(gadgetCtx runtime.GadgetContext, fileName string, paramValues api.ParamValues) error {
+	f, rd, gi, err := openRecording(fileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	defer rd.Close()
+
+	err = gadgetCtx.LoadGadgetInfo(gi, paramValues, true, nil)
+	if err != nil {
+		return fmt.Errorf("initializing local operators: %w", err)
+	}
+	defer gadgetCtx.StopLocalOperators()
+
+	dsNameMap := make(map[string]uint32)
+	for _, ds := range gi.DataSources {
+		dsNameMap[ds.Name] = ds.Id
+	}
+	dsMap := make(map[uint32]datasource.DataSource)
+	for _, ds := range gadgetCtx.GetAllDataSources() {
+		if dsID, ok := dsNameMap[ds.Name()]; ok {
+			dsMap[dsID] = ds
+		}
+	}
+
+	log := gadgetCtx.Logger()
+	log.Debugf("replaying %q", fileName)
+	for {
+		select {
+		case <-gadgetCtx.Context().Done():
+			return nil
+		default:
+		}
+
+		ev, err := rd.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("reading recording %q: %w", fileName, err)
+		}
+		if ev.Type != api.EventTypeGadgetPayload {
+			log.Debugf("skipping event of type %d", ev.Type)
+			continue
+		}
+
+		ds, ok := dsMap[ev.DataSourceID]
+		if !ok {
+			continue
+		}
+		var p datasource.Packet
+		switch ds.Type() {
+		case datasource.TypeSingle:
+			p, err = ds.NewPacketSingleFromRaw(ev.Payload)
+		case datasource.TypeArray:
+			p, err = ds.NewPacketArrayFromRaw(ev.Payload)
+		default:
+			log.Warnf("unknown datasource type %d", ds.Type())
+			continue
+		}
+		if err != nil {
+			log.Debugf("error unmarshaling payload: %v", err)
+			continue
+		}
+		ds.EmitAndRelease(p)
+	}
+}
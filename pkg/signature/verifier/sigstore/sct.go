@@ -0,0 +1,233 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sigstore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/cryptobyte"
+)
+
+// oidSCTList is the OID of the extension embedding the signed certificate
+// timestamps in a certificate, RFC 6962 section 3.3.
+var oidSCTList = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+
+const (
+	sctVersionV1           = 0
+	sctSignatureTypeCert   = 0
+	sctEntryTypePrecert    = 1
+	sctHashAlgorithmSHA256 = 4
+)
+
+type signedCertificateTimestamp struct {
+	logID      []byte
+	timestamp  uint64
+	extensions []byte
+	signature  []byte
+}
+
+// parseSCTList parses the content of the SCT list extension, i.e. an octet
+// string containing the TLS encoded SignedCertificateTimestampList.
+func parseSCTList(value []byte) ([]*signedCertificateTimestamp, error) {
+	var list []byte
+	if rest, err := asn1.Unmarshal(value, &list); err != nil {
+		return nil, fmt.Errorf("decoding extension: %w", err)
+	} else if len(rest) != 0 {
+		return nil, errors.New("trailing data after extension")
+	}
+
+	var scts cryptobyte.String
+	input := cryptobyte.String(list)
+	if !input.ReadUint16LengthPrefixed(&scts) || !input.Empty() {
+		return nil, errors.New("malformed SCT list")
+	}
+
+	var ret []*signedCertificateTimestamp
+	for !scts.Empty() {
+		var raw cryptobyte.String
+		if !scts.ReadUint16LengthPrefixed(&raw) {
+			return nil, errors.New("malformed SCT")
+		}
+
+		var version, hashAlgorithm, signatureAlgorithm uint8
+		var extensions, sig cryptobyte.String
+		sct := &signedCertificateTimestamp{}
+		if !raw.ReadUint8(&version) ||
+			!raw.ReadBytes(&sct.logID, sha256.Size) ||
+			!raw.ReadUint64(&sct.timestamp) ||
+			!raw.ReadUint16LengthPrefixed(&extensions) ||
+			!raw.ReadUint8(&hashAlgorithm) ||
+			!raw.ReadUint8(&signatureAlgorithm) ||
+			!raw.ReadUint16LengthPrefixed(&sig) ||
+			!raw.Empty() {
+			return nil, errors.New("malformed SCT")
+		}
+		if version != sctVersionV1 {
+			return nil, fmt.Errorf("unsupported SCT version %d", version)
+		}
+		if hashAlgorithm != sctHashAlgorithmSHA256 {
+			return nil, fmt.Errorf("unsupported SCT hash algorithm %d", hashAlgorithm)
+		}
+
+		sct.extensions = extensions
+		sct.signature = sig
+		ret = append(ret, sct)
+	}
+
+	return ret, nil
+}
+
+// removeSCTList returns the TBS certificate without the SCT list extension,
+// i.e. the precertificate TBS the logs signed.
+func removeSCTList(cert *x509.Certificate) ([]byte, error) {
+	var tbs asn1.RawValue
+	if _, err := asn1.Unmarshal(cert.RawTBSCertificate, &tbs); err != nil {
+		return nil, fmt.Errorf("decoding TBS certificate: %w", err)
+	}
+
+	var content bytes.Buffer
+	rest := tbs.Bytes
+	for len(rest) > 0 {
+		var field asn1.RawValue
+		var err error
+		rest, err = asn1.Unmarshal(rest, &field)
+		if err != nil {
+			return nil, fmt.Errorf("decoding TBS certificate: %w", err)
+		}
+
+		// The extensions are the only field with the explicit tag 3.
+		if field.Class != asn1.ClassContextSpecific || field.Tag != 3 {
+			content.Write(field.FullBytes)
+			continue
+		}
+
+		var extensions []asn1.RawValue
+		if _, err := asn1.Unmarshal(field.Bytes, &extensions); err != nil {
+			return nil, fmt.Errorf("decoding extensions: %w", err)
+		}
+
+		kept := make([]asn1.RawValue, 0, len(extensions))
+		for _, extension := range extensions {
+			var oid asn1.ObjectIdentifier
+			if _, err := asn1.Unmarshal(extension.Bytes, &oid); err != nil {
+				return nil, fmt.Errorf("decoding extension ID: %w", err)
+			}
+			if !oid.Equal(oidSCTList) {
+				kept = append(kept, extension)
+			}
+		}
+
+		rawExtensions, err := asn1.Marshal(kept)
+		if err != nil {
+			return nil, fmt.Errorf("encoding extensions: %w", err)
+		}
+		rawField, err := asn1.Marshal(asn1.RawValue{
+			Class:      asn1.ClassContextSpecific,
+			Tag:        3,
+			IsCompound: true,
+			Bytes:      rawExtensions,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("encoding extensions: %w", err)
+		}
+		content.Write(rawField)
+	}
+
+	return asn1.Marshal(asn1.RawValue{
+		Class:      asn1.ClassUniversal,
+		Tag:        asn1.TagSequence,
+		IsCompound: true,
+		Bytes:      content.Bytes(),
+	})
+}
+
+// sctSignedData returns the data signed by a log for a precertificate, RFC
+// 6962 section 3.2.
+func sctSignedData(sct *signedCertificateTimestamp, issuer *x509.Certificate, tbs []byte) []byte {
+	issuerKeyHash := sha256.Sum256(issuer.RawSubjectPublicKeyInfo)
+
+	var b cryptobyte.Builder
+	b.AddUint8(sctVersionV1)
+	b.AddUint8(sctSignatureTypeCert)
+	b.AddUint64(sct.timestamp)
+	b.AddUint16(sctEntryTypePrecert)
+	b.AddBytes(issuerKeyHash[:])
+	b.AddUint24LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddBytes(tbs)
+	})
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddBytes(sct.extensions)
+	})
+
+	return b.BytesOrPanic()
+}
+
+// verifySCT checks that at least one of the certificate transparency logs of
+// the trusted root logged the certificate.
+func (t *trustedRoot) verifySCT(cert, issuer *x509.Certificate) error {
+	var value []byte
+	for _, extension := range cert.Extensions {
+		if extension.Id.Equal(oidSCTList) {
+			value = extension.Value
+			break
+		}
+	}
+	if value == nil {
+		return errors.New("certificate has no signed certificate timestamp")
+	}
+
+	scts, err := parseSCTList(value)
+	if err != nil {
+		return fmt.Errorf("parsing signed certificate timestamps: %w", err)
+	}
+
+	tbs, err := removeSCTList(cert)
+	if err != nil {
+		return fmt.Errorf("crafting precertificate: %w", err)
+	}
+
+	errs := make([]error, 0)
+	for _, sct := range scts {
+		logID := hex.EncodeToString(sct.logID)
+		ctlog, ok := t.ctlogs[logID]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown log %s", logID))
+			continue
+		}
+
+		timestamp := time.UnixMilli(int64(sct.timestamp))
+		if !validAt(ctlog.validFor, timestamp) {
+			errs = append(errs, fmt.Errorf("log %s was not valid at %s", logID, timestamp))
+			continue
+		}
+
+		signedData := sctSignedData(sct, issuer, tbs)
+		if err := ctlog.verifier.VerifySignature(bytes.NewReader(sct.signature), bytes.NewReader(signedData)); err != nil {
+			errs = append(errs, fmt.Errorf("verifying timestamp of log %s: %w", logID, err))
+			continue
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("no valid signed certificate timestamp: %w", errors.Join(errs...))
+}
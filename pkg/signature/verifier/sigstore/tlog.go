@@ -0,0 +1,271 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sigstore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+
+	rekorpb "github.com/sigstore/protobuf-specs/gen/pb-go/rekor/v1"
+)
+
+// setPayload is the payload of the signed entry timestamp. Fields are sorted
+// to produce the canonical JSON signed by Rekor.
+type setPayload struct {
+	Body           string `json:"body"`
+	IntegratedTime int64  `json:"integratedTime"`
+	LogID          string `json:"logID"`
+	LogIndex       int64  `json:"logIndex"`
+}
+
+// dsseEntry is the subset of a Rekor dsse v0.0.1 entry we check.
+type dsseEntry struct {
+	Kind       string `json:"kind"`
+	APIVersion string `json:"apiVersion"`
+	Spec       struct {
+		PayloadHash struct {
+			Algorithm string `json:"algorithm"`
+			Value     string `json:"value"`
+		} `json:"payloadHash"`
+		Signatures []struct {
+			Signature string `json:"signature"`
+			Verifier  string `json:"verifier"`
+		} `json:"signatures"`
+	} `json:"spec"`
+}
+
+// verifyTlogEntry checks the entry was added to one of the transparency logs
+// of the trusted root and returns the time it was integrated at.
+func (t *trustedRoot) verifyTlogEntry(entry *rekorpb.TransparencyLogEntry) (time.Time, error) {
+	body := entry.GetCanonicalizedBody()
+	if len(body) == 0 {
+		return time.Time{}, errors.New("entry has no body")
+	}
+
+	logID := hex.EncodeToString(entry.GetLogId().GetKeyId())
+	tlog, ok := t.tlogs[logID]
+	if !ok {
+		return time.Time{}, fmt.Errorf("unknown log %s", logID)
+	}
+
+	// The integrated time is only signed by the signed entry timestamp, the
+	// inclusion proof alone doesn't give a trusted time to check the
+	// short-lived certificate against.
+	set := entry.GetInclusionPromise().GetSignedEntryTimestamp()
+	if len(set) == 0 {
+		return time.Time{}, errors.New("entry has no signed entry timestamp")
+	}
+
+	payload, err := json.Marshal(setPayload{
+		Body:           base64.StdEncoding.EncodeToString(body),
+		IntegratedTime: entry.GetIntegratedTime(),
+		LogID:          logID,
+		LogIndex:       entry.GetLogIndex(),
+	})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("encoding signed entry timestamp payload: %w", err)
+	}
+
+	if err := tlog.verifier.VerifySignature(bytes.NewReader(set), bytes.NewReader(payload)); err != nil {
+		return time.Time{}, fmt.Errorf("verifying signed entry timestamp: %w", err)
+	}
+
+	integratedTime := time.Unix(entry.GetIntegratedTime(), 0)
+	if !validAt(tlog.validFor, integratedTime) {
+		return time.Time{}, fmt.Errorf("log %s was not valid at %s", logID, integratedTime)
+	}
+
+	if proof := entry.GetInclusionProof(); proof != nil {
+		if err := verifyInclusionProof(proof, body, tlog, logID); err != nil {
+			return time.Time{}, fmt.Errorf("verifying inclusion proof: %w", err)
+		}
+	}
+
+	return integratedTime, nil
+}
+
+func hashLeaf(leaf []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0})
+	h.Write(leaf)
+	return h.Sum(nil)
+}
+
+func hashChildren(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{1})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// rootFromInclusionProof computes the root of a Merkle tree from the inclusion
+// proof of one of its leaves, RFC 6962 section 2.1.1.
+func rootFromInclusionProof(index, size uint64, leafHash []byte, proof [][]byte) ([]byte, error) {
+	if index >= size {
+		return nil, fmt.Errorf("index %d is beyond tree size %d", index, size)
+	}
+
+	// The proof is made of the hashes of the subtrees below the point where
+	// the path of the leaf diverges from the path of the last leaf, then of
+	// the hashes of the left borders above it.
+	inner := bits.Len64(index ^ (size - 1))
+	border := bits.OnesCount64(index >> inner)
+	if len(proof) != inner+border {
+		return nil, fmt.Errorf("wrong proof size: expected %d, got %d", inner+border, len(proof))
+	}
+
+	hash := leafHash
+	for i, sibling := range proof[:inner] {
+		if (index>>i)&1 == 0 {
+			hash = hashChildren(hash, sibling)
+		} else {
+			hash = hashChildren(sibling, hash)
+		}
+	}
+	for _, sibling := range proof[inner:] {
+		hash = hashChildren(sibling, hash)
+	}
+
+	return hash, nil
+}
+
+func verifyInclusionProof(proof *rekorpb.InclusionProof, body []byte, tlog *transparencyLog, logID string) error {
+	if proof.GetLogIndex() < 0 || proof.GetTreeSize() < 0 {
+		return errors.New("invalid log index or tree size")
+	}
+
+	root, err := rootFromInclusionProof(uint64(proof.GetLogIndex()), uint64(proof.GetTreeSize()), hashLeaf(body), proof.GetHashes())
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(root, proof.GetRootHash()) {
+		return errors.New("computed root hash does not match the proof one")
+	}
+
+	return verifyCheckpoint(proof.GetCheckpoint().GetEnvelope(), proof, tlog, logID)
+}
+
+// verifyCheckpoint checks the checkpoint, a signed note committing to the
+// size and the root hash of the log, was signed by the log.
+func verifyCheckpoint(envelope string, proof *rekorpb.InclusionProof, tlog *transparencyLog, logID string) error {
+	text, signatures, found := strings.Cut(envelope, "\n\n")
+	if !found {
+		return errors.New("malformed checkpoint")
+	}
+	text += "\n"
+
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	if len(lines) < 3 {
+		return errors.New("malformed checkpoint")
+	}
+	size, err := strconv.ParseInt(lines[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("parsing checkpoint size: %w", err)
+	}
+	if size != proof.GetTreeSize() {
+		return fmt.Errorf("checkpoint size %d does not match tree size %d", size, proof.GetTreeSize())
+	}
+	root, err := base64.StdEncoding.DecodeString(lines[2])
+	if err != nil {
+		return fmt.Errorf("decoding checkpoint root hash: %w", err)
+	}
+	if !bytes.Equal(root, proof.GetRootHash()) {
+		return errors.New("checkpoint root hash does not match the proof one")
+	}
+
+	keyHint, err := hex.DecodeString(logID[:8])
+	if err != nil {
+		return fmt.Errorf("decoding key hint: %w", err)
+	}
+
+	errs := make([]error, 0)
+	for _, line := range strings.Split(strings.TrimSuffix(signatures, "\n"), "\n") {
+		// Signature lines are "— <name> <base64(key hint || signature)>".
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[0] != "—" {
+			errs = append(errs, fmt.Errorf("malformed signature line %q", line))
+			continue
+		}
+
+		sig, err := base64.StdEncoding.DecodeString(fields[2])
+		if err != nil || len(sig) <= len(keyHint) {
+			errs = append(errs, fmt.Errorf("malformed signature of %q", fields[1]))
+			continue
+		}
+		if !bytes.Equal(sig[:len(keyHint)], keyHint) {
+			continue
+		}
+
+		err = tlog.verifier.VerifySignature(bytes.NewReader(sig[len(keyHint):]), strings.NewReader(text))
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+
+	return fmt.Errorf("checkpoint not signed by the log: %w", errors.Join(errs...))
+}
+
+// verifyEntryBody checks the entry logged the signature of the bundle, with
+// the certificate of the bundle.
+func verifyEntryBody(entry *rekorpb.TransparencyLogEntry, cert *x509.Certificate, sig, payload []byte) error {
+	kind := entry.GetKindVersion().GetKind()
+	if kind != "dsse" {
+		return fmt.Errorf("unsupported entry kind %q", kind)
+	}
+
+	var body dsseEntry
+	if err := json.Unmarshal(entry.GetCanonicalizedBody(), &body); err != nil {
+		return fmt.Errorf("decoding entry body: %w", err)
+	}
+	if body.Kind != kind {
+		return fmt.Errorf("entry body kind %q does not match entry kind %q", body.Kind, kind)
+	}
+
+	payloadHash := sha256.Sum256(payload)
+	if body.Spec.PayloadHash.Algorithm != "sha256" || body.Spec.PayloadHash.Value != hex.EncodeToString(payloadHash[:]) {
+		return errors.New("entry payload hash does not match the bundle payload")
+	}
+
+	for _, signature := range body.Spec.Signatures {
+		entrySig, err := base64.StdEncoding.DecodeString(signature.Signature)
+		if err != nil || !bytes.Equal(entrySig, sig) {
+			continue
+		}
+
+		verifier, err := base64.StdEncoding.DecodeString(signature.Verifier)
+		if err != nil {
+			continue
+		}
+		block, _ := pem.Decode(verifier)
+		if block != nil && bytes.Equal(block.Bytes, cert.Raw) {
+			return nil
+		}
+	}
+
+	return errors.New("entry does not contain the bundle signature and certificate")
+}
@@ -0,0 +1,160 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sigstore
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	commonpb "github.com/sigstore/protobuf-specs/gen/pb-go/common/v1"
+	trustrootpb "github.com/sigstore/protobuf-specs/gen/pb-go/trustroot/v1"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// certificateAuthority is a Fulcio instance of the trusted root.
+type certificateAuthority struct {
+	root          *x509.Certificate
+	intermediates []*x509.Certificate
+	validFor      *commonpb.TimeRange
+}
+
+// transparencyLog is a Rekor or a certificate transparency log instance of the
+// trusted root. Logs are identified by the SHA-256 of their public key.
+type transparencyLog struct {
+	verifier signature.Verifier
+	validFor *commonpb.TimeRange
+}
+
+type trustedRoot struct {
+	certificateAuthorities []*certificateAuthority
+	tlogs                  map[string]*transparencyLog
+	ctlogs                 map[string]*transparencyLog
+}
+
+// validAt returns whether t is in the given time range. A missing range or a
+// missing end means the entity is still valid.
+func validAt(validFor *commonpb.TimeRange, t time.Time) bool {
+	if validFor == nil {
+		return true
+	}
+	if start := validFor.GetStart(); start != nil && t.Before(start.AsTime()) {
+		return false
+	}
+	if end := validFor.GetEnd(); end != nil && t.After(end.AsTime()) {
+		return false
+	}
+	return true
+}
+
+func loadTransparencyLogs(instances []*trustrootpb.TransparencyLogInstance) (map[string]*transparencyLog, error) {
+	logs := make(map[string]*transparencyLog, len(instances))
+	for _, instance := range instances {
+		rawKey := instance.GetPublicKey().GetRawBytes()
+		if len(rawKey) == 0 {
+			return nil, fmt.Errorf("log %q has no public key", instance.GetBaseUrl())
+		}
+
+		pub, err := x509.ParsePKIXPublicKey(rawKey)
+		if err != nil {
+			return nil, fmt.Errorf("parsing public key of log %q: %w", instance.GetBaseUrl(), err)
+		}
+
+		verifier, err := signature.LoadVerifier(pub, crypto.SHA256)
+		if err != nil {
+			return nil, fmt.Errorf("loading verifier of log %q: %w", instance.GetBaseUrl(), err)
+		}
+
+		// The log ID is defined as the SHA-256 of the DER public key, don't
+		// trust the one given in the trusted root.
+		logID := sha256.Sum256(rawKey)
+		logs[hex.EncodeToString(logID[:])] = &transparencyLog{
+			verifier: verifier,
+			validFor: instance.GetPublicKey().GetValidFor(),
+		}
+	}
+
+	return logs, nil
+}
+
+func loadCertificateAuthorities(authorities []*trustrootpb.CertificateAuthority) ([]*certificateAuthority, error) {
+	cas := make([]*certificateAuthority, 0, len(authorities))
+	for _, authority := range authorities {
+		rawCerts := authority.GetCertChain().GetCertificates()
+		if len(rawCerts) == 0 {
+			return nil, fmt.Errorf("certificate authority %q has no certificate", authority.GetUri())
+		}
+
+		certs := make([]*x509.Certificate, 0, len(rawCerts))
+		for _, rawCert := range rawCerts {
+			cert, err := x509.ParseCertificate(rawCert.GetRawBytes())
+			if err != nil {
+				return nil, fmt.Errorf("parsing certificate of %q: %w", authority.GetUri(), err)
+			}
+			certs = append(certs, cert)
+		}
+
+		// The chain goes from the intermediates to the root.
+		cas = append(cas, &certificateAuthority{
+			root:          certs[len(certs)-1],
+			intermediates: certs[:len(certs)-1],
+			validFor:      authority.GetValidFor(),
+		})
+	}
+
+	return cas, nil
+}
+
+// parseTrustedRoot parses a trusted root in the format distributed by the
+// Sigstore TUF repository, i.e. trusted_root.json.
+func parseTrustedRoot(data string) (*trustedRoot, error) {
+	pb := &trustrootpb.TrustedRoot{}
+	if err := protojson.Unmarshal([]byte(data), pb); err != nil {
+		return nil, fmt.Errorf("decoding trusted root: %w", err)
+	}
+
+	if len(pb.GetCertificateAuthorities()) == 0 {
+		return nil, errors.New("trusted root has no certificate authority")
+	}
+	if len(pb.GetTlogs()) == 0 {
+		return nil, errors.New("trusted root has no transparency log")
+	}
+
+	cas, err := loadCertificateAuthorities(pb.GetCertificateAuthorities())
+	if err != nil {
+		return nil, fmt.Errorf("loading certificate authorities: %w", err)
+	}
+
+	tlogs, err := loadTransparencyLogs(pb.GetTlogs())
+	if err != nil {
+		return nil, fmt.Errorf("loading transparency logs: %w", err)
+	}
+
+	ctlogs, err := loadTransparencyLogs(pb.GetCtlogs())
+	if err != nil {
+		return nil, fmt.Errorf("loading certificate transparency logs: %w", err)
+	}
+
+	return &trustedRoot{
+		certificateAuthorities: cas,
+		tlogs:                  tlogs,
+		ctlogs:                 ctlogs,
+	}, nil
+}
@@ -0,0 +1,393 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sigstore verifies images signed keylessly with Sigstore, i.e. with
+// a short-lived Fulcio certificate whose use was logged in Rekor. Bundles are
+// verified offline against a trusted root given by the user.
+package sigstore
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+	sigstorebundle "github.com/sigstore/protobuf-specs/gen/pb-go/bundle/v1"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"google.golang.org/protobuf/encoding/protojson"
+	"oras.land/oras-go/v2"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/signature/helpers"
+	signatureformat "github.com/inspektor-gadget/inspektor-gadget/pkg/signature/verifier/cosign/signature-format"
+)
+
+var (
+	// oidIssuerV2 is the Fulcio extension containing the OIDC issuer, as a DER
+	// encoded UTF8String.
+	oidIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+	// oidIssuer is the deprecated Fulcio extension containing the OIDC issuer
+	// as raw bytes.
+	oidIssuer = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+)
+
+type VerifierOptions struct {
+	// TrustedRoot is the content of a trusted_root.json file, as distributed
+	// by the Sigstore TUF repository.
+	TrustedRoot string
+	// PolicyDocument is a JSON document containing the identity policies.
+	PolicyDocument string
+}
+
+// Policy constrains the certificate identities allowed to sign the images
+// of the repositories matching Repository.
+type Policy struct {
+	// Repository is a glob, as understood by path.Match, matched against the
+	// image repository, e.g. ghcr.io/my-org/gadgets/*.
+	Repository string `json:"repository"`
+	// Identity is the expected subject alternative name of the certificate,
+	// e.g. the URI of the workflow which signed the image.
+	Identity       string `json:"identity,omitempty"`
+	IdentityRegexp string `json:"identityRegexp,omitempty"`
+	// Issuer is the expected OIDC issuer of the certificate, e.g.
+	// https://token.actions.githubusercontent.com.
+	Issuer       string `json:"issuer,omitempty"`
+	IssuerRegexp string `json:"issuerRegexp,omitempty"`
+}
+
+type PolicyDocument struct {
+	Policies []Policy `json:"policies"`
+}
+
+type policy struct {
+	Policy
+	identity *regexp.Regexp
+	issuer   *regexp.Regexp
+}
+
+type Verifier struct {
+	trustedRoot *trustedRoot
+	policies    []*policy
+}
+
+// compileMatcher returns a regexp matching exactly value or re.
+func compileMatcher(value, re string) (*regexp.Regexp, error) {
+	if value != "" && re != "" {
+		return nil, errors.New("both a value and a regexp given")
+	}
+	if value != "" {
+		re = regexp.QuoteMeta(value)
+	}
+	if re == "" {
+		return nil, errors.New("no value nor regexp given")
+	}
+
+	return regexp.Compile("^(?:" + re + ")$")
+}
+
+func parsePolicyDocument(document string) ([]*policy, error) {
+	doc := &PolicyDocument{}
+	if err := json.Unmarshal([]byte(document), doc); err != nil {
+		return nil, fmt.Errorf("decoding policy document: %w", err)
+	}
+
+	if len(doc.Policies) == 0 {
+		return nil, errors.New("policy document has no policy")
+	}
+
+	policies := make([]*policy, 0, len(doc.Policies))
+	for _, p := range doc.Policies {
+		if _, err := path.Match(p.Repository, ""); err != nil || p.Repository == "" {
+			return nil, fmt.Errorf("invalid repository pattern %q", p.Repository)
+		}
+
+		identity, err := compileMatcher(p.Identity, p.IdentityRegexp)
+		if err != nil {
+			return nil, fmt.Errorf("identity of policy for %q: %w", p.Repository, err)
+		}
+
+		issuer, err := compileMatcher(p.Issuer, p.IssuerRegexp)
+		if err != nil {
+			return nil, fmt.Errorf("issuer of policy for %q: %w", p.Repository, err)
+		}
+
+		policies = append(policies, &policy{
+			Policy:   p,
+			identity: identity,
+			issuer:   issuer,
+		})
+	}
+
+	return policies, nil
+}
+
+func NewVerifier(opts VerifierOptions) (*Verifier, error) {
+	if opts.TrustedRoot == "" {
+		return nil, errors.New("no trusted root given")
+	}
+
+	trustedRoot, err := parseTrustedRoot(opts.TrustedRoot)
+	if err != nil {
+		return nil, fmt.Errorf("parsing trusted root: %w", err)
+	}
+
+	policies, err := parsePolicyDocument(opts.PolicyDocument)
+	if err != nil {
+		return nil, fmt.Errorf("parsing policy document: %w", err)
+	}
+
+	return &Verifier{
+		trustedRoot: trustedRoot,
+		policies:    policies,
+	}, nil
+}
+
+func loadBundle(ctx context.Context, imageStore oras.GraphTarget, imageDigest string) (*sigstorebundle.Bundle, error) {
+	format := &signatureformat.BundleFormat{}
+	bundleDigest, err := format.FindSignatureTag(ctx, imageStore, imageDigest)
+	if err != nil {
+		return nil, err
+	}
+
+	_, bundleBytes, err := oras.FetchBytes(ctx, imageStore, bundleDigest, oras.DefaultFetchBytesOptions)
+	if err != nil {
+		return nil, fmt.Errorf("getting bundle bytes: %w", err)
+	}
+
+	bundle := &sigstorebundle.Bundle{}
+	if err := protojson.Unmarshal(bundleBytes, bundle); err != nil {
+		return nil, fmt.Errorf("decoding bundle: %w", err)
+	}
+
+	return bundle, nil
+}
+
+// leafCertificate returns the certificate used to sign the bundle and the
+// intermediates given along it.
+func leafCertificate(bundle *sigstorebundle.Bundle) (*x509.Certificate, []*x509.Certificate, error) {
+	material := bundle.GetVerificationMaterial()
+
+	var rawCerts [][]byte
+	if cert := material.GetCertificate(); cert != nil {
+		rawCerts = append(rawCerts, cert.GetRawBytes())
+	} else {
+		for _, cert := range material.GetX509CertificateChain().GetCertificates() {
+			rawCerts = append(rawCerts, cert.GetRawBytes())
+		}
+	}
+	if len(rawCerts) == 0 {
+		return nil, nil, errors.New("bundle has no certificate, it was not signed keylessly")
+	}
+
+	certs := make([]*x509.Certificate, 0, len(rawCerts))
+	for _, rawCert := range rawCerts {
+		cert, err := x509.ParseCertificate(rawCert)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+
+	return certs[0], certs[1:], nil
+}
+
+// verifyCertificate checks the certificate was issued by one of the
+// certificate authorities of the trusted root and was valid at the given
+// time. It returns the certificate of its issuer.
+func (t *trustedRoot) verifyCertificate(cert *x509.Certificate, intermediates []*x509.Certificate, at time.Time) (*x509.Certificate, error) {
+	errs := make([]error, 0)
+	for _, ca := range t.certificateAuthorities {
+		if !validAt(ca.validFor, at) {
+			continue
+		}
+
+		opts := x509.VerifyOptions{
+			Roots:         x509.NewCertPool(),
+			Intermediates: x509.NewCertPool(),
+			CurrentTime:   at,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		}
+		opts.Roots.AddCert(ca.root)
+		for _, intermediate := range ca.intermediates {
+			opts.Intermediates.AddCert(intermediate)
+		}
+		for _, intermediate := range intermediates {
+			opts.Intermediates.AddCert(intermediate)
+		}
+
+		chains, err := cert.Verify(opts)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if len(chains[0]) < 2 {
+			errs = append(errs, errors.New("certificate is a certificate authority"))
+			continue
+		}
+
+		return chains[0][1], nil
+	}
+
+	return nil, fmt.Errorf("certificate not issued by a trusted certificate authority: %w", errors.Join(errs...))
+}
+
+// certificateIssuer returns the OIDC issuer which authenticated the identity
+// of the certificate.
+func certificateIssuer(cert *x509.Certificate) (string, error) {
+	for _, extension := range cert.Extensions {
+		if extension.Id.Equal(oidIssuerV2) {
+			var issuer string
+			if _, err := asn1.UnmarshalWithParams(extension.Value, &issuer, "utf8"); err != nil {
+				return "", fmt.Errorf("decoding issuer: %w", err)
+			}
+			return issuer, nil
+		}
+	}
+
+	for _, extension := range cert.Extensions {
+		if extension.Id.Equal(oidIssuer) {
+			return string(extension.Value), nil
+		}
+	}
+
+	return "", errors.New("certificate has no issuer")
+}
+
+// checkPolicies checks the certificate identity is allowed by one of the
+// policies of the repository.
+func (v *Verifier) checkPolicies(repository string, cert *x509.Certificate) error {
+	issuer, err := certificateIssuer(cert)
+	if err != nil {
+		return err
+	}
+	identities := cryptoutils.GetSubjectAlternateNames(cert)
+
+	found := false
+	for _, p := range v.policies {
+		if matched, _ := path.Match(p.Repository, repository); !matched {
+			continue
+		}
+		found = true
+
+		if !p.issuer.MatchString(issuer) {
+			continue
+		}
+		for _, identity := range identities {
+			if p.identity.MatchString(identity) {
+				return nil
+			}
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("no policy for repository %q", repository)
+	}
+
+	return fmt.Errorf("identity %q issued by %q is not allowed for repository %q", identities, issuer, repository)
+}
+
+func (v *Verifier) Verify(ctx context.Context, imageStore oras.GraphTarget, ref reference.Named) error {
+	imageDigest, err := helpers.GetImageDigest(ctx, imageStore, ref.String())
+	if err != nil {
+		return fmt.Errorf("getting image digest: %w", err)
+	}
+
+	bundle, err := loadBundle(ctx, imageStore, imageDigest)
+	if err != nil {
+		return fmt.Errorf("loading bundle: %w", err)
+	}
+
+	envelope := bundle.GetDsseEnvelope()
+	if envelope == nil {
+		return errors.New("DSSE envelope not found in bundle")
+	}
+	if len(envelope.GetSignatures()) != 1 {
+		return fmt.Errorf("wrong number of signatures: expected 1, got %d", len(envelope.GetSignatures()))
+	}
+	sig := envelope.GetSignatures()[0].GetSig()
+
+	cert, intermediates, err := leafCertificate(bundle)
+	if err != nil {
+		return err
+	}
+
+	// The transparency log gives the trusted time at which the short-lived
+	// certificate must have been valid.
+	entries := bundle.GetVerificationMaterial().GetTlogEntries()
+	if len(entries) == 0 {
+		return errors.New("bundle has no transparency log entry")
+	}
+
+	var integratedTime time.Time
+	errs := make([]error, 0)
+	for _, entry := range entries {
+		if err := verifyEntryBody(entry, cert, sig, envelope.GetPayload()); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		integratedTime, err = v.trustedRoot.verifyTlogEntry(entry)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		break
+	}
+	if integratedTime.IsZero() {
+		return fmt.Errorf("no valid transparency log entry: %w", errors.Join(errs...))
+	}
+
+	issuer, err := v.trustedRoot.verifyCertificate(cert, intermediates, integratedTime)
+	if err != nil {
+		return fmt.Errorf("verifying certificate: %w", err)
+	}
+
+	if len(v.trustedRoot.ctlogs) > 0 {
+		if err := v.trustedRoot.verifySCT(cert, issuer); err != nil {
+			return fmt.Errorf("verifying certificate transparency: %w", err)
+		}
+	}
+
+	verifier, err := signature.LoadVerifier(cert.PublicKey, crypto.SHA256)
+	if err != nil {
+		return fmt.Errorf("loading verifier: %w", err)
+	}
+
+	pae := dsse.PAE(envelope.GetPayloadType(), envelope.GetPayload())
+	if err := verifier.VerifySignature(bytes.NewReader(sig), bytes.NewReader(pae)); err != nil {
+		return fmt.Errorf("the image was not signed by the certificate: %w", err)
+	}
+
+	if err := v.checkPolicies(ref.Name(), cert); err != nil {
+		return fmt.Errorf("checking policies: %w", err)
+	}
+
+	// As for cosign, only read the payload once it's confirmed to be signed.
+	format := &signatureformat.BundleFormat{}
+	if err := format.CheckPayloadImage(envelope.GetPayload(), imageDigest); err != nil {
+		return fmt.Errorf("checking payload image: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,657 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sigstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/distribution/reference"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+	sigstorebundle "github.com/sigstore/protobuf-specs/gen/pb-go/bundle/v1"
+	commonpb "github.com/sigstore/protobuf-specs/gen/pb-go/common/v1"
+	dssepb "github.com/sigstore/protobuf-specs/gen/pb-go/dsse"
+	rekorpb "github.com/sigstore/protobuf-specs/gen/pb-go/rekor/v1"
+	trustrootpb "github.com/sigstore/protobuf-specs/gen/pb-go/trustroot/v1"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/cryptobyte"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/oci"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/signature/helpers"
+)
+
+const (
+	testIdentity = "https://github.com/my-org/gadgets/.github/workflows/release.yml@refs/heads/main"
+	testIssuer   = "https://token.actions.githubusercontent.com"
+)
+
+// testSigstore is a fake Sigstore instance: a Fulcio CA with an
+// intermediate, a Rekor log and a certificate transparency log.
+type testSigstore struct {
+	rootKey         *ecdsa.PrivateKey
+	root            *x509.Certificate
+	intermediateKey *ecdsa.PrivateKey
+	intermediate    *x509.Certificate
+	rekorKey        *ecdsa.PrivateKey
+	ctKey           *ecdsa.PrivateKey
+	notBefore       time.Time
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func newTestSigstore(t *testing.T) *testSigstore {
+	t.Helper()
+
+	s := &testSigstore{
+		rootKey:         newKey(t),
+		intermediateKey: newKey(t),
+		rekorKey:        newKey(t),
+		ctKey:           newKey(t),
+		notBefore:       time.Now().Add(-time.Hour).Truncate(time.Second),
+	}
+
+	rootTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "sigstore", Organization: []string{"test"}},
+		NotBefore:             s.notBefore,
+		NotAfter:              s.notBefore.Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	rawRoot, err := x509.CreateCertificate(rand.Reader, rootTemplate, rootTemplate, s.rootKey.Public(), s.rootKey)
+	require.NoError(t, err)
+	s.root, err = x509.ParseCertificate(rawRoot)
+	require.NoError(t, err)
+
+	intermediateTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "sigstore-intermediate", Organization: []string{"test"}},
+		NotBefore:             s.notBefore,
+		NotAfter:              s.notBefore.Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLen:            0,
+		MaxPathLenZero:        true,
+	}
+	rawIntermediate, err := x509.CreateCertificate(rand.Reader, intermediateTemplate, s.root, s.intermediateKey.Public(), s.rootKey)
+	require.NoError(t, err)
+	s.intermediate, err = x509.ParseCertificate(rawIntermediate)
+	require.NoError(t, err)
+
+	return s
+}
+
+func logID(t *testing.T, key *ecdsa.PrivateKey) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+	id := sha256.Sum256(der)
+	return id[:]
+}
+
+func transparencyLogInstance(t *testing.T, key *ecdsa.PrivateKey, notBefore time.Time) *trustrootpb.TransparencyLogInstance {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+	return &trustrootpb.TransparencyLogInstance{
+		BaseUrl:       "https://log.test",
+		HashAlgorithm: commonpb.HashAlgorithm_SHA2_256,
+		PublicKey: &commonpb.PublicKey{
+			RawBytes:   der,
+			KeyDetails: commonpb.PublicKeyDetails_PKIX_ECDSA_P256_SHA_256,
+			ValidFor:   &commonpb.TimeRange{Start: timestamppb.New(notBefore)},
+		},
+		LogId: &commonpb.LogId{KeyId: logID(t, key)},
+	}
+}
+
+func (s *testSigstore) trustedRoot(t *testing.T) string {
+	t.Helper()
+
+	root := &trustrootpb.TrustedRoot{
+		MediaType: "application/vnd.dev.sigstore.trustedroot+json;version=0.1",
+		CertificateAuthorities: []*trustrootpb.CertificateAuthority{
+			{
+				Uri: "https://fulcio.test",
+				CertChain: &commonpb.X509CertificateChain{
+					Certificates: []*commonpb.X509Certificate{
+						{RawBytes: s.intermediate.Raw},
+						{RawBytes: s.root.Raw},
+					},
+				},
+				ValidFor: &commonpb.TimeRange{Start: timestamppb.New(s.notBefore)},
+			},
+		},
+		Tlogs:  []*trustrootpb.TransparencyLogInstance{transparencyLogInstance(t, s.rekorKey, s.notBefore)},
+		Ctlogs: []*trustrootpb.TransparencyLogInstance{transparencyLogInstance(t, s.ctKey, s.notBefore)},
+	}
+
+	data, err := protojson.Marshal(root)
+	require.NoError(t, err)
+	return string(data)
+}
+
+// sct returns the SCT list extension for a certificate whose TBS, without
+// the extension, is tbs.
+func (s *testSigstore) sct(t *testing.T, tbs []byte) pkix.Extension {
+	t.Helper()
+
+	sct := &signedCertificateTimestamp{
+		logID:     logID(t, s.ctKey),
+		timestamp: uint64(time.Now().UnixMilli()),
+	}
+	digest := sha256.Sum256(sctSignedData(sct, s.intermediate, tbs))
+	sig, err := ecdsa.SignASN1(rand.Reader, s.ctKey, digest[:])
+	require.NoError(t, err)
+
+	var b cryptobyte.Builder
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddUint8(sctVersionV1)
+			b.AddBytes(sct.logID)
+			b.AddUint64(sct.timestamp)
+			b.AddUint16(0)
+			b.AddUint8(sctHashAlgorithmSHA256)
+			// ECDSA
+			b.AddUint8(3)
+			b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+				b.AddBytes(sig)
+			})
+		})
+	})
+
+	value, err := asn1.Marshal(b.BytesOrPanic())
+	require.NoError(t, err)
+	return pkix.Extension{Id: oidSCTList, Value: value}
+}
+
+// leaf issues a short-lived certificate for the given identity, as Fulcio
+// does.
+func (s *testSigstore) leaf(t *testing.T, identity, issuer string) (*ecdsa.PrivateKey, *x509.Certificate) {
+	t.Helper()
+
+	key := newKey(t)
+	uri, err := url.Parse(identity)
+	require.NoError(t, err)
+	rawIssuer, err := asn1.MarshalWithParams(issuer, "utf8")
+	require.NoError(t, err)
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:    big.NewInt(now.UnixNano()),
+		NotBefore:       now.Add(-time.Minute),
+		NotAfter:        now.Add(10 * time.Minute),
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		URIs:            []*url.URL{uri},
+		ExtraExtensions: []pkix.Extension{{Id: oidIssuerV2, Value: rawIssuer}},
+	}
+
+	// The certificate transparency log signs the certificate without the SCT
+	// extension, which is added last.
+	rawPrecert, err := x509.CreateCertificate(rand.Reader, template, s.intermediate, key.Public(), s.intermediateKey)
+	require.NoError(t, err)
+	precert, err := x509.ParseCertificate(rawPrecert)
+	require.NoError(t, err)
+
+	template.ExtraExtensions = append(template.ExtraExtensions, s.sct(t, precert.RawTBSCertificate))
+	rawCert, err := x509.CreateCertificate(rand.Reader, template, s.intermediate, key.Public(), s.intermediateKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(rawCert)
+	require.NoError(t, err)
+
+	return key, cert
+}
+
+// merkleTreeHash and inclusionPath are the reference implementations of RFC
+// 6962 section 2.1.
+func merkleTreeHash(leaves [][]byte) []byte {
+	if len(leaves) == 1 {
+		return hashLeaf(leaves[0])
+	}
+	k := 1
+	for k*2 < len(leaves) {
+		k *= 2
+	}
+	return hashChildren(merkleTreeHash(leaves[:k]), merkleTreeHash(leaves[k:]))
+}
+
+func inclusionPath(m int, leaves [][]byte) [][]byte {
+	if len(leaves) == 1 {
+		return nil
+	}
+	k := 1
+	for k*2 < len(leaves) {
+		k *= 2
+	}
+	if m < k {
+		return append(inclusionPath(m, leaves[:k]), merkleTreeHash(leaves[k:]))
+	}
+	return append(inclusionPath(m-k, leaves[k:]), merkleTreeHash(leaves[:k]))
+}
+
+// tlogEntry logs the DSSE signature in the fake Rekor.
+func (s *testSigstore) tlogEntry(t *testing.T, cert *x509.Certificate, sig, payload []byte) *rekorpb.TransparencyLogEntry {
+	t.Helper()
+
+	payloadHash := sha256.Sum256(payload)
+	body, err := json.Marshal(map[string]any{
+		"apiVersion": "0.0.1",
+		"kind":       "dsse",
+		"spec": map[string]any{
+			"payloadHash": map[string]string{
+				"algorithm": "sha256",
+				"value":     hex.EncodeToString(payloadHash[:]),
+			},
+			"signatures": []map[string]string{
+				{
+					"signature": base64.StdEncoding.EncodeToString(sig),
+					"verifier":  base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})),
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	id := logID(t, s.rekorKey)
+	entry := &rekorpb.TransparencyLogEntry{
+		LogIndex:          42,
+		LogId:             &commonpb.LogId{KeyId: id},
+		KindVersion:       &rekorpb.KindVersion{Kind: "dsse", Version: "0.0.1"},
+		IntegratedTime:    time.Now().Unix(),
+		CanonicalizedBody: body,
+	}
+
+	setData, err := json.Marshal(setPayload{
+		Body:           base64.StdEncoding.EncodeToString(body),
+		IntegratedTime: entry.IntegratedTime,
+		LogID:          hex.EncodeToString(id),
+		LogIndex:       entry.LogIndex,
+	})
+	require.NoError(t, err)
+	digest := sha256.Sum256(setData)
+	set, err := ecdsa.SignASN1(rand.Reader, s.rekorKey, digest[:])
+	require.NoError(t, err)
+	entry.InclusionPromise = &rekorpb.InclusionPromise{SignedEntryTimestamp: set}
+
+	leaves := [][]byte{[]byte("first"), body, []byte("third")}
+	root := merkleTreeHash(leaves)
+	checkpoint := fmt.Sprintf("rekor.test - 1\n%d\n%s\n", len(leaves), base64.StdEncoding.EncodeToString(root))
+	digest = sha256.Sum256([]byte(checkpoint))
+	checkpointSig, err := ecdsa.SignASN1(rand.Reader, s.rekorKey, digest[:])
+	require.NoError(t, err)
+	checkpoint += "\n— rekor.test " + base64.StdEncoding.EncodeToString(append(id[:4:4], checkpointSig...)) + "\n"
+
+	entry.InclusionProof = &rekorpb.InclusionProof{
+		LogIndex:   1,
+		RootHash:   root,
+		TreeSize:   int64(len(leaves)),
+		Hashes:     inclusionPath(1, leaves),
+		Checkpoint: &rekorpb.Checkpoint{Envelope: checkpoint},
+	}
+
+	return entry
+}
+
+// sign pushes an image to the store and signs it keylessly as cosign does,
+// i.e. with an in-toto statement in a DSSE envelope stored in a bundle
+// referring to the image.
+func (s *testSigstore) sign(t *testing.T, store oras.Target, image, identity, issuer string, modifyBundle func(*sigstorebundle.Bundle)) {
+	t.Helper()
+
+	ctx := context.Background()
+	imageDesc, err := oras.PackManifest(ctx, store, oras.PackManifestVersion1_1, "application/vnd.gadget.test", oras.PackManifestOptions{})
+	require.NoError(t, err)
+	require.NoError(t, store.Tag(ctx, imageDesc, image))
+
+	payload, err := json.Marshal(map[string]any{
+		"_type":         "https://in-toto.io/Statement/v1",
+		"predicateType": "https://sigstore.dev/cosign/sign/v1",
+		"predicate":     map[string]any{},
+		"subject": []map[string]any{
+			{
+				"name":   image,
+				"digest": map[string]string{"sha256": imageDesc.Digest.Encoded()},
+			},
+		},
+	})
+	require.NoError(t, err)
+	payloadType := "application/vnd.in-toto+json"
+
+	key, cert := s.leaf(t, identity, issuer)
+	digest := sha256.Sum256(dsse.PAE(payloadType, payload))
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+
+	bundle := &sigstorebundle.Bundle{
+		MediaType: helpers.BundleV03MediaType,
+		VerificationMaterial: &sigstorebundle.VerificationMaterial{
+			Content: &sigstorebundle.VerificationMaterial_Certificate{
+				Certificate: &commonpb.X509Certificate{RawBytes: cert.Raw},
+			},
+			TlogEntries: []*rekorpb.TransparencyLogEntry{s.tlogEntry(t, cert, sig, payload)},
+		},
+		Content: &sigstorebundle.Bundle_DsseEnvelope{
+			DsseEnvelope: &dssepb.Envelope{
+				Payload:     payload,
+				PayloadType: payloadType,
+				Signatures:  []*dssepb.Signature{{Sig: sig}},
+			},
+		},
+	}
+	if modifyBundle != nil {
+		modifyBundle(bundle)
+	}
+
+	bundleBytes, err := protojson.Marshal(bundle)
+	require.NoError(t, err)
+	bundleDesc := content.NewDescriptorFromBytes(helpers.BundleV03MediaType, bundleBytes)
+	require.NoError(t, store.Push(ctx, bundleDesc, bytes.NewReader(bundleBytes)))
+
+	_, err = oras.PackManifest(ctx, store, oras.PackManifestVersion1_1, helpers.BundleV03MediaType, oras.PackManifestOptions{
+		Subject: &imageDesc,
+		Layers:  []ocispec.Descriptor{bundleDesc},
+	})
+	require.NoError(t, err)
+}
+
+func policyDocument(t *testing.T, policies ...Policy) string {
+	t.Helper()
+
+	data, err := json.Marshal(PolicyDocument{Policies: policies})
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestNewVerifier(t *testing.T) {
+	t.Parallel()
+
+	s := newTestSigstore(t)
+	trustedRoot := s.trustedRoot(t)
+	goodPolicy := Policy{Repository: "ghcr.io/my-org/*", Identity: testIdentity, Issuer: testIssuer}
+
+	tests := map[string]struct {
+		opts      VerifierOptions
+		shouldErr bool
+	}{
+		"no_trusted_root": {
+			opts:      VerifierOptions{PolicyDocument: policyDocument(t, goodPolicy)},
+			shouldErr: true,
+		},
+		"malformed_trusted_root": {
+			opts:      VerifierOptions{TrustedRoot: "foobar", PolicyDocument: policyDocument(t, goodPolicy)},
+			shouldErr: true,
+		},
+		"no_policy": {
+			opts:      VerifierOptions{TrustedRoot: trustedRoot, PolicyDocument: policyDocument(t)},
+			shouldErr: true,
+		},
+		"policy_without_identity": {
+			opts: VerifierOptions{
+				TrustedRoot:    trustedRoot,
+				PolicyDocument: policyDocument(t, Policy{Repository: "ghcr.io/my-org/*", Issuer: testIssuer}),
+			},
+			shouldErr: true,
+		},
+		"policy_with_identity_and_regexp": {
+			opts: VerifierOptions{
+				TrustedRoot: trustedRoot,
+				PolicyDocument: policyDocument(t, Policy{
+					Repository:     "ghcr.io/my-org/*",
+					Identity:       testIdentity,
+					IdentityRegexp: ".*",
+					Issuer:         testIssuer,
+				}),
+			},
+			shouldErr: true,
+		},
+		"policy_with_malformed_repository": {
+			opts: VerifierOptions{
+				TrustedRoot:    trustedRoot,
+				PolicyDocument: policyDocument(t, Policy{Repository: "[", Identity: testIdentity, Issuer: testIssuer}),
+			},
+			shouldErr: true,
+		},
+		"correct": {
+			opts: VerifierOptions{TrustedRoot: trustedRoot, PolicyDocument: policyDocument(t, goodPolicy)},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewVerifier(test.opts)
+			if test.shouldErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	s := newTestSigstore(t)
+	other := newTestSigstore(t)
+	image := "ghcr.io/my-org/gadgets/trace_exec:latest"
+	goodPolicy := Policy{Repository: "ghcr.io/my-org/gadgets/*", Identity: testIdentity, Issuer: testIssuer}
+
+	// Same Fulcio and Rekor, but another certificate transparency log
+	otherCTLog := *s
+	otherCTLog.ctKey = other.ctKey
+
+	tests := map[string]struct {
+		trustedRoot  string
+		policies     []Policy
+		identity     string
+		issuer       string
+		modifyBundle func(*sigstorebundle.Bundle)
+		shouldErr    bool
+	}{
+		"good_identity": {
+			policies: []Policy{goodPolicy},
+		},
+		"good_identity_regexp": {
+			policies: []Policy{{
+				Repository:     "ghcr.io/my-org/*/*",
+				IdentityRegexp: `https://github\.com/my-org/.*`,
+				IssuerRegexp:   `https://token\.actions\.githubusercontent\.com`,
+			}},
+		},
+		"several_policies": {
+			policies: []Policy{
+				{Repository: "ghcr.io/my-org/gadgets/*", Identity: "someone@my-org.io", Issuer: "https://accounts.google.com"},
+				goodPolicy,
+			},
+		},
+		"wrong_identity": {
+			policies:  []Policy{goodPolicy},
+			identity:  "https://github.com/my-fork/gadgets/.github/workflows/release.yml@refs/heads/main",
+			shouldErr: true,
+		},
+		"identity_regexp_is_anchored": {
+			policies: []Policy{{
+				Repository:     "ghcr.io/my-org/gadgets/*",
+				IdentityRegexp: `https://github\.com/my-org/gadgets/`,
+				Issuer:         testIssuer,
+			}},
+			shouldErr: true,
+		},
+		"wrong_issuer": {
+			policies:  []Policy{goodPolicy},
+			issuer:    "https://token.evil.com",
+			shouldErr: true,
+		},
+		"no_policy_for_repository": {
+			policies:  []Policy{{Repository: "ghcr.io/other-org/*", Identity: testIdentity, Issuer: testIssuer}},
+			shouldErr: true,
+		},
+		"untrusted_certificate_authority": {
+			trustedRoot: other.trustedRoot(t),
+			policies:    []Policy{goodPolicy},
+			shouldErr:   true,
+		},
+		"untrusted_certificate_transparency_log": {
+			trustedRoot: otherCTLog.trustedRoot(t),
+			policies:    []Policy{goodPolicy},
+			shouldErr:   true,
+		},
+		"tampered_signature": {
+			policies: []Policy{goodPolicy},
+			modifyBundle: func(b *sigstorebundle.Bundle) {
+				b.GetDsseEnvelope().Payload = []byte(`{"_type":"https://in-toto.io/Statement/v1"}`)
+			},
+			shouldErr: true,
+		},
+		"tampered_signed_entry_timestamp": {
+			policies: []Policy{goodPolicy},
+			modifyBundle: func(b *sigstorebundle.Bundle) {
+				b.GetVerificationMaterial().GetTlogEntries()[0].IntegratedTime++
+			},
+			shouldErr: true,
+		},
+		"no_signed_entry_timestamp": {
+			policies: []Policy{goodPolicy},
+			modifyBundle: func(b *sigstorebundle.Bundle) {
+				b.GetVerificationMaterial().GetTlogEntries()[0].InclusionPromise = nil
+			},
+			shouldErr: true,
+		},
+		"wrong_inclusion_proof": {
+			policies: []Policy{goodPolicy},
+			modifyBundle: func(b *sigstorebundle.Bundle) {
+				b.GetVerificationMaterial().GetTlogEntries()[0].InclusionProof.LogIndex = 0
+			},
+			shouldErr: true,
+		},
+		"no_tlog_entry": {
+			policies: []Policy{goodPolicy},
+			modifyBundle: func(b *sigstorebundle.Bundle) {
+				b.GetVerificationMaterial().TlogEntries = nil
+			},
+			shouldErr: true,
+		},
+		"signed_with_public_key": {
+			policies: []Policy{goodPolicy},
+			modifyBundle: func(b *sigstorebundle.Bundle) {
+				b.VerificationMaterial.Content = &sigstorebundle.VerificationMaterial_PublicKey{
+					PublicKey: &commonpb.PublicKeyIdentifier{Hint: "foo"},
+				}
+			},
+			shouldErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			identity := test.identity
+			if identity == "" {
+				identity = testIdentity
+			}
+			issuer := test.issuer
+			if issuer == "" {
+				issuer = testIssuer
+			}
+			trustedRoot := test.trustedRoot
+			if trustedRoot == "" {
+				trustedRoot = s.trustedRoot(t)
+			}
+
+			store, err := oci.New(t.TempDir())
+			require.NoError(t, err)
+			s.sign(t, store, image, identity, issuer, test.modifyBundle)
+
+			verifier, err := NewVerifier(VerifierOptions{
+				TrustedRoot:    trustedRoot,
+				PolicyDocument: policyDocument(t, test.policies...),
+			})
+			require.NoError(t, err)
+
+			ref, err := reference.ParseNormalizedNamed(image)
+			require.NoError(t, err)
+
+			err = verifier.Verify(context.Background(), store, ref)
+			if test.shouldErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRootFromInclusionProof(t *testing.T) {
+	t.Parallel()
+
+	var leaves [][]byte
+	for size := 1; size <= 17; size++ {
+		leaves = append(leaves, []byte(fmt.Sprintf("leaf %d", size)))
+		root := merkleTreeHash(leaves)
+
+		for index := range leaves {
+			proof := inclusionPath(index, leaves)
+			computed, err := rootFromInclusionProof(uint64(index), uint64(size), hashLeaf(leaves[index]), proof)
+			require.NoError(t, err)
+			require.Equal(t, root, computed, "size %d, index %d", size, index)
+
+			if len(proof) > 0 {
+				_, err = rootFromInclusionProof(uint64(index), uint64(size), hashLeaf(leaves[index]), proof[1:])
+				require.Error(t, err)
+			}
+		}
+
+		_, err := rootFromInclusionProof(uint64(size), uint64(size), hashLeaf(leaves[0]), nil)
+		require.Error(t, err)
+	}
+}
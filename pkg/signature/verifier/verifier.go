@@ -24,6 +24,7 @@ import (
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/signature/verifier/cosign"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/signature/verifier/notation"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/signature/verifier/sigstore"
 )
 
 type Verifier interface {
@@ -37,6 +38,7 @@ type SignatureVerifier struct {
 type VerifierOptions struct {
 	CosignVerifierOpts   cosign.VerifierOptions
 	NotationVerifierOpts notation.VerifierOptions
+	SigstoreVerifierOpts sigstore.VerifierOptions
 }
 
 func (v *SignatureVerifier) Verify(ctx context.Context, imageStore oras.GraphTarget, ref reference.Named) error {
@@ -78,5 +80,20 @@ func NewSignatureVerifier(opts VerifierOptions) (*SignatureVerifier, error) {
 		ret.verifiers["notation"] = verifier
 	}
 
+	hasTrustedRoot := len(opts.SigstoreVerifierOpts.TrustedRoot) > 0
+	hasPolicyDocument := len(opts.SigstoreVerifierOpts.PolicyDocument) > 0
+	if hasTrustedRoot != hasPolicyDocument {
+		return nil, errors.New("sigstore verification requires both a trusted root and a policy document")
+	}
+
+	if hasTrustedRoot && hasPolicyDocument {
+		verifier, err := sigstore.NewVerifier(opts.SigstoreVerifierOpts)
+		if err != nil {
+			return nil, fmt.Errorf("creating sigstore verifier: %w", err)
+		}
+
+		ret.verifiers["sigstore"] = verifier
+	}
+
 	return ret, nil
 }
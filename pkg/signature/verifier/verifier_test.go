@@ -0,0 +1,57 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/signature/verifier/sigstore"
+)
+
+func TestNewSignatureVerifierSigstore(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		opts      sigstore.VerifierOptions
+		shouldErr bool
+	}{
+		"none": {},
+		"only_trusted_root": {
+			opts:      sigstore.VerifierOptions{TrustedRoot: "{}"},
+			shouldErr: true,
+		},
+		"only_policy_document": {
+			opts:      sigstore.VerifierOptions{PolicyDocument: `{"policies":[]}`},
+			shouldErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			v, err := NewSignatureVerifier(VerifierOptions{SigstoreVerifierOpts: test.opts})
+			if test.shouldErr {
+				require.ErrorContains(t, err, "requires both a trusted root and a policy document")
+				return
+			}
+
+			require.NoError(t, err)
+			require.Empty(t, v.verifiers)
+		})
+	}
+}
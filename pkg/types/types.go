@@ -88,6 +88,8 @@ const (
 	RuntimeNameContainerd RuntimeName = "containerd"
 	RuntimeNameCrio       RuntimeName = "cri-o"
 	RuntimeNamePodman     RuntimeName = "podman"
+	RuntimeNameIncus      RuntimeName = "incus"
+	RuntimeNameNspawn     RuntimeName = "systemd-nspawn"
 	RuntimeNameUnknown    RuntimeName = "unknown"
 )
 
@@ -101,6 +103,10 @@ func String2RuntimeName(name string) RuntimeName {
 		return RuntimeNameCrio
 	case string(RuntimeNamePodman):
 		return RuntimeNamePodman
+	case string(RuntimeNameIncus):
+		return RuntimeNameIncus
+	case string(RuntimeNameNspawn):
+		return RuntimeNameNspawn
 	}
 	return RuntimeNameUnknown
 }